@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"io"
 
-	pairing "github.com/cloudflare/circl/ecc/bls12381"
+	pairing "github.com/katzenpost/circl/ecc/bls12381"
 )
 
 // matrixG1 represents a matrix of G1 elements. They are stored in row-major order.
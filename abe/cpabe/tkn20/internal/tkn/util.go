@@ -6,7 +6,7 @@ import (
 	"fmt"
 	"sort"
 
-	pairing "github.com/cloudflare/circl/ecc/bls12381"
+	pairing "github.com/katzenpost/circl/ecc/bls12381"
 	"golang.org/x/crypto/blake2b"
 )
 
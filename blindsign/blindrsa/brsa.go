@@ -32,8 +32,8 @@ import (
 	"io"
 	"math/big"
 
-	"github.com/cloudflare/circl/blindsign/blindrsa/internal/common"
-	"github.com/cloudflare/circl/blindsign/blindrsa/internal/keys"
+	"github.com/katzenpost/circl/blindsign/blindrsa/internal/common"
+	"github.com/katzenpost/circl/blindsign/blindrsa/internal/keys"
 )
 
 // An randomBRSAVerifier represents a Verifier in the RSA blind signature protocol.
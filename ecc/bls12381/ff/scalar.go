@@ -34,6 +34,14 @@ func (z *Scalar) Inv(x *Scalar)            { z.expVarTime(x, scOrderMinus2[:]) }
 func (z *Scalar) toMont(in *scRaw)         { fiatScMontMul(&z.i, in, &scRSquare) }
 func (z Scalar) fromMont() (out scRaw)     { fiatScMontMul(&out, &z.i, &scMont{1}); return }
 
+// CMov sets z=x if b == 0 and z=y if b == 1. Its behavior is undefined if b takes any other value.
+func (z *Scalar) CMov(x, y *Scalar, b int) {
+	mask := -uint64(b & 0x1)
+	for i := range z.i {
+		z.i[i] = (x.i[i] &^ mask) | (y.i[i] & mask)
+	}
+}
+
 // ScalarOrder is the order of the scalar field of the pairing groups, order is
 // returned as a big-endian slice.
 //
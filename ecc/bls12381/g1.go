@@ -131,6 +131,10 @@ func (g *G1) IsOnG1() bool { return g.isValidProjective() && g.isOnCurve() && g.
 // IsIdentity return true if the point is the identity of G1.
 func (g *G1) IsIdentity() bool { return g.isValidProjective() && (g.z.IsZero() == 1) }
 
+// CMov sets g to P if b == 1, and leaves g unchanged if b == 0. It runs in
+// constant time.
+func (g *G1) CMov(P *G1, b int) { g.cmov(P, b) }
+
 // cmov sets g to P if b == 1
 func (g *G1) cmov(P *G1, b int) {
 	(&g.x).CMov(&g.x, &P.x, b)
@@ -128,6 +128,10 @@ func (g *G2) IsOnG2() bool { return g.isValidProjective() && g.isOnCurve() && g.
 // IsIdentity return true if the point is the identity of G2.
 func (g *G2) IsIdentity() bool { return g.isValidProjective() && (g.z.IsZero() == 1) }
 
+// CMov sets g to P if b == 1, and leaves g unchanged if b == 0. It runs in
+// constant time.
+func (g *G2) CMov(P *G2, b int) { g.cmov(P, b) }
+
 // cmov sets g to P if b == 1
 func (g *G2) cmov(P *G2, b int) {
 	(&g.x).CMov(&g.x, &P.x, b)
@@ -0,0 +1,199 @@
+package secp256k1
+
+import (
+	"encoding/binary"
+	"math/big"
+	"math/bits"
+)
+
+// fp is an element of the prime field GF(p) of the curve, stored as four
+// 64-bit limbs in little-endian order. Elements are always fully reduced.
+type fp [4]uint64
+
+// fpC is 2^256 mod p, used for the reduction.
+const fpC = 0x1000003D1
+
+var (
+	// fpP is the prime p = 2^256 - 2^32 - 977.
+	fpP = fp{0xFFFFFFFEFFFFFC2F, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF}
+	// fpPMinus2 is the exponent p-2 used for inversion.
+	fpPMinus2 = fp{0xFFFFFFFEFFFFFC2D, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF}
+	// fpSqrtExp is the exponent (p+1)/4 used for square roots.
+	fpSqrtExp = fp{0xFFFFFFFFBFFFFF0C, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF, 0x3FFFFFFFFFFFFFFF}
+	// fpOne is the element one.
+	fpOne = fp{1}
+	// fpB is the coefficient b = 7 of the curve.
+	fpB = fp{7}
+	// fpB3 is 3*b, used by the point addition.
+	fpB3 = fp{21}
+)
+
+// setBytes sets z to the big-endian value in b, which must be 32 bytes long,
+// and returns whether it is smaller than p.
+func (z *fp) setBytes(b []byte) bool {
+	for i := range z {
+		z[i] = binary.BigEndian.Uint64(b[24-8*i:])
+	}
+	var borrow uint64
+	for i := range z {
+		_, borrow = bits.Sub64(z[i], fpP[i], borrow)
+	}
+	return borrow == 1
+}
+
+// bytes returns the big-endian encoding of x in 32 bytes.
+func (x *fp) bytes() []byte {
+	b := make([]byte, 32)
+	for i := range x {
+		binary.BigEndian.PutUint64(b[24-8*i:], x[i])
+	}
+	return b
+}
+
+// setBigInt sets z to b mod p.
+func (z *fp) setBigInt(b *big.Int) {
+	if b.Sign() < 0 || b.BitLen() > 256 {
+		b = new(big.Int).Mod(b, params.P)
+	}
+	var buf [32]byte
+	if !z.setBytes(b.FillBytes(buf[:])) {
+		fpSub(z, z, &fpP)
+	}
+}
+
+// bigInt returns x as a big.Int.
+func (x *fp) bigInt() *big.Int { return new(big.Int).SetBytes(x.bytes()) }
+
+// isZero returns 1 if x is zero, and 0 otherwise.
+func (x *fp) isZero() int {
+	v := x[0] | x[1] | x[2] | x[3]
+	return int(1 ^ ((v | -v) >> 63))
+}
+
+// isEqual returns 1 if x == y, and 0 otherwise.
+func (x *fp) isEqual(y *fp) int {
+	var d fp
+	for i := range d {
+		d[i] = x[i] ^ y[i]
+	}
+	return d.isZero()
+}
+
+// cmov sets z to x if b is 1, and leaves it unchanged if b is 0.
+func (z *fp) cmov(x *fp, b int) {
+	mask := -uint64(b)
+	for i := range z {
+		z[i] ^= mask & (z[i] ^ x[i])
+	}
+}
+
+// reduce sets z to x mod p, for x < 2^256 + p.
+func (z *fp) reduce(x *fp, carry uint64) {
+	var d fp
+	var borrow uint64
+	for i := range d {
+		d[i], borrow = bits.Sub64(x[i], fpP[i], borrow)
+	}
+	// x >= p if there was a carry or no borrow.
+	*z = *x
+	z.cmov(&d, int(carry|(borrow^1)))
+}
+
+// fpAdd sets z = x + y.
+func fpAdd(z, x, y *fp) {
+	var s fp
+	var carry uint64
+	for i := range s {
+		s[i], carry = bits.Add64(x[i], y[i], carry)
+	}
+	z.reduce(&s, carry)
+}
+
+// fpSub sets z = x - y.
+func fpSub(z, x, y *fp) {
+	var borrow, carry uint64
+	for i := range z {
+		z[i], borrow = bits.Sub64(x[i], y[i], borrow)
+	}
+	mask := -borrow
+	for i := range z {
+		z[i], carry = bits.Add64(z[i], fpP[i]&mask, carry)
+	}
+}
+
+// fpNeg sets z = -x.
+func fpNeg(z, x *fp) { fpSub(z, &fp{}, x) }
+
+// fpMul sets z = x * y.
+func fpMul(z, x, y *fp) {
+	var t [8]uint64
+	for i := 0; i < 4; i++ {
+		var c uint64
+		for j := 0; j < 4; j++ {
+			hi, lo := bits.Mul64(x[i], y[j])
+			var cc uint64
+			lo, cc = bits.Add64(lo, t[i+j], 0)
+			hi += cc
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			t[i+j] = lo
+			c = hi
+		}
+		t[i+4] = c
+	}
+
+	// As 2^256 = fpC mod p, t = t_lo + t_hi * fpC, which fits in 290 bits.
+	var r fp
+	var c uint64
+	for i := 0; i < 4; i++ {
+		hi, lo := bits.Mul64(t[4+i], fpC)
+		var cc uint64
+		lo, cc = bits.Add64(lo, t[i], 0)
+		hi += cc
+		lo, cc = bits.Add64(lo, c, 0)
+		hi += cc
+		r[i] = lo
+		c = hi
+	}
+
+	// Fold the top limb, then the possible carry of that addition.
+	hi, lo := bits.Mul64(c, fpC)
+	var carry uint64
+	r[0], carry = bits.Add64(r[0], lo, 0)
+	r[1], carry = bits.Add64(r[1], hi, carry)
+	r[2], carry = bits.Add64(r[2], 0, carry)
+	r[3], carry = bits.Add64(r[3], 0, carry)
+	r[0], carry = bits.Add64(r[0], fpC&-carry, 0)
+	r[1], carry = bits.Add64(r[1], 0, carry)
+	r[2], carry = bits.Add64(r[2], 0, carry)
+	r[3], carry = bits.Add64(r[3], 0, carry)
+	z.reduce(&r, carry)
+}
+
+// fpSqr sets z = x^2.
+func fpSqr(z, x *fp) { fpMul(z, x, x) }
+
+// fpExp sets z = x^e, for a public exponent e.
+func fpExp(z, x, e *fp) {
+	r := fpOne
+	for i := 255; i >= 0; i-- {
+		fpSqr(&r, &r)
+		if (e[i/64]>>(i%64))&1 == 1 {
+			fpMul(&r, &r, x)
+		}
+	}
+	*z = r
+}
+
+// fpInv sets z = 1/x, or zero if x is zero.
+func fpInv(z, x *fp) { fpExp(z, x, &fpPMinus2) }
+
+// fpSqrt sets z to a square root of x, and returns 1 if x is a square, or 0
+// otherwise.
+func fpSqrt(z, x *fp) int {
+	var r, r2 fp
+	fpExp(&r, x, &fpSqrtExp)
+	fpSqr(&r2, &r)
+	*z = r
+	return r2.isEqual(x)
+}
@@ -0,0 +1,91 @@
+package secp256k1
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+)
+
+func randomFp(t *testing.T) (fp, *big.Int) {
+	k, err := rand.Int(rand.Reader, params.P)
+	test.CheckNoErr(t, err, "random failed")
+	var x fp
+	x.setBigInt(k)
+	return x, k
+}
+
+func TestFp(t *testing.T) {
+	const testTimes = 1 << 10
+	p := params.P
+	edge := []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		new(big.Int).Sub(p, big.NewInt(1)),
+		new(big.Int).Sub(p, big.NewInt(fpC)),
+	}
+	for i := 0; i < testTimes; i++ {
+		x, bx := randomFp(t)
+		y, by := randomFp(t)
+		if i < len(edge)*len(edge) {
+			bx, by = edge[i%len(edge)], edge[i/len(edge)]
+			x.setBigInt(bx)
+			y.setBigInt(by)
+		}
+
+		var z fp
+		want := new(big.Int)
+		fpAdd(&z, &x, &y)
+		want.Add(bx, by).Mod(want, p)
+		if z.bigInt().Cmp(want) != 0 {
+			test.ReportError(t, z.bigInt(), want, bx, by)
+		}
+		fpSub(&z, &x, &y)
+		want.Sub(bx, by).Mod(want, p)
+		if z.bigInt().Cmp(want) != 0 {
+			test.ReportError(t, z.bigInt(), want, bx, by)
+		}
+		fpMul(&z, &x, &y)
+		want.Mul(bx, by).Mod(want, p)
+		if z.bigInt().Cmp(want) != 0 {
+			test.ReportError(t, z.bigInt(), want, bx, by)
+		}
+		if i%16 == 0 {
+			fpInv(&z, &x)
+			want.ModInverse(bx, p)
+			if bx.Sign() == 0 {
+				want.SetInt64(0)
+			}
+			if z.bigInt().Cmp(want) != 0 {
+				test.ReportError(t, z.bigInt(), want, bx)
+			}
+
+			isSquare := fpSqrt(&z, &x) == 1
+			wantSquare := big.Jacobi(bx, p) >= 0
+			if isSquare != wantSquare {
+				test.ReportError(t, isSquare, wantSquare, bx)
+			}
+			if isSquare {
+				fpSqr(&z, &z)
+				if z.isEqual(&x) != 1 {
+					test.ReportError(t, z.bigInt(), bx, bx)
+				}
+			}
+		}
+	}
+}
+
+func TestFpSetBytes(t *testing.T) {
+	var x fp
+	test.CheckOk(!x.setBytes(params.P.Bytes()), "p should not be canonical", t)
+	pMinus1 := new(big.Int).Sub(params.P, big.NewInt(1))
+	test.CheckOk(x.setBytes(pMinus1.Bytes()), "p-1 should be canonical", t)
+	if x.bigInt().Cmp(pMinus1) != 0 {
+		test.ReportError(t, x.bigInt(), pMinus1)
+	}
+	x.setBigInt(new(big.Int).Add(params.P, big.NewInt(5)))
+	if x.bigInt().Cmp(big.NewInt(5)) != 0 {
+		test.ReportError(t, x.bigInt(), 5)
+	}
+}
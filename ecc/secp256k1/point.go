@@ -0,0 +1,99 @@
+package secp256k1
+
+import "crypto/subtle"
+
+// point is a point of the curve in projective coordinates (X:Y:Z), which
+// represents the affine point (X/Z, Y/Z). The identity is (0:1:0).
+type point struct{ x, y, z fp }
+
+func identity() *point { return &point{y: fpOne} }
+
+// newAffinePoint returns the point (x, y), where (0, 0) is the identity.
+func newAffinePoint(x, y *fp) *point {
+	P := &point{*x, *y, fpOne}
+	P.cmov(identity(), x.isZero()&y.isZero())
+	return P
+}
+
+// toAffine returns the affine coordinates of P, or (0, 0) if P is the identity.
+func (P *point) toAffine() (x, y fp) {
+	var zInv fp
+	fpInv(&zInv, &P.z)
+	fpMul(&x, &P.x, &zInv)
+	fpMul(&y, &P.y, &zInv)
+	return
+}
+
+// cmov sets P to Q if b is 1, and leaves it unchanged if b is 0.
+func (P *point) cmov(Q *point, b int) {
+	P.x.cmov(&Q.x, b)
+	P.y.cmov(&Q.y, b)
+	P.z.cmov(&Q.z, b)
+}
+
+// add sets P = Q + R using the complete formulas for curves with a = 0 from
+// Algorithm 7 of [1], which are also valid for doubling.
+//
+// [1] https://eprint.iacr.org/2015/1060
+func (P *point) add(Q, R *point) {
+	var t0, t1, t2, t3, t4, x3, y3, z3 fp
+	fpMul(&t0, &Q.x, &R.x)
+	fpMul(&t1, &Q.y, &R.y)
+	fpMul(&t2, &Q.z, &R.z)
+	fpAdd(&t3, &Q.x, &Q.y)
+	fpAdd(&t4, &R.x, &R.y)
+	fpMul(&t3, &t3, &t4)
+	fpAdd(&t4, &t0, &t1)
+	fpSub(&t3, &t3, &t4)
+	fpAdd(&t4, &Q.y, &Q.z)
+	fpAdd(&x3, &R.y, &R.z)
+	fpMul(&t4, &t4, &x3)
+	fpAdd(&x3, &t1, &t2)
+	fpSub(&t4, &t4, &x3)
+	fpAdd(&x3, &Q.x, &Q.z)
+	fpAdd(&y3, &R.x, &R.z)
+	fpMul(&x3, &x3, &y3)
+	fpAdd(&y3, &t0, &t2)
+	fpSub(&y3, &x3, &y3)
+	fpAdd(&x3, &t0, &t0)
+	fpAdd(&t0, &x3, &t0)
+	fpMul(&t2, &fpB3, &t2)
+	fpAdd(&z3, &t1, &t2)
+	fpSub(&t1, &t1, &t2)
+	fpMul(&y3, &fpB3, &y3)
+	fpMul(&x3, &t4, &y3)
+	fpMul(&t2, &t3, &t1)
+	fpSub(&x3, &t2, &x3)
+	fpMul(&y3, &y3, &t0)
+	fpMul(&t1, &t1, &z3)
+	fpAdd(&y3, &t1, &y3)
+	fpMul(&t0, &t0, &t3)
+	fpMul(&z3, &z3, &t4)
+	fpAdd(&z3, &z3, &t0)
+	P.x, P.y, P.z = x3, y3, z3
+}
+
+// scalarMult sets P = [k]Q, where k is a 32-byte big-endian scalar, using a
+// fixed 4-bit window and constant-time table lookups.
+func (P *point) scalarMult(Q *point, k []byte) {
+	var table [16]point
+	table[0] = *identity()
+	table[1] = *Q
+	for i := 2; i < len(table); i++ {
+		table[i].add(&table[i-1], Q)
+	}
+
+	R := identity()
+	var T point
+	for i := 0; i < 2*len(k); i++ {
+		for j := 0; j < 4; j++ {
+			R.add(R, R)
+		}
+		digit := int32(k[i/2]>>(4-4*(i%2))) & 0xF
+		for j := range table {
+			T.cmov(&table[j], subtle.ConstantTimeEq(int32(j), digit))
+		}
+		R.add(R, &T)
+	}
+	*P = *R
+}
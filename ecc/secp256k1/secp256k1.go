@@ -0,0 +1,143 @@
+// Package secp256k1 provides elliptic curve operations on the secp256k1
+// curve of SEC 2 [1], y^2 = x^3 + 7, used by Bitcoin and Ethereum.
+//
+// The curve implements the elliptic.Curve interface, so it can be used with
+// crypto/ecdsa, and with elliptic.Marshal and elliptic.Unmarshal. All the
+// operations on points run in constant time, but the scalars are reduced
+// modulo the order of the curve using math/big.
+//
+// As the curve parameter a is zero, the elliptic.CurveParams returned by
+// Params must not be used to perform operations, as its methods assume that
+// a = -3. Use this method to only recover the parameters of the curve.
+//
+// [1] https://www.secg.org/sec2-v2.pdf
+package secp256k1
+
+import (
+	"crypto/elliptic"
+	"math/big"
+)
+
+type curve struct{}
+
+var params = func() *elliptic.CurveParams {
+	p := &elliptic.CurveParams{Name: "secp256k1", BitSize: 256}
+	p.P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	p.N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	p.B = big.NewInt(7)
+	p.Gx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	p.Gy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+	return p
+}()
+
+// Curve returns an elliptic.Curve which implements secp256k1.
+func Curve() elliptic.Curve { return curve{} }
+
+// Params returns the parameters of the curve.
+func (c curve) Params() *elliptic.CurveParams { return params }
+
+// IsOnCurve reports whether the given (x,y) lies on the curve.
+func (c curve) IsOnCurve(x, y *big.Int) bool {
+	if x.Sign() < 0 || x.Cmp(params.P) >= 0 || y.Sign() < 0 || y.Cmp(params.P) >= 0 {
+		return false
+	}
+	var x1, y1, y2, rhs fp
+	x1.setBigInt(x)
+	y1.setBigInt(y)
+	fpSqr(&y2, &y1)
+	polynomial(&rhs, &x1)
+	return y2.isEqual(&rhs) == 1
+}
+
+// polynomial sets y2 = x^3 + 7.
+func polynomial(y2, x *fp) {
+	fpSqr(y2, x)
+	fpMul(y2, y2, x)
+	fpAdd(y2, y2, &fpB)
+}
+
+// Add returns the sum of (x1,y1) and (x2,y2).
+func (c curve) Add(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	P := newBigPoint(x1, y1)
+	P.add(P, newBigPoint(x2, y2))
+	return toBig(P)
+}
+
+// Double returns 2*(x,y).
+func (c curve) Double(x1, y1 *big.Int) (x, y *big.Int) {
+	P := newBigPoint(x1, y1)
+	P.add(P, P)
+	return toBig(P)
+}
+
+// ScalarMult returns k*(x,y) where k is an integer in big-endian form.
+func (c curve) ScalarMult(x1, y1 *big.Int, k []byte) (x, y *big.Int) {
+	P := newBigPoint(x1, y1)
+	P.scalarMult(P, reduceScalar(k))
+	return toBig(P)
+}
+
+// ScalarBaseMult returns k*G, where G is the base point of the group and k is
+// an integer in big-endian form.
+func (c curve) ScalarBaseMult(k []byte) (x, y *big.Int) {
+	return c.ScalarMult(params.Gx, params.Gy, k)
+}
+
+// Unmarshal converts a point, serialized by elliptic.Marshal, into an x, y
+// pair. It is an error if the point is not in uncompressed form, is not on
+// the curve, or is the point at infinity. On error, x = nil.
+func (c curve) Unmarshal(data []byte) (x, y *big.Int) {
+	if len(data) != 65 || data[0] != 4 {
+		return nil, nil
+	}
+	var x1, y1, y2, rhs fp
+	if !x1.setBytes(data[1:33]) || !y1.setBytes(data[33:]) {
+		return nil, nil
+	}
+	fpSqr(&y2, &y1)
+	polynomial(&rhs, &x1)
+	if y2.isEqual(&rhs) != 1 {
+		return nil, nil
+	}
+	return x1.bigInt(), y1.bigInt()
+}
+
+// UnmarshalCompressed converts a point, serialized by
+// elliptic.MarshalCompressed, into an x, y pair. It is an error if the point
+// is not in compressed form, is not on the curve, or is the point at infinity.
+// On error, x = nil.
+func (c curve) UnmarshalCompressed(data []byte) (x, y *big.Int) {
+	if len(data) != 33 || (data[0] != 2 && data[0] != 3) {
+		return nil, nil
+	}
+	var x1, y1, rhs, negY fp
+	if !x1.setBytes(data[1:]) {
+		return nil, nil
+	}
+	polynomial(&rhs, &x1)
+	if fpSqrt(&y1, &rhs) != 1 {
+		return nil, nil
+	}
+	fpNeg(&negY, &y1)
+	y1.cmov(&negY, int((y1[0]^uint64(data[0]))&1))
+	return x1.bigInt(), y1.bigInt()
+}
+
+// reduceScalar returns k mod N as 32 big-endian bytes.
+func reduceScalar(k []byte) []byte {
+	bigK := new(big.Int).SetBytes(k)
+	bigK.Mod(bigK, params.N)
+	return bigK.FillBytes(make([]byte, 32))
+}
+
+func newBigPoint(x, y *big.Int) *point {
+	var x1, y1 fp
+	x1.setBigInt(x)
+	y1.setBigInt(y)
+	return newAffinePoint(&x1, &y1)
+}
+
+func toBig(P *point) (x, y *big.Int) {
+	x1, y1 := P.toAffine()
+	return x1.bigInt(), y1.bigInt()
+}
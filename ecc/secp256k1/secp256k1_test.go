@@ -0,0 +1,177 @@
+package secp256k1_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/katzenpost/circl/ecc/secp256k1"
+	"github.com/katzenpost/circl/internal/test"
+)
+
+// affineAdd adds points in affine coordinates using math/big, where (0,0) is
+// the identity.
+func affineAdd(x1, y1, x2, y2 *big.Int) (x, y *big.Int) {
+	p := secp256k1.Curve().Params().P
+	if x1.Sign() == 0 && y1.Sign() == 0 {
+		return new(big.Int).Set(x2), new(big.Int).Set(y2)
+	}
+	if x2.Sign() == 0 && y2.Sign() == 0 {
+		return new(big.Int).Set(x1), new(big.Int).Set(y1)
+	}
+	l := new(big.Int)
+	if x1.Cmp(x2) == 0 {
+		if l.Add(y1, y2).Mod(l, p).Sign() == 0 {
+			return new(big.Int), new(big.Int)
+		}
+		// l = 3x^2 / 2y
+		l.Mul(x1, x1).Mul(l, big.NewInt(3))
+		den := new(big.Int).Lsh(y1, 1)
+		l.Mul(l, den.ModInverse(den, p))
+	} else {
+		l.Sub(y2, y1)
+		den := new(big.Int).Sub(x2, x1)
+		den.Mod(den, p)
+		l.Mul(l, den.ModInverse(den, p))
+	}
+	l.Mod(l, p)
+	x = new(big.Int).Mul(l, l)
+	x.Sub(x, x1).Sub(x, x2).Mod(x, p)
+	y = new(big.Int).Sub(x1, x)
+	y.Mul(y, l).Sub(y, y1).Mod(y, p)
+	return x, y
+}
+
+func TestVectors(t *testing.T) {
+	c := secp256k1.Curve()
+	for _, v := range []struct{ k, x, y string }{
+		{
+			"1",
+			"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+			"483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+		},
+		{
+			"3",
+			"f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9",
+			"388f7b0f632de8140fe337e62a37f3566500a99934c2231b6cb9fd7584b8e672",
+		},
+		{
+			"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+			"79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+			"b7c52588d95c3b9aa25b0403f1eef75702e84bb7597aabe663b82f6f04ef2777",
+		},
+		{"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", "0", "0"},
+	} {
+		k, _ := new(big.Int).SetString(v.k, 16)
+		wantX, _ := new(big.Int).SetString(v.x, 16)
+		wantY, _ := new(big.Int).SetString(v.y, 16)
+		gotX, gotY := c.ScalarBaseMult(k.Bytes())
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			test.ReportError(t, gotX, wantX, v.k)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	const testTimes = 1 << 6
+	c := secp256k1.Curve()
+	params := c.Params()
+	for i := 0; i < testTimes; i++ {
+		k1, _ := rand.Int(rand.Reader, params.N)
+		k2, _ := rand.Int(rand.Reader, params.N)
+		x1, y1 := c.ScalarBaseMult(k1.Bytes())
+		x2, y2 := c.ScalarBaseMult(k2.Bytes())
+		test.CheckOk(c.IsOnCurve(x1, y1), "point should be on the curve", t)
+		test.CheckOk(!c.IsOnCurve(x1, new(big.Int).Add(y1, big.NewInt(1))), "point should not be on the curve", t)
+
+		gotX, gotY := c.Add(x1, y1, x2, y2)
+		wantX, wantY := affineAdd(x1, y1, x2, y2)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			test.ReportError(t, gotX, wantX, k1, k2)
+		}
+		gotX, gotY = c.Double(x1, y1)
+		wantX, wantY = affineAdd(x1, y1, x1, y1)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			test.ReportError(t, gotX, wantX, k1)
+		}
+
+		// [k2]([k1]G) = [k1 k2]G
+		gotX, gotY = c.ScalarMult(x1, y1, k2.Bytes())
+		k := new(big.Int).Mul(k1, k2)
+		wantX, wantY = c.ScalarBaseMult(k.Bytes())
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			test.ReportError(t, gotX, wantX, k1, k2)
+		}
+
+		// P + (-P) = O, and P + O = P.
+		negY := new(big.Int).Sub(params.P, y1)
+		gotX, gotY = c.Add(x1, y1, x1, negY)
+		if gotX.Sign() != 0 || gotY.Sign() != 0 {
+			test.ReportError(t, gotX, 0, k1)
+		}
+		gotX, gotY = c.Add(x1, y1, new(big.Int), new(big.Int))
+		if gotX.Cmp(x1) != 0 || gotY.Cmp(y1) != 0 {
+			test.ReportError(t, gotX, x1, k1)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	c := secp256k1.Curve()
+	for i := 0; i < 1<<6; i++ {
+		k, _ := rand.Int(rand.Reader, c.Params().N)
+		x, y := c.ScalarBaseMult(k.Bytes())
+		for _, enc := range [][]byte{elliptic.Marshal(c, x, y), elliptic.MarshalCompressed(c, x, y)} {
+			var gotX, gotY *big.Int
+			if len(enc) == 65 {
+				gotX, gotY = elliptic.Unmarshal(c, enc)
+			} else {
+				gotX, gotY = elliptic.UnmarshalCompressed(c, enc)
+			}
+			if gotX == nil || gotX.Cmp(x) != 0 || gotY.Cmp(y) != 0 {
+				test.ReportError(t, gotX, x, k)
+			}
+			enc[len(enc)-1] ^= 1
+			if len(enc) == 65 {
+				gotX, _ = elliptic.Unmarshal(c, enc)
+				if gotX != nil {
+					test.ReportError(t, gotX, nil, k)
+				}
+			}
+		}
+	}
+	p := c.Params().P.Bytes()
+	x, _ := elliptic.UnmarshalCompressed(c, append([]byte{2}, p...))
+	test.CheckOk(x == nil, "should fail with a non-canonical coordinate", t)
+}
+
+func TestECDSA(t *testing.T) {
+	c := secp256k1.Curve()
+	key, err := ecdsa.GenerateKey(c, rand.Reader)
+	test.CheckNoErr(t, err, "key generation failed")
+	digest := sha256.Sum256([]byte("hello"))
+	sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	test.CheckNoErr(t, err, "signing failed")
+	test.CheckOk(ecdsa.VerifyASN1(&key.PublicKey, digest[:], sig), "verification failed", t)
+	digest[0] ^= 1
+	test.CheckOk(!ecdsa.VerifyASN1(&key.PublicKey, digest[:], sig), "verification should fail", t)
+}
+
+func BenchmarkScalarMult(b *testing.B) {
+	c := secp256k1.Curve()
+	params := c.Params()
+	k, _ := rand.Int(rand.Reader, params.N)
+	b.Run("ScalarBaseMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.ScalarBaseMult(k.Bytes())
+		}
+	})
+	b.Run("ScalarMult", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			c.ScalarMult(params.Gx, params.Gy, k.Bytes())
+		}
+	})
+}
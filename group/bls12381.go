@@ -0,0 +1,439 @@
+package group
+
+import (
+	"crypto"
+	_ "crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/katzenpost/circl/ecc/bls12381"
+	"github.com/katzenpost/circl/expander"
+)
+
+var (
+	// BLS12381G1 is the group G1 of the BLS12-381 pairing-friendly curve.
+	// Elements are encoded as in the package ecc/bls12381, and hashing to
+	// elements follows the suites BLS12381G1_XMD:SHA-256_SSWU_RO_ and
+	// BLS12381G1_XMD:SHA-256_SSWU_NU_ of RFC 9380.
+	BLS12381G1 Group = bls12381G1Group{}
+	// BLS12381G2 is the group G2 of the BLS12-381 pairing-friendly curve.
+	// Elements are encoded as in the package ecc/bls12381, and hashing to
+	// elements follows the suites BLS12381G2_XMD:SHA-256_SSWU_RO_ and
+	// BLS12381G2_XMD:SHA-256_SSWU_NU_ of RFC 9380.
+	BLS12381G2 Group = bls12381G2Group{}
+)
+
+type (
+	bls12381G1Group struct{}
+	bls12381G2Group struct{}
+)
+
+type bls12381G1Element struct {
+	p bls12381.G1
+}
+
+type bls12381G2Element struct {
+	p bls12381.G2
+}
+
+// bls12381Scalar is a scalar of both G1 and G2, which have the same order.
+type bls12381Scalar struct {
+	g Group
+	s bls12381.Scalar
+}
+
+func (g bls12381G1Group) String() string { return "BLS12381G1" }
+func (g bls12381G2Group) String() string { return "BLS12381G2" }
+
+func (g bls12381G1Group) Params() *Params {
+	return &Params{bls12381.G1Size, bls12381.G1SizeCompressed, bls12381.ScalarSize}
+}
+
+func (g bls12381G2Group) Params() *Params {
+	return &Params{bls12381.G2Size, bls12381.G2SizeCompressed, bls12381.ScalarSize}
+}
+
+func (g bls12381G1Group) NewElement() Element { return g.Identity() }
+func (g bls12381G2Group) NewElement() Element { return g.Identity() }
+func (g bls12381G1Group) NewScalar() Scalar   { return &bls12381Scalar{g: g} }
+func (g bls12381G2Group) NewScalar() Scalar   { return &bls12381Scalar{g: g} }
+
+func (g bls12381G1Group) Identity() Element {
+	e := &bls12381G1Element{}
+	e.p.SetIdentity()
+	return e
+}
+
+func (g bls12381G2Group) Identity() Element {
+	e := &bls12381G2Element{}
+	e.p.SetIdentity()
+	return e
+}
+
+func (g bls12381G1Group) Generator() Element { return &bls12381G1Element{*bls12381.G1Generator()} }
+func (g bls12381G2Group) Generator() Element { return &bls12381G2Element{*bls12381.G2Generator()} }
+
+// Order returns zero, as the order of the group does not fit in a scalar.
+func (g bls12381G1Group) Order() Scalar { return g.NewScalar() }
+
+// Order returns zero, as the order of the group does not fit in a scalar.
+func (g bls12381G2Group) Order() Scalar { return g.NewScalar() }
+
+func (g bls12381G1Group) RandomElement(rd io.Reader) Element {
+	return g.HashToElement(bls12381RandomBytes(rd), nil)
+}
+
+func (g bls12381G2Group) RandomElement(rd io.Reader) Element {
+	return g.HashToElement(bls12381RandomBytes(rd), nil)
+}
+
+func (g bls12381G1Group) RandomScalar(rd io.Reader) Scalar {
+	return bls12381RandomScalar(g, rd)
+}
+
+func (g bls12381G2Group) RandomScalar(rd io.Reader) Scalar {
+	return bls12381RandomScalar(g, rd)
+}
+
+func (g bls12381G1Group) RandomNonZeroScalar(rd io.Reader) Scalar {
+	return bls12381RandomNonZeroScalar(g, rd)
+}
+
+func (g bls12381G2Group) RandomNonZeroScalar(rd io.Reader) Scalar {
+	return bls12381RandomNonZeroScalar(g, rd)
+}
+
+func (g bls12381G1Group) HashToElement(msg, dst []byte) Element {
+	e := &bls12381G1Element{}
+	e.p.Hash(msg, dst)
+	return e
+}
+
+func (g bls12381G2Group) HashToElement(msg, dst []byte) Element {
+	e := &bls12381G2Element{}
+	e.p.Hash(msg, dst)
+	return e
+}
+
+func (g bls12381G1Group) HashToElementNonUniform(msg, dst []byte) Element {
+	e := &bls12381G1Element{}
+	e.p.Encode(msg, dst)
+	return e
+}
+
+func (g bls12381G2Group) HashToElementNonUniform(msg, dst []byte) Element {
+	e := &bls12381G2Element{}
+	e.p.Encode(msg, dst)
+	return e
+}
+
+func (g bls12381G1Group) HashToScalar(msg, dst []byte) Scalar {
+	return bls12381HashToScalar(g, msg, dst)
+}
+
+func (g bls12381G2Group) HashToScalar(msg, dst []byte) Scalar {
+	return bls12381HashToScalar(g, msg, dst)
+}
+
+func bls12381RandomBytes(rd io.Reader) []byte {
+	b := make([]byte, bls12381.ScalarSize)
+	if n, err := io.ReadFull(rd, b); err != nil || n != len(b) {
+		panic(err)
+	}
+	return b
+}
+
+func bls12381RandomScalar(g Group, rd io.Reader) Scalar {
+	s := &bls12381Scalar{g: g}
+	if err := s.s.Random(rd); err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func bls12381RandomNonZeroScalar(g Group, rd io.Reader) Scalar {
+	for {
+		s := bls12381RandomScalar(g, rd)
+		if !s.IsZero() {
+			return s
+		}
+	}
+}
+
+// bls12381HashToScalar follows hash_to_field of RFC 9380 with SHA-256 and
+// L = 48 bytes, as for the ciphersuites of BLS12-381.
+func bls12381HashToScalar(g Group, msg, dst []byte) Scalar {
+	const L = 48
+	xmd := expander.NewExpanderMD(crypto.SHA256, dst)
+	s := &bls12381Scalar{g: g}
+	s.s.SetBytes(xmd.Expand(msg, L))
+	return s
+}
+
+// bls12381CheckLength returns whether data has the length of an element in
+// the form given by its most significant bit.
+func bls12381CheckLength(data []byte, size, compressedSize int) bool {
+	if len(data) == 0 {
+		return false
+	}
+	if data[0]&0x80 != 0 {
+		return len(data) == compressedSize
+	}
+	return len(data) == size
+}
+
+func (e *bls12381G1Element) Group() Group   { return BLS12381G1 }
+func (e *bls12381G1Element) String() string { return fmt.Sprintf("%x", e.p.BytesCompressed()) }
+func (e *bls12381G1Element) IsIdentity() bool {
+	return e.p.IsIdentity()
+}
+
+func (e *bls12381G1Element) IsEqual(x Element) bool {
+	return e.p.IsEqual(&x.(*bls12381G1Element).p)
+}
+
+func (e *bls12381G1Element) Set(x Element) Element {
+	e.p = x.(*bls12381G1Element).p
+	return e
+}
+
+func (e *bls12381G1Element) Copy() Element {
+	return &bls12381G1Element{e.p}
+}
+
+func (e *bls12381G1Element) CMov(v int, x Element) Element {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	e.p.CMov(&x.(*bls12381G1Element).p, v)
+	return e
+}
+
+func (e *bls12381G1Element) CSelect(v int, x Element, y Element) Element {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	xx, yy := x.(*bls12381G1Element).p, y.(*bls12381G1Element).p
+	yy.CMov(&xx, v)
+	e.p = yy
+	return e
+}
+
+func (e *bls12381G1Element) Add(x Element, y Element) Element {
+	e.p.Add(&x.(*bls12381G1Element).p, &y.(*bls12381G1Element).p)
+	return e
+}
+
+func (e *bls12381G1Element) Dbl(x Element) Element {
+	e.p = x.(*bls12381G1Element).p
+	e.p.Double()
+	return e
+}
+
+func (e *bls12381G1Element) Neg(x Element) Element {
+	e.p = x.(*bls12381G1Element).p
+	e.p.Neg()
+	return e
+}
+
+func (e *bls12381G1Element) Mul(x Element, y Scalar) Element {
+	e.p.ScalarMult(&y.(*bls12381Scalar).s, &x.(*bls12381G1Element).p)
+	return e
+}
+
+func (e *bls12381G1Element) MulGen(x Scalar) Element {
+	e.p.ScalarMult(&x.(*bls12381Scalar).s, bls12381.G1Generator())
+	return e
+}
+
+func (e *bls12381G1Element) MarshalBinaryCompress() ([]byte, error) {
+	return e.p.BytesCompressed(), nil
+}
+
+func (e *bls12381G1Element) MarshalBinary() ([]byte, error) {
+	return e.p.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an element in either form, and rejects points
+// outside G1.
+func (e *bls12381G1Element) UnmarshalBinary(data []byte) error {
+	if !bls12381CheckLength(data, bls12381.G1Size, bls12381.G1SizeCompressed) {
+		return ErrUnmarshal
+	}
+	var p bls12381.G1
+	if err := p.SetBytes(data); err != nil {
+		return err
+	}
+	e.p = p
+	return nil
+}
+
+func (e *bls12381G2Element) Group() Group   { return BLS12381G2 }
+func (e *bls12381G2Element) String() string { return fmt.Sprintf("%x", e.p.BytesCompressed()) }
+func (e *bls12381G2Element) IsIdentity() bool {
+	return e.p.IsIdentity()
+}
+
+func (e *bls12381G2Element) IsEqual(x Element) bool {
+	return e.p.IsEqual(&x.(*bls12381G2Element).p)
+}
+
+func (e *bls12381G2Element) Set(x Element) Element {
+	e.p = x.(*bls12381G2Element).p
+	return e
+}
+
+func (e *bls12381G2Element) Copy() Element {
+	return &bls12381G2Element{e.p}
+}
+
+func (e *bls12381G2Element) CMov(v int, x Element) Element {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	e.p.CMov(&x.(*bls12381G2Element).p, v)
+	return e
+}
+
+func (e *bls12381G2Element) CSelect(v int, x Element, y Element) Element {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	xx, yy := x.(*bls12381G2Element).p, y.(*bls12381G2Element).p
+	yy.CMov(&xx, v)
+	e.p = yy
+	return e
+}
+
+func (e *bls12381G2Element) Add(x Element, y Element) Element {
+	e.p.Add(&x.(*bls12381G2Element).p, &y.(*bls12381G2Element).p)
+	return e
+}
+
+func (e *bls12381G2Element) Dbl(x Element) Element {
+	e.p = x.(*bls12381G2Element).p
+	e.p.Double()
+	return e
+}
+
+func (e *bls12381G2Element) Neg(x Element) Element {
+	e.p = x.(*bls12381G2Element).p
+	e.p.Neg()
+	return e
+}
+
+func (e *bls12381G2Element) Mul(x Element, y Scalar) Element {
+	e.p.ScalarMult(&y.(*bls12381Scalar).s, &x.(*bls12381G2Element).p)
+	return e
+}
+
+func (e *bls12381G2Element) MulGen(x Scalar) Element {
+	e.p.ScalarMult(&x.(*bls12381Scalar).s, bls12381.G2Generator())
+	return e
+}
+
+func (e *bls12381G2Element) MarshalBinaryCompress() ([]byte, error) {
+	return e.p.BytesCompressed(), nil
+}
+
+func (e *bls12381G2Element) MarshalBinary() ([]byte, error) {
+	return e.p.Bytes(), nil
+}
+
+// UnmarshalBinary decodes an element in either form, and rejects points
+// outside G2.
+func (e *bls12381G2Element) UnmarshalBinary(data []byte) error {
+	if !bls12381CheckLength(data, bls12381.G2Size, bls12381.G2SizeCompressed) {
+		return ErrUnmarshal
+	}
+	var p bls12381.G2
+	if err := p.SetBytes(data); err != nil {
+		return err
+	}
+	e.p = p
+	return nil
+}
+
+func (s *bls12381Scalar) Group() Group              { return s.g }
+func (s *bls12381Scalar) String() string            { return s.s.String() }
+func (s *bls12381Scalar) IsZero() bool              { return s.s.IsZero() == 1 }
+func (s *bls12381Scalar) SetUint64(n uint64) Scalar { s.s.SetUint64(n); return s }
+func (s *bls12381Scalar) SetBigInt(x *big.Int) Scalar {
+	k := new(big.Int).Mod(x, new(big.Int).SetBytes(bls12381.Order()))
+	s.s.SetBytes(k.Bytes())
+	return s
+}
+
+func (s *bls12381Scalar) IsEqual(x Scalar) bool {
+	return s.s.IsEqual(&x.(*bls12381Scalar).s) == 1
+}
+
+func (s *bls12381Scalar) Set(x Scalar) Scalar {
+	s.s.Set(&x.(*bls12381Scalar).s)
+	return s
+}
+
+func (s *bls12381Scalar) Copy() Scalar {
+	return &bls12381Scalar{s.g, s.s}
+}
+
+func (s *bls12381Scalar) CMov(v int, x Scalar) Scalar {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	s.s.CMov(&s.s, &x.(*bls12381Scalar).s, v)
+	return s
+}
+
+func (s *bls12381Scalar) CSelect(v int, x Scalar, y Scalar) Scalar {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	s.s.CMov(&y.(*bls12381Scalar).s, &x.(*bls12381Scalar).s, v)
+	return s
+}
+
+func (s *bls12381Scalar) Add(x Scalar, y Scalar) Scalar {
+	s.s.Add(&x.(*bls12381Scalar).s, &y.(*bls12381Scalar).s)
+	return s
+}
+
+func (s *bls12381Scalar) Sub(x Scalar, y Scalar) Scalar {
+	s.s.Sub(&x.(*bls12381Scalar).s, &y.(*bls12381Scalar).s)
+	return s
+}
+
+func (s *bls12381Scalar) Mul(x Scalar, y Scalar) Scalar {
+	s.s.Mul(&x.(*bls12381Scalar).s, &y.(*bls12381Scalar).s)
+	return s
+}
+
+func (s *bls12381Scalar) Neg(x Scalar) Scalar {
+	s.s.Set(&x.(*bls12381Scalar).s)
+	s.s.Neg()
+	return s
+}
+
+func (s *bls12381Scalar) Inv(x Scalar) Scalar {
+	s.s.Inv(&x.(*bls12381Scalar).s)
+	return s
+}
+
+func (s *bls12381Scalar) MarshalBinary() ([]byte, error) {
+	return s.s.MarshalBinary()
+}
+
+// UnmarshalBinary decodes a scalar in big-endian order, and rejects values
+// not less than the order of the group.
+func (s *bls12381Scalar) UnmarshalBinary(data []byte) error {
+	if len(data) != bls12381.ScalarSize {
+		return ErrUnmarshal
+	}
+	var k bls12381.Scalar
+	if err := k.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	s.s = k
+	return nil
+}
@@ -0,0 +1,462 @@
+package group
+
+import (
+	"bytes"
+	"crypto"
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	r255 "github.com/bwesterb/go-ristretto"
+	ed "github.com/bwesterb/go-ristretto/edwards25519"
+	"github.com/katzenpost/circl/expander"
+	"github.com/katzenpost/circl/internal/conv"
+)
+
+// Edwards25519 is the prime-order subgroup of the edwards25519 curve, with
+// elements and scalars encoded as in RFC 8032. Decoding rejects
+// non-canonical encodings and points outside the prime-order subgroup.
+// Hashing to elements follows the suites edwards25519_XMD:SHA-512_ELL2_RO_
+// and edwards25519_XMD:SHA-512_ELL2_NU_ of RFC 9380.
+var Edwards25519 Group = edwards25519Group{}
+
+// edwards25519Order is the order of the group in little-endian order.
+var edwards25519Order = [32]byte{
+	0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+	0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+}
+
+// edwards25519D is the parameter d = -121665/121666 of the curve.
+var edwards25519D = func() (d ed.FieldElement) {
+	p := new(big.Int).Lsh(big.NewInt(1), 255)
+	p.Sub(p, big.NewInt(19))
+	x := new(big.Int).ModInverse(big.NewInt(121666), p)
+	x.Mul(x, big.NewInt(-121665))
+	d.SetBigInt(x.Mod(x, p))
+	return
+}()
+
+// edwards25519P is the order 2^255-19 of the field.
+var edwards25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// edwards25519Sqrt486664 is the square root of -486664 whose sign is 0,
+// which maps curve25519 to edwards25519, see Appendix D.1 of RFC 9380.
+var edwards25519Sqrt486664 = func() *big.Int {
+	c := new(big.Int).Sub(edwards25519P, big.NewInt(486664))
+	c.ModSqrt(c, edwards25519P)
+	if c.Bit(0) == 1 {
+		c.Sub(edwards25519P, c)
+	}
+	return c
+}()
+
+// edwards25519Base is the generator of RFC 8032. The base point of
+// go-ristretto differs from it by a point of small order, which is harmless
+// for ristretto255 but not here.
+var edwards25519Base = func() (e edwards25519Element) {
+	enc := bytes.Repeat([]byte{0x66}, 32)
+	enc[0] = 0x58
+	if err := e.UnmarshalBinary(enc); err != nil {
+		panic(err)
+	}
+	return
+}()
+
+var (
+	edwards25519TableOnce sync.Once
+	edwards25519Table     ed.ScalarMultTable
+)
+
+type edwards25519Group struct{}
+
+func (g edwards25519Group) String() string {
+	return "edwards25519"
+}
+
+func (g edwards25519Group) Params() *Params {
+	return &Params{32, 32, 32}
+}
+
+type edwards25519Element struct {
+	p ed.ExtendedPoint
+}
+
+type edwards25519Scalar struct {
+	s r255.Scalar
+}
+
+func (g edwards25519Group) NewElement() Element {
+	return g.Identity()
+}
+
+func (g edwards25519Group) NewScalar() Scalar {
+	return &edwards25519Scalar{}
+}
+
+func (g edwards25519Group) Identity() Element {
+	e := &edwards25519Element{}
+	e.p.SetZero()
+	return e
+}
+
+func (g edwards25519Group) Generator() Element {
+	e := edwards25519Base
+	return &e
+}
+
+func (g edwards25519Group) Order() Scalar {
+	q := r255.Scalar{
+		0x5cf5d3ed, 0x5812631a, 0xa2f79cd6, 0x14def9de,
+		0x00000000, 0x00000000, 0x00000000, 0x10000000,
+	}
+	return &edwards25519Scalar{q}
+}
+
+func (g edwards25519Group) RandomElement(r io.Reader) Element {
+	return g.NewElement().MulGen(g.RandomScalar(r))
+}
+
+func (g edwards25519Group) RandomScalar(r io.Reader) Scalar {
+	var buf [64]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		panic(err)
+	}
+	s := &edwards25519Scalar{}
+	s.s.SetReduced(&buf)
+	return s
+}
+
+func (g edwards25519Group) RandomNonZeroScalar(r io.Reader) Scalar {
+	for {
+		s := g.RandomScalar(r)
+		if !s.IsZero() {
+			return s
+		}
+	}
+}
+
+func (g edwards25519Group) HashToElementNonUniform(b, dst []byte) Element {
+	var u [1]big.Int
+	xmd := expander.NewExpanderMD(crypto.SHA512, dst)
+	HashToField(u[:], b, xmd, edwards25519P, 48)
+	e := edwards25519Ell2Map(&u[0])
+	return e.clearCofactor()
+}
+
+func (g edwards25519Group) HashToElement(msg, dst []byte) Element {
+	var u [2]big.Int
+	xmd := expander.NewExpanderMD(crypto.SHA512, dst)
+	HashToField(u[:], msg, xmd, edwards25519P, 48)
+	e := edwards25519Ell2Map(&u[0])
+	e.p.Add(&e.p, &edwards25519Ell2Map(&u[1]).p)
+	return e.clearCofactor()
+}
+
+func (g edwards25519Group) HashToScalar(msg, dst []byte) Scalar {
+	var uniformBytes [64]byte
+	xmd := expander.NewExpanderMD(crypto.SHA512, dst)
+	copy(uniformBytes[:], xmd.Expand(msg, 64))
+	s := &edwards25519Scalar{}
+	s.s.SetReduced(&uniformBytes)
+	return s
+}
+
+func (e *edwards25519Element) Group() Group { return Edwards25519 }
+
+func (e *edwards25519Element) String() string {
+	enc, _ := e.MarshalBinary()
+	return fmt.Sprintf("%x", enc)
+}
+
+func (e *edwards25519Element) IsIdentity() bool {
+	return e.p.X.IsNonZeroI() == 0 && e.p.Y.Equals(&e.p.Z)
+}
+
+func (e *edwards25519Element) IsEqual(x Element) bool {
+	xx := &x.(*edwards25519Element).p
+	var l, r ed.FieldElement
+	l.Mul(&e.p.X, &xx.Z)
+	r.Mul(&xx.X, &e.p.Z)
+	eqX := l.EqualsI(&r)
+	l.Mul(&e.p.Y, &xx.Z)
+	r.Mul(&xx.Y, &e.p.Z)
+	return eqX&l.EqualsI(&r) == 1
+}
+
+func (e *edwards25519Element) Set(x Element) Element {
+	e.p.Set(&x.(*edwards25519Element).p)
+	return e
+}
+
+func (e *edwards25519Element) Copy() Element {
+	return &edwards25519Element{e.p}
+}
+
+func (e *edwards25519Element) CMov(v int, x Element) Element {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	e.p.ConditionalSet(&x.(*edwards25519Element).p, int32(v))
+	return e
+}
+
+func (e *edwards25519Element) CSelect(v int, x Element, y Element) Element {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	e.p.ConditionalSet(&x.(*edwards25519Element).p, int32(v))
+	e.p.ConditionalSet(&y.(*edwards25519Element).p, int32(1-v))
+	return e
+}
+
+func (e *edwards25519Element) Add(x Element, y Element) Element {
+	e.p.Add(&x.(*edwards25519Element).p, &y.(*edwards25519Element).p)
+	return e
+}
+
+func (e *edwards25519Element) Dbl(x Element) Element {
+	e.p.Double(&x.(*edwards25519Element).p)
+	return e
+}
+
+func (e *edwards25519Element) Neg(x Element) Element {
+	e.p.Neg(&x.(*edwards25519Element).p)
+	return e
+}
+
+func (e *edwards25519Element) Mul(x Element, y Scalar) Element {
+	var buf [32]byte
+	y.(*edwards25519Scalar).s.BytesInto(&buf)
+	e.p.ScalarMult(&x.(*edwards25519Element).p, &buf)
+	return e
+}
+
+func (e *edwards25519Element) MulGen(x Scalar) Element {
+	var buf [32]byte
+	x.(*edwards25519Scalar).s.BytesInto(&buf)
+	edwards25519TableOnce.Do(func() { edwards25519Table.Compute(&edwards25519Base.p) })
+	edwards25519Table.ScalarMult(&e.p, &buf)
+	return e
+}
+
+func (e *edwards25519Element) MarshalBinaryCompress() ([]byte, error) {
+	return e.MarshalBinary()
+}
+
+func (e *edwards25519Element) MarshalBinary() ([]byte, error) {
+	var x, y, zInv ed.FieldElement
+	var buf [32]byte
+	zInv.Inverse(&e.p.Z)
+	x.Mul(&e.p.X, &zInv)
+	y.Mul(&e.p.Y, &zInv)
+	y.BytesInto(&buf)
+	buf[31] |= byte(x.IsNegativeI()) << 7
+	return buf[:], nil
+}
+
+func (e *edwards25519Element) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return ErrUnmarshal
+	}
+	var buf, enc [32]byte
+	copy(buf[:], data)
+	signX := int32(buf[31] >> 7)
+	buf[31] &= 0x7f
+
+	var x, y, u, v, uv, one ed.FieldElement
+	y.SetBytes(&buf)
+	y.BytesInto(&enc)
+	if subtle.ConstantTimeCompare(buf[:], enc[:]) != 1 {
+		return ErrUnmarshal
+	}
+
+	one.SetOne()
+	u.Square(&y)
+	v.Mul(&u, &edwards25519D)
+	u.Sub(&u, &one) // u = y^2-1
+	v.Add(&v, &one) // v = dy^2+1
+	if u.IsNonZeroI() == 0 {
+		if signX == 1 {
+			return ErrUnmarshal
+		}
+		x.SetZero()
+	} else {
+		uv.Mul(&u, &v)
+		if x.InvSqrtI(&uv) != 1 {
+			return ErrUnmarshal
+		}
+		x.Mul(&x, &u) // x = u/sqrt(uv) = sqrt(u/v)
+		var xNeg ed.FieldElement
+		xNeg.Neg(&x)
+		x.ConditionalSet(&xNeg, x.IsNegativeI()^signX)
+	}
+
+	var P, Q ed.ExtendedPoint
+	P.X = x
+	P.Y = y
+	P.Z.SetOne()
+	P.T.Mul(&x, &y)
+	Q.ScalarMult(&P, &edwards25519Order)
+	if !(&edwards25519Element{Q}).IsIdentity() {
+		return ErrUnmarshal
+	}
+	e.p = P
+	return nil
+}
+
+func (s *edwards25519Scalar) Group() Group                { return Edwards25519 }
+func (s *edwards25519Scalar) String() string              { return conv.BytesLe2Hex(s.s.Bytes()) }
+func (s *edwards25519Scalar) SetUint64(n uint64) Scalar   { s.s.SetUint64(n); return s }
+func (s *edwards25519Scalar) SetBigInt(x *big.Int) Scalar { s.s.SetBigInt(x); return s }
+func (s *edwards25519Scalar) IsZero() bool                { return s.s.IsNonZeroI() == 0 }
+func (s *edwards25519Scalar) IsEqual(x Scalar) bool {
+	return s.s.Equals(&x.(*edwards25519Scalar).s)
+}
+
+func (s *edwards25519Scalar) Set(x Scalar) Scalar {
+	s.s.Set(&x.(*edwards25519Scalar).s)
+	return s
+}
+
+func (s *edwards25519Scalar) Copy() Scalar {
+	return &edwards25519Scalar{s.s}
+}
+
+func (s *edwards25519Scalar) CMov(v int, x Scalar) Scalar {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	s.s.ConditionalSet(&x.(*edwards25519Scalar).s, int32(v))
+	return s
+}
+
+func (s *edwards25519Scalar) CSelect(v int, x Scalar, y Scalar) Scalar {
+	if !(v == 0 || v == 1) {
+		panic(ErrSelector)
+	}
+	s.s.ConditionalSet(&x.(*edwards25519Scalar).s, int32(v))
+	s.s.ConditionalSet(&y.(*edwards25519Scalar).s, int32(1-v))
+	return s
+}
+
+func (s *edwards25519Scalar) Add(x Scalar, y Scalar) Scalar {
+	s.s.Add(&x.(*edwards25519Scalar).s, &y.(*edwards25519Scalar).s)
+	return s
+}
+
+func (s *edwards25519Scalar) Sub(x Scalar, y Scalar) Scalar {
+	s.s.Sub(&x.(*edwards25519Scalar).s, &y.(*edwards25519Scalar).s)
+	return s
+}
+
+func (s *edwards25519Scalar) Mul(x Scalar, y Scalar) Scalar {
+	s.s.Mul(&x.(*edwards25519Scalar).s, &y.(*edwards25519Scalar).s)
+	return s
+}
+
+func (s *edwards25519Scalar) Neg(x Scalar) Scalar {
+	s.s.Neg(&x.(*edwards25519Scalar).s)
+	return s
+}
+
+func (s *edwards25519Scalar) Inv(x Scalar) Scalar {
+	s.s.Inverse(&x.(*edwards25519Scalar).s)
+	return s
+}
+
+func (s *edwards25519Scalar) MarshalBinary() ([]byte, error) {
+	return s.s.MarshalBinary()
+}
+
+// UnmarshalBinary decodes a scalar in little-endian order, and rejects
+// values not less than the order of the group.
+func (s *edwards25519Scalar) UnmarshalBinary(data []byte) error {
+	if len(data) != 32 {
+		return ErrUnmarshal
+	}
+	var k r255.Scalar
+	if err := k.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	var enc [32]byte
+	k.BytesInto(&enc)
+	if subtle.ConstantTimeCompare(data, enc[:]) != 1 {
+		return ErrUnmarshal
+	}
+	s.s = k
+	return nil
+}
+
+// edwards25519Ell2Map maps u to a point of edwards25519, not necessarily of
+// the prime-order subgroup, with the Elligator 2 map to curve25519 of
+// Section 6.7.1 of RFC 9380 followed by the rational map of Appendix D.1.
+func edwards25519Ell2Map(u *big.Int) *edwards25519Element {
+	p := edwards25519P
+	A := big.NewInt(486662)
+	Z := big.NewInt(2)
+	g := func(x *big.Int) *big.Int { // x^3 + A*x^2 + x
+		r := new(big.Int).Add(x, A)
+		r.Mul(r, x).Add(r, big.NewInt(1))
+		return r.Mul(r, x).Mod(r, p)
+	}
+	isSquare := func(x *big.Int) bool { return big.Jacobi(x, p) >= 0 }
+
+	// x1 = -A / (1 + Z*u^2), or -A if the denominator is zero.
+	x1 := new(big.Int).Mul(u, u)
+	x1.Mul(x1, Z).Add(x1, big.NewInt(1)).Mod(x1, p)
+	if x1.Sign() == 0 {
+		x1.SetInt64(1)
+	}
+	x1.ModInverse(x1, p)
+	x1.Mul(x1, A).Neg(x1).Mod(x1, p)
+
+	// Either x1 or x2 = -x1 - A is the abscissa of a point.
+	var s, t *big.Int
+	if gx1 := g(x1); isSquare(gx1) {
+		s = x1
+		t = new(big.Int).ModSqrt(gx1, p)
+		if t.Bit(0) == 0 {
+			t.Sub(p, t).Mod(t, p)
+		}
+	} else {
+		s = new(big.Int).Add(x1, A)
+		s.Neg(s).Mod(s, p)
+		t = new(big.Int).ModSqrt(g(s), p)
+		if t.Bit(0) == 1 {
+			t.Sub(p, t)
+		}
+	}
+
+	// (x, y) = (sqrt(-486664)*s/t, (s-1)/(s+1)), or the identity if a
+	// denominator is zero.
+	e := &edwards25519Element{}
+	e.p.SetZero()
+	sp1 := new(big.Int).Add(s, big.NewInt(1))
+	sp1.Mod(sp1, p)
+	if t.Sign() == 0 || sp1.Sign() == 0 {
+		return e
+	}
+	x := new(big.Int).ModInverse(t, p)
+	x.Mul(x, s).Mul(x, edwards25519Sqrt486664).Mod(x, p)
+	y := new(big.Int).ModInverse(sp1, p)
+	y.Mul(y, new(big.Int).Sub(s, big.NewInt(1))).Mod(y, p)
+
+	e.p.X.SetBigInt(x)
+	e.p.Y.SetBigInt(y)
+	e.p.Z.SetOne()
+	e.p.T.Mul(&e.p.X, &e.p.Y)
+	return e
+}
+
+// clearCofactor multiplies e by the cofactor 8.
+func (e *edwards25519Element) clearCofactor() *edwards25519Element {
+	e.p.Double(&e.p)
+	e.p.Double(&e.p)
+	e.p.Double(&e.p)
+	return e
+}
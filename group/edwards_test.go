@@ -0,0 +1,68 @@
+package group_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/internal/conv"
+	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/sign/ed25519"
+)
+
+func TestEdwardsPublicKeys(t *testing.T) {
+	// Public keys of RFC 8032 are the secret scalars times the generator.
+	const testTimes = 1 << 5
+	for i := 0; i < testTimes; i++ {
+		seed := make([]byte, ed25519.SeedSize)
+		_, _ = rand.Read(seed)
+		h := sha512.Sum512(seed)
+		h[0] &= 248
+		h[31] &= 127
+		h[31] |= 64
+		k := group.Edwards25519.NewScalar().SetBigInt(conv.BytesLe2BigInt(h[:32]))
+		got, _ := group.Edwards25519.NewElement().MulGen(k).MarshalBinary()
+		want := ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey)
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, seed)
+		}
+	}
+}
+
+func TestEdwardsInvalidEncodings(t *testing.T) {
+	for _, v := range []struct {
+		g   group.Group
+		enc string
+	}{
+		// edwards25519: (0,-1) has order 2.
+		{group.Edwards25519, "ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f"},
+		// edwards25519: the identity with y = p+1.
+		{group.Edwards25519, "eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f"},
+		// edwards25519: the identity with the sign bit set.
+		{group.Edwards25519, "0100000000000000000000000000000000000000000000000000000000000080"},
+		// edwards25519: a point of order 8.
+		{group.Edwards25519, "c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a"},
+	} {
+		enc, _ := hex.DecodeString(v.enc)
+		err := v.g.NewElement().UnmarshalBinary(enc)
+		test.CheckIsErr(t, err, "should reject "+v.enc)
+	}
+
+	// Scalars must be less than the order.
+	for _, v := range []struct {
+		g   group.Group
+		enc string
+	}{
+		{group.Edwards25519, "edd3f55c1a631258d69cf7a2def9de1400000000000000000000000000000010"},
+	} {
+		enc, _ := hex.DecodeString(v.enc)
+		err := v.g.NewScalar().UnmarshalBinary(enc)
+		test.CheckIsErr(t, err, "should reject the order")
+		enc[0]--
+		err = v.g.NewScalar().UnmarshalBinary(enc)
+		test.CheckNoErr(t, err, "should accept the order minus one")
+	}
+}
@@ -13,7 +13,7 @@ import (
 )
 
 func TestHashToElement(t *testing.T) {
-	fileNames, err := filepath.Glob("./testdata/P*.json")
+	fileNames, err := filepath.Glob("./testdata/*.json")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -37,13 +37,19 @@ func TestHashToElement(t *testing.T) {
 
 func testHashing(t *testing.T, vs *vectorSuite) {
 	var G group.Group
-	switch vs.Ciphersuite[0:4] {
-	case "P256":
+	toBytes := point.toBytes
+	switch vs.Curve {
+	case "NIST P-256":
 		G = group.P256
-	case "P384":
+	case "NIST P-384":
 		G = group.P384
-	case "P521":
+	case "NIST P-521":
 		G = group.P521
+	case "secp256k1":
+		G = group.Secp256k1
+	case "edwards25519":
+		G = group.Edwards25519
+		toBytes = point.toEdwardsBytes
 	default:
 		t.Fatal("non supported suite")
 	}
@@ -56,7 +62,7 @@ func testHashing(t *testing.T, vs *vectorSuite) {
 	want := G.NewElement()
 	for i, v := range vs.Vectors {
 		got := hashFunc([]byte(v.Msg), []byte(vs.Dst))
-		err := want.UnmarshalBinary(v.P.toBytes())
+		err := want.UnmarshalBinary(toBytes(v.P))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -104,6 +110,25 @@ func (p point) toBytes() []byte {
 	return append(append([]byte{0x04}, x...), y...)
 }
 
+// toEdwardsBytes returns the encoding of RFC 8032: y in little-endian
+// order, with the sign of x in the top bit.
+func (p point) toEdwardsBytes() []byte {
+	x, err := hex.DecodeString(p.X[2:])
+	if err != nil {
+		panic(err)
+	}
+	y, err := hex.DecodeString(p.Y[2:])
+	if err != nil {
+		panic(err)
+	}
+	enc := make([]byte, len(y))
+	for i := range y {
+		enc[i] = y[len(y)-1-i]
+	}
+	enc[len(enc)-1] |= (x[len(x)-1] & 1) << 7
+	return enc
+}
+
 type vector struct {
 	P   point    `json:"P"`
 	Q0  point    `json:"Q0,omitempty"`
@@ -11,6 +11,7 @@ import (
 	"math/big"
 
 	"github.com/katzenpost/circl/ecc/p384"
+	"github.com/katzenpost/circl/ecc/secp256k1"
 	"github.com/katzenpost/circl/expander"
 )
 
@@ -21,6 +22,8 @@ var (
 	P384 Group = wG{p384.P384()}
 	// P521 is the group generated by P-521 elliptic curve.
 	P521 Group = wG{elliptic.P521()}
+	// Secp256k1 is the group generated by secp256k1 elliptic curve.
+	Secp256k1 Group = wG{secp256k1.Curve()}
 )
 
 type wG struct {
@@ -66,7 +69,7 @@ func (g wG) cvtElt(e Element) *wElt {
 		return g.zeroElement()
 	}
 	ee, ok := e.(*wElt)
-	if !ok || g.c.Params().BitSize != ee.c.Params().BitSize {
+	if !ok || g.c.Params().Name != ee.c.Params().Name {
 		panic(ErrType)
 	}
 	return ee
@@ -77,7 +80,7 @@ func (g wG) cvtScl(s Scalar) *wScl {
 		return g.zeroScalar()
 	}
 	ss, ok := s.(*wScl)
-	if !ok || g.c.Params().BitSize != ss.c.Params().BitSize {
+	if !ok || g.c.Params().Name != ss.c.Params().Name {
 		panic(ErrType)
 	}
 	return ss
@@ -374,20 +377,28 @@ func (s *wScl) UnmarshalBinary(b []byte) error {
 	return nil
 }
 
+// mapToCurveParams returns the map to the curve, and the hash function and
+// the length used to hash to the field.
 func (g wG) mapToCurveParams() (mapping func(u *big.Int) *wElt, h crypto.Hash, L uint) {
 	var Z, C2 big.Int
-	switch g.c.Params().BitSize {
-	case 256:
+	switch g.c.Params().Name {
+	case "secp256k1":
+		mapping = func(u *big.Int) *wElt {
+			e := g.sswu3mod4Map(u, secp256k1IsoA, secp256k1IsoB, secp256k1IsoZ, secp256k1IsoC2)
+			return g.isoMapSecp256k1(e)
+		}
+		return mapping, crypto.SHA256, 48
+	case "P-256":
 		Z.SetInt64(-10)
 		C2.SetString("0x78bc71a02d89ec07214623f6d0f955072c7cc05604a5a6e23ffbf67115fa5301", 0)
 		h = crypto.SHA256
 		L = 48
-	case 384:
+	case "P-384":
 		Z.SetInt64(-12)
 		C2.SetString("0x19877cc1041b7555743c0ae2e3a3e61fb2aaa2e0e87ea557a563d8b598a0940d0a697a9e0b9e92cfaa314f583c9d066", 0)
 		h = crypto.SHA384
 		L = 72
-	case 521:
+	case "P-521":
 		Z.SetInt64(-4)
 		C2.SetInt64(8)
 		h = crypto.SHA512
@@ -395,10 +406,14 @@ func (g wG) mapToCurveParams() (mapping func(u *big.Int) *wElt, h crypto.Hash, L
 	default:
 		panic("curve not supported")
 	}
-	return func(u *big.Int) *wElt { return g.sswu3mod4Map(u, &Z, &C2) }, h, L
+	A := big.NewInt(-3)
+	B := g.c.Params().B
+	return func(u *big.Int) *wElt { return g.sswu3mod4Map(u, A, B, &Z, &C2) }, h, L
 }
 
-func (g wG) sswu3mod4Map(u *big.Int, Z, C2 *big.Int) *wElt {
+// sswu3mod4Map is the simplified SWU map to the curve y^2 = x^3 + A*x + B
+// over a field of order 3 mod 4, see Section F.2.1.2 of RFC 9380.
+func (g wG) sswu3mod4Map(u *big.Int, A, B, Z, C2 *big.Int) *wElt {
 	tv1 := new(big.Int)
 	tv2 := new(big.Int)
 	tv3 := new(big.Int)
@@ -414,8 +429,6 @@ func (g wG) sswu3mod4Map(u *big.Int, Z, C2 *big.Int) *wElt {
 	x := new(big.Int)
 	y := new(big.Int)
 
-	A := big.NewInt(-3)
-	B := g.c.Params().B
 	p := g.c.Params().P
 	c1 := new(big.Int)
 	c1.Sub(p, big.NewInt(3)).Rsh(c1, 2) // 1.  c1 = (q - 3) / 4
@@ -474,3 +487,72 @@ func (g wG) sswu3mod4Map(u *big.Int, Z, C2 *big.Int) *wElt {
 	y.Mod(y, p)
 	return &wElt{g, x, y}
 }
+
+// Parameters of the curve E': y^2 = x^3 + A'*x + B' that is 3-isogenous to
+// secp256k1, and of the simplified SWU map to it, see Section 8.7 of
+// RFC 9380.
+var (
+	secp256k1IsoA  = hexInt("3f8731abdd661adca08a5558f0f5d272e953d363cb6f0e5d405447c01a444533")
+	secp256k1IsoB  = big.NewInt(1771)
+	secp256k1IsoZ  = big.NewInt(-11)
+	secp256k1IsoC2 = new(big.Int).ModSqrt(big.NewInt(1331), secp256k1.Curve().Params().P) // sqrt(-Z^3)
+)
+
+// Coefficients k_(i,j) of the 3-isogeny map from E' to secp256k1, see
+// Appendix E.1 of RFC 9380.
+var (
+	secp256k1IsoXNum = []*big.Int{
+		hexInt("8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa8c7"),
+		hexInt("07d3d4c80bc321d5b9f315cea7fd44c5d595d2fc0bf63b92dfff1044f17c6581"),
+		hexInt("534c328d23f234e6e2a413deca25caece4506144037c40314ecbd0b53d9dd262"),
+		hexInt("8e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38e38daaaaa88c"),
+	}
+	secp256k1IsoXDen = []*big.Int{
+		hexInt("d35771193d94918a9ca34ccbb7b640dd86cd409542f8487d9fe6b745781eb49b"),
+		hexInt("edadc6f64383dc1df7c4b2d51b54225406d36b641f5e41bbc52a56612a8c6d14"),
+		big.NewInt(1),
+	}
+	secp256k1IsoYNum = []*big.Int{
+		hexInt("4bda12f684bda12f684bda12f684bda12f684bda12f684bda12f684b8e38e23c"),
+		hexInt("c75e0c32d5cb7c0fa9d0a54b12a0a6d5647ab046d686da6fdffc90fc201d71a3"),
+		hexInt("29a6194691f91a73715209ef6512e576722830a201be2018a765e85a9ecee931"),
+		hexInt("2f684bda12f684bda12f684bda12f684bda12f684bda12f684bda12f38e38d84"),
+	}
+	secp256k1IsoYDen = []*big.Int{
+		hexInt("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffff93b"),
+		hexInt("7a06534bb8bdb49fd5e9e6632722c2989467c1bfc8e8d978dfb425d2685c2573"),
+		hexInt("6484aa716545ca2cf3a70c3fa8fe337e0a3d21162f0d6299a7bf8192bfd2a76f"),
+		big.NewInt(1),
+	}
+)
+
+func hexInt(s string) *big.Int {
+	x, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("group: invalid constant")
+	}
+	return x
+}
+
+// isoMapSecp256k1 maps a point of E' to secp256k1. It returns the identity
+// if a denominator vanishes.
+func (g wG) isoMapSecp256k1(e *wElt) *wElt {
+	p := g.c.Params().P
+	eval := func(k []*big.Int) *big.Int {
+		r := new(big.Int)
+		for i := len(k) - 1; i >= 0; i-- {
+			r.Mul(r, e.x).Add(r, k[i]).Mod(r, p)
+		}
+		return r
+	}
+	xDen := eval(secp256k1IsoXDen)
+	yDen := eval(secp256k1IsoYDen)
+	if xDen.Sign() == 0 || yDen.Sign() == 0 {
+		return g.zeroElement()
+	}
+	x := eval(secp256k1IsoXNum)
+	x.Mul(x, xDen.ModInverse(xDen, p)).Mod(x, p)
+	y := eval(secp256k1IsoYNum)
+	y.Mul(y, yDen.ModInverse(yDen, p)).Mul(y, e.y).Mod(y, p)
+	return &wElt{g, x, y}
+}
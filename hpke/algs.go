@@ -15,9 +15,11 @@ import (
 	"github.com/katzenpost/circl/dh/x448"
 	"github.com/katzenpost/circl/ecc/p384"
 	"github.com/katzenpost/circl/kem"
-
-	// "github.com/katzenpost/circl/kem/kyber/kyber768"
-
+	"github.com/katzenpost/circl/kem/kyber/kyber768"
+	"github.com/katzenpost/circl/kem/mlkem/mlkem1024"
+	"github.com/katzenpost/circl/kem/mlkem/mlkem512"
+	"github.com/katzenpost/circl/kem/mlkem/mlkem768"
+	"github.com/katzenpost/circl/kem/xwing"
 	"golang.org/x/crypto/chacha20poly1305"
 	"golang.org/x/crypto/hkdf"
 )
@@ -41,6 +43,15 @@ const (
 	// KEM_X25519_KYBER768_DRAFT00 is a hybrid KEM built on DHKEM(X25519, HKDF-SHA256)
 	// and Kyber768Draft00
 	KEM_X25519_KYBER768_DRAFT00 KEM = 0x30
+	// KEM_MLKEM512 is ML-KEM-512 as specified in draft-ietf-hpke-pq.
+	KEM_MLKEM512 KEM = 0x40
+	// KEM_MLKEM768 is ML-KEM-768 as specified in draft-ietf-hpke-pq.
+	KEM_MLKEM768 KEM = 0x41
+	// KEM_MLKEM1024 is ML-KEM-1024 as specified in draft-ietf-hpke-pq.
+	KEM_MLKEM1024 KEM = 0x42
+	// KEM_XWING is the X-Wing hybrid KEM combining X25519 and ML-KEM-768,
+	// as specified in draft-connolly-cfrg-xwing-kem.
+	KEM_XWING KEM = 0x647a
 )
 
 // IsValid returns true if the KEM identifier is supported by the HPKE package.
@@ -51,16 +62,22 @@ func (k KEM) IsValid() bool {
 		KEM_P521_HKDF_SHA512,
 		KEM_X25519_HKDF_SHA256,
 		KEM_X448_HKDF_SHA512,
-		KEM_X25519_KYBER768_DRAFT00:
+		KEM_X25519_KYBER768_DRAFT00,
+		KEM_MLKEM512,
+		KEM_MLKEM768,
+		KEM_MLKEM1024,
+		KEM_XWING:
 		return true
 	default:
 		return false
 	}
 }
 
-// Scheme returns an instance of a KEM that supports authentication. Panics if
-// the KEM identifier is invalid.
-func (k KEM) Scheme() kem.AuthScheme {
+// Scheme returns an instance of a KEM. The Diffie-Hellman based KEMs also
+// implement kem.AuthScheme, and can therefore be used in the Auth and AuthPSK
+// modes; the post-quantum and hybrid KEMs can only be used in the Base and
+// PSK modes. Panics if the KEM identifier is invalid.
+func (k KEM) Scheme() kem.Scheme {
 	switch k {
 	case KEM_P256_HKDF_SHA256:
 		return dhkemp256hkdfsha256
@@ -72,8 +89,16 @@ func (k KEM) Scheme() kem.AuthScheme {
 		return dhkemx25519hkdfsha256
 	case KEM_X448_HKDF_SHA512:
 		return dhkemx448hkdfsha512
-	// case KEM_X25519_KYBER768_DRAFT00:
-	// 	return hybridkemX25519Kyber768
+	case KEM_X25519_KYBER768_DRAFT00:
+		return hybridkemX25519Kyber768
+	case KEM_MLKEM512:
+		return kemMlkem512
+	case KEM_MLKEM768:
+		return kemMlkem768
+	case KEM_MLKEM1024:
+		return kemMlkem1024
+	case KEM_XWING:
+		return kemXwing
 	default:
 		panic(ErrInvalidKEM)
 	}
@@ -239,6 +264,8 @@ var (
 	dhkemp256hkdfsha256, dhkemp384hkdfsha384, dhkemp521hkdfsha512 shortKEM
 	dhkemx25519hkdfsha256, dhkemx448hkdfsha512                    xKEM
 	hybridkemX25519Kyber768                                       hybridKEM
+	kemMlkem512, kemMlkem768, kemMlkem1024                        seedKEM
+	kemXwing                                                      genericNoAuthKEM
 )
 
 func init() {
@@ -272,10 +299,25 @@ func init() {
 	dhkemx448hkdfsha512.dhKemBase.Hash = crypto.SHA512
 	dhkemx448hkdfsha512.dhKemBase.dhKEM = dhkemx448hkdfsha512
 
-	// Commenting out Kyber768 hybrid KEM due to KEM interface incompatibility
-	// hybridkemX25519Kyber768.kemBase.id = KEM_X25519_KYBER768_DRAFT00
-	// hybridkemX25519Kyber768.kemBase.name = "HPKE_KEM_X25519_KYBER768_HKDF_SHA256"
-	// hybridkemX25519Kyber768.kemBase.Hash = crypto.SHA256
-	// hybridkemX25519Kyber768.kemA = dhkemx25519hkdfsha256
-	// hybridkemX25519Kyber768.kemB = kyber768.Scheme()
+	hybridkemX25519Kyber768.kemBase.id = KEM_X25519_KYBER768_DRAFT00
+	hybridkemX25519Kyber768.kemBase.name = "HPKE_KEM_X25519_KYBER768_HKDF_SHA256"
+	hybridkemX25519Kyber768.kemBase.Hash = crypto.SHA256
+	hybridkemX25519Kyber768.kemA = dhkemx25519hkdfsha256
+	hybridkemX25519Kyber768.kemB = kyber768.Scheme()
+
+	kemMlkem512.Scheme = mlkem512.Scheme()
+	kemMlkem512.id = KEM_MLKEM512
+	kemMlkem512.name = "HPKE_KEM_MLKEM512"
+
+	kemMlkem768.Scheme = mlkem768.Scheme()
+	kemMlkem768.id = KEM_MLKEM768
+	kemMlkem768.name = "HPKE_KEM_MLKEM768"
+
+	kemMlkem1024.Scheme = mlkem1024.Scheme()
+	kemMlkem1024.id = KEM_MLKEM1024
+	kemMlkem1024.name = "HPKE_KEM_MLKEM1024"
+
+	kemXwing.Scheme = xwing.Scheme()
+	kemXwing.id = KEM_XWING
+	kemXwing.name = "HPKE_KEM_XWING"
 }
@@ -0,0 +1,117 @@
+package hpke
+
+// Shim to use generic KEM (kem.Scheme) as HPKE KEM.
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/katzenpost/circl/internal/sha3"
+	"github.com/katzenpost/circl/kem"
+	"github.com/katzenpost/hpqc/kem/pem"
+)
+
+// genericNoAuthKEM wraps a generic KEM (kem.Scheme) to be used as a HPKE KEM.
+// It does not implement kem.AuthScheme, so it can only be used in the Base
+// and PSK modes.
+type genericNoAuthKEM struct {
+	kem.Scheme
+	id   KEM
+	name string
+}
+
+func (h genericNoAuthKEM) Name() string { return h.name }
+
+// HPKE requires DeriveKeyPair() to take any seed, whereas typical KEMs expect
+// a specific seed size. Following draft-ietf-hpke-pq, the input keying
+// material is mapped to a seed of the right size using SHAKE256 as a
+// one-stage labeled KDF.
+func (h genericNoAuthKEM) DeriveKeyPair(ikm []byte) (kem.PublicKey, kem.PrivateKey) {
+	return h.Scheme.DeriveKeyPair(h.labeledDerive(ikm, []byte("DeriveKeyPair"), nil,
+		uint16(h.Scheme.SeedSize())))
+}
+
+func (h genericNoAuthKEM) labeledDerive(ikm, label, context []byte, l uint16) []byte {
+	var sid [5]byte
+	sid[0], sid[1], sid[2] = 'K', 'E', 'M'
+	binary.BigEndian.PutUint16(sid[3:5], uint16(h.id))
+
+	var buf [2]byte
+	out := make([]byte, l)
+	hh := sha3.NewShake256()
+	_, _ = hh.Write(ikm)
+	_, _ = hh.Write([]byte(versionLabel))
+	_, _ = hh.Write(sid[:])
+	binary.BigEndian.PutUint16(buf[:], uint16(len(label)))
+	_, _ = hh.Write(buf[:])
+	_, _ = hh.Write(label)
+	binary.BigEndian.PutUint16(buf[:], l)
+	_, _ = hh.Write(buf[:])
+	_, _ = hh.Write(context)
+	_, _ = hh.Read(out)
+	return out
+}
+
+// seedKEM is a genericNoAuthKEM whose private keys are serialized as the
+// seed they are derived from, as draft-ietf-hpke-pq requires for ML-KEM.
+type seedKEM struct {
+	genericNoAuthKEM
+}
+
+type seedPrivateKey struct {
+	kem.PrivateKey
+	scheme seedKEM
+	seed   []byte
+}
+
+func (h seedKEM) PrivateKeySize() int { return h.Scheme.SeedSize() }
+
+func (h seedKEM) GenerateKeyPair() (kem.PublicKey, kem.PrivateKey, error) {
+	seed := make([]byte, h.Scheme.SeedSize())
+	if _, err := rand.Read(seed); err != nil {
+		return nil, nil, err
+	}
+	pk, sk := h.fromSeed(seed)
+	return pk, sk, nil
+}
+
+func (h seedKEM) DeriveKeyPair(ikm []byte) (kem.PublicKey, kem.PrivateKey) {
+	return h.fromSeed(h.labeledDerive(ikm, []byte("DeriveKeyPair"), nil,
+		uint16(h.Scheme.SeedSize())))
+}
+
+func (h seedKEM) fromSeed(seed []byte) (kem.PublicKey, kem.PrivateKey) {
+	pk, sk := h.Scheme.DeriveKeyPair(seed)
+	return pk, &seedPrivateKey{sk, h, append([]byte{}, seed...)}
+}
+
+func (h seedKEM) UnmarshalBinaryPrivateKey(data []byte) (kem.PrivateKey, error) {
+	if len(data) != h.PrivateKeySize() {
+		return nil, kem.ErrPrivKeySize
+	}
+	_, sk := h.fromSeed(data)
+	return sk, nil
+}
+
+func (h seedKEM) UnmarshalTextPrivateKey(text []byte) (kem.PrivateKey, error) {
+	return pem.FromPrivatePEMBytes(text, h)
+}
+
+func (h seedKEM) Decapsulate(sk kem.PrivateKey, ct []byte) ([]byte, error) {
+	ssk, ok := sk.(*seedPrivateKey)
+	if !ok {
+		return nil, kem.ErrTypeMismatch
+	}
+	return h.Scheme.Decapsulate(ssk.PrivateKey, ct)
+}
+
+func (k *seedPrivateKey) Scheme() kem.Scheme { return k.scheme }
+
+func (k *seedPrivateKey) MarshalBinary() ([]byte, error) {
+	return append([]byte{}, k.seed...), nil
+}
+
+func (k *seedPrivateKey) Equal(sk kem.PrivateKey) bool {
+	k1, ok := sk.(*seedPrivateKey)
+	return ok && k.PrivateKey.Equal(k1.PrivateKey)
+}
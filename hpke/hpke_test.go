@@ -63,6 +63,63 @@ func Example() {
 	// Output: true
 }
 
+func TestNoAuthKEMs(t *testing.T) {
+	for _, kemID := range []hpke.KEM{
+		hpke.KEM_X25519_KYBER768_DRAFT00,
+		hpke.KEM_MLKEM512,
+		hpke.KEM_MLKEM768,
+		hpke.KEM_MLKEM1024,
+		hpke.KEM_XWING,
+	} {
+		suite := hpke.NewSuite(kemID, hpke.KDF_HKDF_SHA256, hpke.AEAD_AES128GCM)
+		info := []byte("info")
+		psk, pskID := []byte("a pre-shared key of sufficient length"), []byte("id")
+
+		pkR, skR, err := kemID.Scheme().GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sender, err := suite.NewSender(pkR, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+		receiver, err := suite.NewReceiver(skR, info)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		enc, sealer, err := sender.SetupPSK(rand.Reader, psk, pskID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		opener, err := receiver.SetupPSK(enc, psk, pskID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ct, err := sealer.Seal([]byte("message"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = opener.Open(ct, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		// These KEMs cannot authenticate the sender.
+		if _, _, err = sender.SetupAuth(rand.Reader, skR); err != hpke.ErrInvalidAuthKEM {
+			t.Fatalf("%v: expected ErrInvalidAuthKEM, got %v", suite, err)
+		}
+		if _, _, err = sender.SetupAuthPSK(rand.Reader, skR, psk, pskID); err != hpke.ErrInvalidAuthKEM {
+			t.Fatalf("%v: expected ErrInvalidAuthKEM, got %v", suite, err)
+		}
+		if _, err = receiver.SetupAuth(enc, pkR); err != hpke.ErrInvalidAuthKEM {
+			t.Fatalf("%v: expected ErrInvalidAuthKEM, got %v", suite, err)
+		}
+		if _, err = receiver.SetupAuthPSK(enc, psk, pskID, pkR); err != hpke.ErrInvalidAuthKEM {
+			t.Fatalf("%v: expected ErrInvalidAuthKEM, got %v", suite, err)
+		}
+	}
+}
+
 func runHpkeBenchmark(b *testing.B, kem hpke.KEM, kdf hpke.KDF, aead hpke.AEAD) {
 	suite := hpke.NewSuite(kem, kdf, aead)
 
@@ -160,6 +217,8 @@ func BenchmarkHpkeRoundTrip(b *testing.B) {
 	}{
 		{hpke.KEM_X25519_HKDF_SHA256, hpke.KDF_HKDF_SHA256, hpke.AEAD_AES128GCM},
 		{hpke.KEM_X25519_KYBER768_DRAFT00, hpke.KDF_HKDF_SHA256, hpke.AEAD_AES128GCM},
+		{hpke.KEM_MLKEM768, hpke.KDF_HKDF_SHA256, hpke.AEAD_AES128GCM},
+		{hpke.KEM_XWING, hpke.KDF_HKDF_SHA256, hpke.AEAD_AES128GCM},
 	}
 	for _, test := range tests {
 		runHpkeBenchmark(b, test.kem, test.kdf, test.aead)
@@ -11,6 +11,7 @@ import (
 	"crypto/rand"
 
 	"github.com/katzenpost/circl/kem"
+	"github.com/katzenpost/hpqc/kem/pem"
 )
 
 type hybridKEM struct {
@@ -29,31 +30,25 @@ func (h hybridKEM) EncapsulationSeedSize() int {
 func (h hybridKEM) SharedKeySize() int { return h.kemA.SharedKeySize() + h.kemB.SharedKeySize() }
 func (h hybridKEM) Name() string       { return h.name }
 
-func (h hybridKEM) AuthDecapsulate(skR kem.PrivateKey,
-	ct []byte,
-	pkS kem.PublicKey,
-) ([]byte, error) {
-	panic("AuthDecapsulate is not supported for this KEM")
-}
-
-func (h hybridKEM) AuthEncapsulate(pkr kem.PublicKey, sks kem.PrivateKey) (
-	ct []byte, ss []byte, err error,
-) {
-	panic("AuthEncapsulate is not supported for this KEM")
-}
-
-func (h hybridKEM) AuthEncapsulateDeterministically(pkr kem.PublicKey, sks kem.PrivateKey, seed []byte) (ct, ss []byte, err error) {
-	panic("AuthEncapsulateDeterministically is not supported for this KEM")
-}
-
 func (h hybridKEM) Encapsulate(pkr kem.PublicKey) (
 	ct []byte, ss []byte, err error,
 ) {
-	panic("Encapsulate is not implemented")
+	seed := make([]byte, h.EncapsulationSeedSize())
+	_, err = rand.Read(seed)
+	if err != nil {
+		return nil, nil, err
+	}
+	return h.EncapsulateDeterministically(pkr, seed)
 }
 
 func (h hybridKEM) Decapsulate(skr kem.PrivateKey, ct []byte) ([]byte, error) {
-	hybridSk := skr.(*hybridKEMPrivKey)
+	hybridSk, ok := skr.(*hybridKEMPrivKey)
+	if !ok {
+		return nil, kem.ErrTypeMismatch
+	}
+	if len(ct) != h.CiphertextSize() {
+		return nil, kem.ErrCiphertextSize
+	}
 	ssA, err := h.kemA.Decapsulate(hybridSk.privA, ct[0:h.kemA.CiphertextSize()])
 	if err != nil {
 		return nil, err
@@ -71,7 +66,13 @@ func (h hybridKEM) Decapsulate(skr kem.PrivateKey, ct []byte) ([]byte, error) {
 func (h hybridKEM) EncapsulateDeterministically(
 	pkr kem.PublicKey, seed []byte,
 ) (ct, ss []byte, err error) {
-	hybridPk := pkr.(*hybridKEMPubKey)
+	hybridPk, ok := pkr.(*hybridKEMPubKey)
+	if !ok {
+		return nil, nil, kem.ErrTypeMismatch
+	}
+	if len(seed) != h.EncapsulationSeedSize() {
+		return nil, nil, kem.ErrSeedSize
+	}
 	encA, ssA, err := h.kemA.EncapsulateDeterministically(hybridPk.pubA, seed[0:h.kemA.EncapsulationSeedSize()])
 	if err != nil {
 		return nil, nil, err
@@ -146,6 +147,10 @@ func (k hybridKEMPubKey) MarshalBinary() ([]byte, error) {
 	return append(pkA, pkB...), nil
 }
 
+func (k *hybridKEMPubKey) MarshalText() ([]byte, error) {
+	return pem.ToPublicPEMBytes(k), nil
+}
+
 func (k *hybridKEMPubKey) Equal(pk kem.PublicKey) bool {
 	k1, ok := pk.(*hybridKEMPubKey)
 	return ok &&
@@ -178,12 +183,14 @@ func (h hybridKEM) DeriveKeyPair(seed []byte) (kem.PublicKey, kem.PrivateKey) {
 	pubB, privB := h.kemB.DeriveKeyPair(seedB)
 
 	privKey := &hybridKEMPrivKey{
-		privA: privA,
-		privB: privB,
+		scheme: h,
+		privA:  privA,
+		privB:  privB,
 	}
 	pubKey := &hybridKEMPubKey{
-		pubA: pubA,
-		pubB: pubB,
+		scheme: h,
+		pubA:   pubA,
+		pubB:   pubB,
 	}
 
 	return pubKey, privKey
@@ -200,6 +207,9 @@ func (h hybridKEM) GenerateKeyPair() (kem.PublicKey, kem.PrivateKey, error) {
 }
 
 func (h hybridKEM) UnmarshalBinaryPrivateKey(data []byte) (kem.PrivateKey, error) {
+	if len(data) != h.PrivateKeySize() {
+		return nil, kem.ErrPrivKeySize
+	}
 	skA, err := h.kemA.UnmarshalBinaryPrivateKey(data[0:h.kemA.PrivateKeySize()])
 	if err != nil {
 		return nil, err
@@ -210,12 +220,16 @@ func (h hybridKEM) UnmarshalBinaryPrivateKey(data []byte) (kem.PrivateKey, error
 	}
 
 	return &hybridKEMPrivKey{
-		privA: skA,
-		privB: skB,
+		scheme: h,
+		privA:  skA,
+		privB:  skB,
 	}, nil
 }
 
 func (h hybridKEM) UnmarshalBinaryPublicKey(data []byte) (kem.PublicKey, error) {
+	if len(data) != h.PublicKeySize() {
+		return nil, kem.ErrPubKeySize
+	}
 	pkA, err := h.kemA.UnmarshalBinaryPublicKey(data[0:h.kemA.PublicKeySize()])
 	if err != nil {
 		return nil, err
@@ -226,7 +240,16 @@ func (h hybridKEM) UnmarshalBinaryPublicKey(data []byte) (kem.PublicKey, error)
 	}
 
 	return &hybridKEMPubKey{
-		pubA: pkA,
-		pubB: pkB,
+		scheme: h,
+		pubA:   pkA,
+		pubB:   pkB,
 	}, nil
 }
+
+func (h hybridKEM) UnmarshalTextPublicKey(text []byte) (kem.PublicKey, error) {
+	return pem.FromPublicPEMBytes(text, h)
+}
+
+func (h hybridKEM) UnmarshalTextPrivateKey(text []byte) (kem.PrivateKey, error) {
+	return pem.FromPrivatePEMBytes(text, h)
+}
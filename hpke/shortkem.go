@@ -8,6 +8,7 @@ import (
 	"math/big"
 
 	"github.com/katzenpost/circl/kem"
+	"github.com/katzenpost/hpqc/kem/pem"
 )
 
 type shortKEM struct {
@@ -107,6 +108,14 @@ func (s shortKEM) UnmarshalBinaryPublicKey(data []byte) (kem.PublicKey, error) {
 	return key, nil
 }
 
+func (s shortKEM) UnmarshalTextPublicKey(text []byte) (kem.PublicKey, error) {
+	return pem.FromPublicPEMBytes(text, s)
+}
+
+func (s shortKEM) UnmarshalTextPrivateKey(text []byte) (kem.PrivateKey, error) {
+	return pem.FromPrivatePEMBytes(text, s)
+}
+
 type shortKEMPubKey struct {
 	scheme shortKEM
 	x, y   *big.Int
@@ -120,6 +129,10 @@ func (k *shortKEMPubKey) MarshalBinary() ([]byte, error) {
 	return elliptic.Marshal(k.scheme, k.x, k.y), nil
 }
 
+func (k *shortKEMPubKey) MarshalText() ([]byte, error) {
+	return pem.ToPublicPEMBytes(k), nil
+}
+
 func (k *shortKEMPubKey) Equal(pk kem.PublicKey) bool {
 	k1, ok := pk.(*shortKEMPubKey)
 	return ok &&
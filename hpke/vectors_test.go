@@ -2,11 +2,13 @@ package hpke
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/katzenpost/circl/internal/test"
@@ -23,7 +25,25 @@ var (
 func TestVectors(t *testing.T) {
 	// Test vectors from
 	// https://github.com/cfrg/draft-irtf-cfrg-hpke/blob/master/test-vectors.json
-	vectors := readFile(t, "testdata/vectors_rfc9180_5f503c5.json")
+	vectors := readFile(t, "testdata/vectors_rfc9180_5f503c5.json.gz")
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("v%v", i), v.verify)
+	}
+}
+
+func TestHybridVectors(t *testing.T) {
+	// Test vectors from
+	// https://github.com/bwesterb/draft-westerbaan-cfrg-hpke-xyber768d00
+	vectors := readFile(t, "testdata/hybrid-x25119-kyber768-test-vectors.json")
+	for i, v := range vectors {
+		t.Run(fmt.Sprintf("v%v", i), v.verify)
+	}
+}
+
+func TestPQVectors(t *testing.T) {
+	// Test vectors for ML-KEM and X-Wing from draft-ietf-hpke-pq, restricted
+	// to the suites using HKDF.
+	vectors := readFile(t, "testdata/vectors_hpke_pq.json")
 	for i, v := range vectors {
 		t.Run(fmt.Sprintf("v%v", i), v.verify)
 	}
@@ -43,6 +63,7 @@ func (v *vector) verify(t *testing.T) {
 	}
 	s := NewSuite(kem, kdf, aead)
 
+	v.checkDeriveKeyPair(t, kem.Scheme())
 	sender, recv := v.getActors(t, kem.Scheme(), s)
 	sealer, opener := v.setup(t, kem.Scheme(), sender, recv, m, s)
 
@@ -53,6 +74,16 @@ func (v *vector) verify(t *testing.T) {
 	v.checkExports(t, opener, m)
 }
 
+func (v *vector) checkDeriveKeyPair(t *testing.T, k kem.Scheme) {
+	pkR, _ := k.DeriveKeyPair(hexB(t, v.IkmR))
+	got, err := pkR.MarshalBinary()
+	test.CheckNoErr(t, err, "bad public key")
+	want := hexB(t, v.PkRm)
+	if !bytes.Equal(got, want) {
+		test.ReportError(t, got, want, k.Name())
+	}
+}
+
 func (v *vector) getActors(
 	t *testing.T, dhkem kem.Scheme, s Suite,
 ) (*Sender, *Receiver) {
@@ -79,6 +110,9 @@ func (v *vector) setup(t *testing.T, k kem.Scheme,
 	m modeID, s Suite,
 ) (sealer Sealer, opener Opener) {
 	seed := hexB(t, v.IkmE)
+	if v.Ier != "" {
+		seed = hexB(t, v.Ier)
+	}
 	rd := bytes.NewReader(seed)
 
 	var enc []byte
@@ -197,7 +231,16 @@ func readFile(t *testing.T, fileName string) []vector {
 		t.Fatalf("File %v can not be opened. Error: %v", fileName, err)
 	}
 	defer jsonFile.Close()
-	input, err := io.ReadAll(jsonFile)
+	var r io.Reader = jsonFile
+	if strings.HasSuffix(fileName, ".gz") {
+		gz, err := gzip.NewReader(jsonFile)
+		if err != nil {
+			t.Fatalf("File %v can not be decompressed. Error: %v", fileName, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	input, err := io.ReadAll(r)
 	if err != nil {
 		t.Fatalf("File %v can not be read. Error: %v", fileName, err)
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/katzenpost/circl/dh/x25519"
 	"github.com/katzenpost/circl/dh/x448"
 	"github.com/katzenpost/circl/kem"
+	"github.com/katzenpost/hpqc/kem/pem"
 )
 
 type xKEM struct {
@@ -105,6 +106,14 @@ func (x xKEM) UnmarshalBinaryPublicKey(data []byte) (kem.PublicKey, error) {
 	return pk, nil
 }
 
+func (x xKEM) UnmarshalTextPublicKey(text []byte) (kem.PublicKey, error) {
+	return pem.FromPublicPEMBytes(text, x)
+}
+
+func (x xKEM) UnmarshalTextPrivateKey(text []byte) (kem.PrivateKey, error) {
+	return pem.FromPrivatePEMBytes(text, x)
+}
+
 type xKEMPubKey struct {
 	scheme xKEM
 	pub    []byte
@@ -116,6 +125,10 @@ func (k *xKEMPubKey) MarshalBinary() ([]byte, error) {
 	return append(make([]byte, 0, k.scheme.PublicKeySize()), k.pub...), nil
 }
 
+func (k *xKEMPubKey) MarshalText() ([]byte, error) {
+	return pem.ToPublicPEMBytes(k), nil
+}
+
 func (k *xKEMPubKey) Equal(pk kem.PublicKey) bool {
 	k1, ok := pk.(*xKEMPubKey)
 	return ok &&
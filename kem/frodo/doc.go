@@ -1,5 +1,11 @@
+//go:generate go run gen.go
+
 // Package frodo provides the key encapsulation mechanism FrodoKEM.
 //
+// The parameter sets FrodoKEM-640, FrodoKEM-976 and FrodoKEM-1344 are
+// implemented in the subpackages. The suffix denotes whether the public
+// matrix A is generated with SHAKE128 or AES-128.
+//
 // Compatible with the implementation submitted to round 3 of the
 // NIST PQC competition [1]. This implementation draws heavily from the PQClean
 // implementation [2].
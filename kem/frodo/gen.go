@@ -0,0 +1,159 @@
+//go:build ignore
+// +build ignore
+
+// Autogenerates wrappers from templates to prevent too much duplicated code
+// between the code for different modes.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"strings"
+	"text/template"
+)
+
+type Param struct {
+	N              int
+	LogQ           int
+	ExtractedBits  int
+	SharedKeySize  int
+	PublicKeySize  int
+	PrivateKeySize int
+	CiphertextSize int
+	CdfTable       []uint16
+}
+
+func (p Param) CdfTableLen() int { return len(p.CdfTable) }
+
+func (p Param) CdfTableString() string {
+	s := make([]string, len(p.CdfTable))
+	for i, v := range p.CdfTable {
+		s[i] = fmt.Sprint(v)
+	}
+	return strings.Join(s, ", ")
+}
+
+type Instance struct {
+	Name  string
+	Param Param
+}
+
+func (m Instance) Pkg() string {
+	return "frodo" + strings.ToLower(strings.ReplaceAll(strings.TrimPrefix(m.Name, "FrodoKEM-"), "-", ""))
+}
+
+// Matrix returns the primitive used to generate the matrix A.
+func (m Instance) Matrix() string {
+	return m.Name[strings.LastIndex(m.Name, "-")+1:]
+}
+
+func (m Instance) IsAES() bool {
+	return m.Matrix() == "AES"
+}
+
+// Xof returns the function used for hashing. FrodoKEM-640 uses SHAKE128,
+// whereas the larger parameter sets use SHAKE256.
+func (m Instance) Xof() string {
+	if m.Param.N == 640 {
+		return "NewShake128"
+	}
+	return "NewShake256"
+}
+
+var (
+	FrodoParam640 = Param{
+		N:              640,
+		LogQ:           15,
+		ExtractedBits:  2,
+		SharedKeySize:  16,
+		PublicKeySize:  9616,
+		PrivateKeySize: 19888,
+		CiphertextSize: 9720,
+		CdfTable: []uint16{
+			4643, 13363, 20579, 25843, 29227, 31145, 32103, 32525,
+			32689, 32745, 32762, 32766, 32767,
+		},
+	}
+	FrodoParam976 = Param{
+		N:              976,
+		LogQ:           16,
+		ExtractedBits:  3,
+		SharedKeySize:  24,
+		PublicKeySize:  15632,
+		PrivateKeySize: 31296,
+		CiphertextSize: 15744,
+		CdfTable: []uint16{
+			5638, 15915, 23689, 28571, 31116, 32217, 32613, 32731,
+			32760, 32766, 32767,
+		},
+	}
+	FrodoParam1344 = Param{
+		N:              1344,
+		LogQ:           16,
+		ExtractedBits:  4,
+		SharedKeySize:  32,
+		PublicKeySize:  21520,
+		PrivateKeySize: 43088,
+		CiphertextSize: 21632,
+		CdfTable: []uint16{
+			9142, 23462, 30338, 32361, 32725, 32765, 32767,
+		},
+	}
+	Instances = []Instance{
+		{Name: "FrodoKEM-640-SHAKE", Param: FrodoParam640},
+		{Name: "FrodoKEM-976-SHAKE", Param: FrodoParam976},
+		{Name: "FrodoKEM-976-AES", Param: FrodoParam976},
+		{Name: "FrodoKEM-1344-SHAKE", Param: FrodoParam1344},
+		{Name: "FrodoKEM-1344-AES", Param: FrodoParam1344},
+	}
+
+	TemplateWarning = "// Code generated from"
+)
+
+func main() {
+	generateTemplateFiles("templates/frodo.templ.go", "frodo")
+	generateTemplateFiles("templates/noise.templ.go", "noise")
+	generateTemplateFiles("templates/util.templ.go", "util")
+	generateTemplateFilesIf("templates/matrix_shake.templ.go", "matrix_shake", func(m Instance) bool { return !m.IsAES() })
+	generateTemplateFilesIf("templates/matrix_aes.templ.go", "matrix_aes", func(m Instance) bool { return m.IsAES() })
+}
+
+func generateTemplateFiles(templatePath, outputName string) {
+	generateTemplateFilesIf(templatePath, outputName, func(instance Instance) bool { return true })
+}
+
+func generateTemplateFilesIf(templatePath, outputName string, predicate func(Instance) bool) {
+	tl, err := template.ParseFiles(templatePath)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, mode := range Instances {
+		if !predicate(mode) {
+			continue
+		}
+		buf := new(bytes.Buffer)
+		err := tl.Execute(buf, mode)
+		if err != nil {
+			panic(err)
+		}
+
+		// Formating output code
+		code, err := format.Source(buf.Bytes())
+		if err != nil {
+			panic(fmt.Sprintf("error formating code: %v", err))
+		}
+
+		res := string(code)
+		offset := strings.Index(res, TemplateWarning)
+		if offset == -1 {
+			panic("Missing template warning in " + templatePath)
+		}
+		err = ioutil.WriteFile(mode.Pkg()+"/"+outputName+".go", []byte(res[offset:]), 0o644)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
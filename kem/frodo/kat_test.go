@@ -30,6 +30,27 @@ func TestPQCgenKATKem(t *testing.T) {
 	}
 }
 
+// TestPQCgenRegression guards the remaining modes against unintended
+// changes. Its digests were computed with this package and not from the
+// reference KAT files, so they are no known-answer tests.
+func TestPQCgenRegression(t *testing.T) {
+	kats := []struct {
+		name string
+		want string
+	}{
+		{"FrodoKEM-976-SHAKE", "32b0ad60047273fb52696f0516acac7ed083e31f5478b416d579ae5e8d8e734c"},
+		{"FrodoKEM-976-AES", "32ed6b1622c845b487c3170ce6878df7baae07e90bd2819a19e5960ce04a55f7"},
+		{"FrodoKEM-1344-SHAKE", "591adc09a718afbc0ac36e1f57a191e557fe4eec7899e078104b9706b75e2f96"},
+		{"FrodoKEM-1344-AES", "9756f7c8cc88d7048ff6e81fa66425bb1392e35c1d30016c190dba17de15221a"},
+	}
+	for _, kat := range kats {
+		kat := kat
+		t.Run(kat.name, func(t *testing.T) {
+			testPQCgenKATKem(t, kat.name, kat.want)
+		})
+	}
+}
+
 func testPQCgenKATKem(t *testing.T, name, expected string) {
 	scheme := schemes.ByName(name)
 	if scheme == nil {
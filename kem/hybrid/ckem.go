@@ -200,6 +200,9 @@ func (sch *cScheme) UnmarshalBinaryPublicKey(buf []byte) (kem.PublicKey, error)
 		return nil, kem.ErrPubKeySize
 	}
 	x, y := elliptic.Unmarshal(sch.curve, buf)
+	if x == nil {
+		return nil, kem.ErrPubKey
+	}
 	return &cPublicKey{sch, x, y}, nil
 }
 
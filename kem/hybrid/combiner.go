@@ -0,0 +1,518 @@
+package hybrid
+
+import (
+	"errors"
+	"hash"
+	"io"
+
+	"github.com/katzenpost/hpqc/kem"
+	"github.com/katzenpost/hpqc/kem/pem"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/katzenpost/circl/internal/sha3"
+)
+
+// A Combiner derives the shared key of a combined KEM from the shared keys,
+// ciphertexts and public keys of its components.
+type Combiner interface {
+	// Name of the combiner.
+	Name() string
+
+	// Size of the shared key produced when combining the given schemes.
+	SharedKeySize(schemes []kem.Scheme) int
+
+	// Combine returns the shared key derived from the shared keys ss,
+	// ciphertexts ct and packed public keys pk of the components, each
+	// given in the order of the schemes.
+	Combine(ss, ct, pk [][]byte) []byte
+}
+
+// ConcatCombiner concatenates the shared keys of the components, which is
+// how the fixed hybrids in this package combine shared keys.
+//
+// This is only fine if the shared key is used in its entirety in a next
+// step, such as being hashed together with the ciphertext.
+var ConcatCombiner Combiner = concatCombiner{}
+
+// SHA3Combiner derives the shared key as
+//
+//	SHA3-256(ss₁ ‖ … ‖ ssₙ ‖ ct₁ ‖ … ‖ ctₙ ‖ pk₁ ‖ … ‖ pkₙ).
+//
+// Binding the ciphertexts and public keys makes the combined KEM IND-CCA
+// secure as long as one of its components is, see
+//
+//	https://eprint.iacr.org/2018/024
+var SHA3Combiner Combiner = sha3Combiner{}
+
+// NewHKDFCombiner returns a Combiner that derives the shared key as
+//
+//	HKDF-Expand(HKDF-Extract(label, ss₁ ‖ … ‖ ssₙ),
+//	            label ‖ ct₁ ‖ … ‖ ctₙ ‖ pk₁ ‖ … ‖ pkₙ, L)
+//
+// using the hash function h, where L is the output size of h. The label
+// provides domain separation between applications.
+func NewHKDFCombiner(h func() hash.Hash, label []byte) Combiner {
+	l := make([]byte, len(label))
+	copy(l, label)
+	return &hkdfCombiner{h: h, label: l}
+}
+
+type concatCombiner struct{}
+
+func (concatCombiner) Name() string { return "Concat" }
+
+func (concatCombiner) SharedKeySize(schemes []kem.Scheme) int {
+	ret := 0
+	for _, s := range schemes {
+		ret += s.SharedKeySize()
+	}
+	return ret
+}
+
+func (concatCombiner) Combine(ss, _, _ [][]byte) []byte {
+	var ret []byte
+	for _, s := range ss {
+		ret = append(ret, s...)
+	}
+	return ret
+}
+
+type sha3Combiner struct{}
+
+func (sha3Combiner) Name() string                   { return "SHA3-256" }
+func (sha3Combiner) SharedKeySize([]kem.Scheme) int { return 32 }
+
+func (sha3Combiner) Combine(ss, ct, pk [][]byte) []byte {
+	h := sha3.New256()
+	writeAll(&h, ss, ct, pk)
+	ret := make([]byte, 32)
+	_, _ = h.Read(ret)
+	return ret
+}
+
+type hkdfCombiner struct {
+	h     func() hash.Hash
+	label []byte
+}
+
+func (c *hkdfCombiner) Name() string                   { return "HKDF" }
+func (c *hkdfCombiner) SharedKeySize([]kem.Scheme) int { return c.h().Size() }
+
+func (c *hkdfCombiner) Combine(ss, ct, pk [][]byte) []byte {
+	var ikm, info []byte
+	for _, s := range ss {
+		ikm = append(ikm, s...)
+	}
+	info = append(info, c.label...)
+	for _, b := range [][][]byte{ct, pk} {
+		for _, x := range b {
+			info = append(info, x...)
+		}
+	}
+
+	prk := hkdf.Extract(c.h, ikm, c.label)
+	ret := make([]byte, c.h().Size())
+	_, _ = io.ReadFull(hkdf.Expand(c.h, prk, info), ret)
+	return ret
+}
+
+func writeAll(w io.Writer, bufs ...[][]byte) {
+	for _, b := range bufs {
+		for _, x := range b {
+			_, _ = w.Write(x)
+		}
+	}
+}
+
+// Returns the X25519 based KEM used as classical component of the hybrids
+// in this package.
+func X25519() kem.Scheme { return x25519Kem }
+
+// Returns the X448 based KEM used as classical component of the hybrids
+// in this package.
+func X448() kem.Scheme { return x448Kem }
+
+// Returns the P-256 based KEM used as classical component of the hybrids
+// in this package.
+func P256() kem.Scheme { return p256Kem }
+
+// New returns a KEM that combines the given schemes using combiner.
+//
+// Public keys, private keys and ciphertexts are the concatenation of
+// those of the components. As with the fixed hybrids in this package,
+// the seeds for DeriveKeyPair and EncapsulateDeterministically are
+// expanded to the seeds of each component using SHAKE256.
+//
+// Panics if fewer than two schemes are given.
+func New(name string, combiner Combiner, schemes ...kem.Scheme) kem.Scheme {
+	if len(schemes) < 2 {
+		panic("hybrid: at least two schemes are required")
+	}
+	for _, s := range schemes {
+		if s == nil {
+			panic("hybrid: scheme cannot be nil")
+		}
+	}
+	if combiner == nil {
+		panic("hybrid: combiner cannot be nil")
+	}
+	sch := &nScheme{
+		name:     name,
+		combiner: combiner,
+		schemes:  make([]kem.Scheme, len(schemes)),
+	}
+	copy(sch.schemes, schemes)
+	return sch
+}
+
+// ErrCombinerMismatch is returned by Decapsulate if the combiner does not
+// produce a shared key of the advertised size.
+var ErrCombinerMismatch = errors.New("combiner produced shared key of wrong size")
+
+// Public key of a combined KEM.
+type nPublicKey struct {
+	scheme *nScheme
+	keys   []kem.PublicKey
+}
+
+// Private key of a combined KEM.
+type nPrivateKey struct {
+	scheme *nScheme
+	keys   []kem.PrivateKey
+	pks    [][]byte // packed public keys of the components
+}
+
+// Scheme for a KEM combining an arbitrary number of KEMs.
+type nScheme struct {
+	name     string
+	combiner Combiner
+	schemes  []kem.Scheme
+}
+
+func (sch *nScheme) Name() string { return sch.name }
+
+func (sch *nScheme) PublicKeySize() int {
+	ret := 0
+	for _, s := range sch.schemes {
+		ret += s.PublicKeySize()
+	}
+	return ret
+}
+
+func (sch *nScheme) PrivateKeySize() int {
+	ret := 0
+	for _, s := range sch.schemes {
+		ret += s.PrivateKeySize()
+	}
+	return ret
+}
+
+func (sch *nScheme) SeedSize() int {
+	ret := 0
+	for _, s := range sch.schemes {
+		if s.SeedSize() > ret {
+			ret = s.SeedSize()
+		}
+	}
+	return ret
+}
+
+func (sch *nScheme) SharedKeySize() int {
+	return sch.combiner.SharedKeySize(sch.schemes)
+}
+
+func (sch *nScheme) CiphertextSize() int {
+	ret := 0
+	for _, s := range sch.schemes {
+		ret += s.CiphertextSize()
+	}
+	return ret
+}
+
+func (sch *nScheme) EncapsulationSeedSize() int {
+	ret := 0
+	for _, s := range sch.schemes {
+		if s.EncapsulationSeedSize() > ret {
+			ret = s.EncapsulationSeedSize()
+		}
+	}
+	return ret
+}
+
+func (sk *nPrivateKey) Scheme() kem.Scheme { return sk.scheme }
+func (pk *nPublicKey) Scheme() kem.Scheme  { return pk.scheme }
+
+func (sk *nPrivateKey) MarshalBinary() ([]byte, error) {
+	var ret []byte
+	for _, k := range sk.keys {
+		if k == nil {
+			return nil, ErrUninitialized
+		}
+		buf, err := k.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, buf...)
+	}
+	return ret, nil
+}
+
+func (sk *nPrivateKey) Equal(other kem.PrivateKey) bool {
+	oth, ok := other.(*nPrivateKey)
+	if !ok || oth.scheme != sk.scheme || len(oth.keys) != len(sk.keys) {
+		return false
+	}
+	for i := range sk.keys {
+		if sk.keys[i] == nil || oth.keys[i] == nil {
+			if sk.keys[i] != oth.keys[i] {
+				return false
+			}
+			continue
+		}
+		if !sk.keys[i].Equal(oth.keys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (sk *nPrivateKey) Public() kem.PublicKey {
+	pk := &nPublicKey{sk.scheme, make([]kem.PublicKey, len(sk.keys))}
+	for i, k := range sk.keys {
+		pk.keys[i] = k.Public()
+	}
+	return pk
+}
+
+func (pk *nPublicKey) Equal(other kem.PublicKey) bool {
+	oth, ok := other.(*nPublicKey)
+	if !ok || oth.scheme != pk.scheme || len(oth.keys) != len(pk.keys) {
+		return false
+	}
+	for i := range pk.keys {
+		if pk.keys[i] == nil || oth.keys[i] == nil {
+			if pk.keys[i] != oth.keys[i] {
+				return false
+			}
+			continue
+		}
+		if !pk.keys[i].Equal(oth.keys[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (pk *nPublicKey) MarshalBinary() ([]byte, error) {
+	var ret []byte
+	for _, k := range pk.keys {
+		if k == nil {
+			return nil, ErrUninitialized
+		}
+		buf, err := k.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, buf...)
+	}
+	return ret, nil
+}
+
+func (pk *nPublicKey) MarshalText() (text []byte, err error) {
+	return pem.ToPublicPEMBytes(pk), nil
+}
+
+// Returns a private key with the given components, and caches their
+// packed public keys, which Decapsulate passes to the combiner.
+func (sch *nScheme) newPrivateKey(keys []kem.PrivateKey) (*nPrivateKey, error) {
+	sk := &nPrivateKey{scheme: sch, keys: keys}
+	pks, err := sk.Public().(*nPublicKey).pack()
+	if err != nil {
+		return nil, err
+	}
+	sk.pks = pks
+	return sk, nil
+}
+
+// Returns the packed public keys of the components.
+func (pk *nPublicKey) pack() ([][]byte, error) {
+	ret := make([][]byte, len(pk.keys))
+	for i, k := range pk.keys {
+		if k == nil {
+			return nil, ErrUninitialized
+		}
+		buf, err := k.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = buf
+	}
+	return ret, nil
+}
+
+func (sch *nScheme) GenerateKeyPair() (kem.PublicKey, kem.PrivateKey, error) {
+	pk := &nPublicKey{sch, make([]kem.PublicKey, len(sch.schemes))}
+	keys := make([]kem.PrivateKey, len(sch.schemes))
+	for i, s := range sch.schemes {
+		var err error
+		pk.keys[i], keys[i], err = s.GenerateKeyPair()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	sk, err := sch.newPrivateKey(keys)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pk, sk, nil
+}
+
+func (sch *nScheme) DeriveKeyPair(seed []byte) (kem.PublicKey, kem.PrivateKey) {
+	if len(seed) != sch.SeedSize() {
+		panic(kem.ErrSeedSize)
+	}
+	h := sha3.NewShake256()
+	_, _ = h.Write(seed)
+
+	pk := &nPublicKey{sch, make([]kem.PublicKey, len(sch.schemes))}
+	keys := make([]kem.PrivateKey, len(sch.schemes))
+	for i, s := range sch.schemes {
+		buf := make([]byte, s.SeedSize())
+		_, _ = h.Read(buf)
+		pk.keys[i], keys[i] = s.DeriveKeyPair(buf)
+	}
+	sk, err := sch.newPrivateKey(keys)
+	if err != nil {
+		panic(err)
+	}
+	return pk, sk
+}
+
+func (sch *nScheme) Encapsulate(pk kem.PublicKey) (ct, ss []byte, err error) {
+	pub, ok := pk.(*nPublicKey)
+	if !ok || pub.scheme != sch {
+		return nil, nil, kem.ErrTypeMismatch
+	}
+
+	cts := make([][]byte, len(sch.schemes))
+	sss := make([][]byte, len(sch.schemes))
+	for i, s := range sch.schemes {
+		cts[i], sss[i], err = s.Encapsulate(pub.keys[i])
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return sch.combine(pub, cts, sss)
+}
+
+func (sch *nScheme) EncapsulateDeterministically(
+	pk kem.PublicKey, seed []byte,
+) (ct, ss []byte, err error) {
+	if len(seed) != sch.EncapsulationSeedSize() {
+		return nil, nil, kem.ErrSeedSize
+	}
+	pub, ok := pk.(*nPublicKey)
+	if !ok || pub.scheme != sch {
+		return nil, nil, kem.ErrTypeMismatch
+	}
+
+	h := sha3.NewShake256()
+	_, _ = h.Write(seed)
+
+	cts := make([][]byte, len(sch.schemes))
+	sss := make([][]byte, len(sch.schemes))
+	for i, s := range sch.schemes {
+		buf := make([]byte, s.EncapsulationSeedSize())
+		_, _ = h.Read(buf)
+		cts[i], sss[i], err = s.EncapsulateDeterministically(pub.keys[i], buf)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return sch.combine(pub, cts, sss)
+}
+
+func (sch *nScheme) combine(
+	pub *nPublicKey, cts, sss [][]byte,
+) (ct, ss []byte, err error) {
+	pks, err := pub.pack()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range cts {
+		ct = append(ct, c...)
+	}
+	ss = sch.combiner.Combine(sss, cts, pks)
+	if len(ss) != sch.SharedKeySize() {
+		return nil, nil, ErrCombinerMismatch
+	}
+	return ct, ss, nil
+}
+
+func (sch *nScheme) Decapsulate(sk kem.PrivateKey, ct []byte) ([]byte, error) {
+	if len(ct) != sch.CiphertextSize() {
+		return nil, kem.ErrCiphertextSize
+	}
+	priv, ok := sk.(*nPrivateKey)
+	if !ok || priv.scheme != sch {
+		return nil, kem.ErrTypeMismatch
+	}
+
+	cts := make([][]byte, len(sch.schemes))
+	sss := make([][]byte, len(sch.schemes))
+	for i, s := range sch.schemes {
+		var err error
+		cts[i] = ct[:s.CiphertextSize()]
+		ct = ct[s.CiphertextSize():]
+		sss[i], err = s.Decapsulate(priv.keys[i], cts[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ss := sch.combiner.Combine(sss, cts, priv.pks)
+	if len(ss) != sch.SharedKeySize() {
+		return nil, ErrCombinerMismatch
+	}
+	return ss, nil
+}
+
+func (sch *nScheme) UnmarshalBinaryPublicKey(buf []byte) (kem.PublicKey, error) {
+	if len(buf) != sch.PublicKeySize() {
+		return nil, kem.ErrPubKeySize
+	}
+	pk := &nPublicKey{sch, make([]kem.PublicKey, len(sch.schemes))}
+	for i, s := range sch.schemes {
+		var err error
+		pk.keys[i], err = s.UnmarshalBinaryPublicKey(buf[:s.PublicKeySize()])
+		if err != nil {
+			return nil, err
+		}
+		buf = buf[s.PublicKeySize():]
+	}
+	return pk, nil
+}
+
+func (sch *nScheme) UnmarshalBinaryPrivateKey(buf []byte) (kem.PrivateKey, error) {
+	if len(buf) != sch.PrivateKeySize() {
+		return nil, kem.ErrPrivKeySize
+	}
+	keys := make([]kem.PrivateKey, len(sch.schemes))
+	for i, s := range sch.schemes {
+		var err error
+		keys[i], err = s.UnmarshalBinaryPrivateKey(buf[:s.PrivateKeySize()])
+		if err != nil {
+			return nil, err
+		}
+		buf = buf[s.PrivateKeySize():]
+	}
+	return sch.newPrivateKey(keys)
+}
+
+func (sch *nScheme) UnmarshalTextPublicKey(text []byte) (kem.PublicKey, error) {
+	return pem.FromPublicPEMBytes(text, sch)
+}
+
+func (sch *nScheme) UnmarshalTextPrivateKey(text []byte) (kem.PrivateKey, error) {
+	return pem.FromPrivatePEMBytes(text, sch)
+}
@@ -0,0 +1,185 @@
+package hybrid_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/katzenpost/hpqc/kem"
+
+	"github.com/katzenpost/circl/internal/sha3"
+	"github.com/katzenpost/circl/kem/frodo/frodo640shake"
+	"github.com/katzenpost/circl/kem/hybrid"
+	"github.com/katzenpost/circl/kem/mlkem/mlkem768"
+)
+
+func combined(c hybrid.Combiner) kem.Scheme {
+	return hybrid.New(
+		"X25519-ML-KEM-768-FrodoKEM-640-SHAKE-"+c.Name(),
+		c,
+		hybrid.X25519(),
+		mlkem768.Scheme(),
+		frodo640shake.Scheme(),
+	)
+}
+
+func TestCombiners(t *testing.T) {
+	for _, c := range []hybrid.Combiner{
+		hybrid.ConcatCombiner,
+		hybrid.SHA3Combiner,
+		hybrid.NewHKDFCombiner(sha256.New, []byte("test label")),
+	} {
+		c := c
+		t.Run(c.Name(), func(t *testing.T) {
+			testScheme(t, combined(c))
+		})
+	}
+}
+
+func testScheme(t *testing.T, scheme kem.Scheme) {
+	seed := make([]byte, scheme.SeedSize())
+	eseed := make([]byte, scheme.EncapsulationSeedSize())
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	for i := range eseed {
+		eseed[i] = byte(i + 1)
+	}
+
+	pk, sk := scheme.DeriveKeyPair(seed)
+	pk2, sk2 := scheme.DeriveKeyPair(seed)
+	if !pk.Equal(pk2) || !sk.Equal(sk2) {
+		t.Fatal("DeriveKeyPair is not deterministic")
+	}
+	if !sk.Public().Equal(pk) {
+		t.Fatal()
+	}
+
+	ppk, err := pk.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	psk, err := sk.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ppk) != scheme.PublicKeySize() || len(psk) != scheme.PrivateKeySize() {
+		t.Fatal()
+	}
+	pk3, err := scheme.UnmarshalBinaryPublicKey(ppk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk3, err := scheme.UnmarshalBinaryPrivateKey(psk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pk.Equal(pk3) || !sk.Equal(sk3) {
+		t.Fatal()
+	}
+
+	ct, ss, err := scheme.EncapsulateDeterministically(pk3, eseed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ct) != scheme.CiphertextSize() || len(ss) != scheme.SharedKeySize() {
+		t.Fatal()
+	}
+	ct2, ss2, err := scheme.EncapsulateDeterministically(pk, eseed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ct, ct2) || !bytes.Equal(ss, ss2) {
+		t.Fatal("EncapsulateDeterministically is not deterministic")
+	}
+	ss3, err := scheme.Decapsulate(sk3, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ss, ss3) {
+		t.Fatal("shared key mismatch")
+	}
+
+	ct, ss, err = scheme.Encapsulate(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss3, err = scheme.Decapsulate(sk, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ss, ss3) {
+		t.Fatal("shared key mismatch")
+	}
+
+	// Keys of another combined scheme must be rejected.
+	other := hybrid.New("other", hybrid.ConcatCombiner,
+		hybrid.X25519(), mlkem768.Scheme())
+	opk, _, _ := other.GenerateKeyPair()
+	if _, _, err := scheme.Encapsulate(opk); err != kem.ErrTypeMismatch {
+		t.Fatal("expected type mismatch")
+	}
+}
+
+func TestSHA3Combiner(t *testing.T) {
+	ss := [][]byte{{1, 2}, {3}}
+	ct := [][]byte{{4}, {5, 6}}
+	pk := [][]byte{{7, 8, 9}, {}}
+	got := hybrid.SHA3Combiner.Combine(ss, ct, pk)
+
+	var want [32]byte
+	h := sha3.New256()
+	_, _ = h.Write([]byte{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	_, _ = h.Read(want[:])
+	if !bytes.Equal(got, want[:]) {
+		t.Fatalf("%x ≠ %x", got, want)
+	}
+}
+
+func TestConcatCombiner(t *testing.T) {
+	// The concatenating combiner must agree with the fixed hybrids when
+	// given the same components in the same order.
+	scheme := hybrid.New("X25519MLKEM768", hybrid.ConcatCombiner,
+		mlkem768.Scheme(), hybrid.X25519())
+	fixed := hybrid.X25519MLKEM768()
+
+	seed := make([]byte, fixed.SeedSize())
+	eseed := make([]byte, fixed.EncapsulationSeedSize())
+	pk, _ := scheme.DeriveKeyPair(seed)
+	pk2, _ := fixed.DeriveKeyPair(seed)
+	ppk, _ := pk.MarshalBinary()
+	ppk2, _ := pk2.MarshalBinary()
+	if !bytes.Equal(ppk, ppk2) {
+		t.Fatal("public key mismatch")
+	}
+
+	ct, ss, err := scheme.EncapsulateDeterministically(pk, eseed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct2, ss2, err := fixed.EncapsulateDeterministically(pk2, eseed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ct, ct2) || !bytes.Equal(ss, ss2) {
+		t.Fatal("encapsulation mismatch")
+	}
+}
+
+func TestHKDFCombinerLabel(t *testing.T) {
+	a := combined(hybrid.NewHKDFCombiner(sha256.New, []byte("a")))
+	b := combined(hybrid.NewHKDFCombiner(sha256.New, []byte("b")))
+
+	seed := make([]byte, a.SeedSize())
+	eseed := make([]byte, a.EncapsulationSeedSize())
+	pka, _ := a.DeriveKeyPair(seed)
+	pkb, _ := b.DeriveKeyPair(seed)
+	cta, ssa, _ := a.EncapsulateDeterministically(pka, eseed)
+	ctb, ssb, _ := b.EncapsulateDeterministically(pkb, eseed)
+	if !bytes.Equal(cta, ctb) {
+		t.Fatal("ciphertexts should not depend on the label")
+	}
+	if bytes.Equal(ssa, ssb) {
+		t.Fatal("shared keys should depend on the label")
+	}
+}
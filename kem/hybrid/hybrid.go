@@ -9,6 +9,18 @@
 // Note that this is only fine if the shared secret is used in its entirety
 // in a next step, such as being hashed or used as key.
 //
+// Arbitrary KEMs can be combined with New, which lets the caller choose
+// how the shared secrets are combined, for instance by hashing them
+// together with the ciphertexts and public keys using SHA3Combiner.
+//
+// X25519MLKEM768 and SecP256r1MLKEM768 are the hybrids registered for TLS,
+// see
+//
+//	https://datatracker.ietf.org/doc/draft-kwiatkowski-tls-ecdhe-mlkem/
+//
+// Contrary to the draft combinations, X25519MLKEM768 puts the ML-KEM part
+// first; SecP256r1MLKEM768 puts the P-256 part first.
+//
 // For deriving a KEM keypair deterministically and encapsulating
 // deterministically, we expand a single seed to both using SHAKE256,
 // so that a non-uniform seed (such as a shared secret generated by a hybrid
@@ -39,6 +51,7 @@ import (
 	"github.com/katzenpost/circl/kem/kyber/kyber1024"
 	"github.com/katzenpost/circl/kem/kyber/kyber512"
 	"github.com/katzenpost/circl/kem/kyber/kyber768"
+	"github.com/katzenpost/circl/kem/mlkem/mlkem768"
 )
 
 var ErrUninitialized = errors.New("public or private key not initialized")
@@ -58,6 +71,14 @@ func Kyber1024X448() kem.Scheme { return kyber1024X }
 // Returns the hybrid KEM of Kyber768Draft00 and P-256.
 func P256Kyber768Draft00() kem.Scheme { return p256Kyber768Draft00 }
 
+// Returns the hybrid KEM of ML-KEM-768 and X25519, which is registered
+// for TLS with codepoint 0x11EC.
+func X25519MLKEM768() kem.Scheme { return xmlkem768 }
+
+// Returns the hybrid KEM of P-256 and ML-KEM-768, which is registered
+// for TLS with codepoint 0x11EB.
+func SecP256r1MLKEM768() kem.Scheme { return p256mlkem768 }
+
 var p256Kyber768Draft00 kem.Scheme = &scheme{
 	"P256Kyber768Draft00",
 	p256Kem,
@@ -88,6 +109,18 @@ var kyber1024X kem.Scheme = &scheme{
 	kyber1024.Scheme(),
 }
 
+var xmlkem768 kem.Scheme = &scheme{
+	"X25519MLKEM768",
+	mlkem768.Scheme(),
+	x25519Kem,
+}
+
+var p256mlkem768 kem.Scheme = &scheme{
+	"SecP256r1MLKEM768",
+	p256Kem,
+	mlkem768.Scheme(),
+}
+
 // Public key of a hybrid KEM.
 type publicKey struct {
 	scheme *scheme
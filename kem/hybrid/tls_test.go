@@ -0,0 +1,108 @@
+//go:build go1.24
+// +build go1.24
+
+package hybrid_test
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/rand"
+	"testing"
+
+	"github.com/katzenpost/circl/kem/hybrid"
+)
+
+// Checks that X25519MLKEM768 can be decapsulated from a key share
+// computed as a TLS client following draft-kwiatkowski-tls-ecdhe-mlkem would.
+func TestX25519MLKEM768Interop(t *testing.T) {
+	scheme := hybrid.X25519MLKEM768()
+	pk, sk, err := scheme.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ppk, err := pk.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ppk) != mlkem.EncapsulationKeySize768+32 {
+		t.Fatal()
+	}
+
+	ek, err := mlkem.NewEncapsulationKey768(ppk[:mlkem.EncapsulationKeySize768])
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer, err := ecdh.X25519().NewPublicKey(ppk[mlkem.EncapsulationKeySize768:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss1, ct1 := ek.Encapsulate()
+	ss2, err := eph.ECDH(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ct := append(ct1, eph.PublicKey().Bytes()...)
+	ss, err := scheme.Decapsulate(sk, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ss, append(ss1, ss2...)) {
+		t.Fatal("shared secret mismatch")
+	}
+}
+
+// Checks that SecP256r1MLKEM768 can be decapsulated from a key share
+// computed as a TLS client following draft-kwiatkowski-tls-ecdhe-mlkem would.
+func TestSecP256r1MLKEM768Interop(t *testing.T) {
+	scheme := hybrid.SecP256r1MLKEM768()
+	pk, sk, err := scheme.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ppk, err := pk.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ppk) != 65+mlkem.EncapsulationKeySize768 {
+		t.Fatal()
+	}
+
+	peer, err := ecdh.P256().NewPublicKey(ppk[:65])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek, err := mlkem.NewEncapsulationKey768(ppk[65:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	eph, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss1, err := eph.ECDH(peer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss2, ct2 := ek.Encapsulate()
+
+	ct := append(eph.PublicKey().Bytes(), ct2...)
+	ss, err := scheme.Decapsulate(sk, ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(ss, append(ss1, ss2...)) {
+		t.Fatal("shared secret mismatch")
+	}
+
+	// A ciphertext with an invalid point must be rejected.
+	ct[1] ^= 0xff
+	if _, err := scheme.Decapsulate(sk, ct); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -6,80 +6,23 @@
 package kem
 
 import (
-	"encoding"
-	"errors"
+	hpqcKem "github.com/katzenpost/hpqc/kem"
 )
 
-// A KEM public key
-type PublicKey interface {
-	// Returns the scheme for this public key
-	Scheme() Scheme
+// The KEM interfaces are shared with github.com/katzenpost/hpqc/kem, so that
+// every KEM in this module, including the HPKE DHKEMs, can be used both from
+// the hpke package and from the kem/schemes register. Keys support binary
+// and text (PEM) encodings.
+type (
+	// A KEM public key
+	PublicKey = hpqcKem.PublicKey
 
-	encoding.BinaryMarshaler
-	Equal(PublicKey) bool
-}
-
-// A KEM private key
-type PrivateKey interface {
-	// Returns the scheme for this private key
-	Scheme() Scheme
-
-	encoding.BinaryMarshaler
-	Equal(PrivateKey) bool
-	Public() PublicKey
-}
-
-// A Scheme represents a specific instance of a KEM.
-type Scheme interface {
-	// Name of the scheme
-	Name() string
-
-	// GenerateKeyPair creates a new key pair.
-	GenerateKeyPair() (PublicKey, PrivateKey, error)
-
-	// Encapsulate generates a shared key ss for the public key and
-	// encapsulates it into a ciphertext ct.
-	Encapsulate(pk PublicKey) (ct, ss []byte, err error)
-
-	// Returns the shared key encapsulated in ciphertext ct for the
-	// private key sk.
-	Decapsulate(sk PrivateKey, ct []byte) ([]byte, error)
-
-	// Unmarshals a PublicKey from the provided buffer.
-	UnmarshalBinaryPublicKey([]byte) (PublicKey, error)
-
-	// Unmarshals a PrivateKey from the provided buffer.
-	UnmarshalBinaryPrivateKey([]byte) (PrivateKey, error)
+	// A KEM private key
+	PrivateKey = hpqcKem.PrivateKey
 
-	// Size of encapsulated keys.
-	CiphertextSize() int
-
-	// Size of established shared keys.
-	SharedKeySize() int
-
-	// Size of packed private keys.
-	PrivateKeySize() int
-
-	// Size of packed public keys.
-	PublicKeySize() int
-
-	// DeriveKeyPair deterministically derives a pair of keys from a seed.
-	// Panics if the length of seed is not equal to the value returned by
-	// SeedSize.
-	DeriveKeyPair(seed []byte) (PublicKey, PrivateKey)
-
-	// Size of seed used in DeriveKey
-	SeedSize() int
-
-	// EncapsulateDeterministically generates a shared key ss for the public
-	// key deterministically from the given seed and encapsulates it into
-	// a ciphertext ct. If unsure, you're better off using Encapsulate().
-	EncapsulateDeterministically(pk PublicKey, seed []byte) (
-		ct, ss []byte, err error)
-
-	// Size of seed used in EncapsulateDeterministically().
-	EncapsulationSeedSize() int
-}
+	// A Scheme represents a specific instance of a KEM.
+	Scheme = hpqcKem.Scheme
+)
 
 // AuthScheme represents a KEM that supports authenticated key encapsulation.
 type AuthScheme interface {
@@ -92,27 +35,27 @@ type AuthScheme interface {
 var (
 	// ErrTypeMismatch is the error used if types of, for instance, private
 	// and public keys don't match
-	ErrTypeMismatch = errors.New("types mismatch")
+	ErrTypeMismatch = hpqcKem.ErrTypeMismatch
 
 	// ErrSeedSize is the error used if the provided seed is of the wrong
 	// size.
-	ErrSeedSize = errors.New("wrong seed size")
+	ErrSeedSize = hpqcKem.ErrSeedSize
 
 	// ErrPubKeySize is the error used if the provided public key is of
 	// the wrong size.
-	ErrPubKeySize = errors.New("wrong size for public key")
+	ErrPubKeySize = hpqcKem.ErrPubKeySize
 
 	// ErrCiphertextSize is the error used if the provided ciphertext
 	// is of the wrong size.
-	ErrCiphertextSize = errors.New("wrong size for ciphertext")
+	ErrCiphertextSize = hpqcKem.ErrCiphertextSize
 
 	// ErrPrivKeySize is the error used if the provided private key is of
 	// the wrong size.
-	ErrPrivKeySize = errors.New("wrong size for private key")
+	ErrPrivKeySize = hpqcKem.ErrPrivKeySize
 
 	// ErrPubKey is the error used if the provided public key is invalid.
-	ErrPubKey = errors.New("invalid public key")
+	ErrPubKey = hpqcKem.ErrPubKey
 
 	// ErrCipherText is the error used if the provided ciphertext is invalid.
-	ErrCipherText = errors.New("invalid ciphertext")
+	ErrCipherText = hpqcKem.ErrCipherText
 )
@@ -5,21 +5,30 @@
 // Code generated from pkg.templ.go. DO NOT EDIT.
 
 // Package {{.Pkg}} implements the IND-CCA2 secure key encapsulation mechanism
-// {{.Name}}.CCAKEM as submitted to round 3 of the NIST PQC competition and
+{{ if .NIST -}}
+// {{.KemName}} as defined in FIPS203.
+{{- else -}}
+// {{.KemName}} as submitted to round 3 of the NIST PQC competition and
 // described in
 //
 // https://pq-crystals.org/kyber/data/kyber-specification-round3.pdf
+{{- end }}
 package {{.Pkg}}
 
 import (
 	"bytes"
+	cryptoRand "crypto/rand"
 	"crypto/subtle"
+	{{- if .NIST }}
+	"errors"
+	{{- end }}
 	"io"
 
+	"github.com/katzenpost/hpqc/kem"
+	"github.com/katzenpost/hpqc/kem/pem"
+
 	"github.com/katzenpost/circl/internal/sha3"
-	"github.com/katzenpost/circl/kem"
-	cpapke "github.com/katzenpost/circl/pke/kyber/{{.Pkg}}"
-	cryptoRand "crypto/rand"
+	cpapke "github.com/katzenpost/circl/pke/kyber/{{.PkePkg}}"
 )
 
 const (
@@ -41,15 +50,19 @@ const (
 	// Size of a packed private key.
 	PrivateKeySize = cpapke.PrivateKeySize + cpapke.PublicKeySize + 64
 )
-
-// Type of a {{.Name}}.CCAKEM public key
+{{ if .NIST }}
+// ErrPrivKey is returned when unpacking a private key that does not pass
+// the ML-KEM decapsulation key check.
+var ErrPrivKey = errors.New("invalid private key")
+{{ end }}
+// Type of a {{.KemName}} public key
 type PublicKey struct {
 	pk *cpapke.PublicKey
 
 	hpk [32]byte // H(pk)
 }
 
-// Type of a {{.Name}}.CCAKEM private key
+// Type of a {{.KemName}} private key
 type PrivateKey struct {
 	sk  *cpapke.PrivateKey
 	pk  *cpapke.PublicKey
@@ -59,6 +72,11 @@ type PrivateKey struct {
 
 // NewKeyFromSeed derives a public/private keypair deterministically
 // from the given seed.
+{{- if .NIST }}
+//
+// The seed is the concatenation d ‖ z of the two 32-byte seeds used by
+// ML-KEM.KeyGen_internal in FIPS 203.
+{{- end }}
 //
 // Panics if seed is not of length KeySeedSize.
 func NewKeyFromSeed(seed []byte) (*PublicKey, *PrivateKey) {
@@ -69,7 +87,11 @@ func NewKeyFromSeed(seed []byte) (*PublicKey, *PrivateKey) {
 		panic("seed must be of length KeySeedSize")
 	}
 
+	{{ if .NIST -}}
+	pk.pk, sk.sk = cpapke.NewKeyFromSeedMLKEM(seed[:cpapke.KeySeedSize])
+	{{- else -}}
 	pk.pk, sk.sk = cpapke.NewKeyFromSeed(seed[:cpapke.KeySeedSize])
+	{{- end }}
 	sk.pk = pk.pk
 	copy(sk.z[:], seed[cpapke.KeySeedSize:])
 
@@ -127,11 +149,16 @@ func (pk *PublicKey) EncapsulateTo(ct, ss []byte, seed []byte) {
 		panic("ss must be of length SharedKeySize")
 	}
 
+	{{ if .NIST -}}
+	var m [32]byte
+	copy(m[:], seed)
+	{{- else -}}
 	// m = H(seed)
 	var m [32]byte
 	h := sha3.New256()
 	h.Write(seed[:])
 	h.Read(m[:])
+	{{- end }}
 
 	// (K', r) = G(m ‖ H(pk))
 	var kr [64]byte
@@ -143,6 +170,9 @@ func (pk *PublicKey) EncapsulateTo(ct, ss []byte, seed []byte) {
 	// c = Kyber.CPAPKE.Enc(pk, m, r)
 	pk.pk.EncryptTo(ct, m[:], kr[32:])
 
+	{{ if .NIST -}}
+	copy(ss, kr[:SharedKeySize])
+	{{- else -}}
 	// Compute H(c) and put in second slot of kr, which will be (K', H(c)).
 	h.Reset()
 	h.Write(ct[:CiphertextSize])
@@ -152,6 +182,7 @@ func (pk *PublicKey) EncapsulateTo(ct, ss []byte, seed []byte) {
 	kdf := sha3.NewShake256()
 	kdf.Write(kr[:])
 	kdf.Read(ss[:SharedKeySize])
+	{{- end }}
 }
 
 // DecapsulateTo computes the shared key which is encapsulated in ct
@@ -183,6 +214,24 @@ func (sk *PrivateKey) DecapsulateTo(ss, ct []byte) {
 	var ct2 [CiphertextSize]byte
 	sk.pk.EncryptTo(ct2[:], m2[:], kr2[32:])
 
+	{{ if .NIST -}}
+	var ss2 [SharedKeySize]byte
+
+	// Compute shared secret in case of rejection: ss₂ = PRF(z ‖ c)
+	prf := sha3.NewShake256()
+	prf.Write(sk.z[:])
+	prf.Write(ct[:CiphertextSize])
+	prf.Read(ss2[:])
+
+	// Set ss2 to the real shared secret if c = c'.
+	subtle.ConstantTimeCopy(
+		subtle.ConstantTimeCompare(ct, ct2[:]),
+		ss2[:],
+		kr2[:SharedKeySize],
+	)
+
+	copy(ss, ss2[:])
+	{{- else -}}
 	// Compute H(c) and put in second slot of kr2, which will be (K'', H(c)).
 	h := sha3.New256()
 	h.Write(ct[:CiphertextSize])
@@ -199,6 +248,7 @@ func (sk *PrivateKey) DecapsulateTo(ss, ct []byte) {
 	kdf := sha3.NewShake256()
 	kdf.Write(kr2[:])
 	kdf.Read(ss[:SharedKeySize])
+	{{- end }}
 }
 
 // Packs sk to buf.
@@ -220,20 +270,41 @@ func (sk *PrivateKey) Pack(buf []byte) {
 
 // Unpacks sk from buf.
 //
+{{ if .NIST -}}
+// Returns an error if buf is not of size PrivateKeySize, or private key
+// doesn't pass the ML-KEM decapsulation key check.
+func (sk *PrivateKey) Unpack(buf []byte) error {
+	if len(buf) != PrivateKeySize {
+		return kem.ErrPrivKeySize
+	}
+{{- else -}}
 // Panics if buf is not of size PrivateKeySize.
 func (sk *PrivateKey) Unpack(buf []byte) {
 	if len(buf) != PrivateKeySize {
 		panic("buf must be of length PrivateKeySize")
 	}
+{{- end }}
 
 	sk.sk = new(cpapke.PrivateKey)
 	sk.sk.Unpack(buf[:cpapke.PrivateKeySize])
 	buf = buf[cpapke.PrivateKeySize:]
 	sk.pk = new(cpapke.PublicKey)
 	sk.pk.Unpack(buf[:cpapke.PublicKeySize])
+{{ if .NIST -}}
+	var hpk [32]byte
+	h := sha3.New256()
+	h.Write(buf[:cpapke.PublicKeySize])
+	h.Read(hpk[:])
+{{ end -}}
 	buf = buf[cpapke.PublicKeySize:]
 	copy(sk.hpk[:], buf[:32])
 	copy(sk.z[:], buf[32:])
+{{ if .NIST -}}
+	if !bytes.Equal(hpk[:], sk.hpk[:]) {
+		return ErrPrivKey
+	}
+	return nil
+{{ end -}}
 }
 
 // Packs pk to buf.
@@ -249,19 +320,38 @@ func (pk *PublicKey) Pack(buf []byte) {
 
 // Unpacks pk from buf.
 //
+{{ if .NIST -}}
+// Returns an error if buf is not of size PublicKeySize, or the public key
+// is not normalized.
+func (pk *PublicKey) Unpack(buf []byte) error {
+	if len(buf) != PublicKeySize {
+		return kem.ErrPubKeySize
+	}
+{{- else -}}
 // Panics if buf is not of size PublicKeySize.
 func (pk *PublicKey) Unpack(buf []byte) {
 	if len(buf) != PublicKeySize {
 		panic("buf must be of length PublicKeySize")
 	}
+{{- end }}
 
 	pk.pk = new(cpapke.PublicKey)
+	{{ if .NIST -}}
+	if err := pk.pk.UnpackMLKEM(buf); err != nil {
+		return kem.ErrPubKey
+	}
+	{{- else -}}
 	pk.pk.Unpack(buf)
+	{{- end }}
 
 	// Compute cached H(pk)
 	h := sha3.New256()
 	h.Write(buf)
 	h.Read(pk.hpk[:])
+	{{- if .NIST }}
+
+	return nil
+	{{- end }}
 }
 
 // Boilerplate down below for the KEM scheme API.
@@ -335,6 +425,10 @@ func (pk *PublicKey) MarshalBinary() ([]byte, error) {
 	return ret[:], nil
 }
 
+func (pk *PublicKey) MarshalText() (text []byte, err error) {
+	return pem.ToPublicPEMBytes(pk), nil
+}
+
 func (*scheme) GenerateKeyPair() (kem.PublicKey, kem.PrivateKey, error) {
 	return GenerateKeyPair(cryptoRand.Reader)
 }
@@ -390,11 +484,18 @@ func (*scheme) Decapsulate(sk kem.PrivateKey, ct []byte) ([]byte, error) {
 }
 
 func (*scheme) UnmarshalBinaryPublicKey(buf []byte) (kem.PublicKey, error) {
+	{{ if .NIST -}}
+	var ret PublicKey
+	if err := ret.Unpack(buf); err != nil {
+		return nil, err
+	}
+	{{- else -}}
 	if len(buf) != PublicKeySize {
 		return nil, kem.ErrPubKeySize
 	}
 	var ret PublicKey
 	ret.Unpack(buf)
+	{{- end }}
 	return &ret, nil
 }
 
@@ -403,6 +504,20 @@ func (*scheme) UnmarshalBinaryPrivateKey(buf []byte) (kem.PrivateKey, error) {
 		return nil, kem.ErrPrivKeySize
 	}
 	var ret PrivateKey
+	{{ if .NIST -}}
+	if err := ret.Unpack(buf); err != nil {
+		return nil, err
+	}
+	{{- else -}}
 	ret.Unpack(buf)
+	{{- end }}
 	return &ret, nil
 }
+
+func (s *scheme) UnmarshalTextPublicKey(text []byte) (kem.PublicKey, error) {
+	return pem.FromPublicPEMBytes(text, s)
+}
+
+func (s *scheme) UnmarshalTextPrivateKey(text []byte) (kem.PrivateKey, error) {
+	return pem.FromPrivatePEMBytes(text, s)
+}
@@ -0,0 +1,57 @@
+package mlkem
+
+import (
+	"testing"
+
+	"github.com/katzenpost/hpqc/kem"
+
+	"github.com/katzenpost/circl/kem/schemes"
+)
+
+func TestEncapsulationKeyCheck(t *testing.T) {
+	for _, name := range []string{"ML-KEM-512", "ML-KEM-768", "ML-KEM-1024"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			scheme := schemes.ByName(name)
+			pk, _, err := scheme.GenerateKeyPair()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ppk, err := pk.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Set the first coefficient of t̂ to q = 3329, which is not
+			// reduced and must be rejected by the modulus check.
+			ppk[0] = 0x01
+			ppk[1] = (ppk[1] & 0xf0) | 0x0d
+			if _, err := scheme.UnmarshalBinaryPublicKey(ppk); err != kem.ErrPubKey {
+				t.Fatalf("expected %v, got %v", kem.ErrPubKey, err)
+			}
+		})
+	}
+}
+
+func TestDecapsulationKeyCheck(t *testing.T) {
+	for _, name := range []string{"ML-KEM-512", "ML-KEM-768", "ML-KEM-1024"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			scheme := schemes.ByName(name)
+			_, sk, err := scheme.GenerateKeyPair()
+			if err != nil {
+				t.Fatal(err)
+			}
+			psk, err := sk.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Corrupt H(ek), which is stored just before z.
+			psk[len(psk)-33] ^= 1
+			if _, err := scheme.UnmarshalBinaryPrivateKey(psk); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -12,16 +12,34 @@
 //
 // Post-quantum kems:
 //
-//	FrodoKEM-640-SHAKE
+//	FrodoKEM-640-SHAKE, FrodoKEM-976-SHAKE, FrodoKEM-1344-SHAKE
+//	FrodoKEM-976-AES, FrodoKEM-1344-AES
 //	Kyber512, Kyber768, Kyber1024
+//	ML-KEM-512, ML-KEM-768, ML-KEM-1024
+//
+// Hybrid KEMs:
+//
+//	Kyber512-X25519, Kyber768-X25519, Kyber768-X448, Kyber1024-X448
+//	P256Kyber768Draft00, X25519MLKEM768, SecP256r1MLKEM768
+//	X-Wing
+//
+// Insecure KEMs, only available through ByName with the AllowInsecure option:
+//
+//	SIKEp434, SIKEp503, SIKEp751
+//
+// The security properties of the registered schemes, such as their NIST
+// security level, are available through Metadata and Query.
 package schemes
 
 import (
 	"strings"
 
-	// "github.com/katzenpost/circl/hpke"
-
+	"github.com/katzenpost/circl/hpke"
+	"github.com/katzenpost/circl/kem/frodo/frodo1344aes"
+	"github.com/katzenpost/circl/kem/frodo/frodo1344shake"
 	"github.com/katzenpost/circl/kem/frodo/frodo640shake"
+	"github.com/katzenpost/circl/kem/frodo/frodo976aes"
+	"github.com/katzenpost/circl/kem/frodo/frodo976shake"
 	"github.com/katzenpost/circl/kem/hybrid"
 	"github.com/katzenpost/circl/kem/kyber/kyber1024"
 	"github.com/katzenpost/circl/kem/kyber/kyber512"
@@ -36,53 +54,174 @@ import (
 	"github.com/katzenpost/circl/kem/mceliece/mceliece6960119f"
 	"github.com/katzenpost/circl/kem/mceliece/mceliece8192128"
 	"github.com/katzenpost/circl/kem/mceliece/mceliece8192128f"
+	"github.com/katzenpost/circl/kem/mlkem/mlkem1024"
+	"github.com/katzenpost/circl/kem/mlkem/mlkem512"
+	"github.com/katzenpost/circl/kem/mlkem/mlkem768"
+	"github.com/katzenpost/circl/kem/sike/sikep434"
+	"github.com/katzenpost/circl/kem/sike/sikep503"
+	"github.com/katzenpost/circl/kem/sike/sikep751"
+	"github.com/katzenpost/circl/kem/xwing"
+	"github.com/katzenpost/circl/metadata"
 	"github.com/katzenpost/hpqc/kem"
 )
 
-var allSchemes = [...]kem.Scheme{
-	// HPKE KEMs use a different interface (circl/kem.AuthScheme) so they're excluded
-	// hpke.KEM_P256_HKDF_SHA256.Scheme(),
-	// hpke.KEM_P384_HKDF_SHA384.Scheme(),
-	// hpke.KEM_P521_HKDF_SHA512.Scheme(),
-	// hpke.KEM_X25519_HKDF_SHA256.Scheme(),
-	// hpke.KEM_X448_HKDF_SHA512.Scheme(),
-	frodo640shake.Scheme(),
-	kyber512.Scheme(),
-	kyber768.Scheme(),
-	kyber1024.Scheme(),
-	mceliece348864.Scheme(),
-	mceliece348864f.Scheme(),
-	mceliece460896.Scheme(),
-	mceliece460896f.Scheme(),
-	mceliece6688128.Scheme(),
-	mceliece6688128f.Scheme(),
-	mceliece6960119.Scheme(),
-	mceliece6960119f.Scheme(),
-	mceliece8192128.Scheme(),
-	mceliece8192128f.Scheme(),
-	hybrid.Kyber512X25519(),
-	hybrid.Kyber768X25519(),
-	hybrid.Kyber768X448(),
-	hybrid.Kyber1024X448(),
-	hybrid.P256Kyber768Draft00(),
+type entry struct {
+	scheme kem.Scheme
+	md     metadata.Metadata
+}
+
+// md returns the metadata of a scheme, which is a hybrid if it has
+// components from several families.
+func md(level int, status metadata.Status, families ...metadata.Family) metadata.Metadata {
+	return metadata.Metadata{
+		SecurityLevel: level,
+		Families:      families,
+		Hybrid:        len(families) > 1,
+		Status:        status,
+	}
+}
+
+var allEntries = [...]entry{
+	{hpke.KEM_P256_HKDF_SHA256.Scheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{hpke.KEM_P384_HKDF_SHA384.Scheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{hpke.KEM_P521_HKDF_SHA512.Scheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{hpke.KEM_X25519_HKDF_SHA256.Scheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{hpke.KEM_X448_HKDF_SHA512.Scheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{frodo640shake.Scheme(), md(1, metadata.Submission, metadata.Lattice)},
+	{frodo976shake.Scheme(), md(3, metadata.Submission, metadata.Lattice)},
+	{frodo976aes.Scheme(), md(3, metadata.Submission, metadata.Lattice)},
+	{frodo1344shake.Scheme(), md(5, metadata.Submission, metadata.Lattice)},
+	{frodo1344aes.Scheme(), md(5, metadata.Submission, metadata.Lattice)},
+	{kyber512.Scheme(), md(1, metadata.Submission, metadata.Lattice)},
+	{kyber768.Scheme(), md(3, metadata.Submission, metadata.Lattice)},
+	{kyber1024.Scheme(), md(5, metadata.Submission, metadata.Lattice)},
+	{mlkem512.Scheme(), md(1, metadata.Standardized, metadata.Lattice)},
+	{mlkem768.Scheme(), md(3, metadata.Standardized, metadata.Lattice)},
+	{mlkem1024.Scheme(), md(5, metadata.Standardized, metadata.Lattice)},
+	{mceliece348864.Scheme(), md(1, metadata.Submission, metadata.Code)},
+	{mceliece348864f.Scheme(), md(1, metadata.Submission, metadata.Code)},
+	{mceliece460896.Scheme(), md(3, metadata.Submission, metadata.Code)},
+	{mceliece460896f.Scheme(), md(3, metadata.Submission, metadata.Code)},
+	{mceliece6688128.Scheme(), md(5, metadata.Submission, metadata.Code)},
+	{mceliece6688128f.Scheme(), md(5, metadata.Submission, metadata.Code)},
+	{mceliece6960119.Scheme(), md(5, metadata.Submission, metadata.Code)},
+	{mceliece6960119f.Scheme(), md(5, metadata.Submission, metadata.Code)},
+	{mceliece8192128.Scheme(), md(5, metadata.Submission, metadata.Code)},
+	{mceliece8192128f.Scheme(), md(5, metadata.Submission, metadata.Code)},
+	{hybrid.Kyber512X25519(), md(1, metadata.Submission, metadata.Lattice, metadata.EllipticCurve)},
+	{hybrid.Kyber768X25519(), md(3, metadata.Submission, metadata.Lattice, metadata.EllipticCurve)},
+	{hybrid.Kyber768X448(), md(3, metadata.Submission, metadata.Lattice, metadata.EllipticCurve)},
+	{hybrid.Kyber1024X448(), md(5, metadata.Submission, metadata.Lattice, metadata.EllipticCurve)},
+	{hybrid.P256Kyber768Draft00(), md(3, metadata.Draft, metadata.Lattice, metadata.EllipticCurve)},
+	{hybrid.X25519MLKEM768(), md(3, metadata.Draft, metadata.Lattice, metadata.EllipticCurve)},
+	{hybrid.SecP256r1MLKEM768(), md(3, metadata.Draft, metadata.Lattice, metadata.EllipticCurve)},
+	{xwing.Scheme(), md(3, metadata.Draft, metadata.Lattice, metadata.EllipticCurve)},
 }
 
-var allSchemeNames map[string]kem.Scheme
+// Schemes that have been deprecated because they are known to be broken.
+// They are not listed by All, and ByName refuses them unless the
+// AllowInsecure option is given.
+var insecureEntries = [...]entry{
+	// SIKE is vulnerable to the key recovery attack by Castryck and Decru,
+	// https://eprint.iacr.org/2022/975
+	{sikep434.Scheme(), md(1, metadata.Broken, metadata.Isogeny)},
+	{sikep503.Scheme(), md(2, metadata.Broken, metadata.Isogeny)},
+	{sikep751.Scheme(), md(5, metadata.Broken, metadata.Isogeny)},
+}
+
+var (
+	allSchemes, insecureSchemes         []kem.Scheme
+	allSchemeNames, insecureSchemeNames map[string]kem.Scheme
+	allMetadata                         map[string]metadata.Metadata
+)
 
 func init() {
+	allMetadata = make(map[string]metadata.Metadata)
 	allSchemeNames = make(map[string]kem.Scheme)
-	for _, scheme := range allSchemes {
-		allSchemeNames[strings.ToLower(scheme.Name())] = scheme
+	for _, e := range allEntries {
+		allSchemes = append(allSchemes, e.scheme)
+		allSchemeNames[strings.ToLower(e.scheme.Name())] = e.scheme
+		allMetadata[strings.ToLower(e.scheme.Name())] = e.md
+	}
+	insecureSchemeNames = make(map[string]kem.Scheme)
+	for _, e := range insecureEntries {
+		insecureSchemes = append(insecureSchemes, e.scheme)
+		insecureSchemeNames[strings.ToLower(e.scheme.Name())] = e.scheme
+		allMetadata[strings.ToLower(e.scheme.Name())] = e.md
 	}
 }
 
+// An Option modifies the behaviour of ByName.
+type Option func(*options)
+
+type options struct {
+	allowInsecure bool
+}
+
+// AllowInsecure lets ByName return schemes that are known to be insecure.
+// It should only be used for interoperability with legacy systems.
+func AllowInsecure() Option {
+	return func(o *options) { o.allowInsecure = true }
+}
+
 // ByName returns the scheme with the given name and nil if it is not
-// supported.
+// supported. Schemes that are known to be insecure are only returned if
+// the AllowInsecure option is given.
 //
 // Names are case insensitive.
-func ByName(name string) kem.Scheme {
-	return allSchemeNames[strings.ToLower(name)]
+func ByName(name string, opts ...Option) kem.Scheme {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	name = strings.ToLower(name)
+	if scheme, ok := allSchemeNames[name]; ok {
+		return scheme
+	}
+	if o.allowInsecure {
+		return insecureSchemeNames[name]
+	}
+	return nil
 }
 
-// All returns all KEM schemes supported.
-func All() []kem.Scheme { a := allSchemes; return a[:] }
+// All returns all KEM schemes supported, excluding the insecure ones.
+func All() []kem.Scheme { return append([]kem.Scheme(nil), allSchemes...) }
+
+// AllInsecure returns all KEM schemes that are deprecated because they are
+// known to be insecure.
+func AllInsecure() []kem.Scheme { return append([]kem.Scheme(nil), insecureSchemes...) }
+
+// IsInsecure returns whether the scheme is deprecated because it is known
+// to be insecure.
+func IsInsecure(scheme kem.Scheme) bool {
+	s, ok := insecureSchemeNames[strings.ToLower(scheme.Name())]
+	return ok && s == scheme
+}
+
+// Metadata returns the security properties of the scheme. Schemes
+// implementing metadata.Describer provide their own; otherwise they are
+// looked up in the register, and ok is false if the scheme is not listed.
+func Metadata(scheme kem.Scheme) (m metadata.Metadata, ok bool) {
+	if d, isDescriber := scheme.(metadata.Describer); isDescriber {
+		return d.Metadata(), true
+	}
+	m, ok = allMetadata[strings.ToLower(scheme.Name())]
+	return m, ok
+}
+
+// Query returns the schemes listed by All whose metadata satisfy the given
+// predicate. For instance, the post-quantum KEMs at NIST level 3 or above
+// are listed by
+//
+//	schemes.Query(func(md metadata.Metadata) bool {
+//		return md.SecurityLevel >= 3
+//	})
+func Query(pred func(metadata.Metadata) bool) []kem.Scheme {
+	var ret []kem.Scheme
+	for _, scheme := range allSchemes {
+		if m, _ := Metadata(scheme); pred(m) {
+			ret = append(ret, scheme)
+		}
+	}
+	return ret
+}
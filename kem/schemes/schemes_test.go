@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/katzenpost/circl/hpke"
+	"github.com/katzenpost/circl/kem"
 	"github.com/katzenpost/circl/kem/schemes"
+	"github.com/katzenpost/circl/metadata"
 )
 
 func TestCaseSensitivity(t *testing.T) {
@@ -14,6 +17,147 @@ func TestCaseSensitivity(t *testing.T) {
 	}
 }
 
+func TestMetadata(t *testing.T) {
+	for _, scheme := range schemes.All() {
+		m, ok := schemes.Metadata(scheme)
+		if !ok {
+			t.Fatalf("%v has no metadata", scheme.Name())
+		}
+		if m.Status == 0 || len(m.Families) == 0 {
+			t.Fatalf("%v: incomplete metadata %v", scheme.Name(), m)
+		}
+		if m.Hybrid != (len(m.Families) > 1) {
+			t.Fatalf("%v: inconsistent metadata %v", scheme.Name(), m)
+		}
+		if m.Status == metadata.Broken {
+			t.Fatalf("%v should not be listed", scheme.Name())
+		}
+	}
+
+	for _, scheme := range schemes.AllInsecure() {
+		m, ok := schemes.Metadata(scheme)
+		if !ok || m.Status != metadata.Broken {
+			t.Fatalf("%v should be marked broken", scheme.Name())
+		}
+	}
+
+	level3 := schemes.Query(func(m metadata.Metadata) bool {
+		return m.SecurityLevel >= 3 && m.HasFamily(metadata.Lattice)
+	})
+	for _, name := range []string{"ML-KEM-768", "ML-KEM-1024", "X-Wing"} {
+		if !contains(level3, name) {
+			t.Fatalf("%v missing from query", name)
+		}
+	}
+	for _, name := range []string{"ML-KEM-512", "mceliece460896", "HPKE_KEM_X25519_HKDF_SHA256"} {
+		if contains(level3, name) {
+			t.Fatalf("%v should not match query", name)
+		}
+	}
+}
+
+func contains(list []kem.Scheme, name string) bool {
+	for _, s := range list {
+		if s.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInsecure(t *testing.T) {
+	for _, scheme := range schemes.AllInsecure() {
+		name := scheme.Name()
+		if !schemes.IsInsecure(scheme) {
+			t.Fatalf("%v should be insecure", name)
+		}
+		if schemes.ByName(name) != nil {
+			t.Fatalf("%v returned without AllowInsecure", name)
+		}
+		if schemes.ByName(name, schemes.AllowInsecure()) != scheme {
+			t.Fatalf("%v not returned with AllowInsecure", name)
+		}
+	}
+	for _, scheme := range schemes.All() {
+		if schemes.IsInsecure(scheme) {
+			t.Fatalf("%v should not be insecure", scheme.Name())
+		}
+		if schemes.ByName(scheme.Name(), schemes.AllowInsecure()) != scheme {
+			t.Fatal()
+		}
+	}
+	if schemes.ByName("SIKEp434", schemes.AllowInsecure()) == nil {
+		t.Fatal()
+	}
+}
+
+func TestDHKEMByName(t *testing.T) {
+	for _, name := range []string{
+		"HPKE_KEM_P256_HKDF_SHA256",
+		"HPKE_KEM_P384_HKDF_SHA384",
+		"HPKE_KEM_P521_HKDF_SHA512",
+		"HPKE_KEM_X25519_HKDF_SHA256",
+		"HPKE_KEM_X448_HKDF_SHA512",
+	} {
+		scheme := schemes.ByName(name)
+		if scheme == nil {
+			t.Fatalf("%v not registered", name)
+		}
+		pk, sk, err := scheme.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		text, err := pk.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pk2, err := scheme.UnmarshalTextPublicKey(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pk.Equal(pk2) || !sk.Public().Equal(pk2) {
+			t.Fatal()
+		}
+	}
+}
+
+func TestHPKEKeys(t *testing.T) {
+	// Keys obtained through the register can be used directly with HPKE.
+	kemID := hpke.KEM_X25519_HKDF_SHA256
+	scheme := schemes.ByName(kemID.Scheme().Name())
+	if scheme != kemID.Scheme() {
+		t.Fatal()
+	}
+	pk, sk, err := scheme.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite := hpke.NewSuite(kemID, hpke.KDF_HKDF_SHA256, hpke.AEAD_AES128GCM)
+	sender, err := suite.NewSender(pk, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiver, err := suite.NewReceiver(sk, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, sealer, err := sender.Setup(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opener, err := receiver.Setup(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ct, err := sealer.Seal([]byte("message"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := opener.Open(ct, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func BenchmarkGenerateKeyPair(b *testing.B) {
 	allSchemes := schemes.All()
 	for _, scheme := range allSchemes {
@@ -54,7 +198,7 @@ func BenchmarkDecapsulate(b *testing.B) {
 }
 
 func TestApi(t *testing.T) {
-	allSchemes := schemes.All()
+	allSchemes := append(schemes.All(), schemes.AllInsecure()...)
 	for _, scheme := range allSchemes {
 		scheme := scheme
 		t.Run(scheme.Name(), func(t *testing.T) {
@@ -152,9 +296,16 @@ func Example_schemes() {
 	// HPKE_KEM_X25519_HKDF_SHA256
 	// HPKE_KEM_X448_HKDF_SHA512
 	// FrodoKEM-640-SHAKE
+	// FrodoKEM-976-SHAKE
+	// FrodoKEM-976-AES
+	// FrodoKEM-1344-SHAKE
+	// FrodoKEM-1344-AES
 	// Kyber512
 	// Kyber768
 	// Kyber1024
+	// ML-KEM-512
+	// ML-KEM-768
+	// ML-KEM-1024
 	// mceliece348864
 	// mceliece348864f
 	// mceliece460896
@@ -170,4 +321,7 @@ func Example_schemes() {
 	// Kyber768-X448
 	// Kyber1024-X448
 	// P256Kyber768Draft00
+	// X25519MLKEM768
+	// SecP256r1MLKEM768
+	// X-Wing
 }
@@ -7,4 +7,7 @@
 // SIDH and SIKE are deprecated as were shown vulnerable to a key recovery
 // attack by Castryck-Decru's paper (https://eprint.iacr.org/2022/975). New
 // systems should not rely on this package. This package is frozen.
+//
+// The register in github.com/katzenpost/circl/kem/schemes marks these schemes
+// as insecure: they are only returned by ByName if explicitly allowed.
 package sike
@@ -18,6 +18,7 @@ import (
 	"github.com/katzenpost/circl/dh/sidh"
 	"github.com/katzenpost/circl/internal/sha3"
 	"github.com/katzenpost/circl/kem"
+	"github.com/katzenpost/hpqc/kem/pem"
 )
 
 // Deprecated: not cryptographically secure.
@@ -97,6 +98,10 @@ func (pk *PublicKey) MarshalBinary() ([]byte, error) {
 	return ret, nil
 }
 
+func (pk *PublicKey) MarshalText() ([]byte, error) {
+	return pem.ToPublicPEMBytes(pk), nil
+}
+
 // Deprecated: not cryptographically secure.
 func GenerateKeyPair(rand io.Reader) (kem.PublicKey, kem.PrivateKey, error) {
 	sk := sidh.NewPrivateKey(sidh.Fp434, sidh.KeyVariantSike)
@@ -204,6 +209,14 @@ func (sch *scheme) UnmarshalBinaryPrivateKey(buf []byte) (kem.PrivateKey, error)
 	return &PrivateKey{sk: sk}, nil
 }
 
+func (sch *scheme) UnmarshalTextPublicKey(text []byte) (kem.PublicKey, error) {
+	return pem.FromPublicPEMBytes(text, sch)
+}
+
+func (sch *scheme) UnmarshalTextPrivateKey(text []byte) (kem.PrivateKey, error) {
+	return pem.FromPrivatePEMBytes(text, sch)
+}
+
 func init() {
 	params = sidh.NewSike434(nil)
 }
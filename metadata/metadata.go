@@ -0,0 +1,143 @@
+// Package metadata describes the security properties of KEM and signature
+// schemes, such as the claimed NIST security level and the mathematical
+// family they belong to.
+//
+// The registers in github.com/katzenpost/circl/kem/schemes and
+// github.com/katzenpost/circl/sign/schemes provide the metadata of every
+// scheme they list, so that applications can enforce policies such as
+// "NIST level ≥ 3" or "at least one lattice component".
+package metadata
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Family is the mathematical family on which the security of a scheme
+// relies.
+type Family uint8
+
+const (
+	// EllipticCurve schemes rely on the hardness of the discrete logarithm
+	// problem on elliptic curves.
+	EllipticCurve Family = iota + 1
+	// Lattice schemes rely on the hardness of lattice problems.
+	Lattice
+	// Code schemes rely on the hardness of decoding random linear codes.
+	Code
+	// Isogeny schemes rely on the hardness of finding isogenies between
+	// elliptic curves.
+	Isogeny
+	// HashBased schemes rely only on the security of a hash function.
+	HashBased
+	// Pairing schemes rely on the hardness of problems on pairing-friendly
+	// elliptic curves.
+	Pairing
+	// RSA schemes rely on the hardness of factoring.
+	RSA
+)
+
+func (f Family) String() string {
+	switch f {
+	case EllipticCurve:
+		return "ECC"
+	case Lattice:
+		return "lattice"
+	case Code:
+		return "code"
+	case Isogeny:
+		return "isogeny"
+	case HashBased:
+		return "hash"
+	case Pairing:
+		return "pairing"
+	case RSA:
+		return "RSA"
+	default:
+		return "unknown"
+	}
+}
+
+// Status is the standardization status of a scheme.
+type Status uint8
+
+const (
+	// Standardized schemes are published as a final standard, such as a
+	// FIPS or an RFC.
+	Standardized Status = iota + 1
+	// Draft schemes are specified in a draft that may still change.
+	Draft
+	// Submission schemes are submissions to a standardization process,
+	// which were not selected, or not selected yet.
+	Submission
+	// Broken schemes are known to be insecure.
+	Broken
+)
+
+func (s Status) String() string {
+	switch s {
+	case Standardized:
+		return "standardized"
+	case Draft:
+		return "draft"
+	case Submission:
+		return "submission"
+	case Broken:
+		return "broken"
+	default:
+		return "unknown"
+	}
+}
+
+// Metadata describes the security properties of a scheme.
+type Metadata struct {
+	// SecurityLevel is the claimed NIST post-quantum security level,
+	// between 1 and 5. It is 0 for schemes that do not claim security
+	// against quantum adversaries. Hybrid schemes report the level of
+	// their post-quantum component.
+	SecurityLevel int
+
+	// Families lists the families of the components of the scheme.
+	Families []Family
+
+	// Hybrid is true if the scheme combines several schemes, so that it
+	// stays secure as long as one of them is.
+	Hybrid bool
+
+	// Status is the standardization status of the scheme.
+	Status Status
+}
+
+// HasFamily returns whether one of the components of the scheme belongs to
+// the given family.
+func (m Metadata) HasFamily(f Family) bool {
+	for _, g := range m.Families {
+		if g == f {
+			return true
+		}
+	}
+	return false
+}
+
+// PostQuantum returns whether the scheme claims security against quantum
+// adversaries.
+func (m Metadata) PostQuantum() bool { return m.SecurityLevel > 0 }
+
+func (m Metadata) String() string {
+	fams := make([]string, len(m.Families))
+	for i, f := range m.Families {
+		fams[i] = f.String()
+	}
+	s := "level " + strconv.Itoa(m.SecurityLevel) + ", " +
+		strings.Join(fams, "+") + ", " + m.Status.String()
+	if m.Hybrid {
+		s += ", hybrid"
+	}
+	return s
+}
+
+// Describer is an optional interface implemented by schemes that provide
+// their own metadata. The registers prefer it over their own tables.
+type Describer interface {
+	Metadata() Metadata
+}
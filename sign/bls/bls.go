@@ -0,0 +1,480 @@
+// Package bls provides BLS signatures over the BLS12-381 pairing-friendly
+// curve.
+//
+// This package implements the proof-of-possession scheme of the IETF/CFRG
+// draft for BLS signatures [1], with hashing to the curve as in RFC 9380.
+//
+// # Variants
+//
+// Public keys live in one of the groups G1 or G2 of the pairing, and
+// signatures in the other. The group of public keys is chosen with a type
+// parameter:
+//
+//   - KeyG1SigG2 is the minimal-pubkey-size variant, with 48-byte public keys
+//     and 96-byte signatures, which is the one of BLS12381G2_XMD:SHA-256_SSWU_RO_POP_.
+//   - KeyG2SigG1 is the minimal-signature-size variant, with 96-byte public
+//     keys and 48-byte signatures, which is the one of BLS12381G1_XMD:SHA-256_SSWU_RO_POP_.
+//
+// Points are encoded in compressed form.
+//
+// # Aggregation
+//
+// Signatures of any messages by any signers can be aggregated into a single
+// signature, which AggregateVerify checks with one product of pairings.
+// Signatures of the same message can be verified with FastAggregateVerify,
+// which only adds up the public keys. This is secure against rogue-key
+// attacks only if each public key comes with a proof of possession, created
+// by PopProve, which was checked with PopVerify.
+//
+// # Threshold Signatures
+//
+// A trusted dealer can split a private key into shares with Split, such that
+// any threshold of the holders of the shares can sign. Each of them signs
+// the message with their share, and the signature shares are recombined
+// with Lagrange interpolation by Combine into a plain signature under the
+// public key.
+//
+// # References
+//
+// [1] https://datatracker.ietf.org/doc/html/draft-irtf-cfrg-bls-signature-05
+package bls
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/katzenpost/circl/ecc/bls12381"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Signature is a BLS signature, or an aggregate of them, in compressed form.
+type Signature = []byte
+
+type (
+	// KeyGroup is the group of public keys, the other group of the pairing
+	// being the group of signatures.
+	KeyGroup interface{ bls12381.G1 | bls12381.G2 }
+
+	// KeyG1SigG2 is the minimal-pubkey-size variant.
+	KeyG1SigG2 = bls12381.G1
+	// KeyG2SigG1 is the minimal-signature-size variant.
+	KeyG2SigG1 = bls12381.G2
+)
+
+// Domain separation tags of the proof-of-possession ciphersuites.
+const (
+	dstSigG2 = "BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+	dstPopG2 = "BLS_POP_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_"
+	dstSigG1 = "BLS_SIG_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"
+	dstPopG1 = "BLS_POP_BLS12381G1_XMD:SHA-256_SSWU_RO_POP_"
+)
+
+// keyGenSalt is the initial salt of KeyGen.
+const keyGenSalt = "BLS-SIG-KEYGEN-SALT-"
+
+// PrivateKey is a BLS private key.
+type PrivateKey[K KeyGroup] struct {
+	key bls12381.Scalar
+	pub *PublicKey[K]
+}
+
+// PublicKey is a BLS public key.
+type PublicKey[K KeyGroup] struct {
+	key K
+}
+
+// KeyGen derives a private key from the secret input keying material ikm,
+// which must be at least 32 bytes long, and the optional keyInfo, as in
+// Section 2.3 of [1].
+func KeyGen[K KeyGroup](ikm, keyInfo []byte) (*PrivateKey[K], error) {
+	if len(ikm) < 32 {
+		return nil, ErrShortIKM
+	}
+	// L = ceil((3 * ceil(log2(r))) / 16)
+	const L = 48
+	ikmZero := append(append([]byte{}, ikm...), 0)
+	info := binary.BigEndian.AppendUint16(append([]byte{}, keyInfo...), L)
+	salt := []byte(keyGenSalt)
+	okm := make([]byte, L)
+	k := &PrivateKey[K]{}
+	for {
+		digest := sha256.Sum256(salt)
+		salt = digest[:]
+		rd := hkdf.New(sha256.New, ikmZero, salt, info)
+		if _, err := io.ReadFull(rd, okm); err != nil {
+			return nil, err
+		}
+		k.key.SetBytes(okm)
+		if k.key.IsZero() == 0 {
+			return k, nil
+		}
+	}
+}
+
+// Public returns the public key corresponding to the private key.
+func (k *PrivateKey[K]) Public() *PublicKey[K] {
+	if k.pub == nil {
+		pub := &PublicKey[K]{}
+		switch key := any(&pub.key).(type) {
+		case *bls12381.G1:
+			key.ScalarMult(&k.key, bls12381.G1Generator())
+		case *bls12381.G2:
+			key.ScalarMult(&k.key, bls12381.G2Generator())
+		}
+		k.pub = pub
+	}
+	return k.pub
+}
+
+// Equal returns whether x is the same private key.
+func (k *PrivateKey[K]) Equal(x crypto.PrivateKey) bool {
+	xx, ok := x.(*PrivateKey[K])
+	return ok && k.key.IsEqual(&xx.key) == 1
+}
+
+// MarshalBinary encodes the private key as a big-endian integer of 32 bytes.
+func (k *PrivateKey[K]) MarshalBinary() ([]byte, error) { return k.key.MarshalBinary() }
+
+// UnmarshalBinary decodes a private key, and rejects zero and values not
+// less than the order of the groups.
+func (k *PrivateKey[K]) UnmarshalBinary(data []byte) error {
+	if len(data) != bls12381.ScalarSize {
+		return ErrInvalidKey
+	}
+	var key bls12381.Scalar
+	if err := key.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	if key.IsZero() == 1 {
+		return ErrInvalidKey
+	}
+	*k = PrivateKey[K]{key: key}
+	return nil
+}
+
+// Validate returns whether the public key is valid as in KeyValidate of [1],
+// that is, a point of the group other than the identity.
+func (k *PublicKey[K]) Validate() bool {
+	switch key := any(&k.key).(type) {
+	case *bls12381.G1:
+		return !key.IsIdentity() && key.IsOnG1()
+	case *bls12381.G2:
+		return !key.IsIdentity() && key.IsOnG2()
+	}
+	return false
+}
+
+// Equal returns whether x is the same public key.
+func (k *PublicKey[K]) Equal(x crypto.PublicKey) bool {
+	xx, ok := x.(*PublicKey[K])
+	if !ok {
+		return false
+	}
+	switch key := any(&k.key).(type) {
+	case *bls12381.G1:
+		return key.IsEqual(any(&xx.key).(*bls12381.G1))
+	case *bls12381.G2:
+		return key.IsEqual(any(&xx.key).(*bls12381.G2))
+	}
+	return false
+}
+
+// MarshalBinary encodes the public key in compressed form.
+func (k *PublicKey[K]) MarshalBinary() ([]byte, error) {
+	switch key := any(&k.key).(type) {
+	case *bls12381.G1:
+		return key.BytesCompressed(), nil
+	case *bls12381.G2:
+		return key.BytesCompressed(), nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// UnmarshalBinary decodes a public key in compressed form, and rejects it
+// if it does not pass Validate.
+func (k *PublicKey[K]) UnmarshalBinary(data []byte) error {
+	var pub PublicKey[K]
+	switch key := any(&pub.key).(type) {
+	case *bls12381.G1:
+		p, ok := decodeG1(data)
+		if !ok {
+			return ErrInvalidKey
+		}
+		*key = *p
+	case *bls12381.G2:
+		p, ok := decodeG2(data)
+		if !ok {
+			return ErrInvalidKey
+		}
+		*key = *p
+	}
+	*k = pub
+	return nil
+}
+
+// Sign returns the signature of msg.
+func Sign[K KeyGroup](k *PrivateKey[K], msg []byte) Signature {
+	return coreSign(k, msg, false)
+}
+
+// Verify returns whether sig is a valid signature of msg under pub.
+func Verify[K KeyGroup](pub *PublicKey[K], msg []byte, sig Signature) bool {
+	return coreVerify(pub, msg, sig, false)
+}
+
+// PopProve returns a proof of possession of the private key, which is a
+// signature of the public key under a dedicated domain separation tag.
+func PopProve[K KeyGroup](k *PrivateKey[K]) Signature {
+	msg, _ := k.Public().MarshalBinary()
+	return coreSign(k, msg, true)
+}
+
+// PopVerify returns whether proof is a valid proof of possession of the
+// private key of pub.
+func PopVerify[K KeyGroup](pub *PublicKey[K], proof Signature) bool {
+	msg, err := pub.MarshalBinary()
+	if err != nil {
+		return false
+	}
+	return coreVerify(pub, msg, proof, true)
+}
+
+// Aggregate returns the aggregate of the signatures, which must all be of
+// the same variant K.
+func Aggregate[K KeyGroup](sigs []Signature) (Signature, error) {
+	if len(sigs) == 0 {
+		return nil, ErrAggregate
+	}
+	return combine[K](sigs, nil)
+}
+
+// AggregateVerify returns whether sig is a valid aggregate signature of the
+// messages msgs[i] under the public keys pubs[i]. The messages do not need
+// to be distinct, but the public keys must come with a valid proof of
+// possession.
+func AggregateVerify[K KeyGroup](pubs []*PublicKey[K], msgs [][]byte, sig Signature) bool {
+	n := len(pubs)
+	if n == 0 || n != len(msgs) {
+		return false
+	}
+	for i := range pubs {
+		if !pubs[i].Validate() {
+			return false
+		}
+	}
+
+	// Check that \Prod_i e(PK_i, H(m_i)) * e(G, S)^-1 = 1, or the same with
+	// the arguments of the pairings swapped.
+	P := make([]*bls12381.G1, n+1)
+	Q := make([]*bls12381.G2, n+1)
+	exps := make([]*bls12381.Scalar, n+1)
+	one := new(bls12381.Scalar)
+	one.SetOne()
+	for i := range exps {
+		exps[i] = one
+	}
+	minusOne := new(bls12381.Scalar)
+	minusOne.SetOne()
+	minusOne.Neg()
+	exps[n] = minusOne
+
+	dst := []byte(sigTag[K](false))
+	switch any(new(K)).(type) {
+	case *bls12381.G1:
+		S, ok := decodeG2(sig)
+		if !ok {
+			return false
+		}
+		for i := range pubs {
+			pk := *any(&pubs[i].key).(*bls12381.G1)
+			P[i] = &pk
+			Q[i] = new(bls12381.G2)
+			Q[i].Hash(msgs[i], dst)
+		}
+		P[n], Q[n] = bls12381.G1Generator(), S
+	case *bls12381.G2:
+		S, ok := decodeG1(sig)
+		if !ok {
+			return false
+		}
+		for i := range pubs {
+			P[i] = new(bls12381.G1)
+			P[i].Hash(msgs[i], dst)
+			Q[i] = any(&pubs[i].key).(*bls12381.G2)
+		}
+		P[n], Q[n] = S, bls12381.G2Generator()
+	}
+	return bls12381.ProdPair(P, Q, exps).IsIdentity()
+}
+
+// FastAggregateVerify returns whether sig is a valid aggregate signature of
+// msg under all the public keys, which must come with a valid proof of
+// possession.
+func FastAggregateVerify[K KeyGroup](pubs []*PublicKey[K], msg []byte, sig Signature) bool {
+	if len(pubs) == 0 {
+		return false
+	}
+	var agg PublicKey[K]
+	switch key := any(&agg.key).(type) {
+	case *bls12381.G1:
+		key.SetIdentity()
+		for i := range pubs {
+			pk := any(&pubs[i].key).(*bls12381.G1)
+			if !pubs[i].Validate() {
+				return false
+			}
+			key.Add(key, pk)
+		}
+	case *bls12381.G2:
+		key.SetIdentity()
+		for i := range pubs {
+			pk := any(&pubs[i].key).(*bls12381.G2)
+			if !pubs[i].Validate() {
+				return false
+			}
+			key.Add(key, pk)
+		}
+	}
+	return coreVerify(&agg, msg, sig, false)
+}
+
+// sigTag returns the domain separation tag of signatures, or of proofs of
+// possession if pop is true.
+func sigTag[K KeyGroup](pop bool) string {
+	switch any(new(K)).(type) {
+	case *bls12381.G1:
+		if pop {
+			return dstPopG2
+		}
+		return dstSigG2
+	default:
+		if pop {
+			return dstPopG1
+		}
+		return dstSigG1
+	}
+}
+
+func coreSign[K KeyGroup](k *PrivateKey[K], msg []byte, pop bool) Signature {
+	dst := []byte(sigTag[K](pop))
+	switch any(new(K)).(type) {
+	case *bls12381.G1:
+		var Q bls12381.G2
+		Q.Hash(msg, dst)
+		Q.ScalarMult(&k.key, &Q)
+		return Q.BytesCompressed()
+	case *bls12381.G2:
+		var Q bls12381.G1
+		Q.Hash(msg, dst)
+		Q.ScalarMult(&k.key, &Q)
+		return Q.BytesCompressed()
+	}
+	return nil
+}
+
+func coreVerify[K KeyGroup](pub *PublicKey[K], msg []byte, sig Signature, pop bool) bool {
+	if !pub.Validate() {
+		return false
+	}
+	dst := []byte(sigTag[K](pop))
+	switch key := any(&pub.key).(type) {
+	case *bls12381.G1:
+		S, ok := decodeG2(sig)
+		if !ok {
+			return false
+		}
+		var Q bls12381.G2
+		Q.Hash(msg, dst)
+		// e(PK, H(m)) = e(G, S)
+		pk := *key
+		P := []*bls12381.G1{&pk, bls12381.G1Generator()}
+		return bls12381.ProdPairFrac(P, []*bls12381.G2{&Q, S}, []int{1, -1}).IsIdentity()
+	case *bls12381.G2:
+		S, ok := decodeG1(sig)
+		if !ok {
+			return false
+		}
+		var Q bls12381.G1
+		Q.Hash(msg, dst)
+		// e(H(m), PK) = e(S, G)
+		P := []*bls12381.G1{&Q, S}
+		return bls12381.ProdPairFrac(P, []*bls12381.G2{key, bls12381.G2Generator()}, []int{1, -1}).IsIdentity()
+	}
+	return false
+}
+
+// combine returns the sum of the signatures multiplied by the coefficients,
+// or the plain sum if coeffs is nil.
+func combine[K KeyGroup](sigs []Signature, coeffs []*bls12381.Scalar) (Signature, error) {
+	switch any(new(K)).(type) {
+	case *bls12381.G1:
+		var sum bls12381.G2
+		sum.SetIdentity()
+		for i := range sigs {
+			S, ok := decodeG2(sigs[i])
+			if !ok {
+				return nil, ErrInvalidSignature
+			}
+			if coeffs != nil {
+				S.ScalarMult(coeffs[i], S)
+			}
+			sum.Add(&sum, S)
+		}
+		return sum.BytesCompressed(), nil
+	case *bls12381.G2:
+		var sum bls12381.G1
+		sum.SetIdentity()
+		for i := range sigs {
+			S, ok := decodeG1(sigs[i])
+			if !ok {
+				return nil, ErrInvalidSignature
+			}
+			if coeffs != nil {
+				S.ScalarMult(coeffs[i], S)
+			}
+			sum.Add(&sum, S)
+		}
+		return sum.BytesCompressed(), nil
+	}
+	return nil, ErrInvalidSignature
+}
+
+// decodeG1 decodes a point of G1 in compressed form, and rejects the
+// identity.
+func decodeG1(data []byte) (*bls12381.G1, bool) {
+	if len(data) != bls12381.G1SizeCompressed || data[0]&0x80 == 0 {
+		return nil, false
+	}
+	p := new(bls12381.G1)
+	if p.SetBytes(data) != nil || p.IsIdentity() {
+		return nil, false
+	}
+	return p, true
+}
+
+// decodeG2 decodes a point of G2 in compressed form, and rejects the
+// identity.
+func decodeG2(data []byte) (*bls12381.G2, bool) {
+	if len(data) != bls12381.G2SizeCompressed || data[0]&0x80 == 0 {
+		return nil, false
+	}
+	p := new(bls12381.G2)
+	if p.SetBytes(data) != nil || p.IsIdentity() {
+		return nil, false
+	}
+	return p, true
+}
+
+var (
+	ErrShortIKM          = errors.New("bls: IKM too short")
+	ErrInvalidKey        = errors.New("bls: invalid key")
+	ErrInvalidSignature  = errors.New("bls: invalid signature")
+	ErrAggregate         = errors.New("bls: nothing to aggregate")
+	ErrInvalidParameters = errors.New("bls: invalid threshold parameters")
+	ErrInvalidIdentifier = errors.New("bls: invalid signer identifier")
+	ErrInvalidShare      = errors.New("bls: invalid signature share")
+	ErrNotEnoughShares   = errors.New("bls: not enough signature shares")
+)
@@ -0,0 +1,170 @@
+package bls_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/sign/bls"
+)
+
+func newKey[K bls.KeyGroup](t testing.TB) *bls.PrivateKey[K] {
+	ikm := make([]byte, 32)
+	_, err := rand.Read(ikm)
+	test.CheckNoErr(t, err, "reading randomness failed")
+	k, err := bls.KeyGen[K](ikm, nil)
+	test.CheckNoErr(t, err, "key generation failed")
+	return k
+}
+
+func TestBLS(t *testing.T) {
+	t.Run("KeyG1SigG2", testBLS[bls.KeyG1SigG2])
+	t.Run("KeyG2SigG1", testBLS[bls.KeyG2SigG1])
+}
+
+func testBLS[K bls.KeyGroup](t *testing.T) {
+	const n = 4
+	keys := make([]*bls.PrivateKey[K], n)
+	pubs := make([]*bls.PublicKey[K], n)
+	msgs := make([][]byte, n)
+	sigs := make([]bls.Signature, n)
+	for i := range keys {
+		keys[i] = newKey[K](t)
+		pubs[i] = keys[i].Public()
+		msgs[i] = []byte(fmt.Sprintf("message %v", i))
+		sigs[i] = bls.Sign(keys[i], msgs[i])
+		test.CheckOk(bls.Verify(pubs[i], msgs[i], sigs[i]), "verification failed", t)
+
+		proof := bls.PopProve(keys[i])
+		enc, err := pubs[i].MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		test.CheckOk(bls.PopVerify(pubs[i], proof), "proof of possession failed", t)
+		test.CheckOk(!bls.Verify(pubs[i], enc, proof), "proof should not verify as a signature", t)
+	}
+	test.CheckOk(!bls.Verify(pubs[0], msgs[1], sigs[0]), "should fail with another message", t)
+	test.CheckOk(!bls.Verify(pubs[1], msgs[0], sigs[0]), "should fail with another key", t)
+	test.CheckOk(!bls.PopVerify(pubs[1], bls.PopProve(keys[0])), "should fail with another key", t)
+	test.CheckOk(!bls.Verify(new(bls.PublicKey[K]), msgs[0], sigs[0]), "should fail with an invalid key", t)
+
+	agg, err := bls.Aggregate[K](sigs)
+	test.CheckNoErr(t, err, "aggregation failed")
+	test.CheckOk(bls.AggregateVerify(pubs, msgs, agg), "aggregate verification failed", t)
+	test.CheckOk(!bls.AggregateVerify(pubs[1:], msgs[1:], agg), "should fail with a missing signer", t)
+	test.CheckOk(!bls.AggregateVerify(pubs, msgs[1:], agg), "should fail with mismatched lengths", t)
+	swapped := [][]byte{msgs[1], msgs[0], msgs[2], msgs[3]}
+	test.CheckOk(!bls.AggregateVerify(pubs, swapped, agg), "should fail with swapped messages", t)
+	_, err = bls.Aggregate[K](nil)
+	test.CheckIsErr(t, err, "should fail without signatures")
+	_, err = bls.Aggregate[K]([]bls.Signature{sigs[0][1:]})
+	test.CheckIsErr(t, err, "should fail with an invalid signature")
+
+	msg := []byte("common message")
+	same := make([]bls.Signature, n)
+	for i := range same {
+		same[i] = bls.Sign(keys[i], msg)
+	}
+	agg, err = bls.Aggregate[K](same)
+	test.CheckNoErr(t, err, "aggregation failed")
+	test.CheckOk(bls.FastAggregateVerify(pubs, msg, agg), "fast aggregate verification failed", t)
+	test.CheckOk(bls.AggregateVerify(pubs, [][]byte{msg, msg, msg, msg}, agg), "aggregate verification failed", t)
+	test.CheckOk(!bls.FastAggregateVerify(pubs[1:], msg, agg), "should fail with a missing signer", t)
+	test.CheckOk(!bls.FastAggregateVerify(pubs, msgs[0], agg), "should fail with another message", t)
+	test.CheckOk(!bls.FastAggregateVerify[K](nil, msg, agg), "should fail without keys", t)
+}
+
+func TestMarshal(t *testing.T) {
+	t.Run("KeyG1SigG2", testMarshal[bls.KeyG1SigG2])
+	t.Run("KeyG2SigG1", testMarshal[bls.KeyG2SigG1])
+}
+
+func testMarshal[K bls.KeyGroup](t *testing.T) {
+	k := newKey[K](t)
+	enc, err := k.MarshalBinary()
+	test.CheckNoErr(t, err, "marshal failed")
+	var k2 bls.PrivateKey[K]
+	test.CheckNoErr(t, k2.UnmarshalBinary(enc), "unmarshal failed")
+	test.CheckOk(k.Equal(&k2), "private keys differ", t)
+	test.CheckIsErr(t, k2.UnmarshalBinary(enc[1:]), "should fail with a truncated key")
+	test.CheckIsErr(t, k2.UnmarshalBinary(make([]byte, len(enc))), "should fail with a zero key")
+
+	enc, err = k.Public().MarshalBinary()
+	test.CheckNoErr(t, err, "marshal failed")
+	var pub bls.PublicKey[K]
+	test.CheckNoErr(t, pub.UnmarshalBinary(enc), "unmarshal failed")
+	test.CheckOk(k.Public().Equal(&pub), "public keys differ", t)
+	test.CheckIsErr(t, pub.UnmarshalBinary(enc[1:]), "should fail with a truncated key")
+	identity := make([]byte, len(enc))
+	identity[0] = 0xc0
+	test.CheckIsErr(t, pub.UnmarshalBinary(identity), "should fail with the identity")
+
+	sig := bls.Sign(k, []byte("hello"))
+	test.CheckOk(!bls.Verify(k.Public(), []byte("hello"), sig[1:]), "should fail with a truncated signature", t)
+	identity = make([]byte, len(sig))
+	identity[0] = 0xc0
+	test.CheckOk(!bls.Verify(k.Public(), []byte("hello"), identity), "should fail with the identity", t)
+}
+
+func TestKeyGen(t *testing.T) {
+	_, err := bls.KeyGen[bls.KeyG1SigG2](make([]byte, 31), nil)
+	test.CheckIsErr(t, err, "should fail with a short IKM")
+
+	ikm := bytes.Repeat([]byte{0x42}, 32)
+	k1, err := bls.KeyGen[bls.KeyG1SigG2](ikm, nil)
+	test.CheckNoErr(t, err, "key generation failed")
+	k2, err := bls.KeyGen[bls.KeyG1SigG2](ikm, nil)
+	test.CheckNoErr(t, err, "key generation failed")
+	k3, err := bls.KeyGen[bls.KeyG1SigG2](ikm, []byte("info"))
+	test.CheckNoErr(t, err, "key generation failed")
+	test.CheckOk(k1.Equal(k2), "key generation is not deterministic", t)
+	test.CheckOk(!k1.Equal(k3), "key generation ignores the key info", t)
+}
+
+// TestVector checks a signature of the Ethereum consensus specification,
+// which uses the ciphersuite BLS_SIG_BLS12381G2_XMD:SHA-256_SSWU_RO_POP_.
+func TestVector(t *testing.T) {
+	sk, _ := hex.DecodeString("263dbd792f5b1be47ed85f8938c0f29586af0d3ac7b977f21c278fe1462040e3")
+	pk, _ := hex.DecodeString("a491d1b0ecd9bb917989f0e74f0dea0422eac4a873e5e2644f368dffb9a6e20f" +
+		"d6e10c1b77654d067c0618f6e5a7f79a")
+	want, _ := hex.DecodeString("b6ed936746e01f8ecf281f020953fbf1f01debd5657c4a383940b020b26507f6" +
+		"076334f91e2366c96e9ab279fb5158090352ea1c5b0c9274504f4f0e7053af24" +
+		"802e51e4568d164fe986834f41e55c8e850ce1f98458c0cfc9ab380b55285a55")
+	msg := make([]byte, 32)
+
+	var k bls.PrivateKey[bls.KeyG1SigG2]
+	test.CheckNoErr(t, k.UnmarshalBinary(sk), "unmarshal failed")
+	got, err := k.Public().MarshalBinary()
+	test.CheckNoErr(t, err, "marshal failed")
+	if !bytes.Equal(got, pk) {
+		test.ReportError(t, got, pk)
+	}
+	got = bls.Sign(&k, msg)
+	if !bytes.Equal(got, want) {
+		test.ReportError(t, got, want)
+	}
+	test.CheckOk(bls.Verify(k.Public(), msg, got), "verification failed", t)
+}
+
+func BenchmarkBLS(b *testing.B) {
+	b.Run("KeyG1SigG2", benchmarkBLS[bls.KeyG1SigG2])
+	b.Run("KeyG2SigG1", benchmarkBLS[bls.KeyG2SigG1])
+}
+
+func benchmarkBLS[K bls.KeyGroup](b *testing.B) {
+	k := newKey[K](b)
+	pub := k.Public()
+	msg := []byte("hello")
+	sig := bls.Sign(k, msg)
+	b.Run("Sign", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = bls.Sign(k, msg)
+		}
+	})
+	b.Run("Verify", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = bls.Verify(pub, msg, sig)
+		}
+	})
+}
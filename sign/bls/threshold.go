@@ -0,0 +1,218 @@
+package bls
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/katzenpost/circl/ecc/bls12381"
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/math/polynomial"
+)
+
+// scalarGroup provides the scalars of the pairing to the polynomials. The
+// groups G1 and G2 have the same order, so both key groups use it.
+var scalarGroup = group.BLS12381G1
+
+// KeyShare is the share of a private key held by a signer.
+type KeyShare[K KeyGroup] struct {
+	ID        uint16 // Identifier of the signer, starting at one.
+	Threshold uint16 // Minimum number of signers required to sign.
+	key       PrivateKey[K]
+	groupKey  *PublicKey[K]
+}
+
+// PublicKeyShare is the public counterpart of a KeyShare, used to verify
+// the signature shares of a signer.
+type PublicKeyShare[K KeyGroup] struct {
+	ID  uint16
+	key *PublicKey[K]
+}
+
+// SignatureShare is the signature of a message by a signer with its
+// KeyShare.
+type SignatureShare struct {
+	ID        uint16
+	Signature Signature
+}
+
+// Split acts as a trusted dealer: it splits the private key into players
+// shares with identifiers 1 to players, such that any threshold of them can
+// sign. It requires 2 <= threshold <= players.
+func (k *PrivateKey[K]) Split(rnd io.Reader, threshold, players uint) ([]KeyShare[K], error) {
+	if threshold < 2 || players < threshold || players > math.MaxUint16 {
+		return nil, ErrInvalidParameters
+	}
+	if rnd == nil {
+		return nil, io.ErrNoProgress
+	}
+
+	coeffs := make([]group.Scalar, threshold)
+	coeffs[0] = toGroupScalar(&k.key)
+	for i := 1; i < len(coeffs); i++ {
+		coeffs[i] = scalarGroup.RandomScalar(rnd)
+	}
+	poly := polynomial.New(coeffs)
+
+	shares := make([]KeyShare[K], players)
+	for i := range shares {
+		id := uint16(i + 1)
+		shares[i] = KeyShare[K]{
+			ID:        id,
+			Threshold: uint16(threshold),
+			key:       PrivateKey[K]{key: fromGroupScalar(poly.Evaluate(identifierScalar(id)))},
+			groupKey:  k.Public(),
+		}
+	}
+	return shares, nil
+}
+
+// GroupPublicKey returns the public key of the group of signers.
+func (k *KeyShare[K]) GroupPublicKey() *PublicKey[K] { return k.groupKey }
+
+// Public returns the public key share corresponding to the key share.
+func (k *KeyShare[K]) Public() *PublicKeyShare[K] {
+	return &PublicKeyShare[K]{k.ID, k.key.Public()}
+}
+
+// Sign returns the signature share of msg.
+func (k *KeyShare[K]) Sign(msg []byte) SignatureShare {
+	return SignatureShare{k.ID, Sign(&k.key, msg)}
+}
+
+// VerifyShare returns whether share is a valid signature share of msg by the
+// owner of pub.
+func VerifyShare[K KeyGroup](pub *PublicKeyShare[K], msg []byte, share SignatureShare) bool {
+	return share.ID == pub.ID && Verify(pub.key, msg, share.Signature)
+}
+
+// Combine recombines the signature shares of msg from at least threshold
+// distinct signers into a signature under pub. It returns
+// ErrInvalidSignature if the result does not verify, in which case
+// VerifyShare can identify the invalid shares.
+func (pub *PublicKey[K]) Combine(msg []byte, shares []SignatureShare) (Signature, error) {
+	if len(shares) < 2 {
+		return nil, ErrNotEnoughShares
+	}
+	ids := make([]group.Scalar, len(shares))
+	sigs := make([]Signature, len(shares))
+	seen := make(map[uint16]bool, len(shares))
+	for i := range shares {
+		id := shares[i].ID
+		if id == 0 {
+			return nil, ErrInvalidIdentifier
+		}
+		if seen[id] {
+			return nil, ErrInvalidShare
+		}
+		seen[id] = true
+		ids[i] = identifierScalar(id)
+		sigs[i] = shares[i].Signature
+	}
+
+	// S = \Sum_i L_i(0) S_i
+	zero := scalarGroup.NewScalar()
+	coeffs := make([]*bls12381.Scalar, len(shares))
+	for i := range coeffs {
+		l := fromGroupScalar(polynomial.LagrangeBase(uint(i), ids, zero))
+		coeffs[i] = &l
+	}
+	sig, err := combine[K](sigs, coeffs)
+	if err != nil {
+		return nil, err
+	}
+	if !Verify(pub, msg, sig) {
+		return nil, ErrInvalidSignature
+	}
+	return sig, nil
+}
+
+func identifierScalar(id uint16) group.Scalar {
+	return scalarGroup.NewScalar().SetUint64(uint64(id))
+}
+
+func toGroupScalar(s *bls12381.Scalar) group.Scalar {
+	enc, _ := s.MarshalBinary()
+	gs := scalarGroup.NewScalar()
+	if err := gs.UnmarshalBinary(enc); err != nil {
+		panic(err)
+	}
+	return gs
+}
+
+func fromGroupScalar(gs group.Scalar) (s bls12381.Scalar) {
+	enc, err := gs.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	if err := s.UnmarshalBinary(enc); err != nil {
+		panic(err)
+	}
+	return
+}
+
+// MarshalBinary encodes the key share as the identifier and the threshold,
+// both as big-endian uint16, followed by the share and the group public key.
+func (k *KeyShare[K]) MarshalBinary() ([]byte, error) {
+	share, err := k.key.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := k.groupKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 4, 4+len(share)+len(pub))
+	binary.BigEndian.PutUint16(data[0:], k.ID)
+	binary.BigEndian.PutUint16(data[2:], k.Threshold)
+	return append(append(data, share...), pub...), nil
+}
+
+func (k *KeyShare[K]) UnmarshalBinary(data []byte) error {
+	if len(data) < 4+bls12381.ScalarSize {
+		return ErrInvalidKey
+	}
+	id := binary.BigEndian.Uint16(data[0:])
+	threshold := binary.BigEndian.Uint16(data[2:])
+	if id == 0 || threshold < 2 {
+		return ErrInvalidParameters
+	}
+	var share PrivateKey[K]
+	if err := share.UnmarshalBinary(data[4 : 4+bls12381.ScalarSize]); err != nil {
+		return err
+	}
+	var pub PublicKey[K]
+	if err := pub.UnmarshalBinary(data[4+bls12381.ScalarSize:]); err != nil {
+		return err
+	}
+	*k = KeyShare[K]{ID: id, Threshold: threshold, key: share, groupKey: &pub}
+	return nil
+}
+
+// MarshalBinary encodes the public key share as the identifier, as a
+// big-endian uint16, followed by the public key in compressed form.
+func (k *PublicKeyShare[K]) MarshalBinary() ([]byte, error) {
+	enc, err := k.key.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 2, 2+len(enc))
+	binary.BigEndian.PutUint16(data, k.ID)
+	return append(data, enc...), nil
+}
+
+func (k *PublicKeyShare[K]) UnmarshalBinary(data []byte) error {
+	if len(data) < 2 {
+		return ErrInvalidKey
+	}
+	id := binary.BigEndian.Uint16(data)
+	if id == 0 {
+		return ErrInvalidIdentifier
+	}
+	var pub PublicKey[K]
+	if err := pub.UnmarshalBinary(data[2:]); err != nil {
+		return err
+	}
+	*k = PublicKeyShare[K]{id, &pub}
+	return nil
+}
@@ -0,0 +1,133 @@
+package bls_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/sign/bls"
+)
+
+func TestThreshold(t *testing.T) {
+	t.Run("KeyG1SigG2", testThreshold[bls.KeyG1SigG2])
+	t.Run("KeyG2SigG1", testThreshold[bls.KeyG2SigG1])
+}
+
+func testThreshold[K bls.KeyGroup](t *testing.T) {
+	for _, v := range []struct {
+		threshold, players uint
+		signers            []uint16
+	}{
+		{2, 2, []uint16{1, 2}},
+		{2, 3, []uint16{3, 1}},
+		{3, 5, []uint16{2, 4, 5}},
+		{3, 5, []uint16{1, 2, 3, 5}},
+	} {
+		name := fmt.Sprintf("%v-of-%v/%v", v.threshold, v.players, v.signers)
+		t.Run(name, func(t *testing.T) {
+			k := newKey[K](t)
+			pub := k.Public()
+			keyShares, err := k.Split(rand.Reader, v.threshold, v.players)
+			test.CheckNoErr(t, err, "split failed")
+
+			msg := []byte(name)
+			shares := make([]bls.SignatureShare, len(v.signers))
+			for i, id := range v.signers {
+				ks := &keyShares[id-1]
+				shares[i] = ks.Sign(msg)
+				test.CheckOk(bls.VerifyShare(ks.Public(), msg, shares[i]), "share verification failed", t)
+				test.CheckOk(ks.GroupPublicKey().Equal(pub), "wrong group public key", t)
+			}
+			sig, err := pub.Combine(msg, shares)
+			test.CheckNoErr(t, err, "combine failed")
+			test.CheckOk(bls.Verify(pub, msg, sig), "verification failed", t)
+			want := bls.Sign(k, msg)
+			if string(sig) != string(want) {
+				test.ReportError(t, sig, want)
+			}
+
+			_, err = pub.Combine(msg, shares[:v.threshold-1])
+			test.CheckIsErr(t, err, "should fail below the threshold")
+			_, err = pub.Combine([]byte("other"), shares)
+			test.CheckIsErr(t, err, "should fail with another message")
+		})
+	}
+}
+
+func TestThresholdInvalid(t *testing.T) {
+	k := newKey[bls.KeyG2SigG1](t)
+	pub := k.Public()
+	for _, v := range [][2]uint{{1, 3}, {4, 3}, {0, 0}, {2, 1 << 16}} {
+		_, err := k.Split(rand.Reader, v[0], v[1])
+		if !errors.Is(err, bls.ErrInvalidParameters) {
+			test.ReportError(t, err, bls.ErrInvalidParameters, v)
+		}
+	}
+	_, err := k.Split(nil, 2, 3)
+	test.CheckIsErr(t, err, "should fail without randomness")
+
+	keyShares, err := k.Split(rand.Reader, 2, 3)
+	test.CheckNoErr(t, err, "split failed")
+	msg := []byte("hello")
+	s1 := keyShares[0].Sign(msg)
+	s2 := keyShares[1].Sign(msg)
+	s3 := keyShares[2].Sign(msg)
+
+	test.CheckOk(!bls.VerifyShare(keyShares[0].Public(), msg, s2), "should fail with another share", t)
+	bad := s2
+	bad.ID = 3
+	test.CheckOk(!bls.VerifyShare(keyShares[2].Public(), msg, bad), "should fail with a wrong identifier", t)
+	_, err = pub.Combine(msg, []bls.SignatureShare{s1, bad})
+	if !errors.Is(err, bls.ErrInvalidSignature) {
+		test.ReportError(t, err, bls.ErrInvalidSignature)
+	}
+	_, err = pub.Combine(msg, []bls.SignatureShare{s1, s1})
+	if !errors.Is(err, bls.ErrInvalidShare) {
+		test.ReportError(t, err, bls.ErrInvalidShare)
+	}
+	_, err = pub.Combine(msg, []bls.SignatureShare{{0, s1.Signature}, s2})
+	if !errors.Is(err, bls.ErrInvalidIdentifier) {
+		test.ReportError(t, err, bls.ErrInvalidIdentifier)
+	}
+	_, err = pub.Combine(msg, []bls.SignatureShare{s1})
+	if !errors.Is(err, bls.ErrNotEnoughShares) {
+		test.ReportError(t, err, bls.ErrNotEnoughShares)
+	}
+	_, err = pub.Combine(msg, []bls.SignatureShare{s1, {2, s2.Signature[1:]}})
+	test.CheckIsErr(t, err, "should fail with a malformed share")
+	_, err = pub.Combine(msg, []bls.SignatureShare{s3, s1})
+	test.CheckNoErr(t, err, "combine failed")
+}
+
+func TestThresholdMarshal(t *testing.T) {
+	k := newKey[bls.KeyG1SigG2](t)
+	pub := k.Public()
+	keyShares, err := k.Split(rand.Reader, 2, 2)
+	test.CheckNoErr(t, err, "split failed")
+
+	msg := []byte("hello")
+	shares := make([]bls.SignatureShare, len(keyShares))
+	for i := range keyShares {
+		enc, err := keyShares[i].MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		var ks bls.KeyShare[bls.KeyG1SigG2]
+		test.CheckNoErr(t, ks.UnmarshalBinary(enc), "unmarshal failed")
+		test.CheckIsErr(t, new(bls.KeyShare[bls.KeyG1SigG2]).UnmarshalBinary(enc[:len(enc)-1]), "should fail with a truncated key share")
+		if ks.ID != keyShares[i].ID || ks.Threshold != keyShares[i].Threshold {
+			test.ReportError(t, ks.ID, keyShares[i].ID)
+		}
+
+		enc, err = keyShares[i].Public().MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		var pubShare bls.PublicKeyShare[bls.KeyG1SigG2]
+		test.CheckNoErr(t, pubShare.UnmarshalBinary(enc), "unmarshal failed")
+		test.CheckIsErr(t, new(bls.PublicKeyShare[bls.KeyG1SigG2]).UnmarshalBinary(enc[2:]), "should fail with a missing identifier")
+
+		shares[i] = ks.Sign(msg)
+		test.CheckOk(bls.VerifyShare(&pubShare, msg, shares[i]), "share verification failed", t)
+	}
+	_, err = pub.Combine(msg, shares)
+	test.CheckNoErr(t, err, "combine failed")
+}
@@ -0,0 +1,324 @@
+package sign
+
+import (
+	"crypto"
+	cryptoRand "crypto/rand"
+	"encoding/asn1"
+	"errors"
+	"io"
+
+	"github.com/katzenpost/circl/internal/sha3"
+)
+
+// compositePrefix is the prefix of the messages signed by the components
+// of a composite scheme, as in the IETF composite signatures draft.
+var compositePrefix = []byte("CompositeAlgorithmSignatures2025")
+
+// ErrHashedMessage is the error used by the composite private keys if
+// crypto.Signer.Sign is passed a digest rather than a message.
+var ErrHashedMessage = errors.New("composite: cannot sign hashed message")
+
+// compositeSeedSize is the size of the seeds of composite schemes, which
+// are expanded into the seeds of the components.
+const compositeSeedSize = 32
+
+// CompositeScheme combines two signature schemes, following the IETF
+// composite signatures draft
+//
+// https://datatracker.ietf.org/doc/draft-ietf-lamps-pq-composite-sigs/
+//
+// Both components sign
+//
+//	M' = Prefix || Domain || len(ctx) || ctx || PH(M)
+//
+// where Domain is the DER encoding of the object identifier of the
+// composite, and PH is the optional pre-hash function, or the identity.
+// The first component is the post-quantum one, such as ML-DSA, and is
+// passed Domain as context if it supports contexts. The second component
+// is the traditional one, such as Ed25519 or Ed448, and signs M' with an
+// empty context. Keys and signatures are the concatenations of those of
+// the components, and a signature is valid only if both component
+// signatures are.
+//
+// CompositeScheme implements pki.CertificateScheme.
+type CompositeScheme struct {
+	name          string
+	oid           asn1.ObjectIdentifier
+	domain        []byte
+	hash          crypto.Hash
+	first, second Scheme
+}
+
+// NewComposite returns the composite of the post-quantum scheme first and
+// the traditional scheme second with the given name and object
+// identifier. If hash is not zero, messages are hashed
+// with it before being signed.
+//
+// Panics if oid is not a valid object identifier, or if hash is not
+// available.
+func NewComposite(
+	name string,
+	oid asn1.ObjectIdentifier,
+	first, second Scheme,
+	hash crypto.Hash,
+) *CompositeScheme {
+	domain, err := asn1.Marshal(oid)
+	if err != nil {
+		panic(err)
+	}
+	if hash != 0 && !hash.Available() {
+		panic("composite: hash function not available")
+	}
+	return &CompositeScheme{
+		name:   name,
+		oid:    oid,
+		domain: domain,
+		hash:   hash,
+		first:  first,
+		second: second,
+	}
+}
+
+// Components returns the two schemes combined by s.
+func (s *CompositeScheme) Components() (first, second Scheme) {
+	return s.first, s.second
+}
+
+func (s *CompositeScheme) Name() string          { return s.name }
+func (s *CompositeScheme) SeedSize() int         { return compositeSeedSize }
+func (s *CompositeScheme) SupportsContext() bool { return true }
+func (s *CompositeScheme) Oid() asn1.ObjectIdentifier {
+	return append(asn1.ObjectIdentifier(nil), s.oid...)
+}
+
+func (s *CompositeScheme) PublicKeySize() int {
+	return s.first.PublicKeySize() + s.second.PublicKeySize()
+}
+
+func (s *CompositeScheme) PrivateKeySize() int {
+	return s.first.PrivateKeySize() + s.second.PrivateKeySize()
+}
+
+func (s *CompositeScheme) SignatureSize() int {
+	return s.first.SignatureSize() + s.second.SignatureSize()
+}
+
+func (s *CompositeScheme) GenerateKey() (PublicKey, PrivateKey, error) {
+	var seed [compositeSeedSize]byte
+	if _, err := io.ReadFull(cryptoRand.Reader, seed[:]); err != nil {
+		return nil, nil, err
+	}
+	pk, sk := s.DeriveKey(seed[:])
+	return pk, sk, nil
+}
+
+// DeriveKey derives a key pair from seed. The seeds of the components
+// are derived from it with SHAKE256, as it is not safe in general to use
+// the same seed for both.
+func (s *CompositeScheme) DeriveKey(seed []byte) (PublicKey, PrivateKey) {
+	if len(seed) != compositeSeedSize {
+		panic(ErrSeedSize)
+	}
+	seed1 := make([]byte, s.first.SeedSize())
+	seed2 := make([]byte, s.second.SeedSize())
+	h := sha3.NewShake256()
+	_, _ = h.Write(seed)
+	_, _ = h.Read(seed1)
+	_, _ = h.Read(seed2)
+
+	pk1, sk1 := s.first.DeriveKey(seed1)
+	pk2, sk2 := s.second.DeriveKey(seed2)
+	pk := &compositePublicKey{s, pk1, pk2}
+	return pk, &compositePrivateKey{s, sk1, sk2, pk}
+}
+
+// message returns M' for the message msg and the context ctx, and the
+// options with which the components sign it.
+func (s *CompositeScheme) message(msg []byte, ctx string, det bool) (
+	[]byte, *SignatureOpts, *SignatureOpts,
+) {
+	if s.hash != 0 {
+		h := s.hash.New()
+		_, _ = h.Write(msg)
+		msg = h.Sum(nil)
+	}
+	m := make([]byte, 0, len(compositePrefix)+len(s.domain)+1+len(ctx)+len(msg))
+	m = append(m, compositePrefix...)
+	m = append(m, s.domain...)
+	m = append(m, byte(len(ctx)))
+	m = append(m, ctx...)
+	m = append(m, msg...)
+
+	opts1 := &SignatureOpts{Deterministic: det}
+	if s.first.SupportsContext() {
+		opts1.Context = string(s.domain)
+	}
+	return m, opts1, &SignatureOpts{Deterministic: det}
+}
+
+// Sign signs msg with both components. opts.Deterministic is passed on
+// to the components.
+//
+// Panics if the context is longer than 255 bytes.
+func (s *CompositeScheme) Sign(
+	sk PrivateKey,
+	msg []byte,
+	opts *SignatureOpts,
+) []byte {
+	priv, ok := sk.(*compositePrivateKey)
+	if !ok || priv.scheme != s {
+		panic(ErrTypeMismatch)
+	}
+	var ctx string
+	var det bool
+	if opts != nil {
+		ctx, det = opts.Context, opts.Deterministic
+	}
+	if len(ctx) > 255 {
+		panic(ErrContextTooLong)
+	}
+
+	m, opts1, opts2 := s.message(msg, ctx, det)
+	sig := make([]byte, 0, s.SignatureSize())
+	sig = append(sig, s.first.Sign(priv.first, m, opts1)...)
+	sig = append(sig, s.second.Sign(priv.second, m, opts2)...)
+	return sig
+}
+
+// Verify checks whether both component signatures in sig are valid.
+// Returns false if the context is longer than 255 bytes.
+func (s *CompositeScheme) Verify(
+	pk PublicKey,
+	msg, sig []byte,
+	opts *SignatureOpts,
+) bool {
+	pub, ok := pk.(*compositePublicKey)
+	if !ok || pub.scheme != s {
+		panic(ErrTypeMismatch)
+	}
+	var ctx string
+	if opts != nil {
+		ctx = opts.Context
+	}
+	if len(ctx) > 255 || len(sig) != s.SignatureSize() {
+		return false
+	}
+
+	m, opts1, opts2 := s.message(msg, ctx, false)
+	n := s.first.SignatureSize()
+	ok1 := s.first.Verify(pub.first, m, sig[:n], opts1)
+	ok2 := s.second.Verify(pub.second, m, sig[n:], opts2)
+	return ok1 && ok2
+}
+
+func (s *CompositeScheme) UnmarshalBinaryPublicKey(buf []byte) (PublicKey, error) {
+	if len(buf) != s.PublicKeySize() {
+		return nil, ErrPubKeySize
+	}
+	n := s.first.PublicKeySize()
+	pk1, err := s.first.UnmarshalBinaryPublicKey(buf[:n])
+	if err != nil {
+		return nil, err
+	}
+	pk2, err := s.second.UnmarshalBinaryPublicKey(buf[n:])
+	if err != nil {
+		return nil, err
+	}
+	return &compositePublicKey{s, pk1, pk2}, nil
+}
+
+func (s *CompositeScheme) UnmarshalBinaryPrivateKey(buf []byte) (PrivateKey, error) {
+	if len(buf) != s.PrivateKeySize() {
+		return nil, ErrPrivKeySize
+	}
+	n := s.first.PrivateKeySize()
+	sk1, err := s.first.UnmarshalBinaryPrivateKey(buf[:n])
+	if err != nil {
+		return nil, err
+	}
+	sk2, err := s.second.UnmarshalBinaryPrivateKey(buf[n:])
+	if err != nil {
+		return nil, err
+	}
+	pk1, ok1 := sk1.Public().(PublicKey)
+	pk2, ok2 := sk2.Public().(PublicKey)
+	if !ok1 || !ok2 {
+		return nil, ErrTypeMismatch
+	}
+	return &compositePrivateKey{s, sk1, sk2, &compositePublicKey{s, pk1, pk2}}, nil
+}
+
+type compositePublicKey struct {
+	scheme        *CompositeScheme
+	first, second PublicKey
+}
+
+type compositePrivateKey struct {
+	scheme        *CompositeScheme
+	first, second PrivateKey
+	pk            *compositePublicKey
+}
+
+func (pk *compositePublicKey) Scheme() Scheme  { return pk.scheme }
+func (sk *compositePrivateKey) Scheme() Scheme { return sk.scheme }
+
+func (pk *compositePublicKey) MarshalBinary() ([]byte, error) {
+	b1, err := pk.first.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b2, err := pk.second.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(b1, b2...), nil
+}
+
+func (sk *compositePrivateKey) MarshalBinary() ([]byte, error) {
+	b1, err := sk.first.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	b2, err := sk.second.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(b1, b2...), nil
+}
+
+func (pk *compositePublicKey) Equal(other crypto.PublicKey) bool {
+	o, ok := other.(*compositePublicKey)
+	return ok && pk.scheme == o.scheme &&
+		pk.first.Equal(o.first) && pk.second.Equal(o.second)
+}
+
+func (sk *compositePrivateKey) Equal(other crypto.PrivateKey) bool {
+	o, ok := other.(*compositePrivateKey)
+	if !ok || sk.scheme != o.scheme {
+		return false
+	}
+	// Compare both components regardless of the first result.
+	eq1 := sk.first.Equal(o.first)
+	eq2 := sk.second.Equal(o.second)
+	return eq1 && eq2
+}
+
+// Public returns the composite public key.
+func (sk *compositePrivateKey) Public() crypto.PublicKey { return sk.pk }
+
+// Sign signs msg with an empty context string. opts.HashFunc() must return
+// zero, which can be achieved by passing crypto.Hash(0) for opts. rand is
+// ignored: the components use their own randomness, if any.
+//
+// This function is used to make the private key implement the
+// crypto.Signer interface.
+func (sk *compositePrivateKey) Sign(
+	rand io.Reader,
+	msg []byte,
+	opts crypto.SignerOpts,
+) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, ErrHashedMessage
+	}
+	return sk.scheme.Sign(sk, msg, nil), nil
+}
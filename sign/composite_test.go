@@ -0,0 +1,205 @@
+package sign_test
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"fmt"
+	"testing"
+
+	"github.com/katzenpost/circl/pki"
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/ed25519"
+	"github.com/katzenpost/circl/sign/ed448"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa44"
+	"github.com/katzenpost/circl/sign/slhdsa"
+)
+
+// Object identifiers under the arc 2.999 reserved for examples.
+var (
+	oidMLDSA44Ed25519 = asn1.ObjectIdentifier{2, 999, 1}
+	oidSLHDSAEd448    = asn1.ObjectIdentifier{2, 999, 2}
+)
+
+func TestComposite(t *testing.T) {
+	for _, scheme := range []*sign.CompositeScheme{
+		sign.NewComposite("ML-DSA-44-Ed25519-SHA512", oidMLDSA44Ed25519,
+			mldsa44.Scheme(), ed25519.Scheme(), crypto.SHA512),
+		sign.NewComposite("SLH-DSA-SHAKE-128f-Ed448", oidSLHDSAEd448,
+			slhdsa.SHAKE_128f.Scheme(), ed448.Scheme(), 0),
+	} {
+		scheme := scheme
+		t.Run(scheme.Name(), func(t *testing.T) {
+			first, second := scheme.Components()
+			if scheme.SignatureSize() != first.SignatureSize()+second.SignatureSize() {
+				t.Fatal()
+			}
+			var cert pki.CertificateScheme = scheme
+			if oid := cert.Oid(); !oid.Equal(oidMLDSA44Ed25519) &&
+				!oid.Equal(oidSLHDSAEd448) {
+				t.Fatal(oid)
+			}
+
+			seed := make([]byte, scheme.SeedSize())
+			pk, sk := scheme.DeriveKey(seed)
+			pk2, sk2 := scheme.DeriveKey(seed)
+			if !pk.Equal(pk2) || !sk.Equal(sk2) {
+				t.Fatal("DeriveKey is not deterministic")
+			}
+
+			packedPk, err := pk.MarshalBinary()
+			if err != nil || len(packedPk) != scheme.PublicKeySize() {
+				t.Fatal(err)
+			}
+			packedSk, err := sk.MarshalBinary()
+			if err != nil || len(packedSk) != scheme.PrivateKeySize() {
+				t.Fatal(err)
+			}
+			pk2, err = scheme.UnmarshalBinaryPublicKey(packedPk)
+			if err != nil || !pk.Equal(pk2) {
+				t.Fatal(err)
+			}
+			sk2, err = scheme.UnmarshalBinaryPrivateKey(packedSk)
+			if err != nil || !sk.Equal(sk2) {
+				t.Fatal(err)
+			}
+			if !sk2.Public().(sign.PublicKey).Equal(pk) {
+				t.Fatal()
+			}
+			if pk2.Scheme() != scheme || sk2.Scheme() != scheme {
+				t.Fatal()
+			}
+
+			msg := []byte(fmt.Sprintf("Signing with %s", scheme.Name()))
+			opts := &sign.SignatureOpts{Context: "A context"}
+			sig := scheme.Sign(sk2, msg, opts)
+			if len(sig) != scheme.SignatureSize() {
+				t.Fatal()
+			}
+			if !scheme.Verify(pk, msg, sig, opts) {
+				t.Fatal()
+			}
+			if scheme.Verify(pk, msg, sig, &sign.SignatureOpts{Context: "Wrong context"}) {
+				t.Fatal()
+			}
+			if scheme.Verify(pk, msg, sig, nil) {
+				t.Fatal()
+			}
+			if scheme.Verify(pk, msg[1:], sig, opts) {
+				t.Fatal()
+			}
+
+			// Both components must verify.
+			for _, i := range []int{0, first.SignatureSize()} {
+				sig[i]++
+				if scheme.Verify(pk, msg, sig, opts) {
+					t.Fatalf("accepted a signature tampered at %d", i)
+				}
+				sig[i]--
+			}
+
+			// A component signature alone does not verify the message.
+			n := first.SignatureSize()
+			firstPk, _ := first.UnmarshalBinaryPublicKey(packedPk[:first.PublicKeySize()])
+			if first.Verify(firstPk, msg, sig[:n], nil) {
+				t.Fatal("component signature is not domain separated")
+			}
+
+			// The signature is bound to the object identifier.
+			other := sign.NewComposite(scheme.Name(), asn1.ObjectIdentifier{2, 999, 3},
+				first, second, 0)
+			otherPk, _ := other.UnmarshalBinaryPublicKey(packedPk)
+			if other.Verify(otherPk, msg, sig, opts) {
+				t.Fatal("signature is not bound to the object identifier")
+			}
+
+			sig, err = sk.Sign(nil, msg, crypto.Hash(0))
+			if err != nil || !scheme.Verify(pk, msg, sig, nil) {
+				t.Fatal(err)
+			}
+			if _, err = sk.Sign(nil, msg, crypto.SHA256); err != sign.ErrHashedMessage {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+// TestCompositeMessage checks that the components sign
+// M' = Prefix || Domain || len(ctx) || ctx || PH(M), and that only the
+// post-quantum component is passed Domain as context.
+func TestCompositeMessage(t *testing.T) {
+	for _, tc := range []struct {
+		scheme *sign.CompositeScheme
+		hash   crypto.Hash
+	}{
+		{sign.NewComposite("ML-DSA-44-Ed25519-SHA512", oidMLDSA44Ed25519,
+			mldsa44.Scheme(), ed25519.Scheme(), crypto.SHA512), crypto.SHA512},
+		{sign.NewComposite("ML-DSA-44-Ed448", oidSLHDSAEd448,
+			mldsa44.Scheme(), ed448.Scheme(), 0), 0},
+	} {
+		scheme := tc.scheme
+		t.Run(scheme.Name(), func(t *testing.T) {
+			first, second := scheme.Components()
+			pk, sk, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := []byte("message")
+			ctx := "context"
+			sig := scheme.Sign(sk, msg, &sign.SignatureOpts{Context: ctx})
+
+			domain, err := asn1.Marshal(scheme.Oid())
+			if err != nil {
+				t.Fatal(err)
+			}
+			ph := msg
+			if tc.hash != 0 {
+				h := tc.hash.New()
+				_, _ = h.Write(msg)
+				ph = h.Sum(nil)
+			}
+			m := []byte("CompositeAlgorithmSignatures2025")
+			m = append(m, domain...)
+			m = append(m, byte(len(ctx)))
+			m = append(m, ctx...)
+			m = append(m, ph...)
+
+			packedPk, _ := pk.MarshalBinary()
+			pk1, err := first.UnmarshalBinaryPublicKey(packedPk[:first.PublicKeySize()])
+			if err != nil {
+				t.Fatal(err)
+			}
+			pk2, err := second.UnmarshalBinaryPublicKey(packedPk[first.PublicKeySize():])
+			if err != nil {
+				t.Fatal(err)
+			}
+			sig1, sig2 := sig[:first.SignatureSize()], sig[first.SignatureSize():]
+			if !first.Verify(pk1, m, sig1, &sign.SignatureOpts{Context: string(domain)}) {
+				t.Fatal("first component does not sign M' with Domain as context")
+			}
+			if !second.Verify(pk2, m, sig2, nil) {
+				t.Fatal("second component does not sign M' with an empty context")
+			}
+			if second.SupportsContext() &&
+				second.Verify(pk2, m, sig2, &sign.SignatureOpts{Context: string(domain)}) {
+				t.Fatal("second component signs M' with Domain as context")
+			}
+		})
+	}
+}
+
+func ExampleNewComposite() {
+	// Combine SLH-DSA with Ed448, under an object identifier of the
+	// example arc.
+	scheme := sign.NewComposite("SLH-DSA-SHAKE-128f-Ed448",
+		asn1.ObjectIdentifier{2, 999, 2},
+		slhdsa.SHAKE_128f.Scheme(), ed448.Scheme(), 0)
+
+	pk, sk, err := scheme.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	msg := []byte("hello")
+	sig := scheme.Sign(sk, msg, nil)
+	fmt.Println(scheme.Verify(pk, msg, sig, nil))
+	// Output: true
+}
@@ -13,7 +13,15 @@
 //
 // If your choice for mode is fixed compile-time, use the subpackages.
 // This package provides a convenient wrapper around all of the subpackages
-// so one can be chosen at runtime.
+// so one can be chosen at runtime.  The subpackages are also available
+// through the generic signatures API under
+//
+//	github.com/katzenpost/circl/sign/schemes
+//
+// Round 3 Dilithium is not compatible with ML-DSA, the version of Dilithium
+// standardized in FIPS 204, which can be found in
+//
+//	github.com/katzenpost/circl/sign/mldsa
 //
 // The authors of Dilithium recommend to combine it with a "pre-quantum"
 // signature scheme.  The packages
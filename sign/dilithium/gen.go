@@ -7,6 +7,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/asn1"
 	"fmt"
 	"go/format"
 	"os"
@@ -14,7 +15,7 @@ import (
 	"strings"
 	"text/template"
 
-	"github.com/katzenpost/circl/sign/dilithium/internal/common/params"
+	"github.com/katzenpost/circl/sign/internal/dilithium/params"
 )
 
 type Mode struct {
@@ -28,17 +29,62 @@ type Mode struct {
 	Tau           int
 	Gamma1Bits    int
 	Gamma2        int
+	TRSize        int
+	CTildeSize    int
+
+	// Round 3 Dilithium has no standard OIDs nor TLS code points, so we
+	// use the experimental ones of the Open Quantum Safe project.
+	Oid           asn1.ObjectIdentifier
+	HashOid       asn1.ObjectIdentifier
+	TLSIdentifier int
 }
 
 func (m Mode) Pkg() string {
 	return strings.ToLower(m.Mode())
 }
 
+func (m Mode) PkgPath() string {
+	if m.NIST() {
+		return path.Join("..", "mldsa", m.Pkg())
+	}
+	return m.Pkg()
+}
+
+func (m Mode) NIST() bool {
+	return strings.HasPrefix(m.Name, "ML-DSA-")
+}
+
+func (m Mode) HashName() string {
+	return "Hash" + m.Name + "-with-SHA512"
+}
+
+func (m Mode) OidGo() string {
+	return oidGo(m.Oid)
+}
+
+func (m Mode) HashOidGo() string {
+	return oidGo(m.HashOid)
+}
+
+func oidGo(oid asn1.ObjectIdentifier) string {
+	ret := "asn1.ObjectIdentifier{"
+	for i, v := range oid {
+		if i != 0 {
+			ret += ", "
+		}
+		ret += fmt.Sprintf("%d", v)
+	}
+	return ret + "}"
+}
+
 func (m Mode) Impl() string {
 	return "impl" + m.Mode()
 }
 
 func (m Mode) Mode() string {
+	if m.NIST() {
+		return strings.ReplaceAll(m.Name, "-", "")
+	}
 	return strings.ReplaceAll(strings.ReplaceAll(m.Name,
 		"Dilithium", "Mode"), "-AES", "AES")
 }
@@ -56,6 +102,10 @@ var (
 			Tau:           39,
 			Gamma1Bits:    17,
 			Gamma2:        (params.Q - 1) / 88,
+			TRSize:        32,
+			CTildeSize:    32,
+			Oid:           asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 7, 4, 4},
+			TLSIdentifier: 0xfea0,
 		},
 		{
 			Name:          "Dilithium2-AES",
@@ -68,6 +118,10 @@ var (
 			Tau:           39,
 			Gamma1Bits:    17,
 			Gamma2:        (params.Q - 1) / 88,
+			TRSize:        32,
+			CTildeSize:    32,
+			Oid:           asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 11, 4, 4},
+			TLSIdentifier: 0xfea7,
 		},
 		{
 			Name:          "Dilithium3",
@@ -80,6 +134,10 @@ var (
 			Tau:           49,
 			Gamma1Bits:    19,
 			Gamma2:        (params.Q - 1) / 32,
+			TRSize:        32,
+			CTildeSize:    32,
+			Oid:           asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 7, 6, 5},
+			TLSIdentifier: 0xfea3,
 		},
 		{
 			Name:          "Dilithium3-AES",
@@ -92,6 +150,10 @@ var (
 			Tau:           49,
 			Gamma1Bits:    19,
 			Gamma2:        (params.Q - 1) / 32,
+			TRSize:        32,
+			CTildeSize:    32,
+			Oid:           asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 11, 6, 5},
+			TLSIdentifier: 0xfeaa,
 		},
 		{
 			Name:          "Dilithium5",
@@ -104,6 +166,10 @@ var (
 			Tau:           60,
 			Gamma1Bits:    19,
 			Gamma2:        (params.Q - 1) / 32,
+			TRSize:        32,
+			CTildeSize:    32,
+			Oid:           asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 7, 8, 7},
+			TLSIdentifier: 0xfea5,
 		},
 		{
 			Name:          "Dilithium5-AES",
@@ -116,6 +182,61 @@ var (
 			Tau:           60,
 			Gamma1Bits:    19,
 			Gamma2:        (params.Q - 1) / 32,
+			TRSize:        32,
+			CTildeSize:    32,
+			Oid:           asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 2, 267, 11, 8, 7},
+			TLSIdentifier: 0xfeac,
+		},
+		{
+			Name:          "ML-DSA-44",
+			UseAES:        false,
+			K:             4,
+			L:             4,
+			Eta:           2,
+			DoubleEtaBits: 3,
+			Omega:         80,
+			Tau:           39,
+			Gamma1Bits:    17,
+			Gamma2:        (params.Q - 1) / 88,
+			TRSize:        64,
+			CTildeSize:    32,
+			Oid:           asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 17},
+			HashOid:       asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 32},
+			TLSIdentifier: 0x0904,
+		},
+		{
+			Name:          "ML-DSA-65",
+			UseAES:        false,
+			K:             6,
+			L:             5,
+			Eta:           4,
+			DoubleEtaBits: 4,
+			Omega:         55,
+			Tau:           49,
+			Gamma1Bits:    19,
+			Gamma2:        (params.Q - 1) / 32,
+			TRSize:        64,
+			CTildeSize:    48,
+			Oid:           asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 18},
+			HashOid:       asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 33},
+			TLSIdentifier: 0x0905,
+		},
+		{
+			Name:          "ML-DSA-87",
+			UseAES:        false,
+			K:             8,
+			L:             7,
+			Eta:           2,
+			DoubleEtaBits: 3,
+			Omega:         75,
+			Tau:           60,
+			Gamma1Bits:    19,
+			Gamma2:        (params.Q - 1) / 32,
+			TRSize:        64,
+			CTildeSize:    64,
+			Oid:           asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 19},
+			HashOid:       asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, 34},
+			TLSIdentifier: 0x0906,
 		},
 	}
 	TemplateWarning = "// Code generated from"
@@ -126,6 +247,7 @@ func main() {
 	generateModeToplevelFiles()
 	generateParamsFiles()
 	generateSourceFiles()
+	generateACVPTests()
 }
 
 // Generates modeX/internal/params.go from templates/params.templ.go
@@ -153,7 +275,7 @@ func generateParamsFiles() {
 		if offset == -1 {
 			panic("Missing template warning in params.templ.go")
 		}
-		err = os.WriteFile(mode.Pkg()+"/internal/params.go",
+		err = os.WriteFile(mode.PkgPath()+"/internal/params.go",
 			[]byte(res[offset:]), 0o644)
 		if err != nil {
 			panic(err)
@@ -169,6 +291,11 @@ func generateModeToplevelFiles() {
 	}
 
 	for _, mode := range Modes {
+		// ML-DSA is not a mode of the round 3 Dilithium API.
+		if mode.NIST() {
+			continue
+		}
+
 		buf := new(bytes.Buffer)
 		err := tl.Execute(buf, mode)
 		if err != nil {
@@ -201,12 +328,17 @@ func generateModePackageFiles() {
 			panic(err)
 		}
 
-		res := string(buf.Bytes())
+		code, err := format.Source(buf.Bytes())
+		if err != nil {
+			panic(fmt.Sprintf("error formating code: %v", err))
+		}
+
+		res := string(code)
 		offset := strings.Index(res, TemplateWarning)
 		if offset == -1 {
 			panic("Missing template warning in modePkg.templ.go")
 		}
-		err = os.WriteFile(mode.Pkg()+"/dilithium.go", []byte(res[offset:]), 0o644)
+		err = os.WriteFile(mode.PkgPath()+"/dilithium.go", []byte(res[offset:]), 0o644)
 		if err != nil {
 			panic(err)
 		}
@@ -246,10 +378,10 @@ func generateSourceFiles() {
 			continue
 		}
 
-		fs, err = os.ReadDir(path.Join(mode.Pkg(), "internal"))
+		fs, err = os.ReadDir(path.Join(mode.PkgPath(), "internal"))
 		for _, f := range fs {
 			name := f.Name()
-			fn := path.Join(mode.Pkg(), "internal", name)
+			fn := path.Join(mode.PkgPath(), "internal", name)
 			if ignored(name) {
 				continue
 			}
@@ -273,7 +405,7 @@ func generateSourceFiles() {
 			}
 		}
 		for name, expected := range files {
-			fn := path.Join(mode.Pkg(), "internal", name)
+			fn := path.Join(mode.PkgPath(), "internal", name)
 			expected = []byte(fmt.Sprintf(
 				"%s mode3/internal/%s by gen.go\n\n%s",
 				TemplateWarning,
@@ -294,3 +426,37 @@ func generateSourceFiles() {
 		}
 	}
 }
+
+// Generates mldsaX/acvp_test.go from templates/acvp.templ.go
+func generateACVPTests() {
+	tl, err := template.ParseFiles("templates/acvp.templ.go")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, mode := range Modes {
+		if !mode.NIST() {
+			continue
+		}
+
+		buf := new(bytes.Buffer)
+		err := tl.Execute(buf, mode)
+		if err != nil {
+			panic(err)
+		}
+
+		res, err := format.Source(buf.Bytes())
+		if err != nil {
+			panic("error formating code")
+		}
+
+		offset := strings.Index(string(res), TemplateWarning)
+		if offset == -1 {
+			panic("Missing template warning in acvp.templ.go")
+		}
+		err = os.WriteFile(mode.PkgPath()+"/acvp_test.go", res[offset:], 0o644)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/katzenpost/circl/sign/dilithium/internal/common"
 	"github.com/katzenpost/circl/sign/dilithium/mode2aes"
+	common "github.com/katzenpost/circl/sign/internal/dilithium"
 )
 
 // implMode2AES implements the mode.Mode interface for Dilithium2-AES.
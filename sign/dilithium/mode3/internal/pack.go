@@ -1,7 +1,7 @@
 package internal
 
 import (
-	"github.com/katzenpost/circl/sign/dilithium/internal/common"
+	common "github.com/katzenpost/circl/sign/internal/dilithium"
 )
 
 // Writes p with norm less than or equal η into buf, which must be of
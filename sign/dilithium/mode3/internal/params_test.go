@@ -3,7 +3,7 @@ package internal
 import (
 	"testing"
 
-	"github.com/katzenpost/circl/sign/dilithium/internal/common"
+	common "github.com/katzenpost/circl/sign/internal/dilithium"
 )
 
 // Tests specific to the current mode
@@ -4,7 +4,7 @@ import (
 	"encoding/binary"
 	"testing"
 
-	"github.com/katzenpost/circl/sign/dilithium/internal/common"
+	common "github.com/katzenpost/circl/sign/internal/dilithium"
 )
 
 func TestVectorDeriveUniform(t *testing.T) {
@@ -150,10 +150,10 @@ func TestDeriveUniformLeGamma1(t *testing.T) {
 
 func TestDeriveUniformBall(t *testing.T) {
 	var p common.Poly
-	var seed [32]byte
+	var seed [CTildeSize]byte
 	for i := 0; i < 100; i++ {
 		binary.LittleEndian.PutUint64(seed[:], uint64(i))
-		PolyDeriveUniformBall(&p, &seed)
+		PolyDeriveUniformBall(&p, seed[:])
 		nonzero := 0
 		for j := 0; j < common.N; j++ {
 			if p[j] != 0 {
@@ -198,13 +198,13 @@ func TestDeriveUniformBallX4(t *testing.T) {
 	}
 	var ps [4]common.Poly
 	var p common.Poly
-	var seed [32]byte
+	var seed [CTildeSize]byte
 	PolyDeriveUniformBallX4(
 		[4]*common.Poly{&ps[0], &ps[1], &ps[2], &ps[3]},
-		&seed,
+		seed[:],
 	)
 	for j := 0; j < 4; j++ {
-		PolyDeriveUniformBall(&p, &seed)
+		PolyDeriveUniformBall(&p, seed[:])
 		if ps[j] != p {
 			t.Fatalf("%d\n%v\n%v", j, ps[j], p)
 		}
@@ -212,24 +212,24 @@ func TestDeriveUniformBallX4(t *testing.T) {
 }
 
 func BenchmarkPolyDeriveUniformBall(b *testing.B) {
-	var seed [32]byte
+	var seed [CTildeSize]byte
 	var p common.Poly
 	var w1 VecK
 	for i := 0; i < b.N; i++ {
 		w1[0][0] = uint32(i)
-		PolyDeriveUniformBall(&p, &seed)
+		PolyDeriveUniformBall(&p, seed[:])
 	}
 }
 
 func BenchmarkPolyDeriveUniformBallX4(b *testing.B) {
-	var seed [32]byte
+	var seed [CTildeSize]byte
 	var p common.Poly
 	var w1 VecK
 	for i := 0; i < b.N; i++ {
 		w1[0][0] = uint32(i)
 		PolyDeriveUniformBallX4(
 			[4]*common.Poly{&p, &p, &p, &p},
-			&seed,
+			seed[:],
 		)
 	}
 }
@@ -1,7 +1,7 @@
 package internal
 
 import (
-	"github.com/katzenpost/circl/sign/dilithium/internal/common"
+	common "github.com/katzenpost/circl/sign/internal/dilithium"
 )
 
 // A vector of L polynomials.
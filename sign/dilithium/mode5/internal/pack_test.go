@@ -5,7 +5,7 @@ package internal
 import (
 	"testing"
 
-	"github.com/katzenpost/circl/sign/dilithium/internal/common"
+	common "github.com/katzenpost/circl/sign/internal/dilithium"
 )
 
 func TestPolyPackLeqEta(t *testing.T) {
@@ -10,8 +10,8 @@ import (
 	"fmt"
 	"io"
 
-	"github.com/katzenpost/circl/sign/dilithium/internal/common"
 	"github.com/katzenpost/circl/sign/dilithium/{{.Pkg}}"
+	common "github.com/katzenpost/circl/sign/internal/dilithium"
 )
 
 // {{.Impl}} implements the mode.Mode interface for {{.Name}}.
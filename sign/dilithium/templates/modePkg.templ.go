@@ -4,19 +4,39 @@
 
 // Code generated from modePkg.templ.go. DO NOT EDIT.
 
+{{- if .NIST }}
+// {{.Pkg}} implements the NIST signature scheme {{.Name}} as defined in
+// FIPS 204, together with its pre-hash variant HashML-DSA.
+//
+// https://doi.org/10.6028/NIST.FIPS.204
+{{- else }}
 // {{.Pkg}} implements the CRYSTALS-Dilithium signature scheme {{.Name}}
 // as submitted to round3 of the NIST PQC competition and described in
 //
 // https://pq-crystals.org/dilithium/data/dilithium-specification-round3-20210208.pdf
+{{- end }}
 package {{.Pkg}}
 
 import (
 	"crypto"
+{{- if .NIST }}
+	cryptoRand "crypto/rand"
+	"crypto/sha512"
+{{- end }}
+	"encoding/asn1"
 	"errors"
+{{- if .NIST }}
+	"hash"
+{{- end }}
 	"io"
 
-	"github.com/katzenpost/circl/sign/dilithium/internal/common"
+	"github.com/katzenpost/circl/sign"
+{{- if .NIST }}
+	"github.com/katzenpost/circl/sign/mldsa/{{.Pkg}}/internal"
+{{- else }}
 	"github.com/katzenpost/circl/sign/dilithium/{{.Pkg}}/internal"
+{{- end }}
+	common "github.com/katzenpost/circl/sign/internal/dilithium"
 )
 
 const (
@@ -52,12 +72,186 @@ func NewKeyFromSeed(seed *[SeedSize]byte) (*PublicKey, *PrivateKey) {
 	return (*PublicKey)(pk), (*PrivateKey)(sk)
 }
 
+{{- if .NIST }}
+var (
+	// ErrUnsupportedHash is returned if HashML-DSA is used with a hash
+	// function that is not approved for it.
+	ErrUnsupportedHash = errors.New("{{.Pkg}}: unsupported pre-hash function")
+
+	// ErrDigestSize is returned if the digest passed to HashML-DSA does
+	// not have the output size of the hash function.
+	ErrDigestSize = errors.New("{{.Pkg}}: wrong digest size")
+)
+
+// SignTo signs the given message and writes the signature into sig.
+// It will panic if sig is not of length at least SignatureSize.
+//
+// ctx is the optional context string. Errors if ctx is larger than 255 bytes.
+// A nil context string is equivalent to an empty context string.
+//
+// If randomized is true, the signature is hedged with fresh randomness
+// from crypto/rand, as recommended by FIPS 204. Otherwise, the
+// deterministic variant is used.
+func SignTo(sk *PrivateKey, msg, ctx []byte, randomized bool, sig []byte) error {
+	var rnd [32]byte
+	if randomized {
+		if _, err := cryptoRand.Read(rnd[:]); err != nil {
+			return err
+		}
+	}
+	return signTo(sk, msg, ctx, rnd, sig)
+}
+
+func signTo(sk *PrivateKey, msg, ctx []byte, rnd [32]byte, sig []byte) error {
+	if len(ctx) > 255 {
+		return sign.ErrContextTooLong
+	}
+
+	internal.SignTo(
+		(*internal.PrivateKey)(sk),
+		func(w io.Writer) {
+			_, _ = w.Write([]byte{0, byte(len(ctx))})
+			_, _ = w.Write(ctx)
+			_, _ = w.Write(msg)
+		},
+		rnd,
+		sig,
+	)
+	return nil
+}
+
+// SignPreHashTo signs the digest of a message computed with the hash
+// function h, as HashML-DSA, and writes the signature into sig.
+// It will panic if sig is not of length at least SignatureSize.
+//
+// ctx and randomized are as for SignTo. Errors if h is not approved for
+// HashML-DSA, or if digest is not of length h.Size(). The approved
+// SHAKE128 and SHAKE256 pre-hashes are not supported, as crypto.Hash
+// has no identifiers for them.
+func SignPreHashTo(
+	sk *PrivateKey,
+	h crypto.Hash,
+	digest, ctx []byte,
+	randomized bool,
+	sig []byte,
+) error {
+	var rnd [32]byte
+	if randomized {
+		if _, err := cryptoRand.Read(rnd[:]); err != nil {
+			return err
+		}
+	}
+	return signPreHashTo(sk, h, digest, ctx, rnd, sig)
+}
+
+func signPreHashTo(
+	sk *PrivateKey,
+	h crypto.Hash,
+	digest, ctx []byte,
+	rnd [32]byte,
+	sig []byte,
+) error {
+	oid := common.PreHashOID(h)
+	if oid == nil {
+		return ErrUnsupportedHash
+	}
+	if len(digest) != h.Size() {
+		return ErrDigestSize
+	}
+	if len(ctx) > 255 {
+		return sign.ErrContextTooLong
+	}
+
+	internal.SignTo(
+		(*internal.PrivateKey)(sk),
+		func(w io.Writer) {
+			_, _ = w.Write([]byte{1, byte(len(ctx))})
+			_, _ = w.Write(ctx)
+			_, _ = w.Write(oid)
+			_, _ = w.Write(digest)
+		},
+		rnd,
+		sig,
+	)
+	return nil
+}
+
+// Do not use. Implements ML-DSA.Sign_internal used for compatibility tests.
+func (sk *PrivateKey) unsafeSignInternal(msg []byte, rnd [32]byte) []byte {
+	var ret [SignatureSize]byte
+	internal.SignTo(
+		(*internal.PrivateKey)(sk),
+		func(w io.Writer) {
+			_, _ = w.Write(msg)
+		},
+		rnd,
+		ret[:],
+	)
+	return ret[:]
+}
+
+// Do not use. Implements ML-DSA.Verify_internal used for compatibility tests.
+func unsafeVerifyInternal(pk *PublicKey, msg, sig []byte) bool {
+	return internal.Verify(
+		(*internal.PublicKey)(pk),
+		func(w io.Writer) {
+			_, _ = w.Write(msg)
+		},
+		sig,
+	)
+}
+
+// Verify checks whether the given signature by pk on msg is valid.
+//
+// ctx is the optional context string. Fails if ctx is larger than 255 bytes.
+// A nil context string is equivalent to an empty context string.
+func Verify(pk *PublicKey, msg, ctx, sig []byte) bool {
+	if len(ctx) > 255 {
+		return false
+	}
+	return internal.Verify(
+		(*internal.PublicKey)(pk),
+		func(w io.Writer) {
+			_, _ = w.Write([]byte{0, byte(len(ctx))})
+			_, _ = w.Write(ctx)
+			_, _ = w.Write(msg)
+		},
+		sig,
+	)
+}
+
+// VerifyPreHash checks whether the given HashML-DSA signature by pk on the
+// digest of a message computed with the hash function h is valid.
+//
+// ctx is as for Verify. Fails if h is not approved for HashML-DSA, or if
+// digest is not of length h.Size().
+func VerifyPreHash(pk *PublicKey, h crypto.Hash, digest, ctx, sig []byte) bool {
+	oid := common.PreHashOID(h)
+	if oid == nil || len(digest) != h.Size() || len(ctx) > 255 {
+		return false
+	}
+	return internal.Verify(
+		(*internal.PublicKey)(pk),
+		func(w io.Writer) {
+			_, _ = w.Write([]byte{1, byte(len(ctx))})
+			_, _ = w.Write(ctx)
+			_, _ = w.Write(oid)
+			_, _ = w.Write(digest)
+		},
+		sig,
+	)
+}
+{{- else }}
 // SignTo signs the given message and writes the signature into signature.
 // It will panic if signature is not of length at least SignatureSize.
 func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
+	var rnd [32]byte
 	internal.SignTo(
 		(*internal.PrivateKey)(sk),
-		msg,
+		func(w io.Writer) {
+			_, _ = w.Write(msg)
+		},
+		rnd,
 		signature,
 	)
 }
@@ -66,10 +260,13 @@ func SignTo(sk *PrivateKey, msg []byte, signature []byte) {
 func Verify(pk *PublicKey, msg []byte, signature []byte) bool {
 	return internal.Verify(
 		(*internal.PublicKey)(pk),
-		msg,
+		func(w io.Writer) {
+			_, _ = w.Write(msg)
+		},
 		signature,
 	)
 }
+{{- end }}
 
 // Sets pk to the public key encoded in buf.
 func (pk *PublicKey) Unpack(buf *[PublicKeySize]byte) {
@@ -137,6 +334,43 @@ func (sk *PrivateKey) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+{{- if .NIST }}
+// Sign signs the given message with an empty context string.
+//
+// If opts.HashFunc() is zero, msg is signed with ML-DSA. Otherwise, msg
+// must be the digest of the message computed with opts.HashFunc(),
+// and it is signed with HashML-DSA.
+//
+// If rand is not nil, 32 bytes are read from it to hedge the signature.
+// Otherwise, the signature is deterministic.
+//
+// This function is used to make PrivateKey implement the crypto.Signer
+// interface.  The package-level SignTo and SignPreHashTo functions might
+// be more convenient to use.
+func (sk *PrivateKey) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts) (
+	signature []byte, err error) {
+	var (
+		sig [SignatureSize]byte
+		rnd [32]byte
+	)
+
+	if rand != nil {
+		if _, err = io.ReadFull(rand, rnd[:]); err != nil {
+			return nil, err
+		}
+	}
+
+	if h := opts.HashFunc(); h != crypto.Hash(0) {
+		err = signPreHashTo(sk, h, msg, nil, rnd, sig[:])
+	} else {
+		err = signTo(sk, msg, nil, rnd, sig[:])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sig[:], nil
+}
+{{- else }}
 // Sign signs the given message.
 //
 // opts.HashFunc() must return zero, which can be achieved by passing
@@ -157,6 +391,7 @@ func (sk *PrivateKey) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts) (
 	SignTo(sk, msg, sig[:])
 	return sig[:], nil
 }
+{{- end }}
 
 // Computes the public key corresponding to this private key.
 //
@@ -183,3 +418,289 @@ func (pk *PublicKey) Equal(other crypto.PublicKey) bool {
 	}
 	return (*internal.PublicKey)(pk).Equal((*internal.PublicKey)(castOther))
 }
+
+// Boilerplate for generic signatures API
+
+type scheme struct{}
+
+var sch sign.Scheme = &scheme{}
+
+// Scheme returns a generic signature interface for {{.Name}}.
+func Scheme() sign.Scheme { return sch }
+
+func (*scheme) Name() string          { return "{{.Name}}" }
+func (*scheme) PublicKeySize() int    { return PublicKeySize }
+func (*scheme) PrivateKeySize() int   { return PrivateKeySize }
+func (*scheme) SignatureSize() int    { return SignatureSize }
+func (*scheme) SeedSize() int         { return SeedSize }
+func (*scheme) TLSIdentifier() uint   { return {{printf "0x%04x" .TLSIdentifier}} }
+func (*scheme) SupportsContext() bool { return {{.NIST}} }
+func (*scheme) Oid() asn1.ObjectIdentifier {
+	return {{.OidGo}}
+}
+
+func (*scheme) GenerateKey() (sign.PublicKey, sign.PrivateKey, error) {
+	return GenerateKey(nil)
+}
+{{- if .NIST }}
+
+// Sign signs msg with ML-DSA. The signature is hedged, unless
+// opts.Deterministic is set.
+func (*scheme) Sign(
+	sk sign.PrivateKey,
+	msg []byte,
+	opts *sign.SignatureOpts,
+) []byte {
+	priv, ok := sk.(*PrivateKey)
+	if !ok {
+		panic(sign.ErrTypeMismatch)
+	}
+	ctx, randomized := contextFromOpts(opts)
+	sig := make([]byte, SignatureSize)
+	if err := SignTo(priv, msg, ctx, randomized, sig); err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+func (*scheme) Verify(
+	pk sign.PublicKey,
+	msg, sig []byte,
+	opts *sign.SignatureOpts,
+) bool {
+	pub, ok := pk.(*PublicKey)
+	if !ok {
+		panic(sign.ErrTypeMismatch)
+	}
+	ctx, _ := contextFromOpts(opts)
+	return Verify(pub, msg, ctx, sig)
+}
+{{- else }}
+
+func (*scheme) Sign(
+	sk sign.PrivateKey,
+	msg []byte,
+	opts *sign.SignatureOpts,
+) []byte {
+	priv, ok := sk.(*PrivateKey)
+	if !ok {
+		panic(sign.ErrTypeMismatch)
+	}
+	if opts != nil && opts.Context != "" {
+		panic(sign.ErrContextNotSupported)
+	}
+	sig := make([]byte, SignatureSize)
+	SignTo(priv, msg, sig)
+	return sig
+}
+
+func (*scheme) Verify(
+	pk sign.PublicKey,
+	msg, sig []byte,
+	opts *sign.SignatureOpts,
+) bool {
+	pub, ok := pk.(*PublicKey)
+	if !ok {
+		panic(sign.ErrTypeMismatch)
+	}
+	if opts != nil && opts.Context != "" {
+		panic(sign.ErrContextNotSupported)
+	}
+	return Verify(pub, msg, sig)
+}
+{{- end }}
+
+func (*scheme) DeriveKey(seed []byte) (sign.PublicKey, sign.PrivateKey) {
+	if len(seed) != SeedSize {
+		panic(sign.ErrSeedSize)
+	}
+	var seed2 [SeedSize]byte
+	copy(seed2[:], seed)
+	return NewKeyFromSeed(&seed2)
+}
+
+func (*scheme) UnmarshalBinaryPublicKey(buf []byte) (sign.PublicKey, error) {
+	if len(buf) != PublicKeySize {
+		return nil, sign.ErrPubKeySize
+	}
+	var (
+		buf2 [PublicKeySize]byte
+		ret  PublicKey
+	)
+	copy(buf2[:], buf)
+	ret.Unpack(&buf2)
+	return &ret, nil
+}
+
+func (*scheme) UnmarshalBinaryPrivateKey(buf []byte) (sign.PrivateKey, error) {
+	if len(buf) != PrivateKeySize {
+		return nil, sign.ErrPrivKeySize
+	}
+	var (
+		buf2 [PrivateKeySize]byte
+		ret  PrivateKey
+	)
+	copy(buf2[:], buf)
+	ret.Unpack(&buf2)
+	return &ret, nil
+}
+
+func (sk *PrivateKey) Scheme() sign.Scheme { return sch }
+func (pk *PublicKey) Scheme() sign.Scheme  { return sch }
+{{- if .NIST }}
+
+// contextFromOpts returns the context string and whether the signature
+// should be hedged.
+func contextFromOpts(opts *sign.SignatureOpts) (ctx []byte, randomized bool) {
+	if opts == nil {
+		return nil, true
+	}
+	return []byte(opts.Context), !opts.Deterministic
+}
+
+// Boilerplate for HashML-DSA with SHA-512 in the generic signatures API.
+// Its keys wrap the ML-DSA keys so that they report the right scheme.
+
+type hashScheme struct{}
+
+var hashSch sign.StreamingScheme = &hashScheme{}
+
+// HashScheme returns a generic signature interface for {{.HashName}},
+// which signs the SHA-512 digest of the message, and thus can sign
+// messages written piecewise.
+func HashScheme() sign.StreamingScheme { return hashSch }
+
+type hashPublicKey struct{ *PublicKey }
+
+type hashPrivateKey struct{ *PrivateKey }
+
+func (*hashScheme) Name() string          { return "{{.HashName}}" }
+func (*hashScheme) PublicKeySize() int    { return PublicKeySize }
+func (*hashScheme) PrivateKeySize() int   { return PrivateKeySize }
+func (*hashScheme) SignatureSize() int    { return SignatureSize }
+func (*hashScheme) SeedSize() int         { return SeedSize }
+func (*hashScheme) SupportsContext() bool { return true }
+func (*hashScheme) Oid() asn1.ObjectIdentifier {
+	return {{.HashOidGo}}
+}
+
+func (*hashScheme) GenerateKey() (sign.PublicKey, sign.PrivateKey, error) {
+	pk, sk, err := GenerateKey(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return hashPublicKey{pk}, hashPrivateKey{sk}, nil
+}
+
+// Sign signs the SHA-512 digest of msg with HashML-DSA. The signature is
+// hedged, unless opts.Deterministic is set.
+func (s *hashScheme) Sign(
+	sk sign.PrivateKey,
+	msg []byte,
+	opts *sign.SignatureOpts,
+) []byte {
+	signer := s.NewSigner(sk, opts)
+	_, _ = signer.Write(msg)
+	return signer.Sign()
+}
+
+func (s *hashScheme) Verify(
+	pk sign.PublicKey,
+	msg, sig []byte,
+	opts *sign.SignatureOpts,
+) bool {
+	verifier := s.NewVerifier(pk, opts)
+	_, _ = verifier.Write(msg)
+	return verifier.Verify(sig)
+}
+
+// NewSigner returns a Signer of the message written to it. Its signatures
+// are hedged, unless opts.Deterministic is set. Panics if the context is
+// longer than 255 bytes.
+func (*hashScheme) NewSigner(sk sign.PrivateKey, opts *sign.SignatureOpts) sign.Signer {
+	priv, ok := sk.(hashPrivateKey)
+	if !ok {
+		panic(sign.ErrTypeMismatch)
+	}
+	ctx, randomized := contextFromOpts(opts)
+	if len(ctx) > 255 {
+		panic(sign.ErrContextTooLong)
+	}
+	return &hashSigner{sha512.New(), priv.PrivateKey, ctx, randomized}
+}
+
+// NewVerifier returns a Verifier of the message written to it. Its
+// signatures are rejected if the context is longer than 255 bytes.
+func (*hashScheme) NewVerifier(pk sign.PublicKey, opts *sign.SignatureOpts) sign.Verifier {
+	pub, ok := pk.(hashPublicKey)
+	if !ok {
+		panic(sign.ErrTypeMismatch)
+	}
+	ctx, _ := contextFromOpts(opts)
+	return &hashVerifier{sha512.New(), pub.PublicKey, ctx}
+}
+
+func (*hashScheme) DeriveKey(seed []byte) (sign.PublicKey, sign.PrivateKey) {
+	pk, sk := sch.DeriveKey(seed)
+	return hashPublicKey{pk.(*PublicKey)}, hashPrivateKey{sk.(*PrivateKey)}
+}
+
+func (*hashScheme) UnmarshalBinaryPublicKey(buf []byte) (sign.PublicKey, error) {
+	pk, err := sch.UnmarshalBinaryPublicKey(buf)
+	if err != nil {
+		return nil, err
+	}
+	return hashPublicKey{pk.(*PublicKey)}, nil
+}
+
+func (*hashScheme) UnmarshalBinaryPrivateKey(buf []byte) (sign.PrivateKey, error) {
+	sk, err := sch.UnmarshalBinaryPrivateKey(buf)
+	if err != nil {
+		return nil, err
+	}
+	return hashPrivateKey{sk.(*PrivateKey)}, nil
+}
+
+func (hashPrivateKey) Scheme() sign.Scheme { return hashSch }
+func (hashPublicKey) Scheme() sign.Scheme  { return hashSch }
+
+func (sk hashPrivateKey) Public() crypto.PublicKey {
+	return hashPublicKey{sk.PrivateKey.Public().(*PublicKey)}
+}
+
+func (sk hashPrivateKey) Equal(other crypto.PrivateKey) bool {
+	castOther, ok := other.(hashPrivateKey)
+	return ok && sk.PrivateKey.Equal(castOther.PrivateKey)
+}
+
+func (pk hashPublicKey) Equal(other crypto.PublicKey) bool {
+	castOther, ok := other.(hashPublicKey)
+	return ok && pk.PublicKey.Equal(castOther.PublicKey)
+}
+
+type hashSigner struct {
+	hash.Hash
+	sk         *PrivateKey
+	ctx        []byte
+	randomized bool
+}
+
+func (s *hashSigner) Sign() []byte {
+	sig := make([]byte, SignatureSize)
+	err := SignPreHashTo(s.sk, crypto.SHA512, s.Sum(nil), s.ctx, s.randomized, sig)
+	if err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+type hashVerifier struct {
+	hash.Hash
+	pk  *PublicKey
+	ctx []byte
+}
+
+func (v *hashVerifier) Verify(sig []byte) bool {
+	return VerifyPreHash(v.pk, crypto.SHA512, v.Sum(nil), v.ctx, sig)
+}
+{{- end }}
@@ -0,0 +1,159 @@
+package ed25519
+
+import (
+	cryptoRand "crypto/rand"
+	"io"
+)
+
+// batchScalarSize is the size, in bytes, of the random coefficients of
+// batch verification.
+const batchScalarSize = 16
+
+// BatchOptions selects the acceptance rules of BatchVerify.
+type BatchOptions struct {
+	// ZIP215 selects the rules of ZIP 215, used by Zcash and other
+	// consensus systems: public keys and R may have non-canonical
+	// encodings, as in ModeZIP215. Otherwise, they must be canonical, as
+	// in ModeCofactored.
+	ZIP215 bool
+}
+
+// BatchVerify checks the Ed25519 signatures[i] of messages[i] by
+// publicKeys[i], and returns whether all of them are valid, along with the
+// validity of each one.
+//
+// All the signatures are checked at once by verifying a random linear
+// combination of their equations, which is much faster than verifying them
+// one by one. If the combination does not hold, each signature is verified
+// individually to find out the invalid ones. The coefficients are read
+// from rand; if rand is nil, crypto/rand.Reader is used.
+//
+// Unlike Verify, BatchVerify uses the cofactored equation [8][S]B =
+// [8]R + [8][k]A, as allowed by RFC 8032 and required by ZIP 215, since
+// otherwise the result could depend on the coefficients. Hence, a
+// signature accepted by BatchVerify but rejected by Verify exists, but can
+// only be produced deliberately by the holder of the private key. The
+// results of BatchVerify always agree with those of Verify with
+// ModeCofactored, or ModeZIP215 if opts.ZIP215 is set.
+//
+// Panics if the slices do not have the same length.
+func BatchVerify(
+	rand io.Reader,
+	publicKeys []PublicKey,
+	messages, signatures [][]byte,
+	opts BatchOptions,
+) (bool, []bool) {
+	n := len(publicKeys)
+	if len(messages) != n || len(signatures) != n {
+		panic("ed25519: wrong batch size")
+	}
+	if rand == nil {
+		rand = cryptoRand.Reader
+	}
+
+	valid := make([]bool, n)
+	sigs := make([]decodedSignature, 0, n)
+	idx := make([]int, 0, n)
+	for i := range publicKeys {
+		var d decodedSignature
+		if d.decode(publicKeys[i], messages[i], signatures[i], nil, false, !opts.ZIP215) {
+			sigs = append(sigs, d)
+			idx = append(idx, i)
+		}
+	}
+	if len(sigs) == 0 {
+		return n == 0, valid
+	}
+
+	if batchEquation(rand, sigs) {
+		for _, i := range idx {
+			valid[i] = true
+		}
+		return len(sigs) == n, valid
+	}
+
+	allValid := len(sigs) == n
+	for j := range sigs {
+		valid[idx[j]] = sigs[j].verify()
+		allValid = allValid && valid[idx[j]]
+	}
+	return allValid, valid
+}
+
+// decodedSignature holds the values involved in the verification equation
+// of a signature.
+type decodedSignature struct {
+	negA, negR pointR1
+	s, k       [paramB]byte
+}
+
+// decode checks the encodings of the public key and the signature of
+// PH(M), and sets d accordingly. If canonical is false, A and R may have
+// the non-canonical encodings allowed by ZIP 215.
+func (d *decodedSignature) decode(
+	public PublicKey,
+	PHM, signature, ctx []byte,
+	preHash, canonical bool,
+) bool {
+	if len(public) != PublicKeySize ||
+		len(signature) != SignatureSize ||
+		!isLessThanOrder(signature[paramB:]) {
+		return false
+	}
+	R := signature[:paramB]
+	if !d.negA.fromBytes(public, canonical) || !d.negR.fromBytes(R, canonical) {
+		return false
+	}
+	d.negA.neg()
+	d.negR.neg()
+	copy(d.s[:], signature[paramB:])
+
+	hRAM := computeHRAM(public, PHM, R, ctx, preHash)
+	copy(d.k[:], hRAM[:paramB])
+	return true
+}
+
+// verify returns whether [8]([S]B - R - [k]A) is the identity.
+func (d *decodedSignature) verify() bool {
+	var Q pointR1
+	var R pointR2
+	negA := d.negA
+	Q.doubleMult(&negA, d.s[:], d.k[:])
+	R.fromR1(&d.negR)
+	Q.add(&R)
+	Q.double()
+	Q.double()
+	Q.double()
+	return Q.isIdentity()
+}
+
+// batchEquation returns whether [8]([sum z_i S_i]B - sum [z_i]R_i -
+// sum [z_i k_i]A_i) is the identity for random z_i. Returns false if rand
+// fails.
+func batchEquation(rand io.Reader, sigs []decodedSignature) bool {
+	var zero, sum [paramB]byte
+	z := make([]byte, batchScalarSize*len(sigs))
+	if _, err := io.ReadFull(rand, z); err != nil {
+		return false
+	}
+
+	Q := make([]pointR1, 2*len(sigs))
+	scalars := make([][]byte, 2*len(sigs))
+	buf := make([]byte, 2*paramB*len(sigs))
+	for i := range sigs {
+		zi := buf[2*i*paramB : (2*i+1)*paramB]
+		zk := buf[(2*i+1)*paramB : (2*i+2)*paramB]
+		copy(zi, z[i*batchScalarSize:(i+1)*batchScalarSize])
+		calculateS(zk, zero[:], zi, sigs[i].k[:])
+		calculateS(sum[:], sum[:], zi, sigs[i].s[:])
+		Q[2*i], scalars[2*i] = sigs[i].negR, zi
+		Q[2*i+1], scalars[2*i+1] = sigs[i].negA, zk
+	}
+
+	var P pointR1
+	P.multiMult(sum[:], Q, scalars)
+	P.double()
+	P.double()
+	P.double()
+	return P.isIdentity()
+}
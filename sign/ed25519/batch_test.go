@@ -0,0 +1,137 @@
+package ed25519_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/sign/ed25519"
+)
+
+func batch(t testing.TB, n int) (pks []ed25519.PublicKey, msgs, sigs [][]byte) {
+	pks = make([]ed25519.PublicKey, n)
+	msgs = make([][]byte, n)
+	sigs = make([][]byte, n)
+	for i := 0; i < n; i++ {
+		pk, sk, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pks[i] = pk
+		msgs[i] = []byte(fmt.Sprintf("message %v", i))
+		sigs[i] = ed25519.Sign(sk, msgs[i])
+	}
+	return
+}
+
+type errorReader struct{}
+
+func (errorReader) Read([]byte) (int, error) { return 0, errors.New("no entropy") }
+
+func TestBatchVerify(t *testing.T) {
+	for _, opts := range []ed25519.BatchOptions{{}, {ZIP215: true}} {
+		for _, n := range []int{0, 1, 2, 7, 64} {
+			pks, msgs, sigs := batch(t, n)
+			ok, valid := ed25519.BatchVerify(nil, pks, msgs, sigs, opts)
+			if !ok || len(valid) != n {
+				test.ReportError(t, ok, true, n, opts)
+			}
+			for i := range valid {
+				if !valid[i] {
+					test.ReportError(t, valid[i], true, n, i, opts)
+				}
+			}
+			if n < 2 {
+				continue
+			}
+
+			// Each kind of failure must be pinpointed.
+			bad := n / 2
+			type entries struct {
+				pks        []ed25519.PublicKey
+				msgs, sigs [][]byte
+			}
+			for _, corrupt := range []func(e *entries){
+				func(e *entries) { e.msgs[bad] = []byte("forged") },
+				func(e *entries) { e.sigs[bad][0] ^= 1 },
+				func(e *entries) { e.sigs[bad][63] ^= 0x80 },
+				func(e *entries) { e.pks[bad] = e.pks[bad-1] },
+				func(e *entries) { e.sigs[bad] = e.sigs[bad][:32] },
+			} {
+				pks, msgs, sigs := batch(t, n)
+				corrupt(&entries{pks, msgs, sigs})
+				ok, valid := ed25519.BatchVerify(nil, pks, msgs, sigs, opts)
+				if ok {
+					test.ReportError(t, ok, false, n, opts)
+				}
+				for i := range valid {
+					got, want := valid[i], i != bad
+					if got != want {
+						test.ReportError(t, got, want, n, i, opts)
+					}
+				}
+			}
+		}
+	}
+
+	t.Run("rand", func(t *testing.T) {
+		// Failing to read coefficients falls back to individual checks.
+		pks, msgs, sigs := batch(t, 3)
+		ok, _ := ed25519.BatchVerify(errorReader{}, pks, msgs, sigs, ed25519.BatchOptions{})
+		if !ok {
+			test.ReportError(t, ok, true)
+		}
+		msgs[1] = nil
+		ok, valid := ed25519.BatchVerify(errorReader{}, pks, msgs, sigs, ed25519.BatchOptions{})
+		if ok || !valid[0] || valid[1] || !valid[2] {
+			test.ReportError(t, valid, []bool{true, false, true})
+		}
+	})
+
+	t.Run("zip215", func(t *testing.T) {
+		// Identity as public key and R, and S = 0, with canonical and
+		// non-canonical encodings.
+		canonical := make([]byte, ed25519.PublicKeySize)
+		canonical[0] = 1
+		nonCanonical := []byte{ // y = p+1
+			0xee, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+			0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+		}
+		negZero := make([]byte, ed25519.PublicKeySize) // x = 0, sign bit set
+		negZero[0], negZero[31] = 1, 0x80
+		sig := func(R []byte) []byte {
+			return append(append([]byte(nil), R...), make([]byte, 32)...)
+		}
+
+		pks, msgs, sigs := batch(t, 4)
+		pks = append(pks, canonical, nonCanonical, canonical)
+		msgs = append(msgs, []byte("a"), []byte("b"), []byte("c"))
+		sigs = append(sigs, sig(canonical), sig(canonical), sig(negZero))
+
+		ok, valid := ed25519.BatchVerify(nil, pks, msgs, sigs, ed25519.BatchOptions{})
+		want := []bool{true, true, true, true, true, false, false}
+		if ok || fmt.Sprint(valid) != fmt.Sprint(want) {
+			test.ReportError(t, valid, want)
+		}
+		ok, valid = ed25519.BatchVerify(nil, pks, msgs, sigs, ed25519.BatchOptions{ZIP215: true})
+		if !ok {
+			test.ReportError(t, valid, true)
+		}
+	})
+}
+
+func BenchmarkBatchVerify(b *testing.B) {
+	for _, n := range []int{1, 8, 64, 1024} {
+		pks, msgs, sigs := batch(b, n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ed25519.BatchVerify(nil, pks, msgs, sigs, ed25519.BatchOptions{})
+			}
+		})
+	}
+}
@@ -20,6 +20,13 @@
 // in this package. While Ed25519Ph accepts an empty context, Ed25519Ctx
 // enforces non-empty context strings.
 //
+// # Verification rules
+//
+// By default, verification applies the strict rules of RFC 8032. Passing a
+// VerifyOptions selects other rules instead, such as those of ZIP 215 used
+// by consensus systems. Many signatures can be verified at once with
+// BatchVerify.
+//
 // # Compatibility with crypto.ed25519
 //
 // These functions are compatible with the “Ed25519” function defined in
@@ -91,6 +98,38 @@ const (
 	ED25519Ctx
 )
 
+// VerifyMode selects the acceptance rules of verification, which differ in
+// how edge cases are handled. They all accept the signatures produced by
+// honest signers, but consensus systems must agree on every signature.
+//
+// See "Taming the many EdDSAs" by Chalkias, Garillot and Nikolaenko.
+// https://eprint.iacr.org/2020/1244
+type VerifyMode uint
+
+const (
+	// ModeRFC8032 applies the strict rules of RFC 8032: A and R must be
+	// canonical encodings, S must be less than the order, and the
+	// cofactorless equation [S]B = R + [k]A must hold. This is the default.
+	ModeRFC8032 VerifyMode = iota
+	// ModeCofactored is like ModeRFC8032, but checks the cofactored
+	// equation [8][S]B = [8]R + [8][k]A instead, as allowed by RFC 8032.
+	// These are the rules of BatchVerify by default.
+	ModeCofactored
+	// ModeZIP215 applies the rules of ZIP 215: A and R may also have
+	// non-canonical encodings, S must be less than the order, and the
+	// cofactored equation must hold. These are the rules of BatchVerify
+	// with BatchOptions.ZIP215.
+	//
+	// https://zips.z.cash/zip-0215
+	ModeZIP215
+)
+
+// VerifyOptions holds the options of Verify, VerifyPh and VerifyWithCtx.
+type VerifyOptions struct {
+	// Mode selects the acceptance rules.
+	Mode VerifyMode
+}
+
 // PrivateKey is the type of Ed25519 private keys. It implements crypto.Signer.
 type PrivateKey []byte
 
@@ -216,20 +255,22 @@ func newKeyFromSeed(privateKey, seed []byte) {
 }
 
 func signAll(signature []byte, privateKey PrivateKey, message, ctx []byte, preHash bool) {
+	PHM := message
+	if preHash {
+		h := sha512.Sum512(message)
+		PHM = h[:]
+	}
+	signPHM(signature, privateKey, PHM, ctx, preHash)
+}
+
+// signPHM signs PH(M), that is, the SHA-512 digest of the message if preHash
+// is set, or the message itself otherwise.
+func signPHM(signature []byte, privateKey PrivateKey, PHM, ctx []byte, preHash bool) {
 	if l := len(privateKey); l != PrivateKeySize {
 		panic("ed25519: bad private key length: " + strconv.Itoa(l))
 	}
 
 	H := sha512.New()
-	var PHM []byte
-
-	if preHash {
-		_, _ = H.Write(message)
-		PHM = H.Sum(nil)
-		H.Reset()
-	} else {
-		PHM = message
-	}
 
 	// 1.  Hash the 32-byte private key using SHA-512.
 	_, _ = H.Write(privateKey[:SeedSize])
@@ -319,7 +360,37 @@ func SignWithCtx(privateKey PrivateKey, message []byte, ctx string) []byte {
 	return signature
 }
 
-func verify(public PublicKey, message, signature, ctx []byte, preHash bool) bool {
+func verify(
+	public PublicKey,
+	message, signature, ctx []byte,
+	preHash bool,
+	mode VerifyMode,
+) bool {
+	PHM := message
+	if preHash {
+		h := sha512.Sum512(message)
+		PHM = h[:]
+	}
+	return verifyPHM(public, PHM, signature, ctx, preHash, mode)
+}
+
+// verifyPHM verifies a signature of PH(M), as signed by signPHM.
+func verifyPHM(
+	public PublicKey,
+	PHM, signature, ctx []byte,
+	preHash bool,
+	mode VerifyMode,
+) bool {
+	switch mode {
+	case ModeRFC8032:
+	case ModeCofactored, ModeZIP215:
+		var d decodedSignature
+		return d.decode(public, PHM, signature, ctx, preHash, mode != ModeZIP215) &&
+			d.verify()
+	default:
+		return false
+	}
+
 	if len(public) != PublicKeySize ||
 		len(signature) != SignatureSize ||
 		!isLessThanOrder(signature[paramB:]) {
@@ -331,33 +402,41 @@ func verify(public PublicKey, message, signature, ctx []byte, preHash bool) bool
 		return false
 	}
 
-	H := sha512.New()
-	var PHM []byte
-
-	if preHash {
-		_, _ = H.Write(message)
-		PHM = H.Sum(nil)
-		H.Reset()
-	} else {
-		PHM = message
-	}
-
 	R := signature[:paramB]
+	hRAM := computeHRAM(public, PHM, R, ctx, preHash)
 
-	writeDom(H, ctx, preHash)
+	var Q pointR1
+	encR := (&[paramB]byte{})[:]
+	P.neg()
+	Q.doubleMult(&P, signature[paramB:], hRAM[:paramB])
+	_ = Q.ToBytes(encR)
+	return bytes.Equal(R, encR)
+}
 
+// computeHRAM returns SHA512(dom2(F, C) || R || A || PH(M)) reduced modulo
+// the order of the curve.
+func computeHRAM(public PublicKey, PHM, R, ctx []byte, preHash bool) []byte {
+	H := sha512.New()
+	writeDom(H, ctx, preHash)
 	_, _ = H.Write(R)
 	_, _ = H.Write(public)
 	_, _ = H.Write(PHM)
 	hRAM := H.Sum(nil)
 	reduceModOrder(hRAM[:], true)
+	return hRAM
+}
 
-	var Q pointR1
-	encR := (&[paramB]byte{})[:]
-	P.neg()
-	Q.doubleMult(&P, signature[paramB:], hRAM[:paramB])
-	_ = Q.ToBytes(encR)
-	return bytes.Equal(R, encR)
+// verifyMode returns the mode selected by opts, which must hold at most one
+// VerifyOptions.
+func verifyMode(opts []VerifyOptions) VerifyMode {
+	switch len(opts) {
+	case 0:
+		return ModeRFC8032
+	case 1:
+		return opts[0].Mode
+	default:
+		panic("ed25519: too many verification options")
+	}
 }
 
 // VerifyAny returns true if the signature is valid. Failure cases are invalid
@@ -393,8 +472,9 @@ func VerifyAny(public PublicKey, message, signature []byte, opts crypto.SignerOp
 // signature, or when the public key cannot be decoded.
 // This function supports the signature variant defined in RFC-8032: Ed25519,
 // also known as the pure version of EdDSA.
-func Verify(public PublicKey, message, signature []byte) bool {
-	return verify(public, message, signature, []byte(""), false)
+// The acceptance rules can be selected by passing a VerifyOptions.
+func Verify(public PublicKey, message, signature []byte, opts ...VerifyOptions) bool {
+	return verify(public, message, signature, []byte(""), false, verifyMode(opts))
 }
 
 // VerifyPh returns true if the signature is valid. Failure cases are invalid
@@ -403,8 +483,14 @@ func Verify(public PublicKey, message, signature []byte) bool {
 // meaning it internally hashes the message using SHA-512.
 // Context could be passed to this function, which length should be no more than
 // 255. It can be empty.
-func VerifyPh(public PublicKey, message, signature []byte, ctx string) bool {
-	return verify(public, message, signature, []byte(ctx), true)
+// The acceptance rules can be selected by passing a VerifyOptions.
+func VerifyPh(
+	public PublicKey,
+	message, signature []byte,
+	ctx string,
+	opts ...VerifyOptions,
+) bool {
+	return verify(public, message, signature, []byte(ctx), true, verifyMode(opts))
 }
 
 // VerifyWithCtx returns true if the signature is valid. Failure cases are invalid
@@ -413,12 +499,18 @@ func VerifyPh(public PublicKey, message, signature []byte, ctx string) bool {
 // This function supports the signature variant defined in RFC-8032: Ed25519ctx,
 // meaning it does not handle prehashed messages. Non-empty context string must be
 // provided, and must not be more than 255 of length.
-func VerifyWithCtx(public PublicKey, message, signature []byte, ctx string) bool {
+// The acceptance rules can be selected by passing a VerifyOptions.
+func VerifyWithCtx(
+	public PublicKey,
+	message, signature []byte,
+	ctx string,
+	opts ...VerifyOptions,
+) bool {
 	if len(ctx) == 0 || len(ctx) > ContextMaxSize {
 		return false
 	}
 
-	return verify(public, message, signature, []byte(ctx), false)
+	return verify(public, message, signature, []byte(ctx), false, verifyMode(opts))
 }
 
 func clamp(k []byte) {
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/sign"
 	"github.com/katzenpost/circl/sign/ed25519"
 )
 
@@ -247,3 +248,22 @@ func ExampleSignWithCtx() {
 	fmt.Println(ok)
 	// Output: true
 }
+
+func TestPhScheme(t *testing.T) {
+	scheme := ed25519.PhScheme()
+	pk, sk, err := scheme.GenerateKey()
+	test.CheckNoErr(t, err, "failed to generate key")
+	msg := []byte("message")
+	const ctx = "context"
+
+	priv, _ := sk.MarshalBinary()
+	pub, _ := pk.MarshalBinary()
+	want := ed25519.SignPh(priv, msg, ctx)
+	got := scheme.Sign(sk, msg, &sign.SignatureOpts{Context: ctx})
+	if !bytes.Equal(got, want) {
+		test.ReportError(t, got, want)
+	}
+	if !ed25519.VerifyPh(pub, msg, got, ctx) {
+		test.ReportError(t, false, true)
+	}
+}
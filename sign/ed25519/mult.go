@@ -178,3 +178,94 @@ func (P *pointR1) doubleMult(Q *pointR1, m, n []byte) {
 		}
 	}
 }
+
+// multiMult returns P = mG + sum n[i]Q[i] using interleaved w-NAF
+// recodings, so that the doublings are shared among all the points.
+// The points in Q are overwritten.
+func (P *pointR1) multiMult(m []byte, Q []pointR1, n [][]byte) {
+	if len(Q) != len(n) {
+		panic("wrong number of scalars")
+	}
+	nafFix := omegaNAF(m, omegaFix)
+	nafVar := make([][]int32, len(Q))
+	tabQ := make([][1 << (omegaVar - 2)]pointR2, len(Q))
+	l := len(nafFix)
+	for j := range Q {
+		nafVar[j] = omegaNAF(n[j], omegaVar)
+		if len(nafVar[j]) > l {
+			l = len(nafVar[j])
+		}
+		Q[j].oddMultiples(tabQ[j][:])
+	}
+
+	P.SetIdentity()
+	for i := l - 1; i >= 0; i-- {
+		P.double()
+		// Generator point
+		if i < len(nafFix) && nafFix[i] != 0 {
+			idxM := absolute(nafFix[i]) >> 1
+			R := tabVerif[idxM]
+			if nafFix[i] < 0 {
+				R.neg()
+			}
+			P.mixAdd(&R)
+		}
+		// Variable input points
+		for j := range nafVar {
+			if i < len(nafVar[j]) && nafVar[j][i] != 0 {
+				idxN := absolute(nafVar[j][i]) >> 1
+				S := tabQ[j][idxN]
+				if nafVar[j][i] < 0 {
+					S.neg()
+				}
+				P.add(&S)
+			}
+		}
+	}
+}
+
+// omegaNAF returns the window-w Non-Adjacent Form of the little-endian
+// scalar k of paramB bytes, as math.OmegaNAF does, but without resorting
+// to big integers.
+func omegaNAF(k []byte, w uint) []int32 {
+	var x [numWords64 + 1]uint64
+	for i := 0; i < numWords64; i++ {
+		x[i] = binary.LittleEndian.Uint64(k[i*8 : (i+1)*8])
+	}
+	isZero := func() bool {
+		var t uint64
+		for i := range x {
+			t |= x[i]
+		}
+		return t == 0
+	}
+
+	L := make([]int32, 0, 8*paramB+1)
+	for !isZero() {
+		value := int32(0)
+		if x[0]&1 == 1 {
+			value = int32(x[0] & ((1 << w) - 1))
+			if value >= int32(1)<<(w-1) {
+				value -= int32(1) << w
+			}
+			var c uint64
+			if value > 0 {
+				x[0], c = bits.Sub64(x[0], uint64(value), 0)
+				for i := 1; i < len(x); i++ {
+					x[i], c = bits.Sub64(x[i], 0, c)
+				}
+			} else {
+				x[0], c = bits.Add64(x[0], uint64(-value), 0)
+				for i := 1; i < len(x); i++ {
+					x[i], c = bits.Add64(x[i], 0, c)
+				}
+			}
+		}
+		L = append(L, value)
+		for i := 0; i < len(x)-1; i++ {
+			x[i] = x[i]>>1 | x[i+1]<<63
+		}
+		x[len(x)-1] >>= 1
+	}
+	return L
+}
@@ -5,7 +5,9 @@ import (
 	"flag"
 	"testing"
 
+	"github.com/katzenpost/circl/internal/conv"
 	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/math"
 )
 
 func randomPoint(P *pointR1) {
@@ -61,6 +63,57 @@ func TestPoint(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("multi", func(t *testing.T) {
+		const numPoints = 5
+		var P, R pointR1
+		var S pointR2
+		Q := make([]pointR1, numPoints)
+		k := make([]byte, paramB)
+		l := make([][]byte, numPoints)
+		zero := make([]byte, paramB)
+		for i := 0; i < testTimes/numPoints; i++ {
+			_, _ = rand.Read(k[:])
+			// R = kG + sum l_j Q_j
+			R.fixedMult(k)
+			for j := range Q {
+				randomPoint(&Q[j])
+				l[j] = make([]byte, paramB)
+				_, _ = rand.Read(l[j][:16*(j%2+1)])
+				Qj := Q[j]
+				P.doubleMult(&Qj, zero, l[j])
+				S.fromR1(&P)
+				R.add(&S)
+			}
+
+			P.multiMult(k, Q, l)
+			got := P.isEqual(&R)
+			want := true
+			if got != want {
+				test.ReportError(t, got, want, k, l)
+			}
+		}
+	})
+}
+
+func TestOmegaNAF(t *testing.T) {
+	const testTimes = 1 << 10
+	k := make([]byte, paramB)
+	for i := 0; i < testTimes; i++ {
+		_, _ = rand.Read(k[:(i%paramB)+1])
+		for _, w := range []uint{omegaVar, omegaFix} {
+			got := omegaNAF(k, w)
+			want := math.OmegaNAF(conv.BytesLe2BigInt(k), w)
+			if len(got) != len(want) {
+				test.ReportError(t, got, want, k, w)
+			}
+			for j := range want {
+				if got[j] != want[j] {
+					test.ReportError(t, got, want, k, w)
+				}
+			}
+		}
+	}
 }
 
 var runLongBench = flag.Bool("long", false, "runs longer benchmark")
@@ -0,0 +1,89 @@
+package ed25519
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+)
+
+// mixedOrderSignature returns a signature of msg under a public key
+// A = [a]B + T, where T is a point of order 8, such that [S]B = R + [k]A
+// does not hold, but the cofactored equation does. These are the cases
+// distinguishing the rules in "Taming the many EdDSAs".
+func mixedOrderSignature(t *testing.T) (public PublicKey, msg, sig []byte) {
+	var a, r [2 * paramB]byte
+	_, _ = rand.Read(a[:])
+	_, _ = rand.Read(r[:])
+	reduceModOrder(a[:], true)
+	reduceModOrder(r[:], true)
+
+	T, _ := hex.DecodeString("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	var P, Q pointR1
+	var T2 pointR2
+	if !Q.FromBytes(T) {
+		t.Fatal("invalid point")
+	}
+	T2.fromR1(&Q)
+	P.fixedMult(a[:paramB])
+	P.add(&T2)
+	public = make(PublicKey, PublicKeySize)
+	_ = P.ToBytes(public)
+
+	sig = make([]byte, SignatureSize)
+	P.fixedMult(r[:paramB])
+	_ = P.ToBytes(sig[:paramB])
+	for i := 0; ; i++ {
+		msg = []byte{byte(i)}
+		k := computeHRAM(public, msg, sig[:paramB], nil, false)
+		// [k]T is the identity if k is a multiple of 8.
+		if k[0]%8 != 0 {
+			calculateS(sig[paramB:], r[:paramB], k[:paramB], a[:paramB])
+			return public, msg, sig
+		}
+	}
+}
+
+func TestMixedOrder(t *testing.T) {
+	public, msg, sig := mixedOrderSignature(t)
+	for _, v := range []struct {
+		mode VerifyMode
+		want bool
+	}{
+		{ModeRFC8032, false},
+		{ModeCofactored, true},
+		{ModeZIP215, true},
+	} {
+		got := Verify(public, msg, sig, VerifyOptions{Mode: v.mode})
+		if got != v.want {
+			test.ReportError(t, got, v.want, v.mode)
+		}
+	}
+
+	for _, opts := range []BatchOptions{{}, {ZIP215: true}} {
+		pks := []PublicKey{public, public}
+		msgs := [][]byte{msg, msg}
+		sigs := [][]byte{sig, sig}
+		got, _ := BatchVerify(nil, pks, msgs, sigs, opts)
+		if !got {
+			test.ReportError(t, got, true, opts)
+		}
+	}
+
+	// S+L must be rejected by all the rules.
+	var c uint16
+	for i := range order {
+		c += uint16(sig[paramB+i]) + uint16(order[i])
+		sig[paramB+i] = byte(c)
+		c >>= 8
+	}
+	if c == 0 {
+		for _, mode := range []VerifyMode{ModeRFC8032, ModeCofactored, ModeZIP215} {
+			got := Verify(public, msg, sig, VerifyOptions{Mode: mode})
+			if got {
+				test.ReportError(t, got, false, mode)
+			}
+		}
+	}
+}
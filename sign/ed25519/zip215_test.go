@@ -0,0 +1,120 @@
+package ed25519_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/sign/ed25519"
+)
+
+// smallOrderEncodings are the encodings of the points of small order: the
+// eight canonical ones followed by the six non-canonical ones.
+var smallOrderEncodings = [...]string{
+	"0100000000000000000000000000000000000000000000000000000000000000",
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	"0000000000000000000000000000000000000000000000000000000000000000",
+	"0000000000000000000000000000000000000000000000000000000000000080",
+	"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05",
+	"26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc85",
+	"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a",
+	"c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac03fa",
+	// x = 0 with the sign bit set
+	"0100000000000000000000000000000000000000000000000000000000000080",
+	"ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+	// y >= p
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	"edffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+	"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f",
+	"eeffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+}
+
+const numCanonicalSmallOrder = 8
+
+// TestZIP215 checks the 196 test vectors of ZIP 215: every pair of
+// encodings of small-order points as A and R, with S = 0, signing the
+// message "Zcash". All of them are valid under ZIP 215, while the rules of
+// RFC 8032 reject the non-canonical encodings.
+func TestZIP215(t *testing.T) {
+	var pks []ed25519.PublicKey
+	var msgs, sigs [][]byte
+	var canonical []bool
+	for i, a := range smallOrderEncodings {
+		for j, r := range smallOrderEncodings {
+			pk, _ := hex.DecodeString(a)
+			sig, _ := hex.DecodeString(r + "0000000000000000000000000000000000000000000000000000000000000000")
+			pks = append(pks, pk)
+			msgs = append(msgs, []byte("Zcash"))
+			sigs = append(sigs, sig)
+			canonical = append(canonical,
+				i < numCanonicalSmallOrder && j < numCanonicalSmallOrder)
+		}
+	}
+
+	for i := range pks {
+		for _, v := range []struct {
+			mode ed25519.VerifyMode
+			want bool
+		}{
+			{ed25519.ModeZIP215, true},
+			{ed25519.ModeCofactored, canonical[i]},
+		} {
+			opts := ed25519.VerifyOptions{Mode: v.mode}
+			got := ed25519.Verify(pks[i], msgs[i], sigs[i], opts)
+			if got != v.want {
+				test.ReportError(t, got, v.want, i, v.mode)
+			}
+		}
+		if !canonical[i] && ed25519.Verify(pks[i], msgs[i], sigs[i]) {
+			test.ReportError(t, true, false, i)
+		}
+	}
+
+	ok, _ := ed25519.BatchVerify(nil, pks, msgs, sigs, ed25519.BatchOptions{ZIP215: true})
+	if !ok {
+		test.ReportError(t, ok, true)
+	}
+	_, valid := ed25519.BatchVerify(nil, pks, msgs, sigs, ed25519.BatchOptions{})
+	for i := range valid {
+		if valid[i] != canonical[i] {
+			test.ReportError(t, valid[i], canonical[i], i)
+		}
+	}
+}
+
+func TestVerifyOptions(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("message")
+	const ctx = "context"
+	sig := ed25519.Sign(sk, msg)
+	sigPh := ed25519.SignPh(sk, msg, ctx)
+	sigCtx := ed25519.SignWithCtx(sk, msg, ctx)
+
+	for _, mode := range []ed25519.VerifyMode{
+		ed25519.ModeRFC8032,
+		ed25519.ModeCofactored,
+		ed25519.ModeZIP215,
+	} {
+		opts := ed25519.VerifyOptions{Mode: mode}
+		if !ed25519.Verify(pk, msg, sig, opts) ||
+			!ed25519.VerifyPh(pk, msg, sigPh, ctx, opts) ||
+			!ed25519.VerifyWithCtx(pk, msg, sigCtx, ctx, opts) {
+			test.ReportError(t, false, true, mode)
+		}
+		if ed25519.Verify(pk, msg, sigPh, opts) ||
+			ed25519.VerifyPh(pk, msg, sigCtx, ctx, opts) ||
+			ed25519.VerifyWithCtx(pk, msg, sig, ctx, opts) {
+			test.ReportError(t, true, false, mode)
+		}
+	}
+
+	opts := ed25519.VerifyOptions{Mode: ed25519.ModeZIP215 + 1}
+	if ed25519.Verify(pk, msg, sig, opts) {
+		test.ReportError(t, true, false, opts.Mode)
+	}
+	err = test.CheckPanic(func() { ed25519.Verify(pk, msg, sig, opts, opts) })
+	test.CheckNoErr(t, err, "too many options must panic")
+}
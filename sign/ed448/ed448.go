@@ -35,8 +35,7 @@ import (
 
 	"github.com/katzenpost/circl/ecc/goldilocks"
 	"github.com/katzenpost/circl/internal/sha3"
-
-	"github.com/katzenpost/hpqc/sign"
+	"github.com/katzenpost/circl/sign"
 )
 
 const (
@@ -55,6 +54,7 @@ const (
 const (
 	paramB   = 456 / 8    // Size of keys in bytes.
 	hashSize = 2 * paramB // Size of the hash function's output.
+	phSize   = 64         // Size of the output of the prehash function.
 )
 
 // SignerOptions implements crypto.SignerOpts and augments with parameters
@@ -206,23 +206,31 @@ func newKeyFromSeed(privateKey, seed []byte) {
 }
 
 func signAll(signature []byte, privateKey PrivateKey, message, ctx []byte, preHash bool) {
-	if len(ctx) > ContextMaxSize {
-		panic(fmt.Errorf("ed448: bad context length: " + strconv.Itoa(len(ctx))))
+	PHM := message
+	if preHash {
+		PHM = prehash(message)
 	}
+	signPHM(signature, privateKey, PHM, ctx, preHash)
+}
 
+// prehash returns SHAKE256(message, 64), the PH function of Ed448ph.
+func prehash(message []byte) []byte {
+	var h [phSize]byte
 	H := sha3.NewShake256()
-	var PHM []byte
+	_, _ = H.Write(message)
+	_, _ = H.Read(h[:])
+	return h[:]
+}
 
-	if preHash {
-		var h [64]byte
-		_, _ = H.Write(message)
-		_, _ = H.Read(h[:])
-		PHM = h[:]
-		H.Reset()
-	} else {
-		PHM = message
+// signPHM signs PH(M), that is, the prehash of the message if preHash is
+// set, or the message itself otherwise.
+func signPHM(signature []byte, privateKey PrivateKey, PHM, ctx []byte, preHash bool) {
+	if len(ctx) > ContextMaxSize {
+		panic(fmt.Errorf("ed448: bad context length: " + strconv.Itoa(len(ctx))))
 	}
 
+	H := sha3.NewShake256()
+
 	// 1.  Hash the 57-byte private key using SHAKE256(x, 114).
 	var h [hashSize]byte
 	_, _ = H.Write(privateKey[:SeedSize])
@@ -293,6 +301,15 @@ func SignPh(priv PrivateKey, message []byte, ctx string) []byte {
 }
 
 func verify(public PublicKey, message, signature, ctx []byte, preHash bool) bool {
+	PHM := message
+	if preHash {
+		PHM = prehash(message)
+	}
+	return verifyPHM(public, PHM, signature, ctx, preHash)
+}
+
+// verifyPHM verifies a signature of PH(M), as signed by signPHM.
+func verifyPHM(public PublicKey, PHM, signature, ctx []byte, preHash bool) bool {
 	if len(public) != PublicKeySize ||
 		len(signature) != SignatureSize ||
 		len(ctx) > ContextMaxSize ||
@@ -306,17 +323,6 @@ func verify(public PublicKey, message, signature, ctx []byte, preHash bool) bool
 	}
 
 	H := sha3.NewShake256()
-	var PHM []byte
-
-	if preHash {
-		var h [64]byte
-		_, _ = H.Write(message)
-		_, _ = H.Read(h[:])
-		PHM = h[:]
-		H.Reset()
-	} else {
-		PHM = message
-	}
 
 	var hRAM [hashSize]byte
 	R := signature[:paramB]
@@ -1,10 +1,12 @@
 package ed448
 
 import (
+	"crypto"
 	"crypto/rand"
 	"encoding/asn1"
 
-	"github.com/katzenpost/hpqc/sign"
+	"github.com/katzenpost/circl/internal/sha3"
+	"github.com/katzenpost/circl/sign"
 )
 
 var sch sign.Scheme = &scheme{}
@@ -85,3 +87,157 @@ func (*scheme) UnmarshalBinaryPrivateKey(buf []byte) (sign.PrivateKey, error) {
 	copy(priv, buf[:PrivateKeySize])
 	return priv, nil
 }
+
+// Boilerplate for Ed448ph in the generic signatures API. Its keys wrap the
+// Ed448 keys so that they report the right scheme.
+
+type phScheme struct{}
+
+var phSch sign.StreamingScheme = &phScheme{}
+
+// PhScheme returns a signature interface for Ed448ph, which signs the
+// SHAKE256 digest of the message, and thus can sign messages written
+// piecewise.
+func PhScheme() sign.StreamingScheme { return phSch }
+
+type phPublicKey struct{ PublicKey }
+
+type phPrivateKey struct{ PrivateKey }
+
+func (*phScheme) Name() string          { return "Ed448ph" }
+func (*phScheme) PublicKeySize() int    { return PublicKeySize }
+func (*phScheme) PrivateKeySize() int   { return PrivateKeySize }
+func (*phScheme) SignatureSize() int    { return SignatureSize }
+func (*phScheme) SeedSize() int         { return SeedSize }
+func (*phScheme) SupportsContext() bool { return true }
+
+func (*phScheme) GenerateKey() (sign.PublicKey, sign.PrivateKey, error) {
+	pk, sk, err := GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return phPublicKey{pk}, phPrivateKey{sk}, nil
+}
+
+func (s *phScheme) Sign(
+	sk sign.PrivateKey,
+	message []byte,
+	opts *sign.SignatureOpts,
+) []byte {
+	signer := s.NewSigner(sk, opts)
+	_, _ = signer.Write(message)
+	return signer.Sign()
+}
+
+func (s *phScheme) Verify(
+	pk sign.PublicKey,
+	message, signature []byte,
+	opts *sign.SignatureOpts,
+) bool {
+	verifier := s.NewVerifier(pk, opts)
+	_, _ = verifier.Write(message)
+	return verifier.Verify(signature)
+}
+
+// NewSigner returns a Signer of the message written to it. Panics if the
+// context is longer than ContextMaxSize.
+func (*phScheme) NewSigner(sk sign.PrivateKey, opts *sign.SignatureOpts) sign.Signer {
+	priv, ok := sk.(phPrivateKey)
+	if !ok {
+		panic(sign.ErrTypeMismatch)
+	}
+	ctx := ""
+	if opts != nil {
+		ctx = opts.Context
+	}
+	if len(ctx) > ContextMaxSize {
+		panic(sign.ErrContextTooLong)
+	}
+	return &phSigner{phHash{sha3.NewShake256()}, priv.PrivateKey, []byte(ctx)}
+}
+
+// NewVerifier returns a Verifier of the message written to it. Its
+// signatures are rejected if the context is longer than ContextMaxSize.
+func (*phScheme) NewVerifier(pk sign.PublicKey, opts *sign.SignatureOpts) sign.Verifier {
+	pub, ok := pk.(phPublicKey)
+	if !ok {
+		panic(sign.ErrTypeMismatch)
+	}
+	ctx := ""
+	if opts != nil {
+		ctx = opts.Context
+	}
+	return &phVerifier{phHash{sha3.NewShake256()}, pub.PublicKey, []byte(ctx)}
+}
+
+func (*phScheme) DeriveKey(seed []byte) (sign.PublicKey, sign.PrivateKey) {
+	pk, sk := sch.DeriveKey(seed)
+	return phPublicKey{pk.(PublicKey)}, phPrivateKey{sk.(PrivateKey)}
+}
+
+func (*phScheme) UnmarshalBinaryPublicKey(buf []byte) (sign.PublicKey, error) {
+	pk, err := sch.UnmarshalBinaryPublicKey(buf)
+	if err != nil {
+		return nil, err
+	}
+	return phPublicKey{pk.(PublicKey)}, nil
+}
+
+func (*phScheme) UnmarshalBinaryPrivateKey(buf []byte) (sign.PrivateKey, error) {
+	sk, err := sch.UnmarshalBinaryPrivateKey(buf)
+	if err != nil {
+		return nil, err
+	}
+	return phPrivateKey{sk.(PrivateKey)}, nil
+}
+
+func (phPrivateKey) Scheme() sign.Scheme { return phSch }
+func (phPublicKey) Scheme() sign.Scheme  { return phSch }
+
+func (sk phPrivateKey) Public() crypto.PublicKey {
+	return phPublicKey{sk.PrivateKey.Public().(PublicKey)}
+}
+
+func (sk phPrivateKey) Equal(other crypto.PrivateKey) bool {
+	castOther, ok := other.(phPrivateKey)
+	return ok && sk.PrivateKey.Equal(castOther.PrivateKey)
+}
+
+func (pk phPublicKey) Equal(other crypto.PublicKey) bool {
+	castOther, ok := other.(phPublicKey)
+	return ok && pk.PublicKey.Equal(castOther.PublicKey)
+}
+
+// phHash computes the prehash of the message written to it.
+type phHash struct{ h sha3.State }
+
+func (h *phHash) Write(p []byte) (int, error) { return h.h.Write(p) }
+
+// sum returns the prehash of the message written so far.
+func (h *phHash) sum() []byte {
+	var out [phSize]byte
+	_, _ = h.h.Clone().Read(out[:])
+	return out[:]
+}
+
+type phSigner struct {
+	phHash
+	sk  PrivateKey
+	ctx []byte
+}
+
+func (s *phSigner) Sign() []byte {
+	signature := make([]byte, SignatureSize)
+	signPHM(signature, s.sk, s.sum(), s.ctx, true)
+	return signature
+}
+
+type phVerifier struct {
+	phHash
+	pk  PublicKey
+	ctx []byte
+}
+
+func (v *phVerifier) Verify(signature []byte) bool {
+	return verifyPHM(v.pk, v.sum(), signature, v.ctx, true)
+}
@@ -8,9 +8,9 @@ import (
 	"io"
 
 	"github.com/katzenpost/circl/internal/sha3"
+	"github.com/katzenpost/circl/sign"
 	"github.com/katzenpost/circl/sign/dilithium/mode2"
 	"github.com/katzenpost/circl/sign/ed25519"
-	"github.com/katzenpost/hpqc/sign"
 )
 
 const (
@@ -4,7 +4,7 @@ import (
 	"crypto/rand"
 	"encoding/asn1"
 
-	"github.com/katzenpost/hpqc/sign"
+	"github.com/katzenpost/circl/sign"
 )
 
 var sch sign.Scheme = &scheme{}
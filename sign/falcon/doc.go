@@ -0,0 +1,32 @@
+//go:generate go run gen.go
+
+// Package falcon implements the Falcon signature scheme, as submitted to
+// round 3 of the NIST PQC competition and described in
+//
+// https://falcon-sign.info/falcon.pdf
+//
+// Falcon has much smaller signatures than ML-DSA at similar security
+// levels. NIST is standardizing it as FN-DSA, which is not final yet; this
+// package implements round 3 Falcon with the padded signature format of
+// its reference implementation.
+//
+// Each of the two degrees of Falcon is implemented by a subpackage. For
+// instance, Falcon-512 can be found in
+//
+//	github.com/katzenpost/circl/sign/falcon/falcon512
+//
+// Signing uses floating-point arithmetic, which is emulated with integer
+// operations in constant time, so that signatures do not depend on the
+// floating-point unit of the platform and do not leak through its timing.
+// Falcon signatures are always randomized and do not support context
+// strings.
+//
+// Key generation solves the NTRU equation with integers of fixed sizes, in
+// constant time as well. Its timing only depends on how many candidate keys
+// are rejected, which reveals nothing about the key that is kept.
+//
+// If your choice for degree is fixed compile-time, use the subpackages.
+// To choose a scheme at runtime, use the generic signatures API under
+//
+//	github.com/katzenpost/circl/sign/schemes
+package falcon
@@ -0,0 +1,75 @@
+//go:build ignore
+// +build ignore
+
+// Autogenerates wrappers from templates to prevent too much duplicated code
+// between the code for different degrees.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path"
+	"strings"
+	"text/template"
+
+	"github.com/katzenpost/circl/sign/falcon/internal"
+)
+
+type Mode struct {
+	Name string
+	LogN uint
+}
+
+func (m Mode) Pkg() string {
+	return strings.ToLower(strings.ReplaceAll(m.Name, "-", ""))
+}
+
+func (m Mode) PublicKeySize() int  { return internal.PublicKeySize(m.LogN) }
+func (m Mode) PrivateKeySize() int { return internal.PrivateKeySize(m.LogN) }
+func (m Mode) SignatureSize() int  { return internal.SignatureSize(m.LogN) }
+
+var (
+	Modes = []Mode{
+		{Name: "Falcon-512", LogN: 9},
+		{Name: "Falcon-1024", LogN: 10},
+	}
+	TemplateWarning = "// Code generated from"
+)
+
+func main() {
+	generatePackageFiles()
+}
+
+// Generates falconX/falcon.go from templates/pkg.templ.go
+func generatePackageFiles() {
+	tl, err := template.ParseFiles("templates/pkg.templ.go")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, mode := range Modes {
+		buf := new(bytes.Buffer)
+		err := tl.Execute(buf, mode)
+		if err != nil {
+			panic(err)
+		}
+
+		code, err := format.Source(buf.Bytes())
+		if err != nil {
+			panic(fmt.Sprintf("error formating code: %v", err))
+		}
+
+		res := string(code)
+		offset := strings.Index(res, TemplateWarning)
+		if offset == -1 {
+			panic("Missing template warning in pkg.templ.go")
+		}
+		err = os.WriteFile(path.Join(mode.Pkg(), "falcon.go"),
+			[]byte(res[offset:]), 0o644)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package internal
+
+// Encodings of Falcon: values are packed into bit strings, most
+// significant bit first.
+
+// modqEncode packs the coefficients of h, which are below q, on 14 bits
+// each into buf.
+func modqEncode(buf []byte, h []uint16) {
+	var acc uint32
+	accLen := 0
+	for _, c := range h {
+		acc = acc<<14 | uint32(c)
+		accLen += 14
+		for accLen >= 8 {
+			accLen -= 8
+			buf[0] = byte(acc >> uint(accLen))
+			buf = buf[1:]
+		}
+	}
+}
+
+// modqDecode unpacks the coefficients of h from buf. Returns false if a
+// coefficient is not below q.
+func modqDecode(h []uint16, buf []byte) bool {
+	var acc uint32
+	accLen := 0
+	i := 0
+	for _, b := range buf {
+		acc = acc<<8 | uint32(b)
+		accLen += 8
+		if accLen >= 14 {
+			accLen -= 14
+			c := (acc >> uint(accLen)) & 0x3FFF
+			if c >= Q {
+				return false
+			}
+			h[i] = uint16(c)
+			i++
+		}
+	}
+	return true
+}
+
+// trimEncode packs the coefficients of f, which are in
+// [-(2^(bits-1) - 1), 2^(bits-1) - 1], in two's complement on the given
+// number of bits into buf.
+func trimEncode(buf []byte, f []int8, bits int) {
+	var acc uint32
+	accLen := 0
+	mask := uint32(1)<<uint(bits) - 1
+	for _, c := range f {
+		acc = acc<<uint(bits) | uint32(int32(c))&mask
+		accLen += bits
+		for accLen >= 8 {
+			accLen -= 8
+			buf[0] = byte(acc >> uint(accLen))
+			buf = buf[1:]
+		}
+	}
+}
+
+// trimDecode unpacks the coefficients of f from buf. Returns false if a
+// coefficient is -2^(bits-1).
+func trimDecode(f []int8, buf []byte, bits int) bool {
+	var acc uint32
+	accLen := 0
+	mask1 := uint32(1)<<uint(bits) - 1
+	mask2 := uint32(1) << uint(bits-1)
+	i := 0
+	for _, b := range buf {
+		acc = acc<<8 | uint32(b)
+		accLen += 8
+		for accLen >= bits && i < len(f) {
+			accLen -= bits
+			w := (acc >> uint(accLen)) & mask1
+			w |= -(w & mask2)
+			if w == -mask2 {
+				return false
+			}
+			f[i] = int8(int32(w))
+			i++
+		}
+	}
+	return true
+}
+
+// compEncode writes the compressed encoding of s into buf: for each
+// coefficient its sign, its 7 low bits, and its remaining high bits in
+// unary. Returns false if a coefficient is not in [-2047, 2047] or if buf
+// is too short. The unused bytes of buf are set to zero.
+func compEncode(buf []byte, s []int16) bool {
+	for _, c := range s {
+		if c < -2047 || c > 2047 {
+			return false
+		}
+	}
+
+	for i := range buf {
+		buf[i] = 0
+	}
+	var acc uint32
+	accLen := 0
+	v := 0
+	for _, c := range s {
+		acc <<= 1
+		t := int32(c)
+		if t < 0 {
+			t = -t
+			acc |= 1
+		}
+		w := uint32(t)
+		acc = acc<<7 | w&127
+		w >>= 7
+		accLen += 8
+		acc <<= w + 1
+		acc |= 1
+		accLen += int(w) + 1
+		for accLen >= 8 {
+			accLen -= 8
+			if v >= len(buf) {
+				return false
+			}
+			buf[v] = byte(acc >> uint(accLen))
+			v++
+		}
+	}
+	if accLen > 0 {
+		if v >= len(buf) {
+			return false
+		}
+		buf[v] = byte(acc << uint(8-accLen))
+	}
+	return true
+}
+
+// compDecode reads the compressed encoding of s from buf. Returns false if
+// the encoding is invalid or not followed by zeros only.
+func compDecode(s []int16, buf []byte) bool {
+	var acc uint32
+	accLen := 0
+	v := 0
+	for i := range s {
+		if v >= len(buf) {
+			return false
+		}
+		acc = acc<<8 | uint32(buf[v])
+		v++
+		b := acc >> uint(accLen)
+		sign := b & 128
+		m := b & 127
+		for {
+			if accLen == 0 {
+				if v >= len(buf) {
+					return false
+				}
+				acc = acc<<8 | uint32(buf[v])
+				v++
+				accLen = 8
+			}
+			accLen--
+			if (acc>>uint(accLen))&1 != 0 {
+				break
+			}
+			m += 128
+			if m > 2047 {
+				return false
+			}
+		}
+
+		// -0 is forbidden.
+		if sign != 0 && m == 0 {
+			return false
+		}
+		if sign != 0 {
+			s[i] = -int16(m)
+		} else {
+			s[i] = int16(m)
+		}
+	}
+
+	// Unused bits of the last byte, and the padding, must be zero.
+	if acc&(1<<uint(accLen)-1) != 0 {
+		return false
+	}
+	for _, b := range buf[v:] {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+// Package internal implements Falcon for the degrees 512 and 1024.
+package internal
+
+import (
+	cryptoRand "crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrPublicKey is returned when unpacking an invalid public key.
+	ErrPublicKey = errors.New("falcon: invalid public key")
+
+	// ErrPrivateKey is returned when unpacking an invalid private key.
+	ErrPrivateKey = errors.New("falcon: invalid private key")
+)
+
+// PublicKey is a Falcon public key h = g/f modulo q.
+type PublicKey struct {
+	logn uint
+	h    []uint16
+}
+
+// PrivateKey is a Falcon private key: the short basis [[g, -f], [G, -F]]
+// of the NTRU lattice of h.
+type PrivateKey struct {
+	logn       uint
+	f, g, F, G []int8
+	pk         PublicKey
+	ek         *expandedKey
+}
+
+// GenerateKey generates a key pair of degree 2^logn using entropy from
+// rand. If rand is nil, crypto/rand.Reader will be used.
+func GenerateKey(rand io.Reader, logn uint) (*PublicKey, *PrivateKey, error) {
+	var seed [SeedSize]byte
+	if rand == nil {
+		rand = cryptoRand.Reader
+	}
+	if _, err := io.ReadFull(rand, seed[:]); err != nil {
+		return nil, nil, err
+	}
+	pk, sk := NewKeyFromSeed(seed[:], logn)
+	return pk, sk, nil
+}
+
+// NewKeyFromSeed derives a key pair of degree 2^logn from the seed.
+func NewKeyFromSeed(seed []byte, logn uint) (*PublicKey, *PrivateKey) {
+	f, g, F, G, h := newKeyFromSeed(seed, logn)
+	sk := &PrivateKey{
+		logn: logn,
+		f:    f, g: g, F: F, G: G,
+		pk: PublicKey{logn, h},
+		ek: expandKey(logn, f, g, F, G),
+	}
+	return &sk.pk, sk
+}
+
+// Pack writes the public key into buf, which must be of size
+// PublicKeySize(logn).
+func (pk *PublicKey) Pack(buf []byte) {
+	buf[0] = byte(pk.logn)
+	modqEncode(buf[1:], pk.h)
+}
+
+// Unpack sets pk to the public key of degree 2^logn encoded in buf, which
+// must be of size PublicKeySize(logn).
+func (pk *PublicKey) Unpack(buf []byte, logn uint) error {
+	if buf[0] != byte(logn) {
+		return ErrPublicKey
+	}
+	h := make([]uint16, 1<<logn)
+	if !modqDecode(h, buf[1:]) {
+		return ErrPublicKey
+	}
+	pk.logn = logn
+	pk.h = h
+	return nil
+}
+
+// Pack writes the private key into buf, which must be of size
+// PrivateKeySize(logn). G is not included, as it can be recomputed.
+func (sk *PrivateKey) Pack(buf []byte) {
+	n := 1 << sk.logn
+	bits := fgBits(sk.logn)
+	fgSize := bits * n / 8
+	buf[0] = 0x50 + byte(sk.logn)
+	buf = buf[1:]
+	trimEncode(buf[:fgSize], sk.f, bits)
+	trimEncode(buf[fgSize:2*fgSize], sk.g, bits)
+	trimEncode(buf[2*fgSize:], sk.F, fgBitsMax)
+}
+
+// Unpack sets sk to the private key of degree 2^logn encoded in buf, which
+// must be of size PrivateKeySize(logn).
+func (sk *PrivateKey) Unpack(buf []byte, logn uint) error {
+	if buf[0] != 0x50+byte(logn) {
+		return ErrPrivateKey
+	}
+	n := 1 << logn
+	bits := fgBits(logn)
+	fgSize := bits * n / 8
+	buf = buf[1:]
+	f := make([]int8, n)
+	g := make([]int8, n)
+	F := make([]int8, n)
+	if !trimDecode(f, buf[:fgSize], bits) ||
+		!trimDecode(g, buf[fgSize:2*fgSize], bits) ||
+		!trimDecode(F, buf[2*fgSize:], fgBitsMax) {
+		return ErrPrivateKey
+	}
+
+	h, ok := computePublic(f, g)
+	if !ok {
+		return ErrPrivateKey
+	}
+	G, ok := completePrivate(f, g, F)
+	if !ok {
+		return ErrPrivateKey
+	}
+
+	*sk = PrivateKey{
+		logn: logn,
+		f:    f, g: g, F: F, G: G,
+		pk: PublicKey{logn, h},
+		ek: expandKey(logn, f, g, F, G),
+	}
+	return nil
+}
+
+// Public returns the public key of sk.
+func (sk *PrivateKey) Public() *PublicKey { return &sk.pk }
+
+// Equal returns whether the two public keys are equal.
+func (pk *PublicKey) Equal(other *PublicKey) bool {
+	if pk.logn != other.logn || len(pk.h) != len(other.h) {
+		return false
+	}
+	for i := range pk.h {
+		if pk.h[i] != other.h[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal returns whether the two private keys are equal, in time that only
+// depends on their degree.
+func (sk *PrivateKey) Equal(other *PrivateKey) bool {
+	if sk.logn != other.logn {
+		return false
+	}
+	a := make([]byte, PrivateKeySize(sk.logn))
+	b := make([]byte, PrivateKeySize(sk.logn))
+	sk.Pack(a)
+	other.Pack(b)
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// SignTo signs msg with the given nonce, of size NonceSize, and seed of
+// the sampler, of size RandomSize, and writes the signature into sig,
+// which must be of size SignatureSize(logn).
+func SignTo(sk *PrivateKey, msg, nonce, seed, sig []byte) {
+	sk.ek.signTo(sig, msg, nonce, seed)
+}
+
+// Verify returns whether sig is a valid signature of msg by pk.
+func Verify(pk *PublicKey, msg, sig []byte) bool {
+	return verify(pk.h, pk.logn, msg, sig)
+}
@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randSmall(r *rand.Rand, n int) []int8 {
+	ret := make([]int8, n)
+	for i := range ret {
+		ret[i] = int8(r.Intn(255) - 127)
+	}
+	return ret
+}
+
+// schoolbook returns a·b modulo x^n + 1.
+func schoolbook(a, b []int8) []int64 {
+	n := len(a)
+	ret := make([]int64, n)
+	for i := range a {
+		for j := range b {
+			p := int64(a[i]) * int64(b[j])
+			if i+j < n {
+				ret[i+j] += p
+			} else {
+				ret[i+j-n] -= p
+			}
+		}
+	}
+	return ret
+}
+
+func TestFFT(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for logn := uint(1); logn <= 10; logn++ {
+		n := 1 << logn
+		a, b := randSmall(r, n), randSmall(r, n)
+		want := schoolbook(a, b)
+
+		fa, fb := smallToFFT(a, logn), smallToFFT(b, logn)
+		fab := append([]fpr{}, fa...)
+		polyMulFFT(fab, fb)
+		ifft(fab, logn)
+		for i, c := range fab {
+			if fprRint(c) != want[i] {
+				t.Fatalf("logn=%d: product differs at %d", logn, i)
+			}
+		}
+
+		if logn < 2 {
+			continue
+		}
+		f0 := make([]fpr, n/2)
+		f1 := make([]fpr, n/2)
+		polySplitFFT(f0, f1, fa)
+		ifft(f0, logn-1)
+		ifft(f1, logn-1)
+		for i := 0; i < n/2; i++ {
+			if fprRint(f0[i]) != int64(a[2*i]) || fprRint(f1[i]) != int64(a[2*i+1]) {
+				t.Fatalf("logn=%d: split differs at %d", logn, i)
+			}
+		}
+		fft(f0, logn-1)
+		fft(f1, logn-1)
+		g := make([]fpr, n)
+		polyMergeFFT(g, f0, f1)
+		for i := range g {
+			x := math.Float64frombits(uint64(g[i]))
+			y := math.Float64frombits(uint64(fa[i]))
+			if math.Abs(x-y) > 1e-9*(1+math.Abs(y)) {
+				t.Fatalf("logn=%d: merge differs at %d", logn, i)
+			}
+		}
+	}
+}
+
+func TestNTT(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for logn := uint(1); logn <= 10; logn++ {
+		n := 1 << logn
+		a, b := randSmall(r, n), randSmall(r, n)
+		want := schoolbook(a, b)
+		ta, tb := toMq(a), toMq(b)
+		ntt(ta)
+		ntt(tb)
+		for i := range ta {
+			ta[i] = mqMul(ta[i], tb[i])
+		}
+		invNTT(ta)
+		for i, c := range ta {
+			if int64(c) != (want[i]%Q+Q)%Q {
+				t.Fatalf("logn=%d: product differs at %d", logn, i)
+			}
+		}
+	}
+}
+
+func TestCodec(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	n := 512
+
+	h := make([]uint16, n)
+	for i := range h {
+		h[i] = uint16(r.Intn(Q))
+	}
+	buf := make([]byte, 14*n/8)
+	modqEncode(buf, h)
+	h2 := make([]uint16, n)
+	if !modqDecode(h2, buf) {
+		t.Fatal("modqDecode failed")
+	}
+	for i := range h {
+		if h[i] != h2[i] {
+			t.Fatal("modq round trip")
+		}
+	}
+	buf[0] = 0xFF
+	if modqDecode(h2, buf) {
+		t.Fatal("modqDecode accepted a value above q")
+	}
+
+	for bits := 5; bits <= 8; bits++ {
+		lim := 1<<(bits-1) - 1
+		f := make([]int8, n)
+		for i := range f {
+			f[i] = int8(r.Intn(2*lim+1) - lim)
+		}
+		buf := make([]byte, bits*n/8)
+		trimEncode(buf, f, bits)
+		f2 := make([]int8, n)
+		if !trimDecode(f2, buf, bits) {
+			t.Fatal("trimDecode failed")
+		}
+		for i := range f {
+			if f[i] != f2[i] {
+				t.Fatalf("trim round trip with %d bits", bits)
+			}
+		}
+		// -2^(bits-1) is forbidden.
+		buf[0] = 1 << 7
+		if trimDecode(f2, buf, bits) {
+			t.Fatalf("trimDecode accepted -2^%d", bits-1)
+		}
+	}
+
+	s := make([]int16, n)
+	for i := range s {
+		s[i] = int16(r.NormFloat64() * 165)
+	}
+	s[0] = 2047
+	s[1] = -2047
+	buf = make([]byte, SignatureSize(9)-1-NonceSize)
+	if !compEncode(buf, s) {
+		t.Fatal("compEncode failed")
+	}
+	s2 := make([]int16, n)
+	if !compDecode(s2, buf) {
+		t.Fatal("compDecode failed")
+	}
+	for i := range s {
+		if s[i] != s2[i] {
+			t.Fatal("comp round trip")
+		}
+	}
+	buf[len(buf)-1] = 1
+	if compDecode(s2, buf) {
+		t.Fatal("compDecode accepted nonzero padding")
+	}
+	s[0] = 2048
+	if compEncode(buf, s) {
+		t.Fatal("compEncode accepted 2048")
+	}
+
+	// -0 is forbidden: sign bit set, low bits zero, then the unary stop bit.
+	s[0] = 0
+	compEncode(buf, s)
+	buf[0] |= 0x80
+	if compDecode(s2, buf) {
+		t.Fatal("compDecode accepted -0")
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	for _, logn := range []uint{9, 10} {
+		var seed [SeedSize]byte
+		seed[0] = byte(logn)
+		pk, sk := NewKeyFromSeed(seed[:], logn)
+
+		// Keys survive a round trip through their encodings.
+		buf := make([]byte, PublicKeySize(logn))
+		pk.Pack(buf)
+		var pk2 PublicKey
+		if err := pk2.Unpack(buf, logn); err != nil || !pk.Equal(&pk2) {
+			t.Fatalf("logn=%d: public key round trip", logn)
+		}
+		buf = make([]byte, PrivateKeySize(logn))
+		sk.Pack(buf)
+		var sk2 PrivateKey
+		if err := sk2.Unpack(buf, logn); err != nil || !sk.Equal(&sk2) ||
+			!sk2.Public().Equal(pk) {
+			t.Fatalf("logn=%d: private key round trip", logn)
+		}
+
+		nonce := make([]byte, NonceSize)
+		rnd := make([]byte, RandomSize)
+		sig := make([]byte, SignatureSize(logn))
+		for i := 0; i < 10; i++ {
+			msg := []byte{byte(i)}
+			nonce[0] = byte(i)
+			rnd[0] = byte(i)
+			SignTo(&sk2, msg, nonce, rnd, sig)
+			if !Verify(pk, msg, sig) {
+				t.Fatalf("logn=%d: signature %d does not verify", logn, i)
+			}
+			if Verify(pk, []byte{byte(i + 1)}, sig) {
+				t.Fatalf("logn=%d: signature %d verifies another message", logn, i)
+			}
+		}
+	}
+}
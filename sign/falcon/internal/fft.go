@@ -0,0 +1,279 @@
+package internal
+
+import (
+	"math/big"
+	"math/bits"
+)
+
+// Polynomials of R[x]/(x^n + 1) in FFT representation hold their values at
+// the n/2 roots of x^n + 1 with a positive imaginary part, in bit-reversed
+// order: the real parts in the first half and the imaginary parts in the
+// second half. The values at the other roots are the complex conjugates.
+
+// gmTab holds cos(π·rev(k)/1024) and sin(π·rev(k)/1024) at indices 2k and
+// 2k+1, where rev reverses the 10 bits of k.
+var gmTab = func() (tab [2048]fpr) {
+	// The values are computed with enough precision for them to be
+	// correctly rounded, so that the table is the same on every platform.
+	const prec = 192
+	nf := func() *big.Float { return new(big.Float).SetPrec(prec) }
+
+	// π = 16 arctan(1/5) - 4 arctan(1/239).
+	atanInv := func(x int64) *big.Float {
+		sum, term := nf(), nf().Quo(nf().SetInt64(1), nf().SetInt64(x))
+		x2 := nf().SetInt64(x * x)
+		for k := int64(0); term.Sign() != 0 && term.MantExp(nil) > -prec-8; k++ {
+			t := nf().Quo(term, nf().SetInt64(2*k+1))
+			if k%2 == 0 {
+				sum.Add(sum, t)
+			} else {
+				sum.Sub(sum, t)
+			}
+			term.Quo(term, x2)
+		}
+		return sum
+	}
+	pi := nf().Sub(
+		nf().Mul(nf().SetInt64(16), atanInv(5)),
+		nf().Mul(nf().SetInt64(4), atanInv(239)),
+	)
+
+	// cos and sin of θ = π/1024 from their Taylor series.
+	theta := nf().Quo(pi, nf().SetInt64(1024))
+	c, s := nf().SetInt64(1), nf().Set(theta)
+	term := nf().SetInt64(1)
+	for k := int64(1); k < 40; k++ {
+		term.Mul(term, theta)
+		term.Quo(term, nf().SetInt64(k))
+		switch k % 4 {
+		case 1:
+			if k > 1 {
+				s.Add(s, term)
+			}
+		case 2:
+			c.Sub(c, term)
+		case 3:
+			s.Sub(s, term)
+		case 0:
+			c.Add(c, term)
+		}
+	}
+
+	// Powers of exp(iθ).
+	re, im := nf().SetInt64(1), nf()
+	for r := 0; r < 1024; r++ {
+		k := bits.Reverse16(uint16(r)) >> 6
+		x, _ := re.Float64()
+		y, _ := im.Float64()
+		if r == 512 {
+			x = 0 // cos(π/2), which the approximation misses
+		}
+		tab[2*k] = fprOfFloat(x)
+		tab[2*k+1] = fprOfFloat(y)
+
+		t := nf().Sub(nf().Mul(re, c), nf().Mul(im, s))
+		im.Add(nf().Mul(re, s), nf().Mul(im, c))
+		re = t
+	}
+	return
+}()
+
+func cmul(aRe, aIm, bRe, bIm fpr) (fpr, fpr) {
+	return fprSub(fprMul(aRe, bRe), fprMul(aIm, bIm)),
+		fprAdd(fprMul(aRe, bIm), fprMul(aIm, bRe))
+}
+
+func cdiv(aRe, aIm, bRe, bIm fpr) (fpr, fpr) {
+	m := fprInv(fprAdd(fprSqr(bRe), fprSqr(bIm)))
+	return cmul(aRe, aIm, fprMul(bRe, m), fprMul(fprNeg(bIm), m))
+}
+
+// fft converts f, of length 2^logn, to FFT representation in place.
+func fft(f []fpr, logn uint) {
+	n := 1 << logn
+	hn := n >> 1
+	t := hn
+	for u, m := uint(1), 2; u < logn; u, m = u+1, m<<1 {
+		ht := t >> 1
+		hm := m >> 1
+		for i1, j1 := 0, 0; i1 < hm; i1, j1 = i1+1, j1+t {
+			sRe := gmTab[(m+i1)<<1]
+			sIm := gmTab[(m+i1)<<1+1]
+			for j := j1; j < j1+ht; j++ {
+				xRe, xIm := f[j], f[j+hn]
+				yRe, yIm := cmul(f[j+ht], f[j+ht+hn], sRe, sIm)
+				f[j], f[j+hn] = fprAdd(xRe, yRe), fprAdd(xIm, yIm)
+				f[j+ht], f[j+ht+hn] = fprSub(xRe, yRe), fprSub(xIm, yIm)
+			}
+		}
+		t = ht
+	}
+}
+
+// ifft converts f, of length 2^logn, from FFT representation in place.
+func ifft(f []fpr, logn uint) {
+	n := 1 << logn
+	hn := n >> 1
+	t := 1
+	m := n
+	for u := logn; u > 1; u-- {
+		hm := m >> 1
+		dt := t << 1
+		for i1, j1 := 0, 0; j1 < hn; i1, j1 = i1+1, j1+dt {
+			sRe := gmTab[(hm+i1)<<1]
+			sIm := fprNeg(gmTab[(hm+i1)<<1+1])
+			for j := j1; j < j1+t; j++ {
+				xRe, xIm := f[j], f[j+hn]
+				yRe, yIm := f[j+t], f[j+t+hn]
+				f[j], f[j+hn] = fprAdd(xRe, yRe), fprAdd(xIm, yIm)
+				f[j+t], f[j+t+hn] = cmul(fprSub(xRe, yRe), fprSub(xIm, yIm), sRe, sIm)
+			}
+		}
+		t = dt
+		m = hm
+	}
+
+	// The last layer is folded into the scaling by 2/n.
+	if logn > 0 {
+		ni := fprScaled(1, 1-int(logn))
+		for i := range f[:n] {
+			f[i] = fprMul(f[i], ni)
+		}
+	}
+}
+
+func polyAdd(a, b []fpr) {
+	for i := range a {
+		a[i] = fprAdd(a[i], b[i])
+	}
+}
+
+func polySub(a, b []fpr) {
+	for i := range a {
+		a[i] = fprSub(a[i], b[i])
+	}
+}
+
+func polyNeg(a []fpr) {
+	for i := range a {
+		a[i] = fprNeg(a[i])
+	}
+}
+
+// polyAdjFFT replaces a by its adjoint a(1/x).
+func polyAdjFFT(a []fpr) {
+	hn := len(a) / 2
+	for i := hn; i < len(a); i++ {
+		a[i] = fprNeg(a[i])
+	}
+}
+
+// polyMulFFT sets a to a·b.
+func polyMulFFT(a, b []fpr) {
+	hn := len(a) / 2
+	for i := 0; i < hn; i++ {
+		a[i], a[i+hn] = cmul(a[i], a[i+hn], b[i], b[i+hn])
+	}
+}
+
+// polyMuladjFFT sets a to a·adj(b).
+func polyMuladjFFT(a, b []fpr) {
+	hn := len(a) / 2
+	for i := 0; i < hn; i++ {
+		a[i], a[i+hn] = cmul(a[i], a[i+hn], b[i], fprNeg(b[i+hn]))
+	}
+}
+
+// polyMulselfadjFFT sets a to a·adj(a), which is self-adjoint.
+func polyMulselfadjFFT(a []fpr) {
+	hn := len(a) / 2
+	for i := 0; i < hn; i++ {
+		a[i] = fprAdd(fprSqr(a[i]), fprSqr(a[i+hn]))
+		a[i+hn] = fprZero
+	}
+}
+
+func polyMulconst(a []fpr, x fpr) {
+	for i := range a {
+		a[i] = fprMul(a[i], x)
+	}
+}
+
+// polyDivFFT sets a to a/b.
+func polyDivFFT(a, b []fpr) {
+	hn := len(a) / 2
+	for i := 0; i < hn; i++ {
+		a[i], a[i+hn] = cdiv(a[i], a[i+hn], b[i], b[i+hn])
+	}
+}
+
+// polyInvnorm2FFT sets the first half of d to 1/(a·adj(a) + b·adj(b)),
+// which is self-adjoint.
+func polyInvnorm2FFT(d, a, b []fpr) {
+	hn := len(a) / 2
+	for i := 0; i < hn; i++ {
+		d[i] = fprInv(fprAdd(
+			fprAdd(fprSqr(a[i]), fprSqr(a[i+hn])),
+			fprAdd(fprSqr(b[i]), fprSqr(b[i+hn])),
+		))
+	}
+}
+
+// polyMulAutoadjFFT sets a to a·b, where b is self-adjoint and only the
+// first half of b is used.
+func polyMulAutoadjFFT(a, b []fpr) {
+	hn := len(a) / 2
+	for i := 0; i < hn; i++ {
+		a[i] = fprMul(a[i], b[i])
+		a[i+hn] = fprMul(a[i+hn], b[i])
+	}
+}
+
+// polyLDLmvFFT computes the LDL decomposition of the self-adjoint matrix
+// [[g00, g01], [adj(g01), g11]]: it sets l10 to adj(g01)/g00 and d11 to
+// g11 - g01·adj(g01)/g00.
+func polyLDLmvFFT(d11, l10, g00, g01, g11 []fpr) {
+	hn := len(g00) / 2
+	for i := 0; i < hn; i++ {
+		muRe, muIm := cdiv(g01[i], g01[i+hn], g00[i], g00[i+hn])
+		xiRe, xiIm := cmul(muRe, muIm, g01[i], fprNeg(g01[i+hn]))
+		d11[i], d11[i+hn] = fprSub(g11[i], xiRe), fprSub(g11[i+hn], xiIm)
+		l10[i], l10[i+hn] = muRe, fprNeg(muIm)
+	}
+}
+
+// polySplitFFT sets f0 and f1, of half the length of f, such that
+// f(x) = f0(x^2) + x·f1(x^2).
+func polySplitFFT(f0, f1, f []fpr) {
+	hn := len(f) / 2
+	qn := hn / 2
+
+	// f(w) and f(-w) are stored next to each other.
+	f0[0] = f[0]
+	f1[0] = f[hn]
+	for u := 0; u < qn; u++ {
+		aRe, aIm := f[u<<1], f[u<<1+hn]
+		bRe, bIm := f[u<<1+1], f[u<<1+1+hn]
+		f0[u] = fprHalf(fprAdd(aRe, bRe))
+		f0[u+qn] = fprHalf(fprAdd(aIm, bIm))
+		tRe, tIm := cmul(fprSub(aRe, bRe), fprSub(aIm, bIm),
+			gmTab[(u+hn)<<1], fprNeg(gmTab[(u+hn)<<1+1]))
+		f1[u] = fprHalf(tRe)
+		f1[u+qn] = fprHalf(tIm)
+	}
+}
+
+// polyMergeFFT is the inverse of polySplitFFT.
+func polyMergeFFT(f, f0, f1 []fpr) {
+	hn := len(f) / 2
+	qn := hn / 2
+
+	f[0] = f0[0]
+	f[hn] = f1[0]
+	for u := 0; u < qn; u++ {
+		aRe, aIm := f0[u], f0[u+qn]
+		bRe, bIm := cmul(f1[u], f1[u+qn], gmTab[(u+hn)<<1], gmTab[(u+hn)<<1+1])
+		f[u<<1], f[u<<1+hn] = fprAdd(aRe, bRe), fprAdd(aIm, bIm)
+		f[u<<1+1], f[u<<1+1+hn] = fprSub(aRe, bRe), fprSub(aIm, bIm)
+	}
+}
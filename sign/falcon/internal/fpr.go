@@ -0,0 +1,333 @@
+package internal
+
+import (
+	"math"
+	"math/bits"
+)
+
+// fpr is an IEEE-754 binary64 value whose arithmetic is emulated with
+// integer operations.
+//
+// The emulation is constant-time and rounds exactly as the hardware does,
+// so that key generation and signing produce the same results on every
+// platform, regardless of the floating-point unit or of the fused
+// multiply-adds the compiler may emit for native float64 code. NaNs,
+// infinities and subnormals are not supported: results that would be
+// subnormal are flushed to zero.
+type fpr uint64
+
+const fprSignBit = 1 << 63
+
+// fprOfFloat returns the fpr with the same bits as x. Only used for
+// constants.
+func fprOfFloat(x float64) fpr { return fpr(math.Float64bits(x)) }
+
+var (
+	fprZero         = fprOfFloat(0)
+	fprOne          = fprOfFloat(1)
+	fprQ            = fprOfFloat(Q)
+	fprInverseOfQ   = fprOfFloat(1.0 / Q)
+	fprInvLog2      = fprOfFloat(1.4426950408889634073599246810019)
+	fprLog2         = fprOfFloat(0.69314718055994530941723212145818)
+	fprPtwo63       = fprOfFloat(1 << 63)
+	fprInv2Sqrsigma = fprOfFloat(0.150865048875372721532312163019)
+	fprBnormMax     = fprOfFloat(16822.4121)
+)
+
+// pack returns the value (-1)^s · m · 2^e rounded to nearest, where m is
+// either zero or in [2^54, 2^55), and its least significant bit is sticky.
+// Values below the normal range are flushed to zero.
+func fprPack(s uint64, e int, m uint64) fpr {
+	e += 1076
+	t := uint64(uint32(e) >> 31)
+	m &= t - 1
+	t = m >> 54
+	e &= -int(t)
+
+	// The top bit of m increments the exponent by one, except if m is
+	// zero, in which case the exponent is zero too.
+	x := s<<63 | m>>2
+	x += uint64(uint32(e)) << 52
+
+	// Round up if the low three bits of m are 011, 110 or 111. A carry
+	// into the exponent is what we want.
+	x += uint64(0xC8>>(m&7)) & 1
+	return fpr(x)
+}
+
+// norm64 shifts m left until its top bit is set and adjusts e such that
+// m · 2^e is unchanged. If m is zero, it stays zero.
+func norm64(m uint64, e int) (uint64, int) {
+	e -= 63
+	for k := uint(32); k > 0; k >>= 1 {
+		nt := uint32(m >> (64 - k))
+		nt = (nt | -nt) >> 31
+		m ^= (m ^ m<<k) & (uint64(nt) - 1)
+		e += int(nt) * int(k)
+	}
+	return m, e
+}
+
+// fprScaled returns i · 2^sc.
+func fprScaled(i int64, sc int) fpr {
+	s := uint64(i) >> 63
+	i ^= -int64(s)
+	i += int64(s)
+	m, e := norm64(uint64(i), 9+sc)
+
+	// Scale down to [2^54, 2^55), keeping the dropped bits sticky.
+	m |= uint64(uint32(m)&0x1FF + 0x1FF)
+	m >>= 9
+
+	t := (uint64(i) | -uint64(i)) >> 63
+	m &= -t
+	e &= -int(t)
+	return fprPack(s, e, m)
+}
+
+// fprOf returns i.
+func fprOf(i int64) fpr { return fprScaled(i, 0) }
+
+func fprAdd(x, y fpr) fpr {
+	// Swap so that |x| ≥ |y|. If |x| = |y| and x is negative, swap too, so
+	// that the sum of opposite values is +0.
+	const abs = 1<<63 - 1
+	za := uint64(x)&abs - uint64(y)&abs
+	cs := za>>63 | (1-(-za)>>63)&(uint64(x)>>63)
+	sw := fpr(uint64(x^y) & -cs)
+	x ^= sw
+	y ^= sw
+
+	// Extract the mantissae, scaled to [2^55, 2^56), and the exponents.
+	// The mantissa of zero is zero.
+	ex := int(x >> 52)
+	sx := ex >> 11
+	ex &= 0x7FF
+	m := uint64(uint32((ex+0x7FF)>>11)) << 52
+	xu := (uint64(x)&(1<<52-1) | m) << 3
+	ex -= 1078
+	ey := int(y >> 52)
+	sy := ey >> 11
+	ey &= 0x7FF
+	m = uint64(uint32((ey+0x7FF)>>11)) << 52
+	yu := (uint64(y)&(1<<52-1) | m) << 3
+	ey -= 1078
+
+	// Align y on x, with a sticky low bit. Shifts of 60 bits or more
+	// clear y.
+	cc := ex - ey
+	yu &= -uint64(uint32(cc-60) >> 31)
+	cc &= 63
+	m = uint64(1)<<uint(cc) - 1
+	yu |= (yu & m) + m
+	yu >>= uint(cc)
+
+	// Add or subtract the mantissae, depending on the signs.
+	xu += yu - (yu<<1)&-uint64(sx^sy)
+
+	xu, ex = norm64(xu, ex)
+	xu |= uint64(uint32(xu)&0x1FF + 0x1FF)
+	xu >>= 9
+	ex += 9
+	return fprPack(uint64(sx), ex, xu)
+}
+
+func fprSub(x, y fpr) fpr { return fprAdd(x, y^fprSignBit) }
+func fprNeg(x fpr) fpr    { return x ^ fprSignBit }
+
+// fprHalf returns x/2.
+func fprHalf(x fpr) fpr {
+	x -= 1 << 52
+	t := (uint32(x>>52)&0x7FF + 1) >> 11
+	return x & fpr(uint64(t)-1)
+}
+
+// fprDouble returns 2x.
+func fprDouble(x fpr) fpr {
+	return x + fpr(uint64((uint32(x>>52)&0x7FF+0x7FF)>>11)<<52)
+}
+
+func fprMul(x, y fpr) fpr {
+	xu := uint64(x)&(1<<52-1) | 1<<52
+	yu := uint64(y)&(1<<52-1) | 1<<52
+
+	// The product is in [2^104, 2^106). Keep its top bits in [2^54, 2^56)
+	// with a sticky low bit, then normalize to [2^54, 2^55).
+	hi, lo := bits.Mul64(xu, yu)
+	zu := hi<<14 | lo>>50
+	zu |= (lo&(1<<50-1) + (1<<50 - 1)) >> 50
+	zv := zu>>1 | zu&1
+	w := zu >> 55
+	zu ^= (zu ^ zv) & -w
+
+	ex := int(x>>52) & 0x7FF
+	ey := int(y>>52) & 0x7FF
+	e := ex + ey - 2100 + int(w)
+	s := uint64(x^y) >> 63
+
+	// The product is zero if either operand is.
+	d := ((ex + 0x7FF) & (ey + 0x7FF)) >> 11
+	zu &= -uint64(d)
+	return fprPack(s, e, zu)
+}
+
+func fprSqr(x fpr) fpr { return fprMul(x, x) }
+
+// fprDiv returns x/y. y must not be zero.
+func fprDiv(x, y fpr) fpr {
+	xu := uint64(x)&(1<<52-1) | 1<<52
+	yu := uint64(y)&(1<<52-1) | 1<<52
+
+	// Long division, 55 bits of quotient followed by a sticky bit.
+	var q uint64
+	for i := 0; i < 55; i++ {
+		b := (xu-yu)>>63 - 1
+		xu -= b & yu
+		q |= b & 1
+		xu <<= 1
+		q <<= 1
+	}
+	q |= (xu | -xu) >> 63
+
+	q2 := q>>1 | q&1
+	w := q >> 55
+	q ^= (q ^ q2) & -w
+
+	ex := int(x>>52) & 0x7FF
+	ey := int(y>>52) & 0x7FF
+	e := ex - ey - 55 + int(w)
+	s := uint64(x^y) >> 63
+
+	d := (ex + 0x7FF) >> 11
+	s &= uint64(d)
+	e &= -d
+	q &= -uint64(d)
+	return fprPack(s, e, q)
+}
+
+func fprInv(x fpr) fpr { return fprDiv(fprOne, x) }
+
+// fprSqrt returns the square root of x, which must not be negative.
+func fprSqrt(x fpr) fpr {
+	xu := uint64(x)&(1<<52-1) | 1<<52
+	ex := int(x>>52) & 0x7FF
+	e := ex - 1023
+
+	// Make the exponent even, and halve it.
+	xu += xu & -uint64(e&1)
+	e >>= 1
+	xu <<= 1
+
+	// xu represents a value in [1, 4) with 53 fractional bits. Compute
+	// its square root bit by bit.
+	var q, s uint64
+	r := uint64(1) << 53
+	for i := 0; i < 54; i++ {
+		t := s + r
+		b := (xu-t)>>63 - 1
+		s += (r << 1) & b
+		xu -= t & b
+		q += r & b
+		xu <<= 1
+		r >>= 1
+	}
+
+	// Append a sticky bit for the remainder.
+	q <<= 1
+	q |= (xu | -xu) >> 63
+	e -= 54
+
+	q &= -uint64((ex + 0x7FF) >> 11)
+	return fprPack(0, e, q)
+}
+
+// fprRint returns x rounded to the nearest integer, ties to even. x must
+// be in (-2^63, 2^63).
+func fprRint(x fpr) int64 {
+	m := (uint64(x)<<10 | 1<<62) & (1<<63 - 1)
+	e := 1085 - int(x>>52)&0x7FF
+
+	// Shifts of 64 bits or more, including that of zero, give zero.
+	m &= -uint64(uint32(e-64) >> 31)
+	e &= 63
+
+	// Gather the dropped bits and the lowest kept bit into three bits,
+	// the lowest being sticky, and round as fprPack does.
+	d := m << uint(63-e)
+	dd := uint32(d) | uint32(d>>32)&0x1FFFFFFF
+	f := uint32(d>>61) | (dd|-dd)>>31
+	m = m>>uint(e) + uint64(0xC8>>f)&1
+
+	s := int64(x >> 63)
+	return (int64(m) ^ -s) + s
+}
+
+// fprFloor returns the largest integer not above x, which must be in
+// (-2^63, 2^63).
+func fprFloor(x fpr) int64 {
+	e := int(x>>52) & 0x7FF
+	t := int64(x >> 63)
+	xi := int64((uint64(x)<<10 | 1<<62) & (1<<63 - 1))
+	xi = (xi ^ -t) + t
+	cc := 1085 - e
+
+	// An arithmetic shift rounds towards minus infinity. If the shift
+	// count is 64 or more, the result is 0 or -1 depending on the sign.
+	xi >>= uint(cc & 63)
+	xi ^= (xi ^ -t) & -int64(uint32(63-cc)>>31)
+	return xi
+}
+
+// fprTrunc returns x rounded towards zero. x must be in (-2^63, 2^63).
+func fprTrunc(x fpr) int64 {
+	e := int(x>>52) & 0x7FF
+	xu := (uint64(x)<<10 | 1<<62) & (1<<63 - 1)
+	cc := 1085 - e
+	xu >>= uint(cc & 63)
+	xu &= -uint64(uint32(cc-64) >> 31)
+	t := uint64(x) >> 63
+	xu = (xu ^ -t) + t
+	return int64(xu)
+}
+
+// fprLt returns 1 if x < y and 0 otherwise.
+func fprLt(x, y fpr) int {
+	sx := int64(x)
+	sy := int64(y)
+	sy &^= (sx ^ sy) >> 63
+	cc0 := int((sx-sy)>>63) & 1
+	cc1 := int((sy-sx)>>63) & 1
+	return cc0 ^ ((cc0 ^ cc1) & int((x&y)>>63))
+}
+
+// expmCoeffs are the coefficients, scaled by 2^63, of a polynomial
+// approximation of exp(-x) on [0, ln 2], from highest to lowest degree.
+var expmCoeffs = [...]uint64{
+	0x00000004741183A3,
+	0x00000036548CFC06,
+	0x0000024FDCBF140A,
+	0x0000171D939DE045,
+	0x0000D00CF58F6F84,
+	0x000680681CF796E3,
+	0x002D82D8305B0FEA,
+	0x011111110E066FD0,
+	0x0555555555070F00,
+	0x155555555581FF00,
+	0x400000000002B400,
+	0x7FFFFFFFFFFF4800,
+	0x8000000000000000,
+}
+
+// fprExpmP63 returns 2^63 · ccs · exp(-x), rounded, for x in [0, ln 2]
+// and ccs in [0, 1].
+func fprExpmP63(x, ccs fpr) uint64 {
+	y := expmCoeffs[0]
+	z := uint64(fprTrunc(fprMul(x, fprPtwo63))) << 1
+	for _, c := range expmCoeffs[1:] {
+		hi, _ := bits.Mul64(z, y)
+		y = c - hi
+	}
+	z = uint64(fprTrunc(fprMul(ccs, fprPtwo63))) << 1
+	y, _ = bits.Mul64(z, y)
+	return y
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// randFloat returns a random normal float64 of moderate magnitude, which
+// may be an integer or have few mantissa bits to exercise ties.
+func randFloat(r *rand.Rand) float64 {
+	var x float64
+	switch r.Intn(4) {
+	case 0:
+		x = float64(r.Int63n(1<<20) - 1<<19)
+	case 1:
+		x = float64(r.Int63n(1<<10)) / 8
+	default:
+		x = math.Ldexp(r.Float64()+0.5, r.Intn(120)-60)
+	}
+	if r.Intn(2) == 0 {
+		x = -x
+	}
+	return x
+}
+
+func checkFpr(t *testing.T, op string, got fpr, want float64, args ...float64) {
+	t.Helper()
+	// The sign of zero results may differ.
+	if got != fprOfFloat(want) && (want != 0 || got&^fprSignBit != 0) {
+		t.Fatalf("%s%v = %v, want %v", op, args, math.Float64frombits(uint64(got)), want)
+	}
+}
+
+func TestFprArith(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200000; i++ {
+		x, y := randFloat(r), randFloat(r)
+		if i%17 == 0 {
+			y = -x
+		}
+		if i%19 == 0 {
+			y = 0
+		}
+		fx, fy := fprOfFloat(x), fprOfFloat(y)
+		checkFpr(t, "add", fprAdd(fx, fy), x+y, x, y)
+		checkFpr(t, "sub", fprSub(fx, fy), x-y, x, y)
+		checkFpr(t, "mul", fprMul(fx, fy), x*y, x, y)
+		if y != 0 {
+			checkFpr(t, "div", fprDiv(fx, fy), x/y, x, y)
+		}
+		checkFpr(t, "half", fprHalf(fx), x/2, x)
+		checkFpr(t, "double", fprDouble(fx), x*2, x)
+		checkFpr(t, "sqrt", fprSqrt(fprOfFloat(math.Abs(x))), math.Sqrt(math.Abs(x)), x)
+		if got, want := fprLt(fx, fy), x < y; got != map[bool]int{false: 0, true: 1}[want] {
+			t.Fatalf("lt(%v, %v) = %v", x, y, got)
+		}
+	}
+}
+
+func TestFprConv(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 200000; i++ {
+		n := r.Int63() >> uint(r.Intn(63))
+		if i%2 == 0 {
+			n = -n
+		}
+		checkFpr(t, "of", fprOf(n), float64(n), float64(n))
+		sc := r.Intn(80) - 40
+		checkFpr(t, "scaled", fprScaled(n, sc), math.Ldexp(float64(n), sc), float64(n))
+
+		x := randFloat(r)
+		if math.Abs(x) >= 1<<62 {
+			continue
+		}
+		fx := fprOfFloat(x)
+		if got := fprRint(fx); got != int64(math.RoundToEven(x)) {
+			t.Fatalf("rint(%v) = %v", x, got)
+		}
+		// fprFloor(-0) is -1.
+		if got := fprFloor(fx); got != int64(math.Floor(x)) && x != 0 {
+			t.Fatalf("floor(%v) = %v", x, got)
+		}
+		if got := fprTrunc(fx); got != int64(math.Trunc(x)) {
+			t.Fatalf("trunc(%v) = %v", x, got)
+		}
+	}
+}
+
+func TestFprExpm(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for i := 0; i < 10000; i++ {
+		x := r.Float64() * math.Ln2
+		ccs := r.Float64()
+		got := float64(fprExpmP63(fprOfFloat(x), fprOfFloat(ccs)))
+		want := math.Ldexp(ccs*math.Exp(-x), 63)
+		if math.Abs(got-want) > math.Ldexp(1, 63-45) {
+			t.Fatalf("expm(%v, %v) = %v, want %v", x, ccs, got, want)
+		}
+	}
+}
+
+func TestGmTab(t *testing.T) {
+	for k := 0; k < 1024; k++ {
+		re := math.Float64frombits(uint64(gmTab[2*k]))
+		im := math.Float64frombits(uint64(gmTab[2*k+1]))
+		if math.Abs(re*re+im*im-1) > 1e-15 {
+			t.Fatalf("root %d not of norm 1", k)
+		}
+	}
+	if gmTab[2] != fprZero || gmTab[3] != fprOne {
+		t.Fatal("rev(1) is not i")
+	}
+}
@@ -0,0 +1,427 @@
+package internal
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/katzenpost/circl/internal/sha3"
+)
+
+// gaussTab is the distribution of the sampler of the coefficients of f and
+// g for degree 1024, a discrete Gaussian of standard deviation
+// σ = 1.17·sqrt(q/2048). Entry 0 is 2^63·Pr[x = 0], and entry k > 0 is
+// 2^63·Pr[|x| > k | x ≠ 0].
+var gaussTab = [...]uint64{
+	0x11D137D82DF2AB58, 0x590C40F63FF5F974, 0x3898E41D85B975B7,
+	0x20A964EF50858FF9, 0x1107D1AE973857EB, 0x07FE1EC29220EA37,
+	0x035DAFCACD37A439, 0x0144D98306216D42, 0x006D6BEEEAF81655,
+	0x0020E1A00D6FA84C, 0x0008CDDDCD9DDA9C, 0x0002192FC3DCDCB4,
+	0x000071DFCD3C57E9, 0x00001574938D76EB, 0x000003974B0C33E5,
+	0x000000889D3DA6FE, 0x0000001204DDC6CB, 0x000000021BD3B27A,
+	0x0000000038091F5E, 0x0000000005287DB0, 0x00000000006BC528,
+	0x000000000007CBFB, 0x0000000000007FFC, 0x0000000000000746,
+	0x000000000000005E, 0x0000000000000004,
+}
+
+func rngU64(rng *sha3.State) uint64 {
+	var buf [8]byte
+	_, _ = rng.Read(buf[:])
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// mkgauss samples a coefficient of f or g for degree 2^logn, as the sum of
+// 2^(10-logn) samples for degree 1024, in constant time.
+func mkgauss(rng *sha3.State, logn uint) int {
+	val := 0
+	for u := 0; u < 1<<(10-logn); u++ {
+		// The first word decides whether the value is zero, and its sign.
+		r := rngU64(rng)
+		neg := uint32(r >> 63)
+		r &^= 1 << 63
+		f := uint32((r - gaussTab[0]) >> 63)
+
+		// The second word selects the absolute value: the first k such
+		// that r is not below gaussTab[k].
+		v := uint32(0)
+		r = rngU64(rng) &^ (1 << 63)
+		for k := uint32(1); k < uint32(len(gaussTab)); k++ {
+			t := uint32((r-gaussTab[k])>>63) ^ 1
+			v |= k & -(t & (f ^ 1))
+			f |= t
+		}
+
+		v = (v ^ -neg) + neg
+		val += int(int32(v))
+	}
+	return val
+}
+
+// polySmallMkgauss samples f with coefficients in [-127, 127] whose sum
+// is odd, as otherwise the resultant of f and x^n + 1 is even and f cannot
+// be part of a solution of the NTRU equation.
+func polySmallMkgauss(rng *sha3.State, f []int8, logn uint) {
+	mod2 := 0
+	for i := 0; i < len(f); {
+		s := mkgauss(rng, logn)
+		if s < -127 || s > 127 {
+			continue
+		}
+		if i == len(f)-1 {
+			if mod2^(s&1) == 0 {
+				continue
+			}
+		} else {
+			mod2 ^= s & 1
+		}
+		f[i] = int8(s)
+		i++
+	}
+}
+
+// smallToFFT returns f in FFT representation.
+func smallToFFT(f []int8, logn uint) []fpr {
+	ret := make([]fpr, len(f))
+	for i, c := range f {
+		ret[i] = fprOf(int64(c))
+	}
+	fft(ret, logn)
+	return ret
+}
+
+// newKeyFromSeed generates f, g, F and G from SHAKE256(seed), see
+// Algorithm 4 of the Falcon specification.
+func newKeyFromSeed(seed []byte, logn uint) (f, g, F, G []int8, h []uint16) {
+	n := 1 << logn
+	rng := sha3.NewShake256()
+	_, _ = rng.Write(seed)
+
+	f = make([]int8, n)
+	g = make([]int8, n)
+	lim := int8(1<<(fgBits(logn)-1) - 1)
+	for {
+		// Sample f and g, such that they fit in the encoding of the
+		// private key.
+		polySmallMkgauss(&rng, f, logn)
+		polySmallMkgauss(&rng, g, logn)
+		ok := true
+		for i := 0; i < n; i++ {
+			if f[i] < -lim || f[i] > lim || g[i] < -lim || g[i] > lim {
+				ok = false
+			}
+		}
+		if !ok {
+			continue
+		}
+
+		// The squared norm of (g, -f) must be below (1.17)^2·q.
+		norm := 0
+		for i := 0; i < n; i++ {
+			norm += int(f[i])*int(f[i]) + int(g[i])*int(g[i])
+		}
+		if norm >= 16823 {
+			continue
+		}
+
+		// So must the norm of the orthogonalized vector
+		// (q·adj(f), q·adj(g))/(f·adj(f) + g·adj(g)).
+		rt1 := smallToFFT(f, logn)
+		rt2 := smallToFFT(g, logn)
+		rt3 := make([]fpr, n)
+		polyInvnorm2FFT(rt3, rt1, rt2)
+		for _, rt := range [][]fpr{rt1, rt2} {
+			polyAdjFFT(rt)
+			polyMulconst(rt, fprQ)
+			polyMulAutoadjFFT(rt, rt3)
+			ifft(rt, logn)
+		}
+		bnorm := fprZero
+		for i := 0; i < n; i++ {
+			bnorm = fprAdd(bnorm, fprSqr(rt1[i]))
+			bnorm = fprAdd(bnorm, fprSqr(rt2[i]))
+		}
+		if fprLt(bnorm, fprBnormMax) == 0 {
+			continue
+		}
+
+		h, ok = computePublic(f, g)
+		if !ok {
+			continue
+		}
+
+		F, G, ok = solveNTRU(f, g)
+		if !ok {
+			continue
+		}
+		return f, g, F, G, h
+	}
+}
+
+// Solving the NTRU equation f·G - g·F = q, see Algorithm 6 of the Falcon
+// specification, in constant time. The recursion goes down to degree 1
+// with field norms, where a binary extended GCD of the resultants of f and
+// g gives a solution, which is lifted back up and reduced at each level.
+//
+// The sizes of all the intermediate values are public: their integers
+// have a fixed number of words derived from ntruBitLength, and reductions
+// run a fixed number of iterations. If a key exceeds them, the solution is
+// wrong or too large and the final check rejects it, so that f and g are
+// sampled again, which happens for a negligible fraction of the keys.
+
+// ntruBitLength bounds the bit lengths of the coefficients of the field
+// norms of f and g at each depth of the recursion, whatever the degree.
+// They are about 6.5·2^depth bits, and the bounds are six standard
+// deviations away from the averages measured for all the degrees.
+var ntruBitLength = [...]struct{ min, max int }{
+	{1, 8}, {1, 25}, {7, 44}, {27, 75}, {71, 131}, {153, 249},
+	{338, 458}, {696, 888}, {1454, 1694}, {2923, 3355}, {5975, 6575},
+}
+
+// ntruSlack is the number of bits by which reduced coefficients of F and G
+// may exceed those of f and g.
+const ntruSlack = 16
+
+// ntruReduceBits is the number of bits by which each iteration of the
+// reduction shrinks F and G.
+const ntruReduceBits = 25
+
+// ntruMaxK bounds the coefficients of the multiple of (f, g) subtracted
+// from (F, G) in an iteration of the reduction, so that their
+// approximations are accurate enough.
+const ntruMaxK = 1 << 40
+
+// solveNTRU returns F and G with f·G - g·F = q whose coefficients fit in
+// [-127, 127], or false if there are none or they are not found.
+func solveNTRU(f, g []int8) (F, G []int8, ok bool) {
+	n := len(f)
+	logn := uint(0)
+	for 1<<logn < n {
+		logn++
+	}
+
+	// Field norms of f and g at each depth.
+	fs := make([][]uint64, logn+1)
+	gs := make([][]uint64, logn+1)
+	fs[0] = make([]uint64, n)
+	gs[0] = make([]uint64, n)
+	for i := 0; i < n; i++ {
+		fs[0][i] = uint64(f[i])
+		gs[0][i] = uint64(g[i])
+	}
+	for d := uint(1); d <= logn; d++ {
+		fs[d] = fieldNorm(fs[d-1], d, logn)
+		gs[d] = fieldNorm(gs[d-1], d, logn)
+	}
+
+	// At degree 1, F = q·v and G = q·u for x·u - y·v = 1 where x = |f| and
+	// y = |g|, up to the signs of f and g.
+	w := zWords(ntruBitLength[logn].max)
+	sf := fs[logn][w-1] >> 63
+	sg := gs[logn][w-1] >> 63
+	x := append([]uint64(nil), fs[logn]...)
+	y := append([]uint64(nil), gs[logn]...)
+	zCondNeg(x, sf)
+	zCondNeg(y, sg)
+	u := make([]uint64, w)
+	v := make([]uint64, w)
+	res := x[0] & y[0] & 1
+	res &= zBezout(u, v, x, y)
+	wF := zWords(ntruBitLength[logn].max + ntruSlack)
+	bF := make([]uint64, wF)
+	bG := make([]uint64, wF)
+	t := make([]uint64, wF)
+	zExtend(t, v)
+	zMulSmall(bF, t, Q*(1-2*int64(sg)))
+	zExtend(t, u)
+	zMulSmall(bG, t, Q*(1-2*int64(sf)))
+
+	for d := int(logn) - 1; d >= 0; d-- {
+		bF, bG, wF = ntruLift(fs[d], gs[d], bF, bG, wF, uint(d), logn)
+		res &= ntruReduce(fs[d], gs[d], bF, bG, wF, uint(d), logn)
+
+		// Truncate F and G for the next level.
+		w := zWords(ntruBitLength[d].max + ntruSlack)
+		tF := make([]uint64, w<<(logn-uint(d)))
+		tG := make([]uint64, w<<(logn-uint(d)))
+		for i := 0; i < 1<<(logn-uint(d)); i++ {
+			zExtend(tF[i*w:(i+1)*w], bF[i*wF:(i+1)*wF])
+			zExtend(tG[i*w:(i+1)*w], bG[i*wF:(i+1)*wF])
+		}
+		bF, bG, wF = tF, tG, w
+	}
+
+	// Now wF = 1.
+	F = make([]int8, n)
+	G = make([]int8, n)
+	for i := 0; i < n; i++ {
+		_, b := bits.Sub64(bF[i]+127, 255, 0)
+		res &= b
+		_, b = bits.Sub64(bG[i]+127, 255, 0)
+		res &= b
+		F[i] = int8(bF[i])
+		G[i] = int8(bG[i])
+	}
+	res &= checkNTRU(f, g, F, G)
+	return F, G, res == 1
+}
+
+// fieldNorm returns the field norm N(a) of the polynomial a at depth d-1,
+// of half its degree, such that N(a)(x^2) = a(x)·a(-x).
+func fieldNorm(a []uint64, d, logn uint) []uint64 {
+	hn := 1 << (logn - d)
+	wa := zWords(ntruBitLength[d-1].max)
+	w := zWords(ntruBitLength[d].max)
+	ae := make([]uint64, hn*wa)
+	ao := make([]uint64, hn*wa)
+	for i := 0; i < hn; i++ {
+		copy(ae[i*wa:(i+1)*wa], a[2*i*wa:(2*i+1)*wa])
+		copy(ao[i*wa:(i+1)*wa], a[(2*i+1)*wa:(2*i+2)*wa])
+	}
+	ae2 := make([]uint64, hn*w)
+	ao2 := make([]uint64, hn*w)
+	zPolyMul(ae2, w, ae, wa, ae, wa, hn)
+	zPolyMul(ao2, w, ao, wa, ao, wa, hn)
+
+	// N(a) = ae^2 - x·ao^2.
+	zAdd(ae2[:w], ao2[(hn-1)*w:])
+	for i := 1; i < hn; i++ {
+		zSub(ae2[i*w:(i+1)*w], ao2[(i-1)*w:i*w])
+	}
+	return ae2
+}
+
+// ntruLift returns F(x^2)·g(-x) and G(x^2)·f(-x) at depth d, and the number
+// of words of their coefficients, from F and G at depth d+1 whose
+// coefficients have wF words.
+func ntruLift(f, g, F, G []uint64, wF int, d, logn uint) (nF, nG []uint64, w int) {
+	n := 1 << (logn - d)
+	wfg := zWords(ntruBitLength[d].max)
+	w = zWords(ntruBitLength[d+1].max + ntruSlack + ntruBitLength[d].max + int(logn-d) + 1)
+	lF := make([]uint64, n*wF)
+	lG := make([]uint64, n*wF)
+	copy(lF, F)
+	copy(lG, G)
+	for i := n/2 - 1; i >= 0; i-- {
+		copy(lF[2*i*wF:(2*i+1)*wF], lF[i*wF:(i+1)*wF])
+		copy(lG[2*i*wF:(2*i+1)*wF], lG[i*wF:(i+1)*wF])
+		for j := (2*i + 1) * wF; j < (2*i+2)*wF; j++ {
+			lF[j] = 0
+			lG[j] = 0
+		}
+	}
+	cf := append([]uint64(nil), f...)
+	cg := append([]uint64(nil), g...)
+	for i := 1; i < n; i += 2 {
+		zCondNeg(cf[i*wfg:(i+1)*wfg], 1)
+		zCondNeg(cg[i*wfg:(i+1)*wfg], 1)
+	}
+	nF = make([]uint64, n*w)
+	nG = make([]uint64, n*w)
+	zPolyMul(nF, w, lF, wF, cg, wfg, n)
+	zPolyMul(nG, w, lG, wF, cf, wfg, n)
+	return nF, nG, w
+}
+
+// ntruReduce reduces F and G at depth d, whose coefficients have wF words,
+// with respect to f and g, using Babai's round-off algorithm. It returns 0
+// if the approximations are not accurate enough, and 1 otherwise.
+//
+// Each iteration computes k = (F·adj(f) + G·adj(g))/(f·adj(f) + g·adj(g))
+// from approximations of f, g, F and G, and subtracts (k·f, k·g)·2^s for a
+// scale s such that k fits on about ntruReduceBits bits. The scale starts
+// from the bounds on the coefficients of F and f, and decreases by
+// ntruReduceBits at each iteration until it is zero, which leaves F and G
+// about the size of f and g.
+func ntruReduce(f, g, F, G []uint64, wF int, d, logn uint) uint64 {
+	ln := logn - d
+	n := 1 << ln
+	bl := ntruBitLength[d]
+	wfg := zWords(bl.max)
+	tmp := make([]uint64, wF)
+
+	// f and g, scaled by 2^-ef, ignoring their words below lo.
+	ef := (bl.min + bl.max) / 2
+	lo := 0
+	if bl.min > 110 {
+		lo = (bl.min - 110) / 64
+	}
+	fa := make([]fpr, n)
+	ga := make([]fpr, n)
+	for i := 0; i < n; i++ {
+		fa[i] = zToFpr(f[i*wfg:(i+1)*wfg], lo, -ef, tmp)
+		ga[i] = zToFpr(g[i*wfg:(i+1)*wfg], lo, -ef, tmp)
+	}
+	fft(fa, ln)
+	fft(ga, ln)
+	inv := make([]fpr, n)
+	polyInvnorm2FFT(inv, fa, ga)
+
+	Fa := make([]fpr, n)
+	Ga := make([]fpr, n)
+	k := make([]int64, n)
+	res := uint64(1)
+	bound := ntruBitLength[d+1].max + ntruSlack + bl.max + int(ln) + 1
+	s := bound - bl.min - ntruReduceBits
+	if s < 0 {
+		s = 0
+	}
+	for {
+		// F and G, scaled by 2^-bound, ignoring their words whose
+		// contribution to k is below 2^-10.
+		lo := 0
+		if bl.min+s > 10 {
+			lo = (bl.min + s - 10) / 64
+		}
+		hi := zWords(bound)
+		if hi > wF {
+			hi = wF
+		}
+		for i := 0; i < n; i++ {
+			Fa[i] = zToFpr(F[i*wF:i*wF+hi], lo, -bound, tmp)
+			Ga[i] = zToFpr(G[i*wF:i*wF+hi], lo, -bound, tmp)
+		}
+		fft(Fa, ln)
+		fft(Ga, ln)
+		polyMuladjFFT(Fa, fa)
+		polyMuladjFFT(Ga, ga)
+		polyAdd(Fa, Ga)
+		polyMulAutoadjFFT(Fa, inv)
+		polyMulconst(Fa, fprScaled(1, bound-ef-s))
+		ifft(Fa, ln)
+		for i := 0; i < n; i++ {
+			x := Fa[i] &^ fprSignBit
+			res &= uint64(fprLt(x, fprScaled(ntruMaxK, 0)))
+			k[i] = fprRint(Fa[i])
+		}
+		zPolySubScaledMul(F, wF, f, wfg, k, s, n)
+		zPolySubScaledMul(G, wF, g, wfg, k, s, n)
+
+		if s == 0 {
+			return res
+		}
+		bound = bl.max + s + 32
+		s -= ntruReduceBits
+		if s < 0 {
+			s = 0
+		}
+	}
+}
+
+// checkNTRU returns 1 if f·G - g·F = q modulo x^n + 1 and 0 otherwise.
+func checkNTRU(f, g, F, G []int8) uint64 {
+	n := len(f)
+	var o uint32
+	for k := 0; k < n; k++ {
+		var r int32
+		for i := 0; i <= k; i++ {
+			r += int32(f[i])*int32(G[k-i]) - int32(g[i])*int32(F[k-i])
+		}
+		for i := k + 1; i < n; i++ {
+			r -= int32(f[i])*int32(G[k-i+n]) - int32(g[i])*int32(F[k-i+n])
+		}
+		if k == 0 {
+			r -= Q
+		}
+		o |= uint32(r)
+	}
+	return uint64(1 ^ (o|-o)>>31)
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+// checkEquation checks that f·G - g·F = q modulo x^n + 1.
+func checkEquation(t *testing.T, f, g, F, G []int8) {
+	t.Helper()
+	n := len(f)
+	for k := 0; k < n; k++ {
+		d := int64(0)
+		for i := 0; i < n; i++ {
+			j, s := k-i, int64(1)
+			if j < 0 {
+				j, s = j+n, -1
+			}
+			d += s * (int64(f[i])*int64(G[j]) - int64(g[i])*int64(F[j]))
+		}
+		want := int64(0)
+		if k == 0 {
+			want = Q
+		}
+		if d != want {
+			t.Fatalf("fG - gF = %d at %d, want %d", d, k, want)
+		}
+	}
+}
+
+func TestKeygen(t *testing.T) {
+	for _, logn := range []uint{2, 5, 9, 10} {
+		for i := 0; i < 3; i++ {
+			seed := []byte{byte(logn), byte(i)}
+			f, g, F, G, h := newKeyFromSeed(seed, logn)
+			checkEquation(t, f, g, F, G)
+
+			G2, ok := completePrivate(f, g, F)
+			if !ok {
+				t.Fatal("completePrivate failed")
+			}
+			for j := range G {
+				if G[j] != G2[j] {
+					t.Fatal("completePrivate mismatch")
+				}
+			}
+
+			// h·f = g modulo q.
+			tf := toMq(f)
+			th := make([]uint32, len(h))
+			for j, c := range h {
+				th[j] = uint32(c)
+			}
+			ntt(tf)
+			ntt(th)
+			for j := range tf {
+				th[j] = mqMul(th[j], tf[j])
+			}
+			invNTT(th)
+			tg := toMq(g)
+			for j := range tg {
+				if th[j] != tg[j] {
+					t.Fatal("h·f != g")
+				}
+			}
+		}
+	}
+}
+
+func TestBezout(t *testing.T) {
+	toBig := func(a []uint64) *big.Int {
+		ret := new(big.Int)
+		for i := len(a) - 1; i >= 0; i-- {
+			ret.Lsh(ret, 64)
+			ret.Or(ret, new(big.Int).SetUint64(a[i]))
+		}
+		return ret
+	}
+	one := big.NewInt(1)
+	for _, w := range []int{1, 2, 5} {
+		for i := 0; i < 100; i++ {
+			x := make([]uint64, w)
+			y := make([]uint64, w)
+			for _, a := range [][]uint64{x, y} {
+				b, _ := rand.Int(rand.Reader, new(big.Int).Lsh(one, uint(64*w-1)))
+				b.SetBit(b, 0, 1)
+				for j := range a {
+					a[j] = new(big.Int).Rsh(b, uint(64*j)).Uint64()
+				}
+			}
+			u := make([]uint64, w)
+			v := make([]uint64, w)
+			ok := zBezout(u, v, x, y)
+			bx, by := toBig(x), toBig(y)
+			coprime := new(big.Int).GCD(nil, nil, bx, by).Cmp(one) == 0
+			if (ok == 1) != coprime {
+				t.Fatalf("zBezout(%v, %v) = %d", bx, by, ok)
+			}
+			if ok == 1 {
+				d := new(big.Int).Mul(bx, toBig(u))
+				d.Sub(d, new(big.Int).Mul(by, toBig(v)))
+				if d.Cmp(one) != 0 {
+					t.Fatalf("x·u - y·v = %v for x = %v, y = %v", d, bx, by)
+				}
+			}
+		}
+	}
+}
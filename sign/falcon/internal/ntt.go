@@ -0,0 +1,132 @@
+package internal
+
+import "math/bits"
+
+// Arithmetic modulo q on polynomials of Z_q[x]/(x^n + 1), used for the
+// public key and verification. Coefficients are held in [0, q).
+
+// zetas[k] is ψ^rev(k), where ψ is a primitive 2048-th root of unity
+// modulo q and rev reverses the 10 bits of k. The first 2^logn entries
+// serve the NTT of degree 2^logn, as ψ^rev(k) = (ψ^2)^rev'(k) where rev'
+// reverses the 9 bits of k < 512.
+var zetas = func() (z [1024]uint32) {
+	// 11 generates the multiplicative group modulo q.
+	psi := mqPow(11, (Q-1)/2048)
+	w := uint32(1)
+	for i := 0; i < 1024; i++ {
+		z[bits.Reverse16(uint16(i))>>6] = w
+		w = mqMul(w, psi)
+	}
+	return
+}()
+
+func mqAdd(a, b uint32) uint32 { return (a + b) % Q }
+func mqSub(a, b uint32) uint32 { return (a + Q - b) % Q }
+func mqMul(a, b uint32) uint32 { return a * b % Q }
+
+// mqPow returns a^e modulo q, in time that depends only on e.
+func mqPow(a, e uint32) uint32 {
+	r := uint32(1)
+	for i := 31; i >= 0; i-- {
+		r = mqMul(r, r)
+		if (e>>uint(i))&1 == 1 {
+			r = mqMul(r, a)
+		}
+	}
+	return r
+}
+
+// ntt converts a to NTT representation in place, in bit-reversed order.
+func ntt(a []uint32) {
+	n := len(a)
+	k := 1
+	for l := n / 2; l >= 1; l >>= 1 {
+		for start := 0; start < n; start += 2 * l {
+			zeta := zetas[k]
+			k++
+			for j := start; j < start+l; j++ {
+				t := mqMul(zeta, a[j+l])
+				a[j+l] = mqSub(a[j], t)
+				a[j] = mqAdd(a[j], t)
+			}
+		}
+	}
+}
+
+// invNTT is the inverse of ntt.
+func invNTT(a []uint32) {
+	n := len(a)
+	k := n
+	for l := 1; l < n; l <<= 1 {
+		for start := 0; start < n; start += 2 * l {
+			k--
+			zeta := Q - zetas[k]
+			for j := start; j < start+l; j++ {
+				t := a[j]
+				a[j] = mqAdd(t, a[j+l])
+				a[j+l] = mqMul(zeta, mqSub(t, a[j+l]))
+			}
+		}
+	}
+	ni := mqPow(uint32(n), Q-2)
+	for i := range a {
+		a[i] = mqMul(a[i], ni)
+	}
+}
+
+// toMq returns the small polynomial f modulo q.
+func toMq(f []int8) []uint32 {
+	ret := make([]uint32, len(f))
+	for i, c := range f {
+		ret[i] = uint32(int32(c) + Q*((int32(c)>>31)&1))
+	}
+	return ret
+}
+
+// computePublic returns h = g/f modulo q, or false if f is not invertible
+// modulo q.
+func computePublic(f, g []int8) ([]uint16, bool) {
+	tf := toMq(f)
+	tg := toMq(g)
+	ntt(tf)
+	ntt(tg)
+	zero := uint32(0)
+	for i := range tf {
+		zero |= (tf[i] - 1) >> 31
+		tg[i] = mqMul(tg[i], mqPow(tf[i], Q-2))
+	}
+	if zero != 0 {
+		return nil, false
+	}
+	invNTT(tg)
+	h := make([]uint16, len(tg))
+	for i, c := range tg {
+		h[i] = uint16(c)
+	}
+	return h, true
+}
+
+// completePrivate returns G = g·F/f modulo q, or false if its coefficients
+// do not fit in [-127, 127]. f must be invertible modulo q.
+func completePrivate(f, g, F []int8) ([]int8, bool) {
+	tf := toMq(f)
+	tg := toMq(g)
+	tF := toMq(F)
+	ntt(tf)
+	ntt(tg)
+	ntt(tF)
+	for i := range tf {
+		tg[i] = mqMul(mqMul(tg[i], tF[i]), mqPow(tf[i], Q-2))
+	}
+	invNTT(tg)
+	G := make([]int8, len(tg))
+	ok := uint32(1)
+	for i, c := range tg {
+		// Map to (-q/2, q/2].
+		v := int32(c) - Q*int32(((Q/2)-c)>>31)
+		ok &= uint32(v+127)>>31 ^ 1
+		ok &= uint32(127-v)>>31 ^ 1
+		G[i] = int8(v)
+	}
+	return G, ok == 1
+}
@@ -0,0 +1,65 @@
+package internal
+
+const (
+	// Q is the modulus of Falcon.
+	Q = 12289
+
+	// SeedSize is the size of the seed from which a key pair is derived.
+	SeedSize = 48
+
+	// NonceSize is the size of the salt r hashed with the message.
+	NonceSize = 40
+
+	// RandomSize is the size of the seed of the sampler of a signature.
+	RandomSize = 48
+)
+
+// PublicKeySize returns the size of an encoded public key of degree
+// 2^logn: a header byte followed by the coefficients of h on 14 bits.
+func PublicKeySize(logn uint) int { return 1 + (14<<logn)/8 }
+
+// PrivateKeySize returns the size of an encoded private key of degree
+// 2^logn: a header byte followed by f, g and F. G is recomputed.
+func PrivateKeySize(logn uint) int {
+	return 1 + (2*fgBits(logn)<<logn)/8 + (fgBitsMax << logn / 8)
+}
+
+// SignatureSize returns the size of a signature of degree 2^logn in the
+// padded format: a header byte, the nonce and the compressed s2, padded
+// with zeros.
+func SignatureSize(logn uint) int {
+	return 44 + 3*(256>>(10-logn)) + 2*(128>>(10-logn)) +
+		3*(64>>(10-logn)) + 2*(16>>(10-logn)) -
+		2*(2>>(10-logn)) - 8*(1>>(10-logn))
+}
+
+// fgBits returns the number of bits of the coefficients of f and g in the
+// encoding of a private key.
+func fgBits(logn uint) int {
+	return [...]int{8, 8, 8, 8, 8, 8, 7, 7, 6, 6, 5}[logn]
+}
+
+// fgBitsMax is the number of bits of the coefficients of F and G in the
+// encoding of a private key.
+const fgBitsMax = 8
+
+// sqBound returns the bound on the squared norm of (s1, s2).
+func sqBound(logn uint) uint64 {
+	return [...]uint64{
+		0, 101498, 208714, 428865, 892039, 1852696, 3842630, 7959734,
+		16468416, 34034726, 70265242,
+	}[logn]
+}
+
+// Standard deviation σ of the signatures and its lower bound σ_min at the
+// leaves of the Falcon tree, for logn = 9 and 10.
+var (
+	fprInvSigma = [...]fpr{
+		9:  fprOfFloat(1 / 165.736617183),
+		10: fprOfFloat(1 / 168.388571447),
+	}
+	fprSigmaMin = [...]fpr{
+		9:  fprOfFloat(1.2778336969128335860256340575729),
+		10: fprOfFloat(1.2982803343442918539708792538826),
+	}
+)
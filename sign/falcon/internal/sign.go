@@ -0,0 +1,428 @@
+package internal
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/katzenpost/circl/internal/sha3"
+)
+
+// prng is the ChaCha20-based generator of the randomness of the sampler,
+// seeded from SHAKE256. It produces eight interleaved ChaCha20 blocks at a
+// time.
+type prng struct {
+	buf   [512]byte
+	ptr   int
+	key   [12]uint32
+	count uint64
+}
+
+func (p *prng) init(src *sha3.State) {
+	var tmp [56]byte
+	_, _ = src.Read(tmp[:])
+	for i := range p.key {
+		p.key[i] = binary.LittleEndian.Uint32(tmp[4*i:])
+	}
+	p.count = binary.LittleEndian.Uint64(tmp[48:])
+	p.refill()
+}
+
+var chachaConst = [4]uint32{0x61707865, 0x3320646e, 0x79622d32, 0x6b206574}
+
+func quarterRound(s *[16]uint32, a, b, c, d int) {
+	s[a] += s[b]
+	s[d] = bits.RotateLeft32(s[d]^s[a], 16)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], 12)
+	s[a] += s[b]
+	s[d] = bits.RotateLeft32(s[d]^s[a], 8)
+	s[c] += s[d]
+	s[b] = bits.RotateLeft32(s[b]^s[c], 7)
+}
+
+func (p *prng) refill() {
+	for u := 0; u < 8; u++ {
+		var s, init [16]uint32
+		copy(init[:4], chachaConst[:])
+		copy(init[4:], p.key[:])
+		init[14] ^= uint32(p.count)
+		init[15] ^= uint32(p.count >> 32)
+		s = init
+		for i := 0; i < 10; i++ {
+			quarterRound(&s, 0, 4, 8, 12)
+			quarterRound(&s, 1, 5, 9, 13)
+			quarterRound(&s, 2, 6, 10, 14)
+			quarterRound(&s, 3, 7, 11, 15)
+			quarterRound(&s, 0, 5, 10, 15)
+			quarterRound(&s, 1, 6, 11, 12)
+			quarterRound(&s, 2, 7, 8, 13)
+			quarterRound(&s, 3, 4, 9, 14)
+		}
+		p.count++
+
+		// Word v of block u goes at offset 4u + 32v.
+		for v := range s {
+			binary.LittleEndian.PutUint32(p.buf[u<<2+v<<5:], s[v]+init[v])
+		}
+	}
+	p.ptr = 0
+}
+
+func (p *prng) u64() uint64 {
+	if p.ptr >= len(p.buf)-9 {
+		p.refill()
+	}
+	ret := binary.LittleEndian.Uint64(p.buf[p.ptr:])
+	p.ptr += 8
+	return ret
+}
+
+func (p *prng) u8() uint32 {
+	ret := uint32(p.buf[p.ptr])
+	p.ptr++
+	if p.ptr == len(p.buf) {
+		p.refill()
+	}
+	return ret
+}
+
+// dist is the reverse cumulative distribution of the half-Gaussian of
+// standard deviation σ_max = 1.8205, scaled by 2^72 and split in three
+// 24-bit words, most significant first.
+var dist = [...]uint32{
+	10745844, 3068844, 3741698,
+	5559083, 1580863, 8248194,
+	2260429, 13669192, 2736639,
+	708981, 4421575, 10046180,
+	169348, 7122675, 4136815,
+	30538, 13063405, 7650655,
+	4132, 14505003, 7826148,
+	417, 16768101, 11363290,
+	31, 8444042, 8086568,
+	1, 12844466, 265321,
+	0, 1232676, 13644283,
+	0, 38047, 9111839,
+	0, 870, 6138264,
+	0, 14, 12545723,
+	0, 0, 3104126,
+	0, 0, 28824,
+	0, 0, 198,
+	0, 0, 1,
+}
+
+// gaussian0 samples from the half-Gaussian of standard deviation σ_max,
+// in constant time.
+func (p *prng) gaussian0() int {
+	lo := p.u64()
+	hi := p.u8()
+	v0 := uint32(lo) & 0xFFFFFF
+	v1 := uint32(lo>>24) & 0xFFFFFF
+	v2 := uint32(lo>>48) | hi<<16
+
+	z := 0
+	for u := 0; u < len(dist); u += 3 {
+		cc := (v0 - dist[u+2]) >> 31
+		cc = (v1 - dist[u+1] - cc) >> 31
+		cc = (v2 - dist[u] - cc) >> 31
+		z += int(cc)
+	}
+	return z
+}
+
+// berExp returns 1 with probability ccs·exp(-x), for x ≥ 0.
+func (p *prng) berExp(x, ccs fpr) int {
+	// x = s·log(2) + r with 0 ≤ r < log(2). s is saturated at 63, which
+	// only slightly biases very unlikely values.
+	s := uint32(fprTrunc(fprMul(x, fprInvLog2)))
+	r := fprSub(x, fprMul(fprOf(int64(s)), fprLog2))
+	s ^= (s ^ 63) & -((63 - s) >> 31)
+
+	// Compare a random 64-bit value with 2^64·ccs·exp(-x) byte by byte,
+	// stopping at the first difference.
+	z := (fprExpmP63(r, ccs)<<1 - 1) >> s
+	var w uint32
+	for i := 64; i > 0; {
+		i -= 8
+		w = p.u8() - uint32(z>>uint(i))&0xFF
+		if w != 0 {
+			break
+		}
+	}
+	return int(w >> 31)
+}
+
+// sampler holds the state of the sampler of the integer Gaussians of
+// Falcon.
+type sampler struct {
+	p        prng
+	sigmaMin fpr
+}
+
+// sample returns an integer from the discrete Gaussian of center mu and
+// standard deviation 1/isigma, which must be between σ_min and σ_max.
+func (sp *sampler) sample(mu, isigma fpr) int64 {
+	// mu = s + r with 0 ≤ r < 1.
+	s := fprFloor(mu)
+	r := fprSub(mu, fprOf(s))
+
+	dss := fprHalf(fprSqr(isigma))
+	ccs := fprMul(isigma, sp.sigmaMin)
+
+	// Rejection sampling from the bimodal half-Gaussian around r.
+	for {
+		z0 := sp.p.gaussian0()
+		b := int(sp.p.u8() & 1)
+		z := b + (b<<1-1)*z0
+
+		x := fprMul(fprSqr(fprSub(fprOf(int64(z)), r)), dss)
+		x = fprSub(x, fprMul(fprOf(int64(z0*z0)), fprInv2Sqrsigma))
+		if sp.p.berExp(x, ccs) != 0 {
+			return s + int64(z)
+		}
+	}
+}
+
+// expandedKey is the private basis B = [[g, -f], [G, -F]] in FFT
+// representation and its Falcon tree.
+type expandedKey struct {
+	logn               uint
+	b00, b01, b10, b11 []fpr
+	tree               []fpr
+}
+
+func treeSize(logn uint) int { return int(logn+1) << logn }
+
+func expandKey(logn uint, f, g, F, G []int8) *expandedKey {
+	n := 1 << logn
+	ek := &expandedKey{
+		logn: logn,
+		b00:  smallToFFT(g, logn),
+		b01:  smallToFFT(f, logn),
+		b10:  smallToFFT(G, logn),
+		b11:  smallToFFT(F, logn),
+		tree: make([]fpr, treeSize(logn)),
+	}
+	polyNeg(ek.b01)
+	polyNeg(ek.b11)
+
+	// Gram matrix B·adj(B)^T.
+	gram := func(a, b, c, d []fpr) []fpr {
+		x := append([]fpr{}, a...)
+		y := append([]fpr{}, c...)
+		polyMuladjFFT(x, b)
+		polyMuladjFFT(y, d)
+		polyAdd(x, y)
+		return x
+	}
+	g00 := gram(ek.b00, ek.b00, ek.b01, ek.b01)
+	g01 := gram(ek.b00, ek.b10, ek.b01, ek.b11)
+	g11 := gram(ek.b10, ek.b10, ek.b11, ek.b11)
+
+	ffLDL(ek.tree, g00, g01, g11, logn, make([]fpr, n))
+	normalizeTree(ek.tree, logn, logn)
+	return ek
+}
+
+// ffLDL computes the Falcon tree of the self-adjoint matrix
+// [[g00, g01], [adj(g01), g11]]: its root is the L factor of its LDL
+// decomposition, and its children the trees of the split diagonal factors.
+// It destroys g00, g01 and g11.
+func ffLDL(tree, g00, g01, g11 []fpr, logn uint, tmp []fpr) {
+	if logn == 0 {
+		tree[0] = g00[0]
+		return
+	}
+	n := 1 << logn
+	hn := n >> 1
+
+	d11 := tmp[:n]
+	polyLDLmvFFT(d11, tree[:n], g00, g01, g11)
+
+	// The split diagonal factors are quasicyclic matrices
+	// [[d0, d1], [adj(d1), d0]].
+	d00 := g00
+	polySplitFFT(g01[:hn], g01[hn:n], d00)
+	polySplitFFT(g11[:hn], g11[hn:n], d11)
+
+	tmp = tmp[:hn]
+	sub := treeSize(logn - 1)
+	ffLDL(tree[n:n+sub], g01[:hn], g01[hn:n], g01[:hn], logn-1, tmp)
+	ffLDL(tree[n+sub:], g11[:hn], g11[hn:n], g11[:hn], logn-1, tmp)
+}
+
+// normalizeTree replaces the leaves of the tree by sqrt(leaf)/σ, the
+// inverse of the standard deviation to sample with at each leaf.
+func normalizeTree(tree []fpr, origLogn, logn uint) {
+	if logn == 0 {
+		tree[0] = fprMul(fprSqrt(tree[0]), fprInvSigma[origLogn])
+		return
+	}
+	n := 1 << logn
+	sub := treeSize(logn - 1)
+	normalizeTree(tree[n:n+sub], origLogn, logn-1)
+	normalizeTree(tree[n+sub:], origLogn, logn-1)
+}
+
+// ffSampling samples (z0, z1) close to (t0, t1) with the Falcon tree,
+// using the fast Fourier sampling algorithm.
+func ffSampling(sp *sampler, z0, z1, tree, t0, t1 []fpr, logn uint, tmp []fpr) {
+	if logn == 0 {
+		leaf := tree[0]
+		z0[0] = fprOf(sp.sample(t0[0], leaf))
+		z1[0] = fprOf(sp.sample(t1[0], leaf))
+		return
+	}
+	n := 1 << logn
+	hn := n >> 1
+	sub := treeSize(logn - 1)
+	tree0 := tree[n : n+sub]
+	tree1 := tree[n+sub:]
+
+	// Sample z1 with the tree of d11.
+	polySplitFFT(z1[:hn], z1[hn:n], t1)
+	ffSampling(sp, tmp[:hn], tmp[hn:n], tree1, z1[:hn], z1[hn:n], logn-1, tmp[n:])
+	polyMergeFFT(z1, tmp[:hn], tmp[hn:n])
+
+	// Then z0 around t0 + (t1 - z1)·l10 with the tree of d00.
+	tb0 := tmp[:n]
+	copy(tb0, t1)
+	polySub(tb0, z1)
+	polyMulFFT(tb0, tree[:n])
+	polyAdd(tb0, t0)
+	polySplitFFT(z0[:hn], z0[hn:n], tb0)
+	ffSampling(sp, tmp[:hn], tmp[hn:n], tree0, z0[:hn], z0[hn:n], logn-1, tmp[n:])
+	polyMergeFFT(z0, tmp[:hn], tmp[hn:n])
+}
+
+// hashToPoint returns the hash of the nonce and the message to a
+// polynomial modulo q. It is not constant-time, which is harmless as the
+// message is public.
+func hashToPoint(nonce, msg []byte, logn uint) []uint16 {
+	h := sha3.NewShake256()
+	_, _ = h.Write(nonce)
+	_, _ = h.Write(msg)
+	c := make([]uint16, 1<<logn)
+	var buf [2]byte
+	for i := 0; i < len(c); {
+		_, _ = h.Read(buf[:])
+		w := uint32(buf[0])<<8 | uint32(buf[1])
+		// Reject values above the largest multiple of q below 2^16.
+		if w < 5*Q {
+			c[i] = uint16(w % Q)
+			i++
+		}
+	}
+	return c
+}
+
+// signAttempt samples a signature (s1, s2) of the hashed message hm and
+// returns s2, or false if it is not short enough.
+func (ek *expandedKey) signAttempt(sp *sampler, hm []uint16) ([]int16, bool) {
+	logn := ek.logn
+	n := 1 << logn
+
+	// (t0, t1) = (hm, 0)·B^-1 = (-hm·F, hm·f)/q.
+	t0 := make([]fpr, n)
+	for i, c := range hm {
+		t0[i] = fprOf(int64(c))
+	}
+	fft(t0, logn)
+	t1 := append([]fpr{}, t0...)
+	polyMulFFT(t1, ek.b01)
+	polyMulconst(t1, fprNeg(fprInverseOfQ))
+	polyMulFFT(t0, ek.b11)
+	polyMulconst(t0, fprInverseOfQ)
+
+	z0 := make([]fpr, n)
+	z1 := make([]fpr, n)
+	ffSampling(sp, z0, z1, ek.tree, t0, t1, logn, make([]fpr, 2*n))
+
+	// The lattice point v = z·B is close to (hm, 0).
+	copy(t0, z0)
+	copy(t1, z1)
+	polyMulFFT(t0, ek.b00)
+	polyMulFFT(z1, ek.b10)
+	polyAdd(t0, z1)
+	polyMulFFT(z0, ek.b01)
+	polyMulFFT(t1, ek.b11)
+	polyAdd(t1, z0)
+	ifft(t0, logn)
+	ifft(t1, logn)
+
+	// s = (hm, 0) - v.
+	var sqn, ng uint32
+	s2 := make([]int16, n)
+	for i := range hm {
+		z := int32(hm[i]) - int32(fprRint(t0[i]))
+		sqn += uint32(z * z)
+		ng |= sqn
+	}
+	for i := range s2 {
+		z := -int32(fprRint(t1[i]))
+		sqn += uint32(z * z)
+		ng |= sqn
+		s2[i] = int16(z)
+	}
+	// Saturate on overflow.
+	sqn |= -(ng >> 31)
+	return s2, uint64(sqn) <= sqBound(logn)
+}
+
+// signTo writes the signature of msg into sig, using the given nonce and
+// seeding the sampler with SHAKE256(seed).
+func (ek *expandedKey) signTo(sig, msg, nonce, seed []byte) {
+	logn := ek.logn
+	hm := hashToPoint(nonce, msg, logn)
+
+	rng := sha3.NewShake256()
+	_, _ = rng.Write(seed)
+	sp := sampler{sigmaMin: fprSigmaMin[logn]}
+
+	sig[0] = 0x30 + byte(logn)
+	copy(sig[1:1+NonceSize], nonce)
+	for {
+		sp.p.init(&rng)
+		s2, ok := ek.signAttempt(&sp, hm)
+		if ok && compEncode(sig[1+NonceSize:], s2) {
+			return
+		}
+	}
+}
+
+// verify returns whether sig is a valid signature of msg for the public
+// key h.
+func verify(h []uint16, logn uint, msg, sig []byte) bool {
+	if len(sig) != SignatureSize(logn) || sig[0] != 0x30+byte(logn) {
+		return false
+	}
+	n := 1 << logn
+	s2 := make([]int16, n)
+	if !compDecode(s2, sig[1+NonceSize:]) {
+		return false
+	}
+	c := hashToPoint(sig[1:1+NonceSize], msg, logn)
+
+	// s1 = c - s2·h modulo q.
+	t := make([]uint32, n)
+	th := make([]uint32, n)
+	for i := range s2 {
+		t[i] = uint32(int32(s2[i]) + Q*((int32(s2[i])>>31)&1))
+		th[i] = uint32(h[i])
+	}
+	ntt(t)
+	ntt(th)
+	for i := range t {
+		t[i] = mqMul(t[i], th[i])
+	}
+	invNTT(t)
+
+	var sqn uint64
+	for i := range t {
+		s1 := int64(mqSub(uint32(c[i]), t[i]))
+		if s1 > Q/2 {
+			s1 -= Q
+		}
+		sqn += uint64(s1*s1) + uint64(int64(s2[i])*int64(s2[i]))
+	}
+	return sqn <= sqBound(logn)
+}
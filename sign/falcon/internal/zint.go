@@ -0,0 +1,311 @@
+package internal
+
+import "math/bits"
+
+// The big integers of key generation are in two's complement, as
+// little-endian slices of 64-bit words of a fixed, public length. A
+// polynomial of them is the concatenation of its coefficients, all of the
+// same length. None of the functions below branch on or index memory with
+// the values of the integers.
+
+// zWords returns the number of words of an integer of the given bit length,
+// plus a sign bit.
+func zWords(bitLen int) int { return bitLen/64 + 1 }
+
+// zExtend sets d to a, sign-extended or truncated to len(d) words.
+func zExtend(d, a []uint64) {
+	s := -(a[len(a)-1] >> 63)
+	for i := range d {
+		if i < len(a) {
+			d[i] = a[i]
+		} else {
+			d[i] = s
+		}
+	}
+}
+
+// zAdd sets d to d + a, where a has len(d) words.
+func zAdd(d, a []uint64) {
+	var c uint64
+	for i := range d {
+		d[i], c = bits.Add64(d[i], a[i], c)
+	}
+}
+
+// zSub sets d to d - a, where a has len(d) words.
+func zSub(d, a []uint64) {
+	var b uint64
+	for i := range d {
+		d[i], b = bits.Sub64(d[i], a[i], b)
+	}
+}
+
+// zCondAdd sets d to d + a if ctl is 1, and leaves it unchanged if ctl is
+// 0.
+func zCondAdd(d, a []uint64, ctl uint64) {
+	m := -ctl
+	var c uint64
+	for i := range d {
+		d[i], c = bits.Add64(d[i], a[i]&m, c)
+	}
+}
+
+// zCondSub sets d to d - a if ctl is 1, and leaves it unchanged if ctl is
+// 0. It returns the borrow, seeing d and a as unsigned.
+func zCondSub(d, a []uint64, ctl uint64) uint64 {
+	m := -ctl
+	var b uint64
+	for i := range d {
+		d[i], b = bits.Sub64(d[i], a[i]&m, b)
+	}
+	return b
+}
+
+// zCondSwap swaps a and b if ctl is 1, and leaves them unchanged if ctl is
+// 0.
+func zCondSwap(a, b []uint64, ctl uint64) {
+	m := -ctl
+	for i := range a {
+		t := (a[i] ^ b[i]) & m
+		a[i] ^= t
+		b[i] ^= t
+	}
+}
+
+// zCondNeg negates d if ctl is 1, and leaves it unchanged if ctl is 0.
+func zCondNeg(d []uint64, ctl uint64) {
+	m := -ctl
+	c := ctl
+	for i := range d {
+		d[i], c = bits.Add64(d[i]^m, 0, c)
+	}
+}
+
+// zLess returns 1 if a < b and 0 otherwise, seeing a and b as unsigned.
+func zLess(a, b []uint64) uint64 {
+	var c uint64
+	for i := range a {
+		_, c = bits.Sub64(a[i], b[i], c)
+	}
+	return c
+}
+
+// zIsZero returns 1 if a is zero and 0 otherwise.
+func zIsZero(a []uint64) uint64 {
+	var o uint64
+	for _, x := range a {
+		o |= x
+	}
+	return 1 ^ (o|-o)>>63
+}
+
+// zHalve sets d to d/2, where d must be even and non-negative.
+func zHalve(d []uint64) {
+	for i := 0; i < len(d)-1; i++ {
+		d[i] = d[i]>>1 | d[i+1]<<63
+	}
+	d[len(d)-1] >>= 1
+}
+
+// zShl sets d to d·2^s modulo 2^(64·len(d)).
+func zShl(d []uint64, s int) {
+	w, b := s/64, uint(s%64)
+	for i := len(d) - 1; i >= 0; i-- {
+		var x uint64
+		if i >= w {
+			x = d[i-w] << b
+			if i > w {
+				x |= d[i-w-1] >> (64 - b)
+			}
+		}
+		d[i] = x
+	}
+}
+
+// zMul sets d to a·b modulo 2^(64·len(d)), where a and b have len(d) words
+// and do not overlap with d.
+func zMul(d, a, b []uint64) {
+	for i := range d {
+		d[i] = 0
+	}
+	for i := range a {
+		var c uint64
+		for j := 0; i+j < len(d); j++ {
+			hi, lo := bits.Mul64(a[i], b[j])
+			var cc uint64
+			lo, cc = bits.Add64(lo, c, 0)
+			hi += cc
+			d[i+j], cc = bits.Add64(d[i+j], lo, 0)
+			c = hi + cc
+		}
+	}
+}
+
+// zMulSmall sets d to a·k modulo 2^(64·len(d)), where a has len(d) words.
+func zMulSmall(d, a []uint64, k int64) {
+	s := uint64(k) >> 63
+	uk := (uint64(k) ^ -s) + s
+	var c uint64
+	for i := range d {
+		hi, lo := bits.Mul64(a[i], uk)
+		var cc uint64
+		d[i], cc = bits.Add64(lo, c, 0)
+		c = hi + cc
+	}
+	zCondNeg(d, s)
+}
+
+// zToFpr returns an approximation of a·2^sc, which ignores the words of a
+// below lo.
+func zToFpr(a []uint64, lo, sc int, tmp []uint64) fpr {
+	s := a[len(a)-1] >> 63
+	t := tmp[:len(a)]
+	copy(t, a)
+	zCondNeg(t, s)
+	x := fprZero
+	for i := lo; i < len(t); i++ {
+		x = fprAdd(x, fprScaled(int64(t[i]&0xFFFFFFFF), 64*i+sc))
+		x = fprAdd(x, fprScaled(int64(t[i]>>32), 64*i+32+sc))
+	}
+	return x ^ fpr(s<<63)
+}
+
+// zPolyMul sets d to a·b modulo x^n + 1, where the coefficients of d, a and
+// b have wd, wa and wb words. Those of d are computed modulo 2^(64·wd).
+func zPolyMul(d []uint64, wd int, a []uint64, wa int, b []uint64, wb int, n int) {
+	for i := range d[:n*wd] {
+		d[i] = 0
+	}
+
+	// Modulo 2^64, the products only depend on the low words.
+	if wd == 1 {
+		for i := 0; i < n; i++ {
+			x := a[i*wa]
+			for j := 0; j < n-i; j++ {
+				d[i+j] += x * b[j*wb]
+			}
+			for j := n - i; j < n; j++ {
+				d[i+j-n] -= x * b[j*wb]
+			}
+		}
+		return
+	}
+
+	ea := make([]uint64, n*wd)
+	eb := make([]uint64, n*wd)
+	for i := 0; i < n; i++ {
+		zExtend(ea[i*wd:(i+1)*wd], a[i*wa:(i+1)*wa])
+		zExtend(eb[i*wd:(i+1)*wd], b[i*wb:(i+1)*wb])
+	}
+	t := make([]uint64, wd)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			zMul(t, ea[i*wd:(i+1)*wd], eb[j*wd:(j+1)*wd])
+			if k := i + j; k < n {
+				zAdd(d[k*wd:(k+1)*wd], t)
+			} else {
+				zSub(d[(k-n)*wd:(k-n+1)*wd], t)
+			}
+		}
+	}
+}
+
+// zPolySubScaledMul sets d to d - (a·k)·2^s modulo x^n + 1, where the
+// coefficients of d and a have wd and wa words, and those of k are below
+// 2^40 in absolute value. Those of d are computed modulo 2^(64·wd).
+func zPolySubScaledMul(d []uint64, wd int, a []uint64, wa int, k []int64, s int, n int) {
+	if wd == 1 {
+		for i := 0; i < n; i++ {
+			x := uint64(k[i]) << uint(s)
+			for j := 0; j < n-i; j++ {
+				d[i+j] -= x * a[j*wa]
+			}
+			for j := n - i; j < n; j++ {
+				d[i+j-n] += x * a[j*wa]
+			}
+		}
+		return
+	}
+
+	// The sums of the products fit on one more word than a, since the
+	// coefficients of k are below 2^40.
+	we := wa + 1
+	ea := make([]uint64, n*we)
+	for i := 0; i < n; i++ {
+		zExtend(ea[i*we:(i+1)*we], a[i*wa:(i+1)*wa])
+	}
+	acc := make([]uint64, we)
+	t := make([]uint64, we)
+	sd := make([]uint64, wd)
+	for j := 0; j < n; j++ {
+		for i := range acc {
+			acc[i] = 0
+		}
+		for i := 0; i < n; i++ {
+			if i <= j {
+				zMulSmall(t, ea[(j-i)*we:(j-i+1)*we], k[i])
+				zAdd(acc, t)
+			} else {
+				zMulSmall(t, ea[(j-i+n)*we:(j-i+n+1)*we], k[i])
+				zSub(acc, t)
+			}
+		}
+		zExtend(sd, acc)
+		zShl(sd, s)
+		zSub(d[j*wd:(j+1)*wd], sd)
+	}
+}
+
+// zBezout sets u and v such that x·u - y·v = 1 and returns 1, or returns 0
+// if x and y are not coprime. x and y must be odd and positive. All have
+// the same number of words, and u and v are non-negative.
+//
+// This is the binary extended GCD: it keeps a = x·u0 - y·v0 and
+// b = x·u1 - y·v1, where u0 and u1 are only known modulo y and v0 and v1
+// modulo x, so that halving them is possible. Each iteration subtracts the
+// smaller of a and b from the larger if both are odd, and halves the even
+// one, which reduces their total bit length by one until one is zero. If
+// the other is then 1, x·u - y·v is 1 modulo xy, and in (-xy, xy) it can
+// only be 1.
+func zBezout(u, v, x, y []uint64) uint64 {
+	w := len(x)
+	a := append([]uint64(nil), x...)
+	b := append([]uint64(nil), y...)
+	u0 := make([]uint64, w)
+	v0 := make([]uint64, w)
+	u1 := make([]uint64, w)
+	v1 := append([]uint64(nil), x...)
+	u0[0] = 1
+	v1[0]--
+
+	for it := 0; it < 2*(64*w-1); it++ {
+		odd := a[0] & b[0] & 1
+		sw := odd & zLess(a, b)
+		zCondSwap(a, b, sw)
+		zCondSwap(u0, u1, sw)
+		zCondSwap(v0, v1, sw)
+		zCondSub(a, b, odd)
+		zCondAdd(u0, y, zCondSub(u0, u1, odd))
+		zCondAdd(v0, x, zCondSub(v0, v1, odd))
+
+		sw = a[0] & 1
+		zCondSwap(a, b, sw)
+		zCondSwap(u0, u1, sw)
+		zCondSwap(v0, v1, sw)
+		zHalve(a)
+		zCondAdd(u0, y, u0[0]&1)
+		zHalve(u0)
+		zCondAdd(v0, x, v0[0]&1)
+		zHalve(v0)
+	}
+
+	// One of a and b is zero, and the other one is their GCD.
+	z := zIsZero(a)
+	zCondSwap(a, b, z)
+	zCondSwap(u0, u1, z)
+	zCondSwap(v0, v1, z)
+	copy(u, u0)
+	copy(v, v0)
+	a[0] ^= 1
+	return zIsZero(a)
+}
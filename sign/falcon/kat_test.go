@@ -0,0 +1,210 @@
+package falcon_test
+
+// Code to generate test vectors in the format of the NIST "PQCsignKAT"
+// files. See PQCsignKAT_sign.c and randombytes.c in the reference
+// implementation.
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/nist"
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/falcon/falcon1024"
+	"github.com/katzenpost/circl/sign/falcon/falcon512"
+)
+
+// drbg reads from the NIST DRBG, as randombytes does.
+type drbg struct{ g nist.DRBG }
+
+func (d *drbg) Read(p []byte) (int, error) {
+	d.g.Fill(p)
+	return len(p), nil
+}
+
+func TestPQCgenKATSign(t *testing.T) {
+	// As the keys of this implementation differ from those of the reference
+	// implementation in F and G, which are not unique, and signatures use
+	// the padded format rather than the one of the NIST API, these were
+	// generated by this implementation and only guard against regressions.
+	// TestPQCsignKAT checks the KAT files of the reference implementation.
+	for _, tc := range []struct {
+		scheme sign.Scheme
+		want   string
+	}{
+		{falcon512.Scheme(), "90893d2842f97abdbee7c90638a0db6a46c2b556afe8319f4f2ad807be8c3be3"},
+		{falcon1024.Scheme(), "86df116e5702357023f0b7e0474c98df24fc2fda57048bbb97206dc81c5d3613"},
+	} {
+		t.Run(tc.scheme.Name(), func(t *testing.T) {
+			mode := tc.scheme
+
+			var seed [48]byte
+			var eseed [48]byte
+			for i := 0; i < 48; i++ {
+				seed[i] = byte(i)
+			}
+			f := sha256.New()
+			g := nist.NewDRBG(&seed)
+			fmt.Fprintf(f, "# %s\n\n", mode.Name())
+			for i := 0; i < 10; i++ {
+				mlen := 33 * (i + 1)
+				g.Fill(seed[:])
+				msg := make([]byte, mlen)
+				g.Fill(msg[:])
+
+				fmt.Fprintf(f, "count = %d\n", i)
+				fmt.Fprintf(f, "seed = %X\n", seed)
+				fmt.Fprintf(f, "mlen = %d\n", mlen)
+				fmt.Fprintf(f, "msg = %X\n", msg)
+
+				g2 := &drbg{nist.NewDRBG(&seed)}
+				g2.g.Fill(eseed[:])
+				pk, sk := mode.DeriveKey(eseed[:])
+
+				ppk, err := pk.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				psk, err := sk.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				fmt.Fprintf(f, "pk = %X\n", ppk)
+				fmt.Fprintf(f, "sk = %X\n", psk)
+				fmt.Fprintf(f, "smlen = %d\n", mlen+mode.SignatureSize())
+
+				// The nonce and the seed of the sampler are read from
+				// the DRBG after the seed of the key pair.
+				sig, err := sk.(crypto.Signer).Sign(g2, msg, crypto.Hash(0))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				fmt.Fprintf(f, "sm = %X%X\n\n", sig, msg)
+
+				if !mode.Verify(pk, msg[:], sig, nil) {
+					t.Fatal()
+				}
+			}
+			if got := fmt.Sprintf("%x", f.Sum(nil)); got != tc.want {
+				t.Fatal(got)
+			}
+		})
+	}
+}
+
+// readRsp returns the records of a NIST .rsp file as maps from the names
+// to the decoded values.
+func readRsp(path string) ([]map[string][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []map[string][]byte
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		key, value, ok := strings.Cut(s.Text(), " = ")
+		if !ok {
+			continue
+		}
+		if key == "count" {
+			records = append(records, make(map[string][]byte))
+			continue
+		}
+		if len(records) == 0 {
+			return nil, errors.New("falcon: malformed .rsp file")
+		}
+		if key == "mlen" || key == "smlen" {
+			continue
+		}
+		b, err := hex.DecodeString(value)
+		if err != nil {
+			return nil, err
+		}
+		records[len(records)-1][key] = b
+	}
+	return records, s.Err()
+}
+
+// TestPQCsignKAT checks the public keys of the KAT files of the reference
+// implementation, and verifies their signatures after converting them to
+// the padded format. See testdata/README.md.
+func TestPQCsignKAT(t *testing.T) {
+	for _, tc := range []struct {
+		scheme sign.Scheme
+		logn   byte
+		file   string
+	}{
+		{falcon512.Scheme(), 9, "testdata/PQCsignKAT_1281.rsp"},
+		{falcon1024.Scheme(), 10, "testdata/PQCsignKAT_2305.rsp"},
+	} {
+		t.Run(tc.scheme.Name(), func(t *testing.T) {
+			records, err := readRsp(tc.file)
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Skipf("missing %s, see testdata/README.md", tc.file)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) == 0 {
+				t.Fatal("no records")
+			}
+			mode := tc.scheme
+			for i, r := range records {
+				var seed, eseed [48]byte
+				copy(seed[:], r["seed"])
+				g := nist.NewDRBG(&seed)
+				g.Fill(eseed[:])
+				pk, _ := mode.DeriveKey(eseed[:])
+				ppk, err := pk.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(ppk, r["pk"]) {
+					t.Fatalf("count=%d: public key differs", i)
+				}
+
+				// sm is the length of the compressed signature on two
+				// bytes, the nonce, the message and the compressed
+				// signature, whose header is 0x20 + logn.
+				msg, sm := r["msg"], r["sm"]
+				if len(sm) < 2+40+len(msg)+1 {
+					t.Fatalf("count=%d: malformed sm", i)
+				}
+				esig := sm[2+40+len(msg):]
+				if int(sm[0])<<8|int(sm[1]) != len(esig) ||
+					esig[0] != 0x20+tc.logn ||
+					!bytes.Equal(sm[2+40:2+40+len(msg)], msg) {
+					t.Fatalf("count=%d: malformed sm", i)
+				}
+				sig := make([]byte, mode.SignatureSize())
+				if 1+40+len(esig)-1 > len(sig) {
+					t.Fatalf("count=%d: signature does not fit", i)
+				}
+				sig[0] = 0x30 + tc.logn
+				copy(sig[1:], sm[2:2+40])
+				copy(sig[1+40:], esig[1:])
+				if !mode.Verify(pk, msg, sig, nil) {
+					t.Fatalf("count=%d: verification failed", i)
+				}
+				sig[1] ^= 1
+				if mode.Verify(pk, msg, sig, nil) {
+					t.Fatalf("count=%d: verified corrupted signature", i)
+				}
+			}
+		})
+	}
+}
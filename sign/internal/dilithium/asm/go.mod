@@ -1,4 +1,4 @@
-module github.com/cloudflare/circl/sign/dilithium/internal/common/asm
+module github.com/cloudflare/circl/sign/internal/dilithium/asm
 
 go 1.19
 
@@ -13,4 +13,4 @@ require (
 	golang.org/x/tools v0.9.1 // indirect
 )
 
-replace github.com/cloudflare/circl => ../../../../../
+replace github.com/cloudflare/circl => ../../../../
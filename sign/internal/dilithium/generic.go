@@ -1,7 +1,7 @@
 //go:build !amd64
 // +build !amd64
 
-package common
+package dilithium
 
 // Execute an in-place forward NTT on as.
 //
@@ -1,7 +1,7 @@
-package common
+package dilithium
 
 import (
-	"github.com/katzenpost/circl/sign/dilithium/internal/common/params"
+	"github.com/katzenpost/circl/sign/internal/dilithium/params"
 )
 
 const (
@@ -0,0 +1,39 @@
+package dilithium
+
+import "crypto"
+
+// PreHashOID returns the DER encoding of the object identifier of the hash
+// function h as used in HashML-DSA, or nil if h is not approved for it.
+//
+// FIPS 204 also approves SHAKE128 and SHAKE256 (arcs 11 and 12), but
+// crypto.Hash has no identifiers for them, so they are not supported.
+func PreHashOID(h crypto.Hash) []byte {
+	var id byte
+	switch h {
+	case crypto.SHA256:
+		id = 1
+	case crypto.SHA384:
+		id = 2
+	case crypto.SHA512:
+		id = 3
+	case crypto.SHA224:
+		id = 4
+	case crypto.SHA512_224:
+		id = 5
+	case crypto.SHA512_256:
+		id = 6
+	case crypto.SHA3_224:
+		id = 7
+	case crypto.SHA3_256:
+		id = 8
+	case crypto.SHA3_384:
+		id = 9
+	case crypto.SHA3_512:
+		id = 10
+	default:
+		return nil
+	}
+	return []byte{
+		0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, id,
+	}
+}
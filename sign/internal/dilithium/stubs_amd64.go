@@ -2,7 +2,7 @@
 
 //go:build amd64
 
-package common
+package dilithium
 
 //go:noescape
 func nttAVX2(p *[256]uint32)
@@ -0,0 +1,38 @@
+// Package lms implements the stateful hash-based signature schemes LMS and
+// HSS as defined in RFC 8554.
+//
+// https://www.rfc-editor.org/rfc/rfc8554
+//
+// All LMS and LM-OTS parameter sets of RFC 8554 are supported, in up to
+// eight levels of HSS. A single level of HSS is LMS, except for the four
+// bytes in front of the public key and signature encodings. The private
+// values of the one-time keys, the randomizers of the LM-OTS signatures,
+// and the keys of the trees below the top level are derived with the
+// pseudorandom function of Appendix A of RFC 8554, using the indices
+// 0xFFFD, 0xFFFE and 0xFFFF for the randomizer, the SEED and the I of the
+// key of the child tree below a leaf respectively.
+//
+// A private key can make 2^(h_0 + … + h_{L-1}) signatures, each with its
+// own one-time key of the bottom level. Using a one-time key twice breaks
+// the scheme, so signing requires a stateful.Store holding the index of
+// the next unused one-time key:
+//
+//	levels := []lms.Level{
+//		{lms.LMS_SHA256_M32_H10, lms.LMOTS_SHA256_N32_W4},
+//		{lms.LMS_SHA256_M32_H10, lms.LMOTS_SHA256_N32_W4},
+//	}
+//	pk, sk, err := lms.GenerateKey(nil, levels)
+//	store, err := stateful.CreateFileStore("key.state", 0)
+//	sk.SetStore(store)
+//	err = lms.SignTo(sk, msg, sig)
+//
+// The index is committed to the Store before a signature is computed. If
+// the commit fails, signing fails too, so that a crash can skip one-time
+// keys but never reuse them.
+//
+// Verification does not need any state, but requires all levels of a
+// signature to have the parameter sets of the public key. The parameter
+// sets can be used with the generic signatures API through NewScheme. They
+// are not registered in sign/schemes, as their private keys cannot be used
+// like stateless ones.
+package lms
@@ -0,0 +1,327 @@
+package lms
+
+import (
+	"bytes"
+	"crypto"
+	cryptoRand "crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/stateful"
+)
+
+var (
+	// ErrParams is returned when an invalid HSS parameter set is used.
+	ErrParams = errors.New("lms: invalid parameters")
+
+	// ErrHashedMessage is returned by PrivateKey.Sign if it is asked to
+	// sign a hashed message.
+	ErrHashedMessage = errors.New("lms: cannot sign hashed message")
+)
+
+// PublicKey is the type of HSS public keys.
+type PublicKey struct {
+	levels []Level
+	pub    []byte // LMS public key of the top level
+}
+
+// PrivateKey is the type of HSS private keys.
+//
+// The keys of the trees below the top level are derived from their parent
+// key and the leaf that signs them. The state of the private key thus
+// reduces to a single counter, the index of the next unused one-time key
+// of the bottom level, which is kept in the Store attached with SetStore.
+// A PrivateKey is safe for concurrent use.
+type PrivateKey struct {
+	levels []Level
+	top    key
+	root   []byte
+
+	mu    sync.Mutex
+	store stateful.Store
+	cache []treeCache // one per level
+}
+
+// GenerateKey generates a key pair of the HSS parameter set levels, whose
+// first entry is the top level, using entropy from rand. If rand is nil,
+// crypto/rand.Reader will be used.
+//
+// This computes the tree of the top level, which takes a long time for
+// trees of height 20 and above. The private key has no Store attached.
+func GenerateKey(rand io.Reader, levels []Level) (*PublicKey, *PrivateKey, error) {
+	if err := checkLevels(levels); err != nil {
+		return nil, nil, err
+	}
+	if rand == nil {
+		rand = cryptoRand.Reader
+	}
+	seed := make([]byte, seedSize)
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+	return NewKeyFromSeed(levels, seed)
+}
+
+// NewKeyFromSeed derives a key pair of the HSS parameter set levels from
+// seed, which is the concatenation SEED ‖ I of the private key of the top
+// level. Panics if seed is not of length 48; see Scheme.SeedSize().
+//
+// The private key has no Store attached. Deriving the same key twice and
+// signing with both copies reuses one-time keys, unless they share the
+// same Store.
+func NewKeyFromSeed(levels []Level, seed []byte) (*PublicKey, *PrivateKey, error) {
+	if err := checkLevels(levels); err != nil {
+		return nil, nil, err
+	}
+	if len(seed) != seedSize {
+		panic(sign.ErrSeedSize)
+	}
+
+	sk := &PrivateKey{
+		levels: append([]Level(nil), levels...),
+		top:    key{Level: levels[0]},
+		cache:  make([]treeCache, len(levels)),
+	}
+	copy(sk.top.seed[:], seed[:n])
+	copy(sk.top.id[:], seed[n:])
+
+	top := &sk.cache[0]
+	top.fill(&sk.top, 0)
+	sk.root = append([]byte(nil), top.root()...)
+
+	return sk.public(), sk, nil
+}
+
+// SetStore attaches the Store holding the index of the next unused
+// one-time key to sk. Signing fails until a Store is attached.
+func (sk *PrivateKey) SetStore(store stateful.Store) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	sk.store = store
+}
+
+// Remaining returns the number of signatures sk can still make, according
+// to its Store.
+func (sk *PrivateKey) Remaining() (uint64, error) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	if sk.store == nil {
+		return 0, stateful.ErrNoStore
+	}
+	next, err := sk.store.Load()
+	if err != nil {
+		return 0, err
+	}
+	max := signatures(sk.levels)
+	if next >= max {
+		return 0, nil
+	}
+	return max - next, nil
+}
+
+// SignTo signs the given message and writes the signature into sig. It will
+// panic if sig is not of length Scheme().SignatureSize().
+//
+// The index of the one-time key is reserved in the Store of sk before
+// signing. Errors, and does not sign, if no Store is attached, if the
+// Store fails to commit the next index, or if sk is exhausted.
+func SignTo(sk *PrivateKey, msg, sig []byte) error {
+	if len(sig) != signatureSize(sk.levels) {
+		panic("lms: wrong signature size")
+	}
+
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+
+	idx, err := stateful.Reserve(sk.store, signatures(sk.levels))
+	if err != nil {
+		return err
+	}
+	sk.signInternal(sig, msg, idx)
+	return nil
+}
+
+// signInternal writes the HSS signature of msg with the one-time key idx
+// of the bottom level into sig, see Algorithm 8 of RFC 8554. Must be
+// called with sk.mu held.
+func (sk *PrivateKey) signInternal(sig, msg []byte, idx uint64) {
+	L := len(sk.levels)
+	if sk.cache == nil {
+		sk.cache = make([]treeCache, L)
+	}
+
+	// Split idx into the leaves q[i] used in each level, starting from the
+	// bottom. The bits of idx above those of a level select its tree.
+	q := make([]uint32, L)
+	prefix := make([]uint64, L)
+	rest := idx
+	for i := L - 1; i >= 0; i-- {
+		h := sk.levels[i].LMS.params().h
+		q[i] = uint32(rest & (1<<h - 1))
+		rest >>= h
+		prefix[i] = rest
+	}
+
+	// Update the trees whose prefix changed from the top down, as the key
+	// of a tree is derived from its parent.
+	if sk.cache[0].key == nil {
+		sk.cache[0].fill(&sk.top, 0)
+	}
+	for i := 1; i < L; i++ {
+		c := &sk.cache[i]
+		if c.key == nil || c.prefix != prefix[i] {
+			c.fill(sk.cache[i-1].child(sk.levels[i], q[i-1]), prefix[i])
+		}
+	}
+
+	binary.BigEndian.PutUint32(sig, uint32(L-1))
+	sig = sig[4:]
+	for i := 0; i < L-1; i++ {
+		c := &sk.cache[i]
+		size := sk.levels[i].lmsSigSize()
+		if !c.hasSig || c.sigQ != q[i] {
+			if c.sig == nil {
+				c.sig = make([]byte, size)
+			}
+			c.lmsSign(c.sig, q[i], sk.cache[i+1].pub)
+			c.sigQ = q[i]
+			c.hasSig = true
+		}
+		copy(sig, c.sig)
+		copy(sig[size:], sk.cache[i+1].pub)
+		sig = sig[size+lmsPublicKeySize:]
+	}
+	sk.cache[L-1].lmsSign(sig, q[L-1], msg)
+}
+
+// Verify checks whether the given signature by pk on msg is valid, see
+// Algorithm 7 of RFC 8554. The levels of the signature must have the
+// parameter sets of pk.
+func Verify(pk *PublicKey, msg, sig []byte) bool {
+	L := len(pk.levels)
+	if len(sig) != signatureSize(pk.levels) ||
+		int(binary.BigEndian.Uint32(sig)) != L-1 {
+		return false
+	}
+	sig = sig[4:]
+	pub := pk.pub
+	for i := 0; i < L-1; i++ {
+		size := pk.levels[i].lmsSigSize()
+		next := sig[size : size+lmsPublicKeySize]
+		if LMSType(binary.BigEndian.Uint32(next)) != pk.levels[i+1].LMS ||
+			OTSType(binary.BigEndian.Uint32(next[4:])) != pk.levels[i+1].OTS ||
+			!lmsVerify(pub, next, sig[:size]) {
+			return false
+		}
+		pub = next
+		sig = sig[size+lmsPublicKeySize:]
+	}
+	return lmsVerify(pub, msg, sig)
+}
+
+// Levels returns the parameter sets of the levels of the key, starting
+// with the top level.
+func (pk *PublicKey) Levels() []Level {
+	return append([]Level(nil), pk.levels...)
+}
+
+// Levels returns the parameter sets of the levels of the key, starting
+// with the top level.
+func (sk *PrivateKey) Levels() []Level {
+	return append([]Level(nil), sk.levels...)
+}
+
+// MarshalBinary returns u32str(L) ‖ pub[0].
+func (pk *PublicKey) MarshalBinary() ([]byte, error) {
+	ret := make([]byte, 4, publicKeySize)
+	binary.BigEndian.PutUint32(ret, uint32(len(pk.levels)))
+	return append(ret, pk.pub...), nil
+}
+
+// MarshalBinary returns u32str(L) ‖ (u32str(type) ‖ u32str(otstype))^L ‖
+// I ‖ SEED ‖ T[1], where I, SEED and T[1] belong to the top level. The
+// index of the next unused one-time key is not included.
+func (sk *PrivateKey) MarshalBinary() ([]byte, error) {
+	ret := make([]byte, 4+8*len(sk.levels), privateKeySize(sk.levels))
+	binary.BigEndian.PutUint32(ret, uint32(len(sk.levels)))
+	for i, l := range sk.levels {
+		binary.BigEndian.PutUint32(ret[4+8*i:], uint32(l.LMS))
+		binary.BigEndian.PutUint32(ret[8+8*i:], uint32(l.OTS))
+	}
+	ret = append(ret, sk.top.id[:]...)
+	ret = append(ret, sk.top.seed[:]...)
+	return append(ret, sk.root...), nil
+}
+
+func (sk *PrivateKey) public() *PublicKey {
+	pub := make([]byte, lmsPublicKeySize)
+	binary.BigEndian.PutUint32(pub, uint32(sk.top.LMS))
+	binary.BigEndian.PutUint32(pub[4:], uint32(sk.top.OTS))
+	copy(pub[8:], sk.top.id[:])
+	copy(pub[8+idSize:], sk.root)
+	return &PublicKey{levels: sk.levels, pub: pub}
+}
+
+// Public returns the public key corresponding to sk.
+func (sk *PrivateKey) Public() crypto.PublicKey { return sk.public() }
+
+// Equal returns whether the two public keys are equal.
+func (pk *PublicKey) Equal(other crypto.PublicKey) bool {
+	castOther, ok := other.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return levelsEqual(pk.levels, castOther.levels) &&
+		bytes.Equal(pk.pub, castOther.pub)
+}
+
+// Equal returns whether the two private keys are equal. The attached
+// Stores are not compared.
+func (sk *PrivateKey) Equal(other crypto.PrivateKey) bool {
+	castOther, ok := other.(*PrivateKey)
+	if !ok {
+		return false
+	}
+	a, _ := sk.MarshalBinary()
+	b, _ := castOther.MarshalBinary()
+	return len(a) == len(b) && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+func levelsEqual(a, b []Level) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Sign signs the given message.
+//
+// opts.HashFunc() must return zero, which can be achieved by passing
+// crypto.Hash(0) for opts. rand is ignored, as the randomizers of the
+// LM-OTS signatures are derived from the private key. Errors as SignTo
+// does.
+//
+// This function is used to make PrivateKey implement the crypto.Signer
+// interface.  The package-level SignTo function might be more convenient
+// to use.
+func (sk *PrivateKey) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts) (
+	signature []byte, err error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, ErrHashedMessage
+	}
+
+	sig := make([]byte, signatureSize(sk.levels))
+	if err := SignTo(sk, msg, sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
@@ -0,0 +1,441 @@
+package lms
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/stateful"
+)
+
+func TestParams(t *testing.T) {
+	// Sizes of Tables 1 and 2 of RFC 8554.
+	for typ, want := range map[OTSType]int{
+		LMOTS_SHA256_N32_W1: 8516, LMOTS_SHA256_N32_W2: 4292,
+		LMOTS_SHA256_N32_W4: 2180, LMOTS_SHA256_N32_W8: 1124,
+	} {
+		if got := typ.params().sigSize(); got != want {
+			t.Fatalf("%v: signature size %d, want %d", typ, got, want)
+		}
+	}
+	if got := (Level{LMS_SHA256_M32_H10, LMOTS_SHA256_N32_W4}).lmsSigSize(); got != 2508 {
+		t.Fatalf("LMS signature size %d", got)
+	}
+
+	for _, levels := range [][]Level{
+		nil,
+		{{LMS_SHA256_M32_H5, 0}},
+		{{LMS_SHA256_M32_H25 + 1, LMOTS_SHA256_N32_W1}},
+		make([]Level, maxLevels+1),
+		{
+			{LMS_SHA256_M32_H25, LMOTS_SHA256_N32_W8},
+			{LMS_SHA256_M32_H20, LMOTS_SHA256_N32_W8},
+			{LMS_SHA256_M32_H20, LMOTS_SHA256_N32_W8},
+		},
+	} {
+		if _, err := NewScheme(levels); err != ErrParams {
+			t.Fatalf("accepted %v", levels)
+		}
+	}
+}
+
+// Regression values for the key derived from the seed 0, 1, …, 47 and the
+// signature of "message" with the given one-time key. They were computed
+// with this package; TestRFC8554 checks the test cases of the RFC.
+func TestRegression(t *testing.T) {
+	const (
+		H5  = LMS_SHA256_M32_H5
+		H10 = LMS_SHA256_M32_H10
+		W1  = LMOTS_SHA256_N32_W1
+		W2  = LMOTS_SHA256_N32_W2
+		W4  = LMOTS_SHA256_N32_W4
+		W8  = LMOTS_SHA256_N32_W8
+	)
+	for _, tc := range []struct {
+		levels []Level
+		idx    uint64
+		pk     string
+		sig    string
+	}{
+		{[]Level{{H5, W8}}, 31, "000000010000000500000004202122232425262728292a2b2c2d2e2fd424fb3cc1f2db618cbb3254a2812f2005f8929f49b78fbfdd7e6b68641cceeb", "aacba5552be9774245c7df4d8cd123e62b895f569f23cfb89b533f507df1627a"},
+		{[]Level{{H10, W8}}, 7, "000000010000000600000004202122232425262728292a2b2c2d2e2f87e7ba11006df29e44f8d303b2eb3a9eb2a9cdc96ace306ac6de8f12210e0a8f", "342a286a4bc5afac4973f7f825842687cdedf9f03ffed2df2ca3306466411d05"},
+		{[]Level{{H5, W8}, {H5, W8}}, 0x25, "000000020000000500000004202122232425262728292a2b2c2d2e2fd424fb3cc1f2db618cbb3254a2812f2005f8929f49b78fbfdd7e6b68641cceeb", "03819793d93ac10623d492ac49f667fdd001f4e8a7d793e38a1c076310f7bf0e"},
+		{[]Level{{H10, W4}, {H5, W2}}, 0x7d11, "000000020000000600000003202122232425262728292a2b2c2d2e2f298f2f7d1c20da4115e878cc365dd2cb6981df89ffab493d49181e3cda0c602c", "b82fc81595a9344200e5a136ac4dab5ba5c95fcfa2ce19d2f1a8084cea6c8e8d"},
+		{[]Level{{H5, W1}, {H5, W4}, {H5, W2}}, 0x3e0, "000000030000000500000001202122232425262728292a2b2c2d2e2fc0a952b90f5ed4919efa9a467a432df45d56193ea9b6174f6d4f3316e8104359", "68d93ef15fbf6ff9178a2c718abe364a695ca7d191fd7b7074f3f45a33879e2c"},
+	} {
+		scheme, err := NewScheme(tc.levels)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Run(scheme.Name(), func(t *testing.T) {
+			seed := make([]byte, scheme.SeedSize())
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+			pk, sk := scheme.DeriveKey(seed)
+			ppk, _ := pk.MarshalBinary()
+			if got := hex.EncodeToString(ppk); got != tc.pk {
+				t.Fatalf("public key %s, want %s", got, tc.pk)
+			}
+
+			sk.(*PrivateKey).SetStore(stateful.NewMemoryStore(tc.idx))
+			msg := []byte("message")
+			sig := scheme.Sign(sk, msg, nil)
+			h := sha256.Sum256(sig)
+			if got := hex.EncodeToString(h[:]); got != tc.sig {
+				t.Fatalf("signature %s, want %s", got, tc.sig)
+			}
+			if !scheme.Verify(pk, msg, sig, nil) {
+				t.Fatal("verification failed")
+			}
+			if scheme.Verify(pk, []byte("massage"), sig, nil) {
+				t.Fatal("verified wrong message")
+			}
+			for _, i := range []int{0, 7, 40, len(sig) - 1} {
+				sig[i] ^= 1
+				if scheme.Verify(pk, msg, sig, nil) {
+					t.Fatalf("verified signature corrupted at %d", i)
+				}
+				sig[i] ^= 1
+			}
+			if scheme.Verify(pk, msg, sig[:len(sig)-1], nil) {
+				t.Fatal("verified truncated signature")
+			}
+		})
+	}
+}
+
+// levelsOf returns the parameter sets of the levels of an HSS public key
+// and a signature by it, or nil if they are malformed.
+func levelsOf(pk, sig []byte) []Level {
+	if len(pk) != publicKeySize || len(sig) < 4 {
+		return nil
+	}
+	L := int(binary.BigEndian.Uint32(pk))
+	if L == 0 || L > maxLevels || int(binary.BigEndian.Uint32(sig)) != L-1 {
+		return nil
+	}
+	levels := []Level{{
+		LMSType(binary.BigEndian.Uint32(pk[4:])),
+		OTSType(binary.BigEndian.Uint32(pk[8:])),
+	}}
+	off := 4
+	for i := 0; i < L-1; i++ {
+		if checkLevels(levels) != nil {
+			return nil
+		}
+		off += levels[i].lmsSigSize()
+		if len(sig) < off+lmsPublicKeySize {
+			return nil
+		}
+		levels = append(levels, Level{
+			LMSType(binary.BigEndian.Uint32(sig[off:])),
+			OTSType(binary.BigEndian.Uint32(sig[off+4:])),
+		})
+		off += lmsPublicKeySize
+	}
+	if checkLevels(levels) != nil {
+		return nil
+	}
+	return levels
+}
+
+// TestRFC8554 checks the HSS test cases of Appendix F of RFC 8554, see
+// testdata/README.md.
+func TestRFC8554(t *testing.T) {
+	buf, err := os.ReadFile("testdata/rfc8554.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("missing testdata/rfc8554.json, see testdata/README.md")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []struct {
+		Name      string   `json:"name"`
+		PublicKey hexBytes `json:"publicKey"`
+		Message   hexBytes `json:"message"`
+		Signature hexBytes `json:"signature"`
+	}
+	if err := json.Unmarshal(buf, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no test cases")
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			levels := levelsOf(v.PublicKey, v.Signature)
+			if levels == nil {
+				t.Fatal("malformed test case")
+			}
+			scheme, err := NewScheme(levels)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pk, err := scheme.UnmarshalBinaryPublicKey(v.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !Verify(pk.(*PublicKey), v.Message, v.Signature) {
+				t.Fatal("verification failed")
+			}
+			sig := append([]byte(nil), v.Signature...)
+			sig[len(sig)-1] ^= 1
+			if Verify(pk.(*PublicKey), v.Message, sig) {
+				t.Fatal("verified corrupted signature")
+			}
+		})
+	}
+}
+
+// TestRFC8554KeyGen derives the HSS public key of Test Case 2 of Appendix F
+// of RFC 8554 from the SEED and I of its top level, which the RFC gives in
+// its private key.
+func TestRFC8554KeyGen(t *testing.T) {
+	seed, _ := hex.DecodeString("558b8966c48ae9cb898b423c83443aae" +
+		"014a72f1b1ab5cc85cf1d892903b5439" + // SEED
+		"d08fabd4a2091ff0a8cb4ed834e74534") // I
+	want, _ := hex.DecodeString("00000002" + // Levels
+		"00000006" + // LMS_SHA256_M32_H10
+		"00000003" + // LMOTS_SHA256_N32_W4
+		"d08fabd4a2091ff0a8cb4ed834e74534" +
+		"32a58885cd9ba0431235466bff9651c6" +
+		"c92124404d45fa53cf161c28f1ad5a8e")
+	levels := []Level{
+		{LMS_SHA256_M32_H10, LMOTS_SHA256_N32_W4},
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W8},
+	}
+	pk, _, err := NewKeyFromSeed(levels, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := pk.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("public key %x, want %x", got, want)
+	}
+}
+
+type hexBytes []byte
+
+func (b *hexBytes) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*b, err = hex.DecodeString(s)
+	return err
+}
+
+func TestCache(t *testing.T) {
+	// Split the trees of height 5 at height 3 to exercise the cache of the
+	// subtrees below the cut.
+	defer func(h int) { maxCacheHeight = h }(maxCacheHeight)
+
+	levels := []Level{
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W8},
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W8},
+	}
+	seed := make([]byte, seedSize)
+	sig1 := make([]byte, signatureSize(levels))
+	sig2 := make([]byte, signatureSize(levels))
+	pk1, sk1, _ := NewKeyFromSeed(levels, seed)
+	maxCacheHeight = 2
+	pk2, sk2, _ := NewKeyFromSeed(levels, seed)
+	if !pk1.Equal(pk2) {
+		t.Fatal("public keys differ")
+	}
+
+	store1 := stateful.NewMemoryStore(0)
+	sk1.SetStore(store1)
+	for _, idx := range []uint64{0, 1, 7, 8, 9, 31, 32, 33, 0x1f3, 0x3ff} {
+		_ = store1.Commit(idx)
+		sk2.SetStore(stateful.NewMemoryStore(idx))
+		if err := SignTo(sk1, nil, sig1); err != nil {
+			t.Fatal(err)
+		}
+		if err := SignTo(sk2, nil, sig2); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig1, sig2) {
+			t.Fatalf("signatures differ at %d", idx)
+		}
+		if !Verify(pk1, nil, sig2) {
+			t.Fatalf("verification failed at %d", idx)
+		}
+	}
+}
+
+type failingStore struct{ stateful.MemoryStore }
+
+var errFailing = errors.New("commit failed")
+
+func (*failingStore) Commit(uint64) error { return errFailing }
+
+func TestState(t *testing.T) {
+	levels := []Level{
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W4},
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W4},
+	}
+	seed := make([]byte, seedSize)
+	pk, sk, _ := NewKeyFromSeed(levels, seed)
+	msg := []byte("message")
+	sig := make([]byte, signatureSize(levels))
+
+	if err := SignTo(sk, msg, sig); err != stateful.ErrNoStore {
+		t.Fatalf("signed without store: %v", err)
+	}
+	if !bytes.Equal(sig, make([]byte, len(sig))) {
+		t.Fatal("signature written without store")
+	}
+
+	sk.SetStore(&failingStore{})
+	if err := SignTo(sk, msg, sig); err != errFailing {
+		t.Fatalf("signed without commit: %v", err)
+	}
+
+	store := stateful.NewMemoryStore(30)
+	sk.SetStore(store)
+	seen := make(map[[32]byte]bool)
+	for i := 0; i < 4; i++ {
+		if err := SignTo(sk, msg, sig); err != nil {
+			t.Fatal(err)
+		}
+		if next, _ := store.Load(); next != uint64(31+i) {
+			t.Fatalf("index %d after %d signatures", next, i+1)
+		}
+		if !Verify(pk, msg, sig) {
+			t.Fatal("verification failed")
+		}
+		seen[sha256.Sum256(sig)] = true
+	}
+	if len(seen) != 4 {
+		t.Fatal("signatures repeat")
+	}
+
+	sk.SetStore(stateful.NewMemoryStore(1<<10 - 1))
+	if n, _ := sk.Remaining(); n != 1 {
+		t.Fatalf("%d remaining, want 1", n)
+	}
+	if err := SignTo(sk, msg, sig); err != nil {
+		t.Fatal(err)
+	}
+	if err := SignTo(sk, msg, sig); err != stateful.ErrExhausted {
+		t.Fatalf("signed with exhausted key: %v", err)
+	}
+}
+
+func TestScheme(t *testing.T) {
+	scheme, err := NewScheme([]Level{
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W8},
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pk, sk, err := scheme.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("message")
+	sig := scheme.Sign(sk, msg, nil)
+	if !scheme.Verify(pk, msg, sig, nil) {
+		t.Fatal("verification failed")
+	}
+	if pk.Scheme().Name() != scheme.Name() || sk.Scheme().Name() != scheme.Name() {
+		t.Fatal("wrong scheme")
+	}
+
+	ppk, _ := pk.MarshalBinary()
+	psk, _ := sk.MarshalBinary()
+	pk2, err := scheme.UnmarshalBinaryPublicKey(ppk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk2, err := scheme.UnmarshalBinaryPrivateKey(psk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pk.Equal(pk2) || !sk.Equal(sk2) {
+		t.Fatal("marshalling roundtrip failed")
+	}
+
+	other, _ := NewScheme([]Level{
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W8},
+		{LMS_SHA256_M32_H5, LMOTS_SHA256_N32_W8},
+	})
+	if _, err := other.UnmarshalBinaryPrivateKey(psk); err == nil {
+		t.Fatal("unmarshalled private key of other parameter set")
+	}
+
+	func() {
+		defer func() {
+			if recover() != stateful.ErrNoStore {
+				t.Fatal("expected panic")
+			}
+		}()
+		scheme.Sign(sk2, msg, nil)
+	}()
+
+	// Continue where the first key stopped.
+	sk2.(*PrivateKey).SetStore(stateful.NewMemoryStore(1))
+	sig2 := scheme.Sign(sk2, msg, nil)
+	if bytes.Equal(sig, sig2) || !scheme.Verify(pk2, msg, sig2, nil) {
+		t.Fatal("signature with unmarshalled key")
+	}
+
+	func() {
+		defer func() {
+			if recover() != sign.ErrContextNotSupported {
+				t.Fatal("expected panic")
+			}
+		}()
+		scheme.Sign(sk, msg, &sign.SignatureOpts{Context: "abc"})
+	}()
+
+	if _, err := sk.Sign(nil, msg, crypto.SHA256); err != ErrHashedMessage {
+		t.Fatal("signed hashed message")
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	levels := []Level{
+		{LMS_SHA256_M32_H10, LMOTS_SHA256_N32_W4},
+		{LMS_SHA256_M32_H10, LMOTS_SHA256_N32_W4},
+	}
+	_, sk, _ := NewKeyFromSeed(levels, make([]byte, seedSize))
+	sk.SetStore(stateful.NewMemoryStore(0))
+	sig := make([]byte, signatureSize(levels))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := SignTo(sk, nil, sig); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	levels := []Level{
+		{LMS_SHA256_M32_H10, LMOTS_SHA256_N32_W4},
+		{LMS_SHA256_M32_H10, LMOTS_SHA256_N32_W4},
+	}
+	pk, sk, _ := NewKeyFromSeed(levels, make([]byte, seedSize))
+	sk.SetStore(stateful.NewMemoryStore(0))
+	sig := make([]byte, signatureSize(levels))
+	_ = SignTo(sk, nil, sig)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Verify(pk, nil, sig)
+	}
+}
@@ -0,0 +1,187 @@
+package lms
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// LM-OTS one-time signatures, see Section 4 of RFC 8554.
+
+// Domain separators of the hashes, see Section 7.1 of RFC 8554.
+const (
+	dPblc = 0x8080
+	dMesg = 0x8181
+	dLeaf = 0x8282
+	dIntr = 0x8383
+)
+
+// Values of i of the pseudorandom derivation H(I ‖ u32str(q) ‖ u16str(i) ‖
+// u8str(0xff) ‖ SEED) of Appendix A of RFC 8554 beyond the chain indices,
+// for the randomizer C of a signature, and the SEED and I of the key of
+// the child tree of a leaf.
+const (
+	deriveC       = 0xfffd
+	deriveChildSK = 0xfffe
+	deriveChildID = 0xffff
+)
+
+// key is the private key of an LMS tree, from which the LM-OTS private
+// keys of its leaves are derived.
+type key struct {
+	Level
+	id   [idSize]byte
+	seed [n]byte
+}
+
+// derive writes H(I ‖ u32str(q) ‖ u16str(i) ‖ u8str(0xff) ‖ SEED) into
+// out.
+func (k *key) derive(out []byte, q uint32, i uint16) {
+	var buf [idSize + 7 + n]byte
+	copy(buf[:], k.id[:])
+	binary.BigEndian.PutUint32(buf[idSize:], q)
+	binary.BigEndian.PutUint16(buf[idSize+4:], i)
+	buf[idSize+6] = 0xff
+	copy(buf[idSize+7:], k.seed[:])
+	h := sha256.Sum256(buf[:])
+	copy(out, h[:])
+}
+
+// child returns the private key of the tree below the leaf q.
+func (k *key) child(level Level, q uint32) *key {
+	var id [n]byte
+	c := &key{Level: level}
+	k.derive(c.seed[:], q, deriveChildSK)
+	k.derive(id[:], q, deriveChildID)
+	copy(c.id[:], id[:])
+	return c
+}
+
+// chain applies the hash chain of chain i of the one-time key q from step
+// from up to step to to x in place.
+func chain(x []byte, id *[idSize]byte, q uint32, i uint16, from, to int) {
+	var buf [idSize + 7 + n]byte
+	copy(buf[:], id[:])
+	binary.BigEndian.PutUint32(buf[idSize:], q)
+	binary.BigEndian.PutUint16(buf[idSize+4:], i)
+	copy(buf[idSize+7:], x)
+	for j := from; j < to; j++ {
+		buf[idSize+6] = byte(j)
+		h := sha256.Sum256(buf[:])
+		copy(buf[idSize+7:], h[:])
+	}
+	copy(x, buf[idSize+7:])
+}
+
+// coef returns the i-th digit of w bits of s.
+func coef(s []byte, i, w int) byte {
+	return byte((1<<w - 1) & (int(s[i*w/8]) >> (8 - (w*(i%(8/w)) + w))))
+}
+
+// digits writes the digits of Q ‖ Cksm(Q) into out, which must be of
+// length p.
+func (p *otsParams) digits(out []byte, q []byte) {
+	var s [n + 2]byte
+	copy(s[:], q)
+	sum := 0
+	for i := 0; i < n*8/p.w; i++ {
+		sum += 1<<p.w - 1 - int(coef(q, i, p.w))
+	}
+	sum <<= p.ls
+	binary.BigEndian.PutUint16(s[n:], uint16(sum))
+	for i := range out {
+		out[i] = coef(s[:], i, p.w)
+	}
+}
+
+// msgHash writes Q = H(I ‖ u32str(q) ‖ u16str(D_MESG) ‖ C ‖ msg) into out.
+func msgHash(out []byte, id *[idSize]byte, q uint32, c, msg []byte) {
+	var buf [idSize + 6]byte
+	copy(buf[:], id[:])
+	binary.BigEndian.PutUint32(buf[idSize:], q)
+	binary.BigEndian.PutUint16(buf[idSize+4:], dMesg)
+	h := sha256.New()
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write(c)
+	_, _ = h.Write(msg)
+	h.Sum(out[:0])
+}
+
+// otsPublicKey writes the LM-OTS public key K of the one-time key q into
+// out, see Algorithm 1 of RFC 8554.
+func (k *key) otsPublicKey(out []byte, q uint32) {
+	p := k.OTS.params()
+	var buf [idSize + 6]byte
+	copy(buf[:], k.id[:])
+	binary.BigEndian.PutUint32(buf[idSize:], q)
+	binary.BigEndian.PutUint16(buf[idSize+4:], dPblc)
+	h := sha256.New()
+	_, _ = h.Write(buf[:])
+
+	var y [n]byte
+	for i := 0; i < p.p; i++ {
+		k.derive(y[:], q, uint16(i))
+		chain(y[:], &k.id, q, uint16(i), 0, 1<<p.w-1)
+		_, _ = h.Write(y[:])
+	}
+	h.Sum(out[:0])
+}
+
+// otsSign writes the LM-OTS signature of msg with the one-time key q into
+// sig, see Algorithm 3 of RFC 8554.
+func (k *key) otsSign(sig []byte, q uint32, msg []byte) {
+	p := k.OTS.params()
+	binary.BigEndian.PutUint32(sig, uint32(k.OTS))
+	c := sig[4 : 4+n]
+	k.derive(c, q, deriveC)
+
+	var (
+		qHash  [n]byte
+		digits [265]byte
+	)
+	msgHash(qHash[:], &k.id, q, c, msg)
+	p.digits(digits[:p.p], qHash[:])
+
+	y := sig[4+n:]
+	for i := 0; i < p.p; i++ {
+		yi := y[i*n : (i+1)*n]
+		k.derive(yi, q, uint16(i))
+		chain(yi, &k.id, q, uint16(i), 0, int(digits[i]))
+	}
+}
+
+// otsPublicKeyFromSig writes the candidate public key Kc computed from the
+// LM-OTS signature sig of msg into out, see Algorithm 4b of RFC 8554.
+// Returns false if sig is malformed.
+func otsPublicKeyFromSig(out []byte, typ OTSType, id *[idSize]byte, q uint32, sig, msg []byte) bool {
+	if len(sig) < 4 || OTSType(binary.BigEndian.Uint32(sig)) != typ {
+		return false
+	}
+	p := typ.params()
+	if len(sig) != p.sigSize() {
+		return false
+	}
+
+	var (
+		qHash  [n]byte
+		digits [265]byte
+	)
+	msgHash(qHash[:], id, q, sig[4:4+n], msg)
+	p.digits(digits[:p.p], qHash[:])
+
+	var buf [idSize + 6]byte
+	copy(buf[:], id[:])
+	binary.BigEndian.PutUint32(buf[idSize:], q)
+	binary.BigEndian.PutUint16(buf[idSize+4:], dPblc)
+	h := sha256.New()
+	_, _ = h.Write(buf[:])
+
+	var z [n]byte
+	y := sig[4+n:]
+	for i := 0; i < p.p; i++ {
+		copy(z[:], y[i*n:(i+1)*n])
+		chain(z[:], id, q, uint16(i), int(digits[i]), 1<<p.w-1)
+		_, _ = h.Write(z[:])
+	}
+	h.Sum(out[:0])
+	return true
+}
@@ -0,0 +1,165 @@
+package lms
+
+import "fmt"
+
+// LMSType identifies an LMS parameter set, see Section 5.1 of RFC 8554.
+type LMSType uint32
+
+// OTSType identifies an LM-OTS parameter set, see Section 4.1 of RFC 8554.
+type OTSType uint32
+
+// LMS parameter sets of Section 5.1 of RFC 8554: SHA-256 with m = 32 and
+// trees of height 5, 10, 15, 20 or 25.
+const (
+	LMS_SHA256_M32_H5 LMSType = iota + 5
+	LMS_SHA256_M32_H10
+	LMS_SHA256_M32_H15
+	LMS_SHA256_M32_H20
+	LMS_SHA256_M32_H25
+)
+
+// LM-OTS parameter sets of Section 4.1 of RFC 8554: SHA-256 with n = 32
+// and Winternitz parameter w = 1, 2, 4 or 8.
+const (
+	LMOTS_SHA256_N32_W1 OTSType = iota + 1
+	LMOTS_SHA256_N32_W2
+	LMOTS_SHA256_N32_W4
+	LMOTS_SHA256_N32_W8
+)
+
+// Level is the parameter set of a level of an HSS hypertree.
+type Level struct {
+	LMS LMSType
+	OTS OTSType
+}
+
+// Length in bytes of hashes, n for LM-OTS and m for LMS, and of the
+// identifier I of an LMS key.
+const (
+	n      = 32
+	idSize = 16
+)
+
+// maxLevels is the maximal number of levels of HSS, see Section 6 of
+// RFC 8554.
+const maxLevels = 8
+
+type lmsParams struct {
+	name string
+	h    int // height of the tree
+}
+
+type otsParams struct {
+	name string
+	w    int // number of bits of a digit
+	p    int // number of chains
+	ls   int // left shift of the checksum
+}
+
+var lmsParamSets = [...]lmsParams{
+	LMS_SHA256_M32_H5:  {"LMS_SHA256_M32_H5", 5},
+	LMS_SHA256_M32_H10: {"LMS_SHA256_M32_H10", 10},
+	LMS_SHA256_M32_H15: {"LMS_SHA256_M32_H15", 15},
+	LMS_SHA256_M32_H20: {"LMS_SHA256_M32_H20", 20},
+	LMS_SHA256_M32_H25: {"LMS_SHA256_M32_H25", 25},
+}
+
+var otsParamSets = [...]otsParams{
+	LMOTS_SHA256_N32_W1: {"LMOTS_SHA256_N32_W1", 1, 265, 7},
+	LMOTS_SHA256_N32_W2: {"LMOTS_SHA256_N32_W2", 2, 133, 6},
+	LMOTS_SHA256_N32_W4: {"LMOTS_SHA256_N32_W4", 4, 67, 4},
+	LMOTS_SHA256_N32_W8: {"LMOTS_SHA256_N32_W8", 8, 34, 0},
+}
+
+// IsValid returns whether t is one of the LMS parameter sets of RFC 8554.
+func (t LMSType) IsValid() bool {
+	return t >= LMS_SHA256_M32_H5 && t <= LMS_SHA256_M32_H25
+}
+
+// IsValid returns whether t is one of the LM-OTS parameter sets of
+// RFC 8554.
+func (t OTSType) IsValid() bool {
+	return t >= LMOTS_SHA256_N32_W1 && t <= LMOTS_SHA256_N32_W8
+}
+
+func (t LMSType) String() string {
+	if !t.IsValid() {
+		return fmt.Sprintf("LMS(%d)", uint32(t))
+	}
+	return lmsParamSets[t].name
+}
+
+func (t OTSType) String() string {
+	if !t.IsValid() {
+		return fmt.Sprintf("LMOTS(%d)", uint32(t))
+	}
+	return otsParamSets[t].name
+}
+
+func (t LMSType) params() *lmsParams { return &lmsParamSets[t] }
+func (t OTSType) params() *otsParams { return &otsParamSets[t] }
+
+// sigSize returns the size of an LM-OTS signature u32str(type) ‖ C ‖ y.
+func (p *otsParams) sigSize() int { return 4 + n + p.p*n }
+
+// lmsSigSize returns the size of an LMS signature
+// u32str(q) ‖ lmots_signature ‖ u32str(type) ‖ path.
+func (l Level) lmsSigSize() int {
+	return 8 + l.OTS.params().sigSize() + l.LMS.params().h*n
+}
+
+// lmsPublicKeySize is the size of an LMS public key
+// u32str(type) ‖ u32str(otstype) ‖ I ‖ T[1].
+const lmsPublicKeySize = 8 + idSize + n
+
+// checkLevels returns whether levels is a valid HSS parameter set, whose
+// number of signatures fits in a uint64.
+func checkLevels(levels []Level) error {
+	if len(levels) == 0 || len(levels) > maxLevels {
+		return ErrParams
+	}
+	height := 0
+	for _, l := range levels {
+		if !l.LMS.IsValid() || !l.OTS.IsValid() {
+			return ErrParams
+		}
+		height += l.LMS.params().h
+	}
+	if height >= 64 {
+		return ErrParams
+	}
+	return nil
+}
+
+// signatures returns the number of signatures of an HSS private key,
+// which is 2 to the sum of the heights of the levels.
+func signatures(levels []Level) uint64 {
+	height := 0
+	for _, l := range levels {
+		height += l.LMS.params().h
+	}
+	return 1 << height
+}
+
+// signatureSize returns the size of an HSS signature
+// u32str(Nspk) ‖ (signed public keys) ‖ sig[Nspk].
+func signatureSize(levels []Level) int {
+	size := 4 + (len(levels)-1)*lmsPublicKeySize
+	for _, l := range levels {
+		size += l.lmsSigSize()
+	}
+	return size
+}
+
+// publicKeySize is the size of an HSS public key u32str(L) ‖ pub[0].
+const publicKeySize = 4 + lmsPublicKeySize
+
+// privateKeySize returns the size of the encoding
+// u32str(L) ‖ (u32str(type) ‖ u32str(otstype))^L ‖ I ‖ SEED ‖ T[1]
+// of the private key.
+func privateKeySize(levels []Level) int {
+	return 4 + 8*len(levels) + idSize + 2*n
+}
+
+// seedSize is the size of the SEED ‖ I of the top level private key.
+const seedSize = n + idSize
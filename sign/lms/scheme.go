@@ -0,0 +1,145 @@
+package lms
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/stateful"
+)
+
+// Boilerplate for generic signatures API
+
+type scheme struct{ levels []Level }
+
+// NewScheme returns a generic signature interface for the HSS parameter
+// set levels, whose first entry is the top level. HSS with a single level
+// is LMS, except for the encoding of keys and signatures.
+//
+// The Sign method of the returned scheme panics, instead of reusing a
+// one-time key, if the private key has no Store attached or its Store
+// fails.
+func NewScheme(levels []Level) (sign.Scheme, error) {
+	if err := checkLevels(levels); err != nil {
+		return nil, err
+	}
+	return &scheme{append([]Level(nil), levels...)}, nil
+}
+
+// Name returns HSS(t_0,o_0;…;t_{L-1},o_{L-1}) where t_i and o_i are the
+// LMS and LM-OTS types of level i.
+func (s *scheme) Name() string {
+	names := make([]string, len(s.levels))
+	for i, l := range s.levels {
+		names[i] = l.LMS.String() + "," + l.OTS.String()
+	}
+	return "HSS(" + strings.Join(names, ";") + ")"
+}
+
+func (s *scheme) PublicKeySize() int    { return publicKeySize }
+func (s *scheme) PrivateKeySize() int   { return privateKeySize(s.levels) }
+func (s *scheme) SignatureSize() int    { return signatureSize(s.levels) }
+func (s *scheme) SeedSize() int         { return seedSize }
+func (s *scheme) SupportsContext() bool { return false }
+
+// GenerateKey generates a fresh key pair, whose private key has a
+// stateful.MemoryStore attached.
+func (s *scheme) GenerateKey() (sign.PublicKey, sign.PrivateKey, error) {
+	pk, sk, err := GenerateKey(nil, s.levels)
+	if err != nil {
+		return nil, nil, err
+	}
+	sk.SetStore(stateful.NewMemoryStore(0))
+	return pk, sk, nil
+}
+
+func (s *scheme) Sign(
+	sk sign.PrivateKey,
+	msg []byte,
+	opts *sign.SignatureOpts,
+) []byte {
+	priv, ok := sk.(*PrivateKey)
+	if !ok || !levelsEqual(priv.levels, s.levels) {
+		panic(sign.ErrTypeMismatch)
+	}
+	if opts != nil && opts.Context != "" {
+		panic(sign.ErrContextNotSupported)
+	}
+	sig := make([]byte, s.SignatureSize())
+	if err := SignTo(priv, msg, sig); err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+func (s *scheme) Verify(
+	pk sign.PublicKey,
+	msg, sig []byte,
+	opts *sign.SignatureOpts,
+) bool {
+	pub, ok := pk.(*PublicKey)
+	if !ok || !levelsEqual(pub.levels, s.levels) {
+		panic(sign.ErrTypeMismatch)
+	}
+	if opts != nil && opts.Context != "" {
+		panic(sign.ErrContextNotSupported)
+	}
+	return Verify(pub, msg, sig)
+}
+
+// DeriveKey derives a key pair from seed. The private key has no Store
+// attached.
+func (s *scheme) DeriveKey(seed []byte) (sign.PublicKey, sign.PrivateKey) {
+	pk, sk, err := NewKeyFromSeed(s.levels, seed)
+	if err != nil {
+		panic(err)
+	}
+	return pk, sk
+}
+
+// UnmarshalBinaryPublicKey parses the HSS public key u32str(L) ‖ pub[0] of
+// Section 6 of RFC 8554.
+func (s *scheme) UnmarshalBinaryPublicKey(buf []byte) (sign.PublicKey, error) {
+	if len(buf) != publicKeySize {
+		return nil, sign.ErrPubKeySize
+	}
+	if int(binary.BigEndian.Uint32(buf)) != len(s.levels) ||
+		LMSType(binary.BigEndian.Uint32(buf[4:])) != s.levels[0].LMS ||
+		OTSType(binary.BigEndian.Uint32(buf[8:])) != s.levels[0].OTS {
+		return nil, ErrParams
+	}
+	return &PublicKey{
+		levels: s.levels,
+		pub:    append([]byte(nil), buf[4:]...),
+	}, nil
+}
+
+// UnmarshalBinaryPrivateKey unmarshals a private key, which has no Store
+// attached.
+func (s *scheme) UnmarshalBinaryPrivateKey(buf []byte) (sign.PrivateKey, error) {
+	if len(buf) != s.PrivateKeySize() {
+		return nil, sign.ErrPrivKeySize
+	}
+	if int(binary.BigEndian.Uint32(buf)) != len(s.levels) {
+		return nil, ErrParams
+	}
+	for i, l := range s.levels {
+		if LMSType(binary.BigEndian.Uint32(buf[4+8*i:])) != l.LMS ||
+			OTSType(binary.BigEndian.Uint32(buf[8+8*i:])) != l.OTS {
+			return nil, ErrParams
+		}
+	}
+	buf = buf[4+8*len(s.levels):]
+
+	sk := &PrivateKey{
+		levels: s.levels,
+		top:    key{Level: s.levels[0]},
+		root:   append([]byte(nil), buf[idSize+n:]...),
+	}
+	copy(sk.top.id[:], buf)
+	copy(sk.top.seed[:], buf[idSize:])
+	return sk, nil
+}
+
+func (sk *PrivateKey) Scheme() sign.Scheme { return &scheme{sk.levels} }
+func (pk *PublicKey) Scheme() sign.Scheme  { return &scheme{pk.levels} }
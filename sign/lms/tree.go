@@ -0,0 +1,222 @@
+package lms
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+// LMS trees, see Section 5 of RFC 8554. The node at height z and index j
+// of a tree of height h has number r = 2^(h-z) + j.
+
+// leaf writes the leaf T[2^h + q] of the tree of k into out.
+func (k *key) leaf(out []byte, q uint32) {
+	var otsPk [n]byte
+	k.otsPublicKey(otsPk[:], q)
+	leafHash(out, &k.id, uint32(1)<<k.LMS.params().h+q, otsPk[:])
+}
+
+// leafHash writes H(I ‖ u32str(r) ‖ u16str(D_LEAF) ‖ otsPk) into out.
+func leafHash(out []byte, id *[idSize]byte, r uint32, otsPk []byte) {
+	var buf [idSize + 6 + n]byte
+	copy(buf[:], id[:])
+	binary.BigEndian.PutUint32(buf[idSize:], r)
+	binary.BigEndian.PutUint16(buf[idSize+4:], dLeaf)
+	copy(buf[idSize+6:], otsPk)
+	h := sha256.Sum256(buf[:])
+	copy(out, h[:])
+}
+
+// interiorHash writes H(I ‖ u32str(r) ‖ u16str(D_INTR) ‖ left ‖ right)
+// into out.
+func interiorHash(out []byte, id *[idSize]byte, r uint32, left, right []byte) {
+	var buf [idSize + 6 + 2*n]byte
+	copy(buf[:], id[:])
+	binary.BigEndian.PutUint32(buf[idSize:], r)
+	binary.BigEndian.PutUint16(buf[idSize+4:], dIntr)
+	copy(buf[idSize+6:], left)
+	copy(buf[idSize+6+n:], right)
+	h := sha256.Sum256(buf[:])
+	copy(out, h[:])
+}
+
+// newLevels allocates the levels of a subtree of height hh, which hold
+// 2^hh, 2^(hh-1), ..., 1 nodes.
+func newLevels(hh int) [][]byte {
+	buf := make([]byte, ((2<<hh)-1)*n)
+	levels := make([][]byte, hh+1)
+	for z := range levels {
+		l := (1 << (hh - z)) * n
+		levels[z], buf = buf[:l], buf[l:]
+	}
+	return levels
+}
+
+// hashLevels computes levels[1:] of a subtree of the tree of k from
+// levels[0], which holds the consecutive nodes at height base starting at
+// index start.
+func (k *key) hashLevels(levels [][]byte, base int, start uint32) {
+	h := k.LMS.params().h
+	for r := 1; r < len(levels); r++ {
+		below := levels[r-1]
+		for j := 0; j < len(levels[r])/n; j++ {
+			node := uint32(1)<<(h-base-r) + start>>r + uint32(j)
+			interiorHash(levels[r][j*n:(j+1)*n], &k.id, node,
+				below[2*j*n:(2*j+1)*n], below[(2*j+1)*n:(2*j+2)*n])
+		}
+	}
+}
+
+// subtree computes all nodes of the subtree of the tree of k whose leaves
+// are start, ..., start + 2^(len(levels)-1) - 1.
+func (k *key) subtree(levels [][]byte, start uint32) {
+	for j := 0; j < len(levels[0])/n; j++ {
+		k.leaf(levels[0][j*n:(j+1)*n], start+uint32(j))
+	}
+	k.hashLevels(levels, 0, start)
+}
+
+// maxCacheHeight bounds the height of the subtrees of which a private key
+// keeps all nodes in memory. It is a variable for testing.
+var maxCacheHeight = 10
+
+// treeCache holds the key and nodes of the tree of a level in which a
+// private key currently signs, so that the authentication paths of
+// consecutive signatures do not have to be recomputed from scratch.
+//
+// Trees of height h ≤ maxCacheHeight are kept entirely. Higher trees are
+// split at height cut = h - maxCacheHeight: only the nodes above the cut
+// are kept, together with the subtree of height cut below it that holds
+// the last leaf used.
+type treeCache struct {
+	*key
+	prefix   uint64   // the bits of the counter that select the tree
+	cut      int      // height at which the tree is split
+	top      [][]byte // nodes at heights cut, ..., h
+	low      [][]byte // nodes at heights 0, ..., cut of the last subtree
+	lowStart uint32   // first leaf of low
+	pub      []byte   // LMS public key of the tree
+
+	// LMS signature of the public key of the child tree, which is reused
+	// for all signatures with the same leaf.
+	sig    []byte
+	sigQ   uint32
+	hasSig bool
+}
+
+// fill sets the key of c to k and computes the nodes above the cut.
+func (c *treeCache) fill(k *key, prefix uint64) {
+	h := k.LMS.params().h
+	cut := 0
+	if h > maxCacheHeight {
+		cut = h - maxCacheHeight
+	}
+
+	c.key = k
+	c.prefix = prefix
+	c.cut = cut
+	c.top = newLevels(h - cut)
+	c.low = nil
+	c.hasSig = false
+	if cut == 0 {
+		k.subtree(c.top, 0)
+	} else {
+		low := newLevels(cut)
+		for j := 0; j < 1<<(h-cut); j++ {
+			k.subtree(low, uint32(j)<<cut)
+			copy(c.top[0][j*n:(j+1)*n], low[cut])
+		}
+		k.hashLevels(c.top, cut, 0)
+	}
+
+	c.pub = make([]byte, lmsPublicKeySize)
+	binary.BigEndian.PutUint32(c.pub, uint32(k.LMS))
+	binary.BigEndian.PutUint32(c.pub[4:], uint32(k.OTS))
+	copy(c.pub[8:], k.id[:])
+	copy(c.pub[8+idSize:], c.root())
+}
+
+// root returns the root T[1] of the tree held by c.
+func (c *treeCache) root() []byte { return c.top[len(c.top)-1] }
+
+// lmsSign writes the LMS signature of msg with the leaf q of the tree held
+// by c into sig, see Algorithm 5 of RFC 8554.
+func (c *treeCache) lmsSign(sig []byte, q uint32, msg []byte) {
+	h, cut := c.LMS.params().h, c.cut
+	otsSize := c.OTS.params().sigSize()
+	binary.BigEndian.PutUint32(sig, q)
+	c.otsSign(sig[4:4+otsSize], q, msg)
+	binary.BigEndian.PutUint32(sig[4+otsSize:], uint32(c.LMS))
+	path := sig[8+otsSize:]
+
+	if cut > 0 {
+		start := q >> cut << cut
+		if c.low == nil || c.lowStart != start {
+			if c.low == nil {
+				c.low = newLevels(cut)
+			}
+			c.subtree(c.low, start)
+			c.lowStart = start
+		}
+	}
+
+	for z := 0; z < h; z++ {
+		sib := (q >> z) ^ 1
+		var node []byte
+		if z < cut {
+			node = c.low[z][(sib-c.lowStart>>z)*n:]
+		} else {
+			node = c.top[z-cut][sib*n:]
+		}
+		copy(path[z*n:(z+1)*n], node[:n])
+	}
+}
+
+// lmsVerify returns whether sig, which must be of the exact length, is a
+// valid LMS signature of msg under the LMS public key pub, see Algorithm
+// 6a of RFC 8554.
+func lmsVerify(pub, msg, sig []byte) bool {
+	if len(pub) != lmsPublicKeySize {
+		return false
+	}
+	lmsType := LMSType(binary.BigEndian.Uint32(pub))
+	otsType := OTSType(binary.BigEndian.Uint32(pub[4:]))
+	if !lmsType.IsValid() || !otsType.IsValid() {
+		return false
+	}
+	l := Level{lmsType, otsType}
+	if len(sig) != l.lmsSigSize() {
+		return false
+	}
+
+	h := lmsType.params().h
+	otsSize := otsType.params().sigSize()
+	q := binary.BigEndian.Uint32(sig)
+	if LMSType(binary.BigEndian.Uint32(sig[4+otsSize:])) != lmsType ||
+		q >= 1<<h {
+		return false
+	}
+
+	var (
+		id   [idSize]byte
+		node [n]byte
+	)
+	copy(id[:], pub[8:])
+	if !otsPublicKeyFromSig(node[:], otsType, &id, q, sig[4:4+otsSize], msg) {
+		return false
+	}
+
+	r := uint32(1)<<h + q
+	leafHash(node[:], &id, r, node[:])
+	path := sig[8+otsSize:]
+	for i := 0; r > 1; i++ {
+		if r&1 == 1 {
+			interiorHash(node[:], &id, r/2, path[i*n:(i+1)*n], node[:])
+		} else {
+			interiorHash(node[:], &id, r/2, node[:], path[i*n:(i+1)*n])
+		}
+		r /= 2
+	}
+
+	return subtle.ConstantTimeCompare(node[:], pub[8+idSize:]) == 1
+}
@@ -0,0 +1,16 @@
+// mldsa implements NIST post-quantum signature scheme ML-DSA (FIPS204)
+//
+// Each of the three different security levels of ML-DSA is implemented by a
+// subpackage. For instance, mldsa44 can be found in
+//
+//	github.com/katzenpost/circl/sign/mldsa/mldsa44
+//
+// Besides pure ML-DSA, every subpackage implements the pre-hash variant
+// HashML-DSA, and supports the context strings of FIPS204 as well as both
+// hedged and deterministic signing.
+//
+// If your choice for mode is fixed compile-time, use the subpackages.
+// To choose a scheme at runtime, use the generic signatures API under
+//
+//	github.com/katzenpost/circl/sign/schemes
+package mldsa
@@ -0,0 +1,86 @@
+package mldsa_test
+
+// Code to generate the NIST "PQCsignKAT" test vectors.
+// See PQCsignKAT_sign.c and randombytes.c in the reference implementation.
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/nist"
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa44"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa65"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa87"
+)
+
+func TestPQCgenKATSign(t *testing.T) {
+	// Generated from reference implementation commit cbcd8753a43402885c90343c
+	// which can be found at https://github.com/pq-crystals/dilithium
+	// with the DILITHIUM_RANDOMIZED_SIGNING macro unset in ref/config.h
+	// to disable randomized signing. The reference implementation still
+	// names the parameter sets after their round 3 counterparts.
+	for _, tc := range []struct {
+		scheme    sign.Scheme
+		nameInKat string
+		want      string
+	}{
+		{mldsa44.Scheme(), "Dilithium2", "14f92c48abc0d63ea263cce3c83183c8360c6ede7cbd5b65bd7c6f31e38f0ea5"},
+		{mldsa65.Scheme(), "Dilithium3", "595a8eff6988159c94eb5398294458c5d27d21c994fb64cadbee339173abcf63"},
+		{mldsa87.Scheme(), "Dilithium5", "35e2ce3d88b3311517bf8d41aa2cd24aa0fbda2bb8052ca8af4ad8d7c7344074"},
+	} {
+		t.Run(tc.scheme.Name(), func(t *testing.T) {
+			mode := tc.scheme
+			opts := &sign.SignatureOpts{Deterministic: true}
+
+			var seed [48]byte
+			var eseed [32]byte
+			for i := 0; i < 48; i++ {
+				seed[i] = byte(i)
+			}
+			f := sha256.New()
+			g := nist.NewDRBG(&seed)
+			fmt.Fprintf(f, "# %s\n\n", tc.nameInKat)
+			for i := 0; i < 100; i++ {
+				mlen := 33 * (i + 1)
+				g.Fill(seed[:])
+				msg := make([]byte, mlen)
+				g.Fill(msg[:])
+
+				fmt.Fprintf(f, "count = %d\n", i)
+				fmt.Fprintf(f, "seed = %X\n", seed)
+				fmt.Fprintf(f, "mlen = %d\n", mlen)
+				fmt.Fprintf(f, "msg = %X\n", msg)
+
+				g2 := nist.NewDRBG(&seed)
+				g2.Fill(eseed[:])
+				pk, sk := mode.DeriveKey(eseed[:])
+
+				ppk, err := pk.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				psk, err := sk.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				fmt.Fprintf(f, "pk = %X\n", ppk)
+				fmt.Fprintf(f, "sk = %X\n", psk)
+				fmt.Fprintf(f, "smlen = %d\n", mlen+mode.SignatureSize())
+
+				sig := mode.Sign(sk, msg[:], opts)
+
+				fmt.Fprintf(f, "sm = %X%X\n\n", sig, msg)
+
+				if !mode.Verify(pk, msg[:], sig, opts) {
+					t.Fatal()
+				}
+			}
+			if fmt.Sprintf("%x", f.Sum(nil)) != tc.want {
+				t.Fatal()
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package mldsa_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"strings"
+	"testing"
+
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa44"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa65"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa87"
+)
+
+var allSchemes = []sign.Scheme{
+	mldsa44.Scheme(),
+	mldsa65.Scheme(),
+	mldsa87.Scheme(),
+	mldsa44.HashScheme(),
+	mldsa65.HashScheme(),
+	mldsa87.HashScheme(),
+}
+
+func TestContext(t *testing.T) {
+	msg := []byte("message")
+	for _, scheme := range allSchemes {
+		t.Run(scheme.Name(), func(t *testing.T) {
+			if !scheme.SupportsContext() {
+				t.Fatal("context not supported")
+			}
+			pk, sk, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := &sign.SignatureOpts{Context: "context"}
+			sig := scheme.Sign(sk, msg, opts)
+			if !scheme.Verify(pk, msg, sig, opts) {
+				t.Fatal("signature with context does not verify")
+			}
+			if scheme.Verify(pk, msg, sig, nil) {
+				t.Fatal("signature verifies without its context")
+			}
+			if scheme.Verify(pk, msg, sig, &sign.SignatureOpts{Context: "other"}) {
+				t.Fatal("signature verifies with another context")
+			}
+
+			// A nil and an empty context are equivalent.
+			sig = scheme.Sign(sk, msg, nil)
+			if !scheme.Verify(pk, msg, sig, &sign.SignatureOpts{}) {
+				t.Fatal("nil and empty context differ")
+			}
+
+			long := &sign.SignatureOpts{Context: strings.Repeat("x", 256)}
+			if scheme.Verify(pk, msg, sig, long) {
+				t.Fatal("verification accepted a context that is too long")
+			}
+			func() {
+				defer func() {
+					if recover() == nil {
+						t.Fatal("signing accepted a context that is too long")
+					}
+				}()
+				scheme.Sign(sk, msg, long)
+			}()
+		})
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	msg := []byte("message")
+	for _, scheme := range allSchemes {
+		t.Run(scheme.Name(), func(t *testing.T) {
+			pk, sk, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			det := &sign.SignatureOpts{Deterministic: true}
+			sig1 := scheme.Sign(sk, msg, det)
+			sig2 := scheme.Sign(sk, msg, det)
+			if !bytes.Equal(sig1, sig2) {
+				t.Fatal("deterministic signatures differ")
+			}
+
+			sig3 := scheme.Sign(sk, msg, nil)
+			if bytes.Equal(sig1, sig3) {
+				t.Fatal("hedged signature equals deterministic one")
+			}
+			if !scheme.Verify(pk, msg, sig3, nil) {
+				t.Fatal("hedged signature does not verify")
+			}
+		})
+	}
+}
+
+func TestPreHash(t *testing.T) {
+	msg := []byte("message")
+	pk, sk, err := mldsa44.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256(msg)
+	sig := make([]byte, mldsa44.SignatureSize)
+	err = mldsa44.SignPreHashTo(sk, crypto.SHA256, digest[:], nil, true, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mldsa44.VerifyPreHash(pk, crypto.SHA256, digest[:], nil, sig) {
+		t.Fatal("HashML-DSA signature does not verify")
+	}
+	if mldsa44.VerifyPreHash(pk, crypto.SHA3_256, digest[:], nil, sig) {
+		t.Fatal("HashML-DSA signature verifies with another hash function")
+	}
+	if mldsa44.Verify(pk, digest[:], nil, sig) {
+		t.Fatal("HashML-DSA signature verifies as ML-DSA signature")
+	}
+
+	err = mldsa44.SignPreHashTo(sk, crypto.MD5, digest[:16], nil, true, sig)
+	if err != mldsa44.ErrUnsupportedHash {
+		t.Fatalf("expected ErrUnsupportedHash, got %v", err)
+	}
+	err = mldsa44.SignPreHashTo(sk, crypto.SHA512, digest[:], nil, true, sig)
+	if err != mldsa44.ErrDigestSize {
+		t.Fatalf("expected ErrDigestSize, got %v", err)
+	}
+
+	// The generic HashML-DSA scheme signs the SHA-512 digest.
+	hpk, err := mldsa44.HashScheme().UnmarshalBinaryPublicKey(pk.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest512 := sha512.Sum512(msg)
+	err = mldsa44.SignPreHashTo(sk, crypto.SHA512, digest512[:], nil, false, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mldsa44.HashScheme().Verify(hpk, msg, sig, nil) {
+		t.Fatal("generic HashML-DSA does not use SHA-512")
+	}
+}
+
+func TestSigner(t *testing.T) {
+	msg := []byte("message")
+	pk, sk, err := mldsa65.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := sk.Sign(rand.Reader, msg, crypto.Hash(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mldsa65.Verify(pk, msg, nil, sig) {
+		t.Fatal("ML-DSA signature does not verify")
+	}
+
+	digest := sha512.Sum512(msg)
+	sig, err = sk.Sign(nil, digest[:], crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mldsa65.VerifyPreHash(pk, crypto.SHA512, digest[:], nil, sig) {
+		t.Fatal("HashML-DSA signature does not verify")
+	}
+	sig2, err := sk.Sign(nil, digest[:], crypto.SHA512)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sig, sig2) {
+		t.Fatal("signatures without randomness differ")
+	}
+
+	if pk.Scheme() == mldsa65.HashScheme() {
+		t.Fatal("ML-DSA key reports HashML-DSA scheme")
+	}
+	hpk, hsk := mldsa87.HashScheme().DeriveKey(make([]byte, mldsa87.SeedSize))
+	if hpk.Scheme() != mldsa87.HashScheme() || hsk.Scheme() != mldsa87.HashScheme() {
+		t.Fatal("HashML-DSA keys report the wrong scheme")
+	}
+}
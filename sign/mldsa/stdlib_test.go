@@ -0,0 +1,91 @@
+//go:build go1.27
+// +build go1.27
+
+package mldsa_test
+
+import (
+	"bytes"
+	"crypto"
+	stdmldsa "crypto/mldsa"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/sha3"
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa44"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa65"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa87"
+)
+
+// Cross-checks the context strings and HashML-DSA against the standard
+// library. The latter does not implement HashML-DSA, so we feed it the
+// message representative μ computed from the HashML-DSA encoding.
+func TestStdlib(t *testing.T) {
+	msg := []byte("message")
+	ctx := "context"
+	for _, tc := range []struct {
+		scheme     sign.Scheme
+		hashScheme sign.Scheme
+		params     stdmldsa.Parameters
+	}{
+		{mldsa44.Scheme(), mldsa44.HashScheme(), stdmldsa.MLDSA44()},
+		{mldsa65.Scheme(), mldsa65.HashScheme(), stdmldsa.MLDSA65()},
+		{mldsa87.Scheme(), mldsa87.HashScheme(), stdmldsa.MLDSA87()},
+	} {
+		t.Run(tc.scheme.Name(), func(t *testing.T) {
+			seed := make([]byte, tc.scheme.SeedSize())
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+			pk, sk := tc.scheme.DeriveKey(seed)
+			stdSk, err := stdmldsa.NewPrivateKey(tc.params, seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ppk, err := pk.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(ppk, stdSk.PublicKey().Bytes()) {
+				t.Fatal("public keys differ")
+			}
+
+			opts := &sign.SignatureOpts{Context: ctx, Deterministic: true}
+			sig := tc.scheme.Sign(sk, msg, opts)
+			sig2, err := stdSk.SignDeterministic(msg, &stdmldsa.Options{Context: ctx})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sig, sig2) {
+				t.Fatal("ML-DSA signatures differ")
+			}
+
+			// μ = SHAKE256(tr ‖ 1 ‖ len(ctx) ‖ ctx ‖ OID ‖ SHA-512(msg))
+			var tr [64]byte
+			h := sha3.NewShake256()
+			_, _ = h.Write(ppk)
+			_, _ = h.Read(tr[:])
+			digest := sha512.Sum512(msg)
+			h.Reset()
+			_, _ = h.Write(tr[:])
+			_, _ = h.Write([]byte{1, byte(len(ctx))})
+			_, _ = h.Write([]byte(ctx))
+			_, _ = h.Write([]byte{
+				0x06, 0x09, 0x60, 0x86, 0x48, 0x01, 0x65, 0x03, 0x04, 0x02, 0x03,
+			})
+			_, _ = h.Write(digest[:])
+			var mu [64]byte
+			_, _ = h.Read(mu[:])
+
+			_, hsk := tc.hashScheme.DeriveKey(seed)
+			sig = tc.hashScheme.Sign(hsk, msg, opts)
+			sig2, err = stdSk.SignDeterministic(mu[:], crypto.MLDSAMu)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(sig, sig2) {
+				t.Fatal("HashML-DSA signatures differ")
+			}
+		})
+	}
+}
@@ -2,35 +2,114 @@
 //
 // Implemented schemes:
 //
-//	Ed25519
-//	Ed448
+//	Ed25519, Ed25519ph
+//	Ed448, Ed448ph
 //	Ed25519-Dilithium2
 //	Ed448-Dilithium3
+//	Dilithium2, Dilithium3, Dilithium5
+//	Dilithium2-AES, Dilithium3-AES, Dilithium5-AES
+//	ML-DSA-44, ML-DSA-65, ML-DSA-87
+//	HashML-DSA-44-with-SHA512, HashML-DSA-65-with-SHA512,
+//	HashML-DSA-87-with-SHA512
+//	SLH-DSA-SHA2-128s, SLH-DSA-SHAKE-128s, SLH-DSA-SHA2-128f, SLH-DSA-SHAKE-128f
+//	SLH-DSA-SHA2-192s, SLH-DSA-SHAKE-192s, SLH-DSA-SHA2-192f, SLH-DSA-SHAKE-192f
+//	SLH-DSA-SHA2-256s, SLH-DSA-SHAKE-256s, SLH-DSA-SHA2-256f, SLH-DSA-SHAKE-256f
+//	Falcon-512, Falcon-1024
+//
+// Pre-hash schemes, such as Ed25519ph and HashML-DSA, implement
+// sign.StreamingScheme.
+//
+// The security properties of the registered schemes, such as their NIST
+// security level, are available through Metadata and Query.
 package schemes
 
 import (
 	"strings"
 
+	"github.com/katzenpost/circl/metadata"
 	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/dilithium/mode2"
+	"github.com/katzenpost/circl/sign/dilithium/mode2aes"
+	"github.com/katzenpost/circl/sign/dilithium/mode3"
+	"github.com/katzenpost/circl/sign/dilithium/mode3aes"
+	"github.com/katzenpost/circl/sign/dilithium/mode5"
+	"github.com/katzenpost/circl/sign/dilithium/mode5aes"
 	"github.com/katzenpost/circl/sign/ed25519"
 	"github.com/katzenpost/circl/sign/ed448"
 	"github.com/katzenpost/circl/sign/eddilithium2"
 	"github.com/katzenpost/circl/sign/eddilithium3"
+	"github.com/katzenpost/circl/sign/falcon/falcon1024"
+	"github.com/katzenpost/circl/sign/falcon/falcon512"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa44"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa65"
+	"github.com/katzenpost/circl/sign/mldsa/mldsa87"
+	"github.com/katzenpost/circl/sign/slhdsa"
 )
 
-var allSchemes = [...]sign.Scheme{
-	ed25519.Scheme(),
-	ed448.Scheme(),
-	eddilithium2.Scheme(),
-	eddilithium3.Scheme(),
+type entry struct {
+	scheme sign.Scheme
+	md     metadata.Metadata
+}
+
+// md returns the metadata of a scheme, which is a hybrid if it has
+// components from several families.
+func md(level int, status metadata.Status, families ...metadata.Family) metadata.Metadata {
+	return metadata.Metadata{
+		SecurityLevel: level,
+		Families:      families,
+		Hybrid:        len(families) > 1,
+		Status:        status,
+	}
+}
+
+var allEntries = [...]entry{
+	{ed25519.Scheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{ed25519.PhScheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{ed448.Scheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{ed448.PhScheme(), md(0, metadata.Standardized, metadata.EllipticCurve)},
+	{eddilithium2.Scheme(), md(2, metadata.Submission, metadata.Lattice, metadata.EllipticCurve)},
+	{eddilithium3.Scheme(), md(3, metadata.Submission, metadata.Lattice, metadata.EllipticCurve)},
+	{mode2.Scheme(), md(2, metadata.Submission, metadata.Lattice)},
+	{mode3.Scheme(), md(3, metadata.Submission, metadata.Lattice)},
+	{mode5.Scheme(), md(5, metadata.Submission, metadata.Lattice)},
+	{mode2aes.Scheme(), md(2, metadata.Submission, metadata.Lattice)},
+	{mode3aes.Scheme(), md(3, metadata.Submission, metadata.Lattice)},
+	{mode5aes.Scheme(), md(5, metadata.Submission, metadata.Lattice)},
+	{mldsa44.Scheme(), md(2, metadata.Standardized, metadata.Lattice)},
+	{mldsa65.Scheme(), md(3, metadata.Standardized, metadata.Lattice)},
+	{mldsa87.Scheme(), md(5, metadata.Standardized, metadata.Lattice)},
+	{mldsa44.HashScheme(), md(2, metadata.Standardized, metadata.Lattice)},
+	{mldsa65.HashScheme(), md(3, metadata.Standardized, metadata.Lattice)},
+	{mldsa87.HashScheme(), md(5, metadata.Standardized, metadata.Lattice)},
+	{slhdsa.SHA2_128s.Scheme(), md(1, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHAKE_128s.Scheme(), md(1, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHA2_128f.Scheme(), md(1, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHAKE_128f.Scheme(), md(1, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHA2_192s.Scheme(), md(3, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHAKE_192s.Scheme(), md(3, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHA2_192f.Scheme(), md(3, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHAKE_192f.Scheme(), md(3, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHA2_256s.Scheme(), md(5, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHAKE_256s.Scheme(), md(5, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHA2_256f.Scheme(), md(5, metadata.Standardized, metadata.HashBased)},
+	{slhdsa.SHAKE_256f.Scheme(), md(5, metadata.Standardized, metadata.HashBased)},
+	{falcon512.Scheme(), md(1, metadata.Draft, metadata.Lattice)},
+	{falcon1024.Scheme(), md(5, metadata.Draft, metadata.Lattice)},
 }
 
-var allSchemeNames map[string]sign.Scheme
+var (
+	allSchemes     []sign.Scheme
+	allSchemeNames map[string]sign.Scheme
+	allMetadata    map[string]metadata.Metadata
+)
 
 func init() {
 	allSchemeNames = make(map[string]sign.Scheme)
-	for _, scheme := range allSchemes {
-		allSchemeNames[strings.ToLower(scheme.Name())] = scheme
+	allMetadata = make(map[string]metadata.Metadata)
+	for _, e := range allEntries {
+		allSchemes = append(allSchemes, e.scheme)
+		allSchemeNames[strings.ToLower(e.scheme.Name())] = e.scheme
+		allMetadata[strings.ToLower(e.scheme.Name())] = e.md
 	}
 }
 
@@ -43,4 +122,27 @@ func ByName(name string) sign.Scheme {
 }
 
 // All returns all signature schemes supported.
-func All() []sign.Scheme { a := allSchemes; return a[:] }
+func All() []sign.Scheme { return append([]sign.Scheme(nil), allSchemes...) }
+
+// Metadata returns the security properties of the scheme. Schemes
+// implementing metadata.Describer provide their own; otherwise they are
+// looked up in the register, and ok is false if the scheme is not listed.
+func Metadata(scheme sign.Scheme) (m metadata.Metadata, ok bool) {
+	if d, isDescriber := scheme.(metadata.Describer); isDescriber {
+		return d.Metadata(), true
+	}
+	m, ok = allMetadata[strings.ToLower(scheme.Name())]
+	return m, ok
+}
+
+// Query returns the schemes listed by All whose metadata satisfy the given
+// predicate.
+func Query(pred func(metadata.Metadata) bool) []sign.Scheme {
+	var ret []sign.Scheme
+	for _, scheme := range allSchemes {
+		if m, _ := Metadata(scheme); pred(m) {
+			ret = append(ret, scheme)
+		}
+	}
+	return ret
+}
@@ -1,9 +1,11 @@
 package schemes_test
 
 import (
+	"bytes"
 	"fmt"
 	"testing"
 
+	"github.com/katzenpost/circl/metadata"
 	"github.com/katzenpost/circl/sign"
 	"github.com/katzenpost/circl/sign/schemes"
 )
@@ -14,6 +16,80 @@ func TestCaseSensitivity(t *testing.T) {
 	}
 }
 
+func TestMetadata(t *testing.T) {
+	for _, scheme := range schemes.All() {
+		m, ok := schemes.Metadata(scheme)
+		if !ok {
+			t.Fatalf("%v has no metadata", scheme.Name())
+		}
+		if m.Status == 0 || len(m.Families) == 0 {
+			t.Fatalf("%v: incomplete metadata %v", scheme.Name(), m)
+		}
+		if m.Hybrid != (len(m.Families) > 1) {
+			t.Fatalf("%v: inconsistent metadata %v", scheme.Name(), m)
+		}
+		if m.Status == metadata.Broken {
+			t.Fatalf("%v should not be listed", scheme.Name())
+		}
+	}
+
+	hybrid3 := schemes.Query(func(m metadata.Metadata) bool {
+		return m.SecurityLevel >= 3 && m.Hybrid
+	})
+	if len(hybrid3) != 1 || hybrid3[0].Name() != "Ed448-Dilithium3" {
+		t.Fatal()
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	msg := make([]byte, 10000)
+	for i := range msg {
+		msg[i] = byte(i)
+	}
+	for _, scheme := range schemes.All() {
+		scheme, ok := scheme.(sign.StreamingScheme)
+		if !ok {
+			continue
+		}
+		t.Run(scheme.Name(), func(t *testing.T) {
+			pk, sk, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			opts := &sign.SignatureOpts{Context: "A context", Deterministic: true}
+
+			signer := scheme.NewSigner(sk, opts)
+			verifier := scheme.NewVerifier(pk, opts)
+			for i := 0; i < len(msg); i += 999 {
+				end := i + 999
+				if end > len(msg) {
+					end = len(msg)
+				}
+				_, _ = signer.Write(msg[i:end])
+				_, _ = verifier.Write(msg[i:end])
+			}
+			sig := signer.Sign()
+			if !bytes.Equal(sig, signer.Sign()) {
+				t.Fatal("Sign changed the state")
+			}
+			if !bytes.Equal(sig, scheme.Sign(sk, msg, opts)) {
+				t.Fatal("streamed signature differs")
+			}
+			if !verifier.Verify(sig) || !scheme.Verify(pk, msg, sig, opts) {
+				t.Fatal("streamed signature rejected")
+			}
+
+			_, _ = verifier.Write([]byte{0})
+			if verifier.Verify(sig) {
+				t.Fatal("signature of another message accepted")
+			}
+			if scheme.NewVerifier(pk, nil).Verify(scheme.NewSigner(sk, opts).Sign()) {
+				t.Fatal("signature with another context accepted")
+			}
+		})
+	}
+}
+
 func TestApi(t *testing.T) {
 	allSchemes := schemes.All()
 	for _, scheme := range allSchemes {
@@ -114,9 +190,37 @@ func Example() {
 	}
 	// Output:
 	// Ed25519
+	// Ed25519ph
 	// Ed448
+	// Ed448ph
 	// Ed25519-Dilithium2
 	// Ed448-Dilithium3
+	// Dilithium2
+	// Dilithium3
+	// Dilithium5
+	// Dilithium2-AES
+	// Dilithium3-AES
+	// Dilithium5-AES
+	// ML-DSA-44
+	// ML-DSA-65
+	// ML-DSA-87
+	// HashML-DSA-44-with-SHA512
+	// HashML-DSA-65-with-SHA512
+	// HashML-DSA-87-with-SHA512
+	// SLH-DSA-SHA2-128s
+	// SLH-DSA-SHAKE-128s
+	// SLH-DSA-SHA2-128f
+	// SLH-DSA-SHAKE-128f
+	// SLH-DSA-SHA2-192s
+	// SLH-DSA-SHAKE-192s
+	// SLH-DSA-SHA2-192f
+	// SLH-DSA-SHAKE-192f
+	// SLH-DSA-SHA2-256s
+	// SLH-DSA-SHAKE-256s
+	// SLH-DSA-SHA2-256f
+	// SLH-DSA-SHAKE-256f
+	// Falcon-512
+	// Falcon-1024
 }
 
 func BenchmarkGenerateKeyPair(b *testing.B) {
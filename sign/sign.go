@@ -3,18 +3,30 @@
 // A register of schemes is available in the package
 //
 //	github.com/katzenpost/circl/sign/schemes
+//
+// Pre-hash schemes implement StreamingScheme, which signs and verifies
+// messages too large to be held in memory.
+//
+// NewComposite combines two schemes into a composite one, such as a
+// post-quantum scheme with a traditional one.
 package sign
 
 import (
 	"crypto"
 	"encoding"
 	"errors"
+	"io"
 )
 
 type SignatureOpts struct {
 	// If non-empty, includes the given context in the signature if supported
 	// and will cause an error during signing otherwise.
 	Context string
+
+	// If true, requests a deterministic signature from schemes that
+	// support both hedged and deterministic signing, such as ML-DSA.
+	// Ignored by other schemes.
+	Deterministic bool
 }
 
 // A public key is used to verify a signature set by the corresponding private
@@ -87,6 +99,45 @@ type Scheme interface {
 	SupportsContext() bool
 }
 
+// A StreamingScheme is a Scheme that can sign and verify a message written
+// piecewise, so that it does not need to be held in memory. These are
+// pre-hash schemes, such as Ed25519ph, which only sign a digest of the
+// message.
+type StreamingScheme interface {
+	Scheme
+
+	// Returns a Signer of the message written to it using the PrivateKey.
+	// The signature is the same as that returned by Sign on the whole
+	// message with the same opts.
+	//
+	// Panics if key is nil or wrong type or opts context is not supported.
+	NewSigner(sk PrivateKey, opts *SignatureOpts) Signer
+
+	// Returns a Verifier of signatures of the message written to it set
+	// by the private key corresponding to the given public key.
+	//
+	// Panics if key is nil or wrong type or opts context is not supported.
+	NewVerifier(pk PublicKey, opts *SignatureOpts) Verifier
+}
+
+// A Signer signs the message written to it.
+type Signer interface {
+	io.Writer
+
+	// Returns a signature on the message written so far. It does not
+	// change the underlying state.
+	Sign() []byte
+}
+
+// A Verifier checks signatures on the message written to it.
+type Verifier interface {
+	io.Writer
+
+	// Checks whether the given signature is valid on the message written
+	// so far. It does not change the underlying state.
+	Verify(signature []byte) bool
+}
+
 var (
 	// ErrTypeMismatch is the error used if types of, for instance, private
 	// and public keys don't match.
@@ -107,4 +158,8 @@ var (
 	// ErrContextNotSupported is the error used if a context is not
 	// supported.
 	ErrContextNotSupported = errors.New("context not supported")
+
+	// ErrContextTooLong is the error used if the context string is longer
+	// than the scheme allows.
+	ErrContextTooLong = errors.New("context string too long")
 )
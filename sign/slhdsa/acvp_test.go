@@ -0,0 +1,292 @@
+package slhdsa
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+// []byte but is encoded in hex for JSON
+type HexBytes []byte
+
+func (b HexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hex.EncodeToString(b))
+}
+
+func (b *HexBytes) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*b, err = hex.DecodeString(s)
+	return err
+}
+
+func readGzip(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func idByName(name string) (ID, bool) {
+	for _, id := range allIDs() {
+		if id.String() == name {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// TestACVP checks the NIST ACVP vectors for SLH-DSA, see testdata/README.md.
+func TestACVP(t *testing.T) {
+	for _, sub := range []string{
+		"keyGen",
+		"sigGen",
+		"sigVer",
+	} {
+		t.Run(sub, func(t *testing.T) {
+			testACVP(t, sub)
+		})
+	}
+}
+
+// nolint:funlen,gocyclo
+func testACVP(t *testing.T, sub string) {
+	dir := "testdata/SLH-DSA-" + sub + "-FIPS205/"
+	buf, err := readGzip(dir + "prompt.json.gz")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("missing %s, see testdata/README.md", dir)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var prompt struct {
+		TestGroups []json.RawMessage `json:"testGroups"`
+	}
+
+	if err = json.Unmarshal(buf, &prompt); err != nil {
+		t.Fatal(err)
+	}
+
+	buf, err = readGzip(dir + "expectedResults.json.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var results struct {
+		TestGroups []json.RawMessage `json:"testGroups"`
+	}
+
+	if err := json.Unmarshal(buf, &results); err != nil {
+		t.Fatal(err)
+	}
+
+	rawResults := make(map[int]json.RawMessage)
+
+	for _, rawGroup := range results.TestGroups {
+		var abstractGroup struct {
+			Tests []json.RawMessage `json:"tests"`
+		}
+		if err := json.Unmarshal(rawGroup, &abstractGroup); err != nil {
+			t.Fatal(err)
+		}
+		for _, rawTest := range abstractGroup.Tests {
+			var abstractTest struct {
+				TcID int `json:"tcId"`
+			}
+			if err := json.Unmarshal(rawTest, &abstractTest); err != nil {
+				t.Fatal(err)
+			}
+			if _, exists := rawResults[abstractTest.TcID]; exists {
+				t.Fatalf("Duplicate test id: %d", abstractTest.TcID)
+			}
+			rawResults[abstractTest.TcID] = rawTest
+		}
+	}
+
+	for _, rawGroup := range prompt.TestGroups {
+		var abstractGroup struct {
+			TestType     string `json:"testType"`
+			ParameterSet string `json:"parameterSet"`
+
+			// Only present in the revisions of the vectors that test the
+			// external interface, in which case HashSLH-DSA is skipped.
+			SignatureInterface string `json:"signatureInterface"`
+			PreHash            string `json:"preHash"`
+		}
+		if err := json.Unmarshal(rawGroup, &abstractGroup); err != nil {
+			t.Fatal(err)
+		}
+		id, ok := idByName(abstractGroup.ParameterSet)
+		if !ok {
+			t.Fatalf("unknown parameter set %s", abstractGroup.ParameterSet)
+		}
+		if testing.Short() && isSmall(id) && sub != "keyGen" {
+			continue
+		}
+		if abstractGroup.PreHash == "preHash" {
+			continue
+		}
+		external := abstractGroup.SignatureInterface == "external"
+		scheme := id.Scheme()
+
+		// Returns M' of slh_sign and slh_verify for the external
+		// interface, and msg for the internal one.
+		message := func(msg, ctx []byte) [][]byte {
+			if !external {
+				return [][]byte{msg}
+			}
+			return [][]byte{{0, byte(len(ctx))}, ctx, msg}
+		}
+
+		switch {
+		case abstractGroup.TestType == "AFT" && sub == "keyGen":
+			var group struct {
+				Tests []struct {
+					TcID   int      `json:"tcId"`
+					SkSeed HexBytes `json:"skSeed"`
+					SkPrf  HexBytes `json:"skPrf"`
+					PkSeed HexBytes `json:"pkSeed"`
+				}
+			}
+			if err := json.Unmarshal(rawGroup, &group); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, test := range group.Tests {
+				var result struct {
+					Pk HexBytes `json:"pk"`
+					Sk HexBytes `json:"sk"`
+				}
+				rawResult, ok := rawResults[test.TcID]
+				if !ok {
+					t.Fatalf("Missing result: %d", test.TcID)
+				}
+				if err := json.Unmarshal(rawResult, &result); err != nil {
+					t.Fatal(err)
+				}
+
+				var seed []byte
+				seed = append(seed, test.SkSeed...)
+				seed = append(seed, test.SkPrf...)
+				seed = append(seed, test.PkSeed...)
+				pk, sk := NewKeyFromSeed(id, seed)
+
+				pk2, err := scheme.UnmarshalBinaryPublicKey(result.Pk)
+				if err != nil {
+					t.Fatalf("tc=%d: %v", test.TcID, err)
+				}
+				sk2, err := scheme.UnmarshalBinaryPrivateKey(result.Sk)
+				if err != nil {
+					t.Fatalf("tc=%d: %v", test.TcID, err)
+				}
+
+				if !pk.Equal(pk2) {
+					t.Fatalf("tc=%d: pk does not match", test.TcID)
+				}
+				if !sk.Equal(sk2) {
+					t.Fatalf("tc=%d: sk does not match", test.TcID)
+				}
+			}
+		case abstractGroup.TestType == "AFT" && sub == "sigGen":
+			var group struct {
+				Deterministic bool `json:"deterministic"`
+				Tests         []struct {
+					TcID                 int      `json:"tcId"`
+					Sk                   HexBytes `json:"sk"`
+					Message              HexBytes `json:"message"`
+					Context              HexBytes `json:"context"`
+					AdditionalRandomness HexBytes `json:"additionalRandomness"`
+				}
+			}
+			if err := json.Unmarshal(rawGroup, &group); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, test := range group.Tests {
+				var result struct {
+					Signature HexBytes `json:"signature"`
+				}
+				rawResult, ok := rawResults[test.TcID]
+				if !ok {
+					t.Fatalf("Missing result: %d", test.TcID)
+				}
+				if err := json.Unmarshal(rawResult, &result); err != nil {
+					t.Fatal(err)
+				}
+
+				sk, err := scheme.UnmarshalBinaryPrivateKey(test.Sk)
+				if err != nil {
+					t.Fatalf("tc=%d: %v", test.TcID, err)
+				}
+				priv := sk.(*PrivateKey)
+
+				optRand := priv.pkSeed
+				if !group.Deterministic {
+					optRand = test.AdditionalRandomness
+				}
+
+				sig := make([]byte, scheme.SignatureSize())
+				priv.signInternal(sig, message(test.Message, test.Context), optRand)
+
+				if !bytes.Equal(sig, result.Signature) {
+					t.Fatalf("tc=%d: signature does not match", test.TcID)
+				}
+			}
+		case abstractGroup.TestType == "AFT" && sub == "sigVer":
+			var group struct {
+				Tests []struct {
+					TcID      int      `json:"tcId"`
+					Pk        HexBytes `json:"pk"`
+					Message   HexBytes `json:"message"`
+					Context   HexBytes `json:"context"`
+					Signature HexBytes `json:"signature"`
+				}
+			}
+			if err := json.Unmarshal(rawGroup, &group); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, test := range group.Tests {
+				var result struct {
+					TestPassed bool `json:"testPassed"`
+				}
+				rawResult, ok := rawResults[test.TcID]
+				if !ok {
+					t.Fatalf("Missing result: %d", test.TcID)
+				}
+				if err := json.Unmarshal(rawResult, &result); err != nil {
+					t.Fatal(err)
+				}
+
+				pk, err := scheme.UnmarshalBinaryPublicKey(test.Pk)
+				if err != nil {
+					t.Fatalf("tc=%d: %v", test.TcID, err)
+				}
+
+				passed := pk.(*PublicKey).verifyInternal(
+					message(test.Message, test.Context), test.Signature)
+				if passed != result.TestPassed {
+					t.Fatalf("tc=%d: verification %v ≠ %v",
+						test.TcID, passed, result.TestPassed)
+				}
+			}
+		default:
+			t.Fatalf("unknown type %s for %s", abstractGroup.TestType, sub)
+		}
+	}
+}
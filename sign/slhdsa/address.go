@@ -0,0 +1,50 @@
+package slhdsa
+
+import "encoding/binary"
+
+// Types of addresses, see Section 4.2 of FIPS 205.
+const (
+	addrWotsHash = iota
+	addrWotsPk
+	addrTree
+	addrForsTree
+	addrForsRoots
+	addrWotsPrf
+	addrForsPrf
+)
+
+// address is the 32-byte ADRS structure used for domain separation.
+type address [32]byte
+
+func (a *address) setLayer(l uint32) { binary.BigEndian.PutUint32(a[0:], l) }
+
+func (a *address) setTree(t uint64) {
+	binary.BigEndian.PutUint32(a[4:], 0)
+	binary.BigEndian.PutUint64(a[8:], t)
+}
+
+func (a *address) setTypeAndClear(t uint32) {
+	binary.BigEndian.PutUint32(a[16:], t)
+	for i := 20; i < 32; i++ {
+		a[i] = 0
+	}
+}
+
+func (a *address) setKeyPair(i uint32) { binary.BigEndian.PutUint32(a[20:], i) }
+func (a *address) keyPair() uint32     { return binary.BigEndian.Uint32(a[20:]) }
+
+func (a *address) setChain(i uint32)      { binary.BigEndian.PutUint32(a[24:], i) }
+func (a *address) setTreeHeight(z uint32) { binary.BigEndian.PutUint32(a[24:], z) }
+
+func (a *address) setHash(i uint32)      { binary.BigEndian.PutUint32(a[28:], i) }
+func (a *address) setTreeIndex(i uint32) { binary.BigEndian.PutUint32(a[28:], i) }
+func (a *address) treeIndex() uint32     { return binary.BigEndian.Uint32(a[28:]) }
+
+// compress writes the 22-byte compressed address ADRSc used by the SHA2
+// instantiation, see Section 11.2 of FIPS 205.
+func (a *address) compress(out *[22]byte) {
+	out[0] = a[3]
+	copy(out[1:9], a[8:16])
+	out[9] = a[19]
+	copy(out[10:], a[20:32])
+}
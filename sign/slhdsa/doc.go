@@ -0,0 +1,27 @@
+// Package slhdsa implements the stateless hash-based signature scheme
+// SLH-DSA as defined in FIPS 205.
+//
+// https://doi.org/10.6028/NIST.FIPS.205
+//
+// All twelve parameter sets are supported: they combine the SHA2 or SHAKE
+// instantiation with the security levels 128, 192 and 256, each of which is
+// available in a variant optimized for small signatures (s) or for fast
+// signing (f). A parameter set is selected by its ID, for instance
+//
+//	pk, sk, err := slhdsa.GenerateKey(nil, slhdsa.SHAKE_128s)
+//
+// Signatures support the context strings of FIPS 205, and are either hedged
+// or deterministic. The security of SLH-DSA relies only on the hash
+// functions, which makes it a conservative choice, at the cost of large
+// signatures and slow signing.
+//
+// When available, the SHAKE instantiation computes four hash chains or
+// tree leaves at once with the four-way Keccak-f[1600] permutation of
+//
+//	github.com/katzenpost/circl/simd/keccakf1600
+//
+// The parameter sets are also available through the generic signatures
+// API under
+//
+//	github.com/katzenpost/circl/sign/schemes
+package slhdsa
@@ -0,0 +1,132 @@
+package slhdsa
+
+// FORS few-time signatures, see Section 8 of FIPS 205.
+
+// forsIndices splits the message digest md into k indices of a bits each,
+// most significant bit first.
+func (s *state) forsIndices(indices []uint32, md []byte) {
+	var (
+		total uint32
+		bits  int
+		in    int
+	)
+	for i := range indices {
+		for bits < s.a {
+			total = total<<8 | uint32(md[in])
+			in++
+			bits += 8
+		}
+		bits -= s.a
+		indices[i] = (total >> bits) & (1<<s.a - 1)
+	}
+}
+
+// forsSkGen writes the FORS secret value with index idx of the FORS key
+// pair of adrs into out.
+func (s *state) forsSkGen(out []byte, adrs *address, idx uint32) {
+	skAdrs := *adrs
+	skAdrs.setTypeAndClear(addrForsPrf)
+	skAdrs.setKeyPair(adrs.keyPair())
+	skAdrs.setTreeIndex(idx)
+	s.prf(out, &skAdrs)
+}
+
+// forsNode writes the node at index i and height z of the FORS trees of
+// adrs into out.
+func (s *state) forsNode(out []byte, i uint32, z int, adrs *address) {
+	n := s.n
+	if z == 0 {
+		var sk [32]byte
+		s.forsSkGen(sk[:n], adrs, i)
+		adrs.setTreeHeight(0)
+		adrs.setTreeIndex(i)
+		s.f(out, sk[:n], adrs)
+		return
+	}
+
+	var buf [4 * 32]byte
+	if x4, ok := s.hasher.(hasherX4); ok && z == 2 {
+		// Compute the four leaves below this node in parallel.
+		var (
+			skAdrsX4, adrsX4 [4]address
+			outX4            [4][]byte
+		)
+		for j := 0; j < 4; j++ {
+			idx := 4*i + uint32(j)
+			skAdrsX4[j] = *adrs
+			skAdrsX4[j].setTypeAndClear(addrForsPrf)
+			skAdrsX4[j].setKeyPair(adrs.keyPair())
+			skAdrsX4[j].setTreeIndex(idx)
+			adrsX4[j] = *adrs
+			adrsX4[j].setTreeHeight(0)
+			adrsX4[j].setTreeIndex(idx)
+			outX4[j] = buf[j*n : (j+1)*n]
+		}
+		x4.prfX4(&outX4, &skAdrsX4)
+		x4.fX4(&outX4, &outX4, &adrsX4)
+
+		adrs.setTreeHeight(1)
+		adrs.setTreeIndex(2 * i)
+		s.h(buf[:n], buf[:n], buf[n:2*n], adrs)
+		adrs.setTreeIndex(2*i + 1)
+		s.h(buf[n:2*n], buf[2*n:3*n], buf[3*n:4*n], adrs)
+	} else {
+		s.forsNode(buf[:n], 2*i, z-1, adrs)
+		s.forsNode(buf[n:2*n], 2*i+1, z-1, adrs)
+	}
+
+	adrs.setTreeHeight(uint32(z))
+	adrs.setTreeIndex(i)
+	s.h(out, buf[:n], buf[n:2*n], adrs)
+}
+
+// forsSign writes the FORS signature of the message digest md with the
+// FORS key pair of adrs into sig.
+func (s *state) forsSign(sig, md []byte, adrs *address) {
+	n, a := s.n, s.a
+	indices := make([]uint32, s.k)
+	s.forsIndices(indices, md)
+
+	for i, idx := range indices {
+		sigI := sig[i*(a+1)*n : (i+1)*(a+1)*n]
+		s.forsSkGen(sigI[:n], adrs, uint32(i)<<a+idx)
+		for j := 0; j < a; j++ {
+			sIdx := (idx >> j) ^ 1
+			s.forsNode(sigI[(j+1)*n:(j+2)*n], uint32(i)<<(a-j)+sIdx, j, adrs)
+		}
+	}
+}
+
+// forsPkFromSig writes the FORS public key computed from the signature sig
+// of the message digest md into out.
+func (s *state) forsPkFromSig(out, sig, md []byte, adrs *address) {
+	n, a := s.n, s.a
+	indices := make([]uint32, s.k)
+	s.forsIndices(indices, md)
+	roots := make([]byte, s.k*n)
+
+	for i, idx := range indices {
+		sigI := sig[i*(a+1)*n : (i+1)*(a+1)*n]
+		node := roots[i*n : (i+1)*n]
+		adrs.setTreeHeight(0)
+		adrs.setTreeIndex(uint32(i)<<a + idx)
+		s.f(node, sigI[:n], adrs)
+
+		for j := 0; j < a; j++ {
+			auth := sigI[(j+1)*n : (j+2)*n]
+			adrs.setTreeHeight(uint32(j + 1))
+			if (idx>>j)&1 == 0 {
+				adrs.setTreeIndex(adrs.treeIndex() / 2)
+				s.h(node, node, auth, adrs)
+			} else {
+				adrs.setTreeIndex((adrs.treeIndex() - 1) / 2)
+				s.h(node, auth, node, adrs)
+			}
+		}
+	}
+
+	pkAdrs := *adrs
+	pkAdrs.setTypeAndClear(addrForsRoots)
+	pkAdrs.setKeyPair(adrs.keyPair())
+	s.t(out, roots, &pkAdrs)
+}
@@ -0,0 +1,291 @@
+package slhdsa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"encoding/binary"
+	"hash"
+
+	"github.com/katzenpost/circl/internal/sha3"
+	"github.com/katzenpost/circl/simd/keccakf1600"
+)
+
+// hasher implements the tweakable hash functions and pseudorandom functions
+// of Section 11 of FIPS 205 for a fixed PK.seed and SK.seed.
+//
+// The output may alias the inputs. A hasher is not safe for concurrent use.
+type hasher interface {
+	// prf computes PRF(PK.seed, SK.seed, adrs).
+	prf(out []byte, adrs *address)
+
+	// f computes F(PK.seed, adrs, in).
+	f(out, in []byte, adrs *address)
+
+	// h computes H(PK.seed, adrs, left ‖ right).
+	h(out, left, right []byte, adrs *address)
+
+	// t computes T_l(PK.seed, adrs, in) where l = len(in)/n.
+	t(out, in []byte, adrs *address)
+
+	// prfMsg computes PRF_msg(SK.prf, optRand, msg).
+	prfMsg(out, skPrf, optRand []byte, msg [][]byte)
+
+	// hMsg computes H_msg(r, PK.seed, pkRoot, msg).
+	hMsg(out, r, pkRoot []byte, msg [][]byte)
+}
+
+// hasherX4 is implemented by hashers that can compute four instances of
+// prf and f in parallel.
+type hasherX4 interface {
+	prfX4(out *[4][]byte, adrs *[4]address)
+	fX4(out, in *[4][]byte, adrs *[4]address)
+}
+
+func newHasher(p *params, pkSeed, skSeed []byte) hasher {
+	if p.sha2 {
+		return newSHA2Hasher(p, pkSeed, skSeed)
+	}
+	s := shakeHasher{n: p.n, pkSeed: pkSeed, skSeed: skSeed}
+	if keccakf1600.IsEnabledX4() {
+		return &shakeHasherX4{s}
+	}
+	return &s
+}
+
+// shakeHasher is the SHAKE instantiation of Section 11.1 of FIPS 205.
+//
+// The inputs of prf, f and h, PK.seed ‖ ADRS ‖ X, fit in a single block
+// of SHAKE256, so we can call the permutation directly.
+type shakeHasher struct {
+	n      int
+	pkSeed []byte
+	skSeed []byte
+}
+
+// shakeRate is the rate of SHAKE256 in bytes.
+const shakeRate = 136
+
+// block returns the padded block PK.seed ‖ adrs ‖ in1 ‖ in2.
+func (s *shakeHasher) block(adrs *address, in1, in2 []byte) (buf [shakeRate]byte) {
+	l := copy(buf[:], s.pkSeed)
+	l += copy(buf[l:], adrs[:])
+	l += copy(buf[l:], in1)
+	l += copy(buf[l:], in2)
+	buf[l] ^= 0x1f
+	buf[shakeRate-1] ^= 0x80
+	return buf
+}
+
+func (s *shakeHasher) hashBlock(out []byte, adrs *address, in1, in2 []byte) {
+	var a [25]uint64
+	buf := s.block(adrs, in1, in2)
+	for i := 0; i < shakeRate/8; i++ {
+		a[i] = binary.LittleEndian.Uint64(buf[8*i:])
+	}
+	sha3.KeccakF1600(&a, false)
+	for i := 0; i < s.n/8; i++ {
+		binary.LittleEndian.PutUint64(out[8*i:], a[i])
+	}
+}
+
+func (s *shakeHasher) hashBlockX4(out *[4][]byte, adrs *[4]address, in *[4][]byte) {
+	var perm keccakf1600.StateX4
+	a := perm.Initialize(false)
+	for j := 0; j < 4; j++ {
+		var buf [shakeRate]byte
+		if in == nil {
+			buf = s.block(&adrs[j], s.skSeed, nil)
+		} else {
+			buf = s.block(&adrs[j], in[j], nil)
+		}
+		for i := 0; i < shakeRate/8; i++ {
+			a[4*i+j] = binary.LittleEndian.Uint64(buf[8*i:])
+		}
+	}
+	perm.Permute()
+	for j := 0; j < 4; j++ {
+		for i := 0; i < s.n/8; i++ {
+			binary.LittleEndian.PutUint64(out[j][8*i:], a[4*i+j])
+		}
+	}
+}
+
+func (s *shakeHasher) prf(out []byte, adrs *address) {
+	s.hashBlock(out, adrs, s.skSeed, nil)
+}
+
+func (s *shakeHasher) f(out, in []byte, adrs *address) {
+	s.hashBlock(out, adrs, in, nil)
+}
+
+func (s *shakeHasher) h(out, left, right []byte, adrs *address) {
+	s.hashBlock(out, adrs, left, right)
+}
+
+func (s *shakeHasher) t(out, in []byte, adrs *address) {
+	x := sha3.NewShake256()
+	_, _ = x.Write(s.pkSeed)
+	_, _ = x.Write(adrs[:])
+	_, _ = x.Write(in)
+	_, _ = x.Read(out[:s.n])
+}
+
+func (s *shakeHasher) prfMsg(out, skPrf, optRand []byte, msg [][]byte) {
+	x := sha3.NewShake256()
+	_, _ = x.Write(skPrf)
+	_, _ = x.Write(optRand)
+	for _, m := range msg {
+		_, _ = x.Write(m)
+	}
+	_, _ = x.Read(out[:s.n])
+}
+
+func (s *shakeHasher) hMsg(out, r, pkRoot []byte, msg [][]byte) {
+	x := sha3.NewShake256()
+	_, _ = x.Write(r)
+	_, _ = x.Write(s.pkSeed)
+	_, _ = x.Write(pkRoot)
+	for _, m := range msg {
+		_, _ = x.Write(m)
+	}
+	_, _ = x.Read(out)
+}
+
+// shakeHasherX4 is a shakeHasher that uses the four-way Keccak-f[1600]
+// permutation for prfX4 and fX4.
+type shakeHasherX4 struct {
+	shakeHasher
+}
+
+func (s *shakeHasherX4) prfX4(out *[4][]byte, adrs *[4]address) {
+	s.hashBlockX4(out, adrs, nil)
+}
+
+func (s *shakeHasherX4) fX4(out, in *[4][]byte, adrs *[4]address) {
+	s.hashBlockX4(out, adrs, in)
+}
+
+// sha2Hasher is the SHA2 instantiation of Sections 11.2.1 and 11.2.2
+// of FIPS 205.
+//
+// PK.seed is padded to a full block of the hash function, so we store the
+// state after absorbing it and restore it before every call.
+type sha2Hasher struct {
+	n      int
+	m      int
+	pkSeed []byte
+	skSeed []byte
+
+	// SHA-256 is used for prf and f, and for the other functions at
+	// security category 1. SHA-512 is used for the other functions at
+	// security categories 3 and 5.
+	small, big         hash.Hash
+	smallSeed, bigSeed []byte
+	newBig             func() hash.Hash
+	bigSize            int
+	buf                [sha512.Size]byte
+	addrc              [22]byte
+}
+
+func newSHA2Hasher(p *params, pkSeed, skSeed []byte) *sha2Hasher {
+	s := &sha2Hasher{
+		n:      p.n,
+		m:      p.m,
+		pkSeed: pkSeed,
+		skSeed: skSeed,
+		small:  sha256.New(),
+	}
+	if p.n == 16 {
+		s.newBig = sha256.New
+	} else {
+		s.newBig = sha512.New
+	}
+	s.big = s.newBig()
+	s.bigSize = s.big.Size()
+	s.smallSeed = absorbSeed(s.small, pkSeed)
+	s.bigSeed = absorbSeed(s.big, pkSeed)
+	return s
+}
+
+// absorbSeed writes PK.seed padded with zeroes to a full block into h and
+// returns the resulting state.
+func absorbSeed(h hash.Hash, pkSeed []byte) []byte {
+	pad := make([]byte, h.BlockSize()-len(pkSeed))
+	_, _ = h.Write(pkSeed)
+	_, _ = h.Write(pad)
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return state
+}
+
+// hash computes Trunc_n(Hash(PK.seed ‖ pad ‖ ADRSc ‖ in1 ‖ in2)).
+func (s *sha2Hasher) hash(
+	h hash.Hash,
+	seed []byte,
+	out []byte,
+	adrs *address,
+	in1, in2 []byte,
+) {
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(seed); err != nil {
+		panic(err)
+	}
+	adrs.compress(&s.addrc)
+	_, _ = h.Write(s.addrc[:])
+	_, _ = h.Write(in1)
+	_, _ = h.Write(in2)
+	copy(out[:s.n], h.Sum(s.buf[:0]))
+}
+
+func (s *sha2Hasher) prf(out []byte, adrs *address) {
+	s.hash(s.small, s.smallSeed, out, adrs, s.skSeed, nil)
+}
+
+func (s *sha2Hasher) f(out, in []byte, adrs *address) {
+	s.hash(s.small, s.smallSeed, out, adrs, in, nil)
+}
+
+func (s *sha2Hasher) h(out, left, right []byte, adrs *address) {
+	s.hash(s.big, s.bigSeed, out, adrs, left, right)
+}
+
+func (s *sha2Hasher) t(out, in []byte, adrs *address) {
+	s.hash(s.big, s.bigSeed, out, adrs, in, nil)
+}
+
+func (s *sha2Hasher) prfMsg(out, skPrf, optRand []byte, msg [][]byte) {
+	mac := hmac.New(s.newBig, skPrf)
+	_, _ = mac.Write(optRand)
+	for _, m := range msg {
+		_, _ = mac.Write(m)
+	}
+	copy(out[:s.n], mac.Sum(s.buf[:0]))
+}
+
+// hMsg computes MGF1-Hash(r ‖ PK.seed ‖ Hash(r ‖ PK.seed ‖ pkRoot ‖ msg), m).
+func (s *sha2Hasher) hMsg(out, r, pkRoot []byte, msg [][]byte) {
+	h := s.newBig()
+	_, _ = h.Write(r)
+	_, _ = h.Write(s.pkSeed)
+	_, _ = h.Write(pkRoot)
+	for _, m := range msg {
+		_, _ = h.Write(m)
+	}
+	seed := make([]byte, 0, 2*s.n+s.bigSize+4)
+	seed = append(seed, r...)
+	seed = append(seed, s.pkSeed...)
+	seed = h.Sum(seed)
+
+	var ctr [4]byte
+	out = out[:s.m]
+	for i := uint32(0); len(out) > 0; i++ {
+		binary.BigEndian.PutUint32(ctr[:], i)
+		h.Reset()
+		_, _ = h.Write(seed)
+		_, _ = h.Write(ctr[:])
+		out = out[copy(out, h.Sum(s.buf[:0])):]
+	}
+}
@@ -0,0 +1,98 @@
+package slhdsa
+
+import (
+	"encoding/asn1"
+	"fmt"
+)
+
+// ID identifies a parameter set of SLH-DSA.
+type ID uint8
+
+const (
+	SHA2_128s ID = iota + 1
+	SHAKE_128s
+	SHA2_128f
+	SHAKE_128f
+	SHA2_192s
+	SHAKE_192s
+	SHA2_192f
+	SHAKE_192f
+	SHA2_256s
+	SHAKE_256s
+	SHA2_256f
+	SHAKE_256f
+)
+
+// params holds the parameters of a parameter set, see Table 2 of FIPS 205.
+type params struct {
+	name   string
+	n      int  // length in bytes of hashes and of the WOTS+ chain values
+	height int  // height h of the hypertree
+	d      int  // number of layers of the hypertree
+	hPrime int  // height of the XMSS trees
+	a      int  // height of the FORS trees
+	k      int  // number of FORS trees
+	m      int  // length in bytes of the message digest
+	level  int  // claimed NIST security level
+	sha2   bool // whether to use the SHA2 rather than the SHAKE instantiation
+	oid    int  // last arc of the object identifier
+}
+
+var paramSets = [...]params{
+	SHA2_128s:  {"SLH-DSA-SHA2-128s", 16, 63, 7, 9, 12, 14, 30, 1, true, 20},
+	SHAKE_128s: {"SLH-DSA-SHAKE-128s", 16, 63, 7, 9, 12, 14, 30, 1, false, 26},
+	SHA2_128f:  {"SLH-DSA-SHA2-128f", 16, 66, 22, 3, 6, 33, 34, 1, true, 21},
+	SHAKE_128f: {"SLH-DSA-SHAKE-128f", 16, 66, 22, 3, 6, 33, 34, 1, false, 27},
+	SHA2_192s:  {"SLH-DSA-SHA2-192s", 24, 63, 7, 9, 14, 17, 39, 3, true, 22},
+	SHAKE_192s: {"SLH-DSA-SHAKE-192s", 24, 63, 7, 9, 14, 17, 39, 3, false, 28},
+	SHA2_192f:  {"SLH-DSA-SHA2-192f", 24, 66, 22, 3, 8, 33, 42, 3, true, 23},
+	SHAKE_192f: {"SLH-DSA-SHAKE-192f", 24, 66, 22, 3, 8, 33, 42, 3, false, 29},
+	SHA2_256s:  {"SLH-DSA-SHA2-256s", 32, 64, 8, 8, 14, 22, 47, 5, true, 24},
+	SHAKE_256s: {"SLH-DSA-SHAKE-256s", 32, 64, 8, 8, 14, 22, 47, 5, false, 30},
+	SHA2_256f:  {"SLH-DSA-SHA2-256f", 32, 68, 17, 4, 9, 35, 49, 5, true, 25},
+	SHAKE_256f: {"SLH-DSA-SHAKE-256f", 32, 68, 17, 4, 9, 35, 49, 5, false, 31},
+}
+
+// IsValid returns whether id is one of the parameter sets of FIPS 205.
+func (id ID) IsValid() bool { return id >= SHA2_128s && id <= SHAKE_256f }
+
+func (id ID) String() string {
+	if !id.IsValid() {
+		return fmt.Sprintf("SLH-DSA(%d)", uint8(id))
+	}
+	return paramSets[id].name
+}
+
+func (id ID) params() *params {
+	if !id.IsValid() {
+		panic(ErrID)
+	}
+	return &paramSets[id]
+}
+
+// Winternitz parameter w = 2^lgW. FIPS 205 fixes lgW = 4.
+const (
+	lgW = 4
+	w   = 1 << lgW
+)
+
+// wotsLen returns the number of chains of a WOTS+ key, that is
+// len1 + len2 with len1 = 2n and len2 = 3.
+func (p *params) wotsLen() int { return 2*p.n + 3 }
+
+func (p *params) wotsSigSize() int { return p.wotsLen() * p.n }
+
+func (p *params) xmssSigSize() int { return (p.wotsLen() + p.hPrime) * p.n }
+
+func (p *params) forsSigSize() int { return p.k * (p.a + 1) * p.n }
+
+func (p *params) publicKeySize() int  { return 2 * p.n }
+func (p *params) privateKeySize() int { return 4 * p.n }
+func (p *params) seedSize() int       { return 3 * p.n }
+func (p *params) signatureSize() int {
+	return p.n + p.forsSigSize() + p.d*p.xmssSigSize()
+}
+
+func (p *params) objectIdentifier() asn1.ObjectIdentifier {
+	return asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 3, p.oid}
+}
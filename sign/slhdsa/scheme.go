@@ -0,0 +1,113 @@
+package slhdsa
+
+import (
+	"encoding/asn1"
+
+	"github.com/katzenpost/circl/sign"
+)
+
+// Boilerplate for generic signatures API
+
+type scheme struct{ id ID }
+
+var schemes = func() (ret [SHAKE_256f + 1]sign.Scheme) {
+	for id := SHA2_128s; id <= SHAKE_256f; id++ {
+		ret[id] = &scheme{id}
+	}
+	return
+}()
+
+// Scheme returns a generic signature interface for the parameter set.
+// Panics if id is not valid.
+func (id ID) Scheme() sign.Scheme {
+	if !id.IsValid() {
+		panic(ErrID)
+	}
+	return schemes[id]
+}
+
+func (s *scheme) Name() string          { return s.id.String() }
+func (s *scheme) PublicKeySize() int    { return s.id.params().publicKeySize() }
+func (s *scheme) PrivateKeySize() int   { return s.id.params().privateKeySize() }
+func (s *scheme) SignatureSize() int    { return s.id.params().signatureSize() }
+func (s *scheme) SeedSize() int         { return s.id.params().seedSize() }
+func (s *scheme) SupportsContext() bool { return true }
+func (s *scheme) Oid() asn1.ObjectIdentifier {
+	return s.id.params().objectIdentifier()
+}
+
+func (s *scheme) GenerateKey() (sign.PublicKey, sign.PrivateKey, error) {
+	return GenerateKey(nil, s.id)
+}
+
+// Sign signs msg with SLH-DSA. The signature is hedged, unless
+// opts.Deterministic is set.
+func (s *scheme) Sign(
+	sk sign.PrivateKey,
+	msg []byte,
+	opts *sign.SignatureOpts,
+) []byte {
+	priv, ok := sk.(*PrivateKey)
+	if !ok || priv.id != s.id {
+		panic(sign.ErrTypeMismatch)
+	}
+	ctx, randomized := contextFromOpts(opts)
+	sig := make([]byte, s.SignatureSize())
+	if err := SignTo(priv, msg, ctx, randomized, sig); err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+func (s *scheme) Verify(
+	pk sign.PublicKey,
+	msg, sig []byte,
+	opts *sign.SignatureOpts,
+) bool {
+	pub, ok := pk.(*PublicKey)
+	if !ok || pub.id != s.id {
+		panic(sign.ErrTypeMismatch)
+	}
+	ctx, _ := contextFromOpts(opts)
+	return Verify(pub, msg, ctx, sig)
+}
+
+func (s *scheme) DeriveKey(seed []byte) (sign.PublicKey, sign.PrivateKey) {
+	return NewKeyFromSeed(s.id, seed)
+}
+
+func (s *scheme) UnmarshalBinaryPublicKey(buf []byte) (sign.PublicKey, error) {
+	if len(buf) != s.PublicKeySize() {
+		return nil, sign.ErrPubKeySize
+	}
+	buf2 := append([]byte(nil), buf...)
+	n := len(buf2) / 2
+	return &PublicKey{id: s.id, seed: buf2[:n], root: buf2[n:]}, nil
+}
+
+func (s *scheme) UnmarshalBinaryPrivateKey(buf []byte) (sign.PrivateKey, error) {
+	if len(buf) != s.PrivateKeySize() {
+		return nil, sign.ErrPrivKeySize
+	}
+	buf2 := append([]byte(nil), buf...)
+	n := len(buf2) / 4
+	return &PrivateKey{
+		id:     s.id,
+		seed:   buf2[:n],
+		prf:    buf2[n : 2*n],
+		pkSeed: buf2[2*n : 3*n],
+		pkRoot: buf2[3*n:],
+	}, nil
+}
+
+func (sk *PrivateKey) Scheme() sign.Scheme { return sk.id.Scheme() }
+func (pk *PublicKey) Scheme() sign.Scheme  { return pk.id.Scheme() }
+
+// contextFromOpts returns the context string and whether the signature
+// should be hedged.
+func contextFromOpts(opts *sign.SignatureOpts) (ctx []byte, randomized bool) {
+	if opts == nil {
+		return nil, true
+	}
+	return []byte(opts.Context), !opts.Deterministic
+}
@@ -0,0 +1,279 @@
+package slhdsa
+
+import (
+	"bytes"
+	"crypto"
+	cryptoRand "crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/katzenpost/circl/sign"
+)
+
+var (
+	// ErrID is returned or raised when an invalid parameter set is used.
+	ErrID = errors.New("slhdsa: invalid parameter set")
+
+	// ErrHashedMessage is returned by PrivateKey.Sign if it is asked to
+	// sign a hashed message.
+	ErrHashedMessage = errors.New("slhdsa: cannot sign hashed message")
+)
+
+// PublicKey is the type of SLH-DSA public keys.
+type PublicKey struct {
+	id   ID
+	seed []byte
+	root []byte
+}
+
+// PrivateKey is the type of SLH-DSA private keys.
+type PrivateKey struct {
+	id     ID
+	seed   []byte
+	prf    []byte
+	pkSeed []byte
+	pkRoot []byte
+}
+
+// GenerateKey generates a key pair of the parameter set id using
+// entropy from rand. If rand is nil, crypto/rand.Reader will be used.
+func GenerateKey(rand io.Reader, id ID) (*PublicKey, *PrivateKey, error) {
+	if !id.IsValid() {
+		return nil, nil, ErrID
+	}
+	if rand == nil {
+		rand = cryptoRand.Reader
+	}
+	seed := make([]byte, id.params().seedSize())
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+	pk, sk := NewKeyFromSeed(id, seed)
+	return pk, sk, nil
+}
+
+// NewKeyFromSeed derives a key pair of the parameter set id from seed,
+// which is the concatenation SK.seed ‖ SK.prf ‖ PK.seed of the random
+// values of slh_keygen of FIPS 205. Panics if seed is not of length
+// 3n; see Scheme().SeedSize().
+func NewKeyFromSeed(id ID, seed []byte) (*PublicKey, *PrivateKey) {
+	p := id.params()
+	if len(seed) != p.seedSize() {
+		panic(sign.ErrSeedSize)
+	}
+
+	n := p.n
+	buf := make([]byte, 4*n)
+	copy(buf, seed)
+	sk := &PrivateKey{
+		id:     id,
+		seed:   buf[:n],
+		prf:    buf[n : 2*n],
+		pkSeed: buf[2*n : 3*n],
+		pkRoot: buf[3*n:],
+	}
+
+	var adrs address
+	adrs.setLayer(uint32(p.d - 1))
+	s := newState(p, sk.pkSeed, sk.seed)
+	s.xmssNode(sk.pkRoot, 0, p.hPrime, &adrs)
+
+	return sk.public(), sk
+}
+
+// SignTo signs the given message with the given context string and writes
+// the signature into sig. It will panic if sig is not of length
+// Scheme().SignatureSize().
+//
+// ctx is the optional context string. Errors if ctx is larger than 255 bytes.
+// A nil context string is equivalent to an empty context string.
+//
+// If randomized is true, the signature is hedged with fresh randomness
+// from crypto/rand, as recommended by FIPS 205. Otherwise, the
+// deterministic variant is used.
+func SignTo(sk *PrivateKey, msg, ctx []byte, randomized bool, sig []byte) error {
+	if len(ctx) > 255 {
+		return sign.ErrContextTooLong
+	}
+
+	optRand := sk.pkSeed
+	if randomized {
+		optRand = make([]byte, len(sk.pkSeed))
+		if _, err := cryptoRand.Read(optRand); err != nil {
+			return err
+		}
+	}
+
+	sk.signInternal(sig, [][]byte{{0, byte(len(ctx))}, ctx, msg}, optRand)
+	return nil
+}
+
+// Verify checks whether the given signature by pk on msg with the given
+// context string is valid.
+//
+// ctx is the optional context string. Fails if ctx is larger than 255 bytes.
+// A nil context string is equivalent to an empty context string.
+func Verify(pk *PublicKey, msg, ctx, sig []byte) bool {
+	if len(ctx) > 255 {
+		return false
+	}
+	return pk.verifyInternal([][]byte{{0, byte(len(ctx))}, ctx, msg}, sig)
+}
+
+// digest splits the message digest into the FORS message and the indices
+// of the XMSS tree and leaf, see Algorithm 19 of FIPS 205.
+func (p *params) digest(digest []byte) (md []byte, idxTree uint64, idxLeaf uint32) {
+	mdLen := (p.k*p.a + 7) / 8
+	treeLen := (p.height - p.hPrime + 7) / 8
+	leafLen := (p.hPrime + 7) / 8
+
+	var buf [8]byte
+	md = digest[:mdLen]
+	copy(buf[8-treeLen:], digest[mdLen:mdLen+treeLen])
+	idxTree = binary.BigEndian.Uint64(buf[:])
+	if bits := p.height - p.hPrime; bits < 64 {
+		idxTree &= 1<<bits - 1
+	}
+
+	buf = [8]byte{}
+	copy(buf[8-leafLen:], digest[mdLen+treeLen:mdLen+treeLen+leafLen])
+	idxLeaf = uint32(binary.BigEndian.Uint64(buf[:]) & (1<<p.hPrime - 1))
+	return md, idxTree, idxLeaf
+}
+
+// signInternal implements slh_sign_internal of FIPS 205.
+func (sk *PrivateKey) signInternal(sig []byte, msg [][]byte, optRand []byte) {
+	p := sk.id.params()
+	n := p.n
+	if len(sig) != p.signatureSize() {
+		panic("slhdsa: wrong signature size")
+	}
+	s := newState(p, sk.pkSeed, sk.seed)
+
+	r := sig[:n]
+	s.prfMsg(r, sk.prf, optRand, msg)
+	digest := make([]byte, p.m)
+	s.hMsg(digest, r, sk.pkRoot, msg)
+	md, idxTree, idxLeaf := p.digest(digest)
+
+	var (
+		adrs   address
+		pkFors [32]byte
+	)
+	adrs.setTree(idxTree)
+	adrs.setTypeAndClear(addrForsTree)
+	adrs.setKeyPair(idxLeaf)
+	sigFors := sig[n : n+p.forsSigSize()]
+	s.forsSign(sigFors, md, &adrs)
+	s.forsPkFromSig(pkFors[:n], sigFors, md, &adrs)
+
+	s.htSign(sig[n+p.forsSigSize():], pkFors[:n], idxTree, idxLeaf)
+}
+
+// verifyInternal implements slh_verify_internal of FIPS 205.
+func (pk *PublicKey) verifyInternal(msg [][]byte, sig []byte) bool {
+	p := pk.id.params()
+	n := p.n
+	if len(sig) != p.signatureSize() {
+		return false
+	}
+	s := newState(p, pk.seed, nil)
+
+	r := sig[:n]
+	digest := make([]byte, p.m)
+	s.hMsg(digest, r, pk.root, msg)
+	md, idxTree, idxLeaf := p.digest(digest)
+
+	var (
+		adrs   address
+		pkFors [32]byte
+	)
+	adrs.setTree(idxTree)
+	adrs.setTypeAndClear(addrForsTree)
+	adrs.setKeyPair(idxLeaf)
+	sigFors := sig[n : n+p.forsSigSize()]
+	s.forsPkFromSig(pkFors[:n], sigFors, md, &adrs)
+
+	return s.htVerify(pkFors[:n], sig[n+p.forsSigSize():], pk.root, idxTree, idxLeaf)
+}
+
+// ID returns the parameter set of the key.
+func (pk *PublicKey) ID() ID { return pk.id }
+
+// ID returns the parameter set of the key.
+func (sk *PrivateKey) ID() ID { return sk.id }
+
+// MarshalBinary returns PK.seed ‖ PK.root.
+func (pk *PublicKey) MarshalBinary() ([]byte, error) {
+	ret := make([]byte, 0, 2*len(pk.seed))
+	ret = append(ret, pk.seed...)
+	return append(ret, pk.root...), nil
+}
+
+// MarshalBinary returns SK.seed ‖ SK.prf ‖ PK.seed ‖ PK.root.
+func (sk *PrivateKey) MarshalBinary() ([]byte, error) {
+	ret := make([]byte, 0, 4*len(sk.seed))
+	ret = append(ret, sk.seed...)
+	ret = append(ret, sk.prf...)
+	ret = append(ret, sk.pkSeed...)
+	return append(ret, sk.pkRoot...), nil
+}
+
+func (sk *PrivateKey) public() *PublicKey {
+	return &PublicKey{id: sk.id, seed: sk.pkSeed, root: sk.pkRoot}
+}
+
+// Public returns the public key corresponding to sk.
+func (sk *PrivateKey) Public() crypto.PublicKey { return sk.public() }
+
+// Equal returns whether the two public keys are equal.
+func (pk *PublicKey) Equal(other crypto.PublicKey) bool {
+	castOther, ok := other.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return pk.id == castOther.id &&
+		bytes.Equal(pk.seed, castOther.seed) &&
+		bytes.Equal(pk.root, castOther.root)
+}
+
+// Equal returns whether the two private keys are equal.
+func (sk *PrivateKey) Equal(other crypto.PrivateKey) bool {
+	castOther, ok := other.(*PrivateKey)
+	if !ok {
+		return false
+	}
+	a, _ := sk.MarshalBinary()
+	b, _ := castOther.MarshalBinary()
+	return sk.id == castOther.id && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Sign signs the given message with an empty context string.
+//
+// opts.HashFunc() must return zero, which can be achieved by passing
+// crypto.Hash(0) for opts. If rand is not nil, the signature is hedged
+// with randomness read from it. Otherwise, the signature is deterministic.
+//
+// This function is used to make PrivateKey implement the crypto.Signer
+// interface.  The package-level SignTo function might be more convenient
+// to use.
+func (sk *PrivateKey) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts) (
+	signature []byte, err error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, ErrHashedMessage
+	}
+
+	optRand := sk.pkSeed
+	if rand != nil {
+		optRand = make([]byte, len(sk.pkSeed))
+		if _, err = io.ReadFull(rand, optRand); err != nil {
+			return nil, err
+		}
+	}
+
+	sig := make([]byte, sk.id.params().signatureSize())
+	sk.signInternal(sig, [][]byte{{0, 0}, msg}, optRand)
+	return sig, nil
+}
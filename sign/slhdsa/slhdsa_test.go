@@ -0,0 +1,219 @@
+package slhdsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/katzenpost/circl/sign"
+)
+
+func allIDs() []ID {
+	var ret []ID
+	for id := SHA2_128s; id <= SHAKE_256f; id++ {
+		ret = append(ret, id)
+	}
+	return ret
+}
+
+// isSmall returns whether id is optimized for small signatures, which
+// makes it slow to sign.
+func isSmall(id ID) bool { return strings.HasSuffix(id.String(), "s") }
+
+func TestParams(t *testing.T) {
+	// Table 2 of FIPS 205.
+	sizes := map[ID][2]int{
+		SHA2_128s: {32, 7856}, SHA2_128f: {32, 17088},
+		SHA2_192s: {48, 16224}, SHA2_192f: {48, 35664},
+		SHA2_256s: {64, 29792}, SHA2_256f: {64, 49856},
+	}
+	for _, id := range allIDs() {
+		p := id.params()
+		if p.m != (p.k*p.a+7)/8+(p.height-p.hPrime+7)/8+(p.hPrime+7)/8 {
+			t.Fatalf("%v: wrong m", id)
+		}
+		if p.height != p.d*p.hPrime {
+			t.Fatalf("%v: wrong h", id)
+		}
+		sha2ID := id
+		if !p.sha2 {
+			sha2ID--
+		}
+		want := sizes[sha2ID]
+		if p.publicKeySize() != want[0] || p.signatureSize() != want[1] {
+			t.Fatalf("%v: wrong sizes", id)
+		}
+	}
+	if ID(0).IsValid() || (SHAKE_256f + 1).IsValid() {
+		t.Fatal()
+	}
+	if _, _, err := GenerateKey(nil, ID(0)); err != ErrID {
+		t.Fatal()
+	}
+}
+
+// Regression values for the key derived from the seed 0, 1, …, 3n-1 and the
+// deterministic signature of "message" with context "ctx". They were
+// computed with this package; TestACVP checks the official vectors.
+func TestRegression(t *testing.T) {
+	for _, tc := range []struct {
+		id   ID
+		root string
+		sig  string
+	}{
+		{SHA2_128s, "990ce6298792b128846a8e4a3a68954c", "75dd88f52d5158b47531b84490e077f3cad276d721cc78dcca64e021ddb3f7f8"},
+		{SHAKE_128s, "89fd81fdbb5b94129b14761bdc6bf682", "82067c7a84ab7bb147a2b2a0964a865f9416fb9c87dc1d04ed1d26463b428bd7"},
+		{SHA2_128f, "3b56e816847f000386aeec2e2bb9e1b5", "15a8953fd8b33b49b163e90016f444b71126fd5def550b6293581ec054fd8e32"},
+		{SHAKE_128f, "a90e4715b9a925c332801767fd786371", "93cbbb6e4d56f3f218fdc8a3423acfbada3e42f2530e11919d11fd4cddc49367"},
+		{SHA2_192s, "b6f282ce116ff59bce2d9fc4a67c6031dabdce326c34f541", "c0c5c23f596185dacc2ec09ba44df505c555d8ee77f475f841d2dd9622f4a265"},
+		{SHAKE_192s, "eb247f955d8eca24a5860536c56b2c4d1e8d8e835eb27d2d", "e573477f775d72e45687dd298254c7698c118510dc0706d996dc8f8fdf450712"},
+		{SHA2_192f, "9236ccebbb3a90ac2452dd89de49dab1340ec02419a2870e", "9d2f973ddbf3c02e6b2d79ededf579bb52b2af4858789c7f03d9c1826a58f38e"},
+		{SHAKE_192f, "3f01b06bebed020a459696868d115fe8507ded8dc08e825d", "8f401e226bee2e8561e0d666ec34d12500f7d282653ff65a96e1c288125a340e"},
+		{SHA2_256s, "da7163e601352515bc0f06f9f4f44be71a5a65ee9dca5575cf4a7b6d4a87d6e2", "97e5a7b6e3dd35d99961361b4770c30b57cf459463feed7628d4cab130851c84"},
+		{SHAKE_256s, "27ea444dbc8ca9c169fd484b9e977eb77a4f233550757e025cf180ede7e8839f", "3c02d95a5e57cde1189f5b62469c0a5ea5bf70a07de916137815130f929e3ff6"},
+		{SHA2_256f, "42cffe64ddbd6731063752684df77c8b58c225dc6b491208916b654ea1393176", "c4dfe2b99211768b1648046960696c97da2542879638a55d259043db436583ee"},
+		{SHAKE_256f, "818d7e76beef979b5bbf9161fdefa21bd0fe0bfe19157a5711a8de8a8f6878e6", "3bbc444d8978102462f42e74be00f620e2ae4aa0cebcaf5ae10306e0af59cd1a"},
+	} {
+		t.Run(tc.id.String(), func(t *testing.T) {
+			if testing.Short() && isSmall(tc.id) {
+				t.Skip("slow")
+			}
+			seed := make([]byte, tc.id.Scheme().SeedSize())
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+			pk, sk := NewKeyFromSeed(tc.id, seed)
+			if root := fmt.Sprintf("%x", pk.root); root != tc.root {
+				t.Fatalf("root %s ≠ %s", root, tc.root)
+			}
+
+			sig := make([]byte, tc.id.Scheme().SignatureSize())
+			if err := SignTo(sk, []byte("message"), []byte("ctx"), false, sig); err != nil {
+				t.Fatal(err)
+			}
+			if h := fmt.Sprintf("%x", sha256.Sum256(sig)); h != tc.sig {
+				t.Fatalf("signature %s ≠ %s", h, tc.sig)
+			}
+			if !Verify(pk, []byte("message"), []byte("ctx"), sig) {
+				t.Fatal("signature does not verify")
+			}
+		})
+	}
+}
+
+// Checks that the four-way SHAKE code paths agree with the generic ones.
+func TestX4(t *testing.T) {
+	for _, id := range []ID{SHAKE_128f, SHAKE_192f, SHAKE_256f} {
+		p := id.params()
+		var pkSeed, skSeed [32]byte
+		_, _ = rand.Read(pkSeed[:])
+		_, _ = rand.Read(skSeed[:])
+		generic := shakeHasher{n: p.n, pkSeed: pkSeed[:p.n], skSeed: skSeed[:p.n]}
+		s1 := state{p, &generic}
+		s2 := state{p, &shakeHasherX4{generic}}
+
+		var (
+			adrs1, adrs2 address
+			out1, out2   [32]byte
+		)
+		adrs1.setLayer(3)
+		adrs1.setTree(0x1234567890)
+		adrs1.setKeyPair(5)
+		adrs2 = adrs1
+		s1.wotsPkGen(out1[:p.n], &adrs1)
+		s2.wotsPkGen(out2[:p.n], &adrs2)
+		if out1 != out2 {
+			t.Fatalf("%v: wotsPkGen differs", id)
+		}
+
+		adrs1.setTypeAndClear(addrForsTree)
+		adrs1.setKeyPair(7)
+		adrs2 = adrs1
+		s1.forsNode(out1[:p.n], 3, p.a, &adrs1)
+		s2.forsNode(out2[:p.n], 3, p.a, &adrs2)
+		if out1 != out2 {
+			t.Fatalf("%v: forsNode differs", id)
+		}
+	}
+}
+
+func TestScheme(t *testing.T) {
+	msg := []byte("message")
+	for _, id := range allIDs() {
+		scheme := id.Scheme()
+		t.Run(scheme.Name(), func(t *testing.T) {
+			if testing.Short() && isSmall(id) {
+				t.Skip("slow")
+			}
+			pk, sk, err := scheme.GenerateKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			opts := &sign.SignatureOpts{Context: "context"}
+			sig := scheme.Sign(sk, msg, opts)
+			if !scheme.Verify(pk, msg, sig, opts) {
+				t.Fatal("signature does not verify")
+			}
+			if scheme.Verify(pk, msg, sig, nil) {
+				t.Fatal("signature verifies without its context")
+			}
+			if scheme.Verify(pk, msg[1:], sig, opts) {
+				t.Fatal("signature verifies for another message")
+			}
+			if scheme.Verify(pk, msg, sig[1:], opts) {
+				t.Fatal("truncated signature verifies")
+			}
+			long := &sign.SignatureOpts{Context: strings.Repeat("x", 256)}
+			if scheme.Verify(pk, msg, sig, long) {
+				t.Fatal("verification accepted a context that is too long")
+			}
+
+			// The regression values check deterministic signatures.
+			det := &sign.SignatureOpts{Context: "context", Deterministic: true}
+			sig1 := scheme.Sign(sk, msg, det)
+			if bytes.Equal(sig, sig1) {
+				t.Fatal("hedged signature equals deterministic one")
+			}
+
+			sig2, err := sk.(crypto.Signer).Sign(rand.Reader, msg, crypto.Hash(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !scheme.Verify(pk, msg, sig2, nil) {
+				t.Fatal("signature of crypto.Signer does not verify")
+			}
+		})
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	msg := []byte("message")
+	for _, id := range allIDs() {
+		_, sk, _ := GenerateKey(nil, id)
+		sig := make([]byte, id.Scheme().SignatureSize())
+		b.Run(id.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = SignTo(sk, msg, nil, false, sig)
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	msg := []byte("message")
+	for _, id := range allIDs() {
+		pk, sk, _ := GenerateKey(nil, id)
+		sig := make([]byte, id.Scheme().SignatureSize())
+		_ = SignTo(sk, msg, nil, false, sig)
+		b.Run(id.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Verify(pk, msg, nil, sig)
+			}
+		})
+	}
+}
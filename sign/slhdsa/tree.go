@@ -0,0 +1,116 @@
+package slhdsa
+
+import "crypto/subtle"
+
+// XMSS and the hypertree, see Sections 6 and 7 of FIPS 205.
+
+// xmssNode writes the node at index i and height z of the XMSS tree of
+// adrs into out.
+func (s *state) xmssNode(out []byte, i uint32, z int, adrs *address) {
+	if z == 0 {
+		adrs.setTypeAndClear(addrWotsHash)
+		adrs.setKeyPair(i)
+		s.wotsPkGen(out, adrs)
+		return
+	}
+
+	n := s.n
+	var buf [2 * 32]byte
+	s.xmssNode(buf[:n], 2*i, z-1, adrs)
+	s.xmssNode(buf[n:2*n], 2*i+1, z-1, adrs)
+	adrs.setTypeAndClear(addrTree)
+	adrs.setTreeHeight(uint32(z))
+	adrs.setTreeIndex(i)
+	s.h(out, buf[:n], buf[n:2*n], adrs)
+}
+
+// xmssSign writes the XMSS signature of the n-byte message msg with the
+// leaf idx of the XMSS tree of adrs into sig.
+func (s *state) xmssSign(sig, msg []byte, idx uint32, adrs *address) {
+	n := s.n
+	auth := sig[s.wotsSigSize():]
+	for j := 0; j < s.hPrime; j++ {
+		k := (idx >> j) ^ 1
+		s.xmssNode(auth[j*n:(j+1)*n], k, j, adrs)
+	}
+
+	adrs.setTypeAndClear(addrWotsHash)
+	adrs.setKeyPair(idx)
+	s.wotsSign(sig[:s.wotsSigSize()], msg, adrs)
+}
+
+// xmssPkFromSig writes the root of the XMSS tree computed from the
+// signature sig with leaf idx of the n-byte message msg into out.
+func (s *state) xmssPkFromSig(out []byte, idx uint32, sig, msg []byte, adrs *address) {
+	n := s.n
+	var node [32]byte
+	adrs.setTypeAndClear(addrWotsHash)
+	adrs.setKeyPair(idx)
+	s.wotsPkFromSig(node[:n], sig[:s.wotsSigSize()], msg, adrs)
+
+	auth := sig[s.wotsSigSize():]
+	adrs.setTypeAndClear(addrTree)
+	adrs.setTreeIndex(idx)
+	for k := 0; k < s.hPrime; k++ {
+		authK := auth[k*n : (k+1)*n]
+		adrs.setTreeHeight(uint32(k + 1))
+		if (idx>>k)&1 == 0 {
+			adrs.setTreeIndex(adrs.treeIndex() / 2)
+			s.h(node[:n], node[:n], authK, adrs)
+		} else {
+			adrs.setTreeIndex((adrs.treeIndex() - 1) / 2)
+			s.h(node[:n], authK, node[:n], adrs)
+		}
+	}
+	copy(out[:n], node[:n])
+}
+
+// htSign writes the hypertree signature of the n-byte message msg with
+// the given XMSS tree of the bottom layer and leaf in it into sig.
+func (s *state) htSign(sig, msg []byte, idxTree uint64, idxLeaf uint32) {
+	var (
+		adrs address
+		root [32]byte
+	)
+	n, size := s.n, s.xmssSigSize()
+	adrs.setTree(idxTree)
+	copy(root[:n], msg)
+
+	for j := 0; j < s.d; j++ {
+		if j > 0 {
+			idxLeaf = uint32(idxTree & (1<<s.hPrime - 1))
+			idxTree >>= s.hPrime
+			adrs.setLayer(uint32(j))
+			adrs.setTree(idxTree)
+		}
+		sigJ := sig[j*size : (j+1)*size]
+		s.xmssSign(sigJ, root[:n], idxLeaf, &adrs)
+		if j < s.d-1 {
+			s.xmssPkFromSig(root[:n], idxLeaf, sigJ, root[:n], &adrs)
+		}
+	}
+}
+
+// htVerify returns whether sig is a valid hypertree signature of the
+// n-byte message msg with the given tree and leaf under pkRoot.
+func (s *state) htVerify(msg, sig, pkRoot []byte, idxTree uint64, idxLeaf uint32) bool {
+	var (
+		adrs address
+		node [32]byte
+	)
+	n, size := s.n, s.xmssSigSize()
+	adrs.setTree(idxTree)
+	copy(node[:n], msg)
+
+	for j := 0; j < s.d; j++ {
+		if j > 0 {
+			idxLeaf = uint32(idxTree & (1<<s.hPrime - 1))
+			idxTree >>= s.hPrime
+			adrs.setLayer(uint32(j))
+			adrs.setTree(idxTree)
+		}
+		s.xmssPkFromSig(node[:n], idxLeaf, sig[j*size:(j+1)*size], node[:n], &adrs)
+	}
+
+	return subtle.ConstantTimeCompare(node[:n], pkRoot) == 1
+}
@@ -0,0 +1,146 @@
+package slhdsa
+
+// WOTS+ one-time signatures, see Section 5 of FIPS 205.
+
+// state holds a parameter set together with the hash functions keyed with
+// PK.seed and SK.seed of a key pair.
+type state struct {
+	*params
+	hasher
+}
+
+func newState(p *params, pkSeed, skSeed []byte) state {
+	return state{p, newHasher(p, pkSeed, skSeed)}
+}
+
+// chain applies steps iterations of F to x, starting at index i of the
+// chain, and writes the result into out.
+func (s *state) chain(out, x []byte, i, steps uint32, adrs *address) {
+	copy(out[:s.n], x)
+	for j := i; j < i+steps; j++ {
+		adrs.setHash(j)
+		s.f(out, out, adrs)
+	}
+}
+
+// wotsDigits writes the base-w digits of msg followed by those of its
+// checksum into digits, which must be of length wotsLen().
+func (s *state) wotsDigits(digits []byte, msg []byte) {
+	len1 := 2 * s.n
+	csum := 0
+	for i := 0; i < s.n; i++ {
+		digits[2*i] = msg[i] >> 4
+		digits[2*i+1] = msg[i] & 0xf
+		csum += 2*(w-1) - int(digits[2*i]) - int(digits[2*i+1])
+	}
+
+	// The checksum is shifted left to be byte aligned, encoded as two bytes
+	// and split into len2 = 3 digits, which are its three top nibbles.
+	csum <<= 4
+	digits[len1] = byte(csum>>12) & 0xf
+	digits[len1+1] = byte(csum>>8) & 0xf
+	digits[len1+2] = byte(csum>>4) & 0xf
+}
+
+// wotsSkAddress returns the address of the secret values of the WOTS+ key
+// pair of adrs.
+func wotsSkAddress(adrs *address) address {
+	skAdrs := *adrs
+	skAdrs.setTypeAndClear(addrWotsPrf)
+	skAdrs.setKeyPair(adrs.keyPair())
+	return skAdrs
+}
+
+// wotsCompress writes the WOTS+ public key made of the ends of the chains
+// in tmp into out.
+func (s *state) wotsCompress(out, tmp []byte, adrs *address) {
+	pkAdrs := *adrs
+	pkAdrs.setTypeAndClear(addrWotsPk)
+	pkAdrs.setKeyPair(adrs.keyPair())
+	s.t(out, tmp, &pkAdrs)
+}
+
+// wotsPkGen writes the WOTS+ public key of the key pair of adrs into out.
+func (s *state) wotsPkGen(out []byte, adrs *address) {
+	n, l := s.n, s.wotsLen()
+	var buf [(2*32 + 3) * 32]byte
+	tmp := buf[:l*n]
+	skAdrs := wotsSkAddress(adrs)
+
+	i := 0
+	if x4, ok := s.hasher.(hasherX4); ok {
+		// Compute the chains four at a time. The last batch may be padded
+		// by recomputing chains, whose results are discarded.
+		var (
+			adrsX4, skAdrsX4 [4]address
+			outX4            [4][]byte
+			dummy            [4][32]byte
+		)
+		for ; i < l; i += 4 {
+			for j := 0; j < 4; j++ {
+				adrsX4[j], skAdrsX4[j] = *adrs, skAdrs
+				if i+j < l {
+					adrsX4[j].setChain(uint32(i + j))
+					skAdrsX4[j].setChain(uint32(i + j))
+					outX4[j] = tmp[(i+j)*n : (i+j+1)*n]
+				} else {
+					outX4[j] = dummy[j][:n]
+				}
+			}
+			x4.prfX4(&outX4, &skAdrsX4)
+			for k := uint32(0); k < w-1; k++ {
+				for j := 0; j < 4; j++ {
+					adrsX4[j].setHash(k)
+				}
+				x4.fX4(&outX4, &outX4, &adrsX4)
+			}
+		}
+	} else {
+		for ; i < l; i++ {
+			sk := tmp[i*n : (i+1)*n]
+			skAdrs.setChain(uint32(i))
+			s.prf(sk, &skAdrs)
+			adrs.setChain(uint32(i))
+			s.chain(sk, sk, 0, w-1, adrs)
+		}
+	}
+
+	s.wotsCompress(out, tmp, adrs)
+}
+
+// wotsSign writes the WOTS+ signature of the n-byte message msg with the
+// key pair of adrs into sig.
+func (s *state) wotsSign(sig, msg []byte, adrs *address) {
+	n := s.n
+	var digits [2*32 + 3]byte
+	s.wotsDigits(digits[:s.wotsLen()], msg)
+	skAdrs := wotsSkAddress(adrs)
+
+	for i := 0; i < s.wotsLen(); i++ {
+		sigI := sig[i*n : (i+1)*n]
+		skAdrs.setChain(uint32(i))
+		s.prf(sigI, &skAdrs)
+		adrs.setChain(uint32(i))
+		s.chain(sigI, sigI, 0, uint32(digits[i]), adrs)
+	}
+}
+
+// wotsPkFromSig writes the WOTS+ public key computed from the signature sig
+// of the n-byte message msg into out.
+func (s *state) wotsPkFromSig(out, sig, msg []byte, adrs *address) {
+	n, l := s.n, s.wotsLen()
+	var (
+		digits [2*32 + 3]byte
+		buf    [(2*32 + 3) * 32]byte
+	)
+	tmp := buf[:l*n]
+	s.wotsDigits(digits[:l], msg)
+
+	for i := 0; i < l; i++ {
+		adrs.setChain(uint32(i))
+		s.chain(tmp[i*n:(i+1)*n], sig[i*n:(i+1)*n],
+			uint32(digits[i]), w-1-uint32(digits[i]), adrs)
+	}
+
+	s.wotsCompress(out, tmp, adrs)
+}
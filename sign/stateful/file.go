@@ -0,0 +1,117 @@
+package stateful
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrCorruptState is returned by a FileStore when its file does not hold
+// a valid index.
+var ErrCorruptState = errors.New("stateful: corrupt state file")
+
+// FileStore is a Store that keeps the index in a file, as an 8-byte
+// big-endian integer.
+//
+// The file is replaced atomically on every commit by writing a temporary
+// file in the same directory, syncing it and renaming it over the old one.
+// A FileStore must be the only user of its file: two FileStores, possibly
+// in different processes, on the same file can hand out the same index.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// CreateFileStore creates the file at path holding the index next and
+// returns a FileStore for it. Fails if the file already exists, so that
+// the state of a key in use is never overwritten by accident.
+func CreateFileStore(path string, next uint64) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], next)
+	if _, err = f.Write(buf[:]); err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	if err := syncDir(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	return &FileStore{path: path}, nil
+}
+
+// OpenFileStore returns a FileStore for the existing file at path.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path}
+	if _, err := s.Load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load reads the index from the file.
+func (s *FileStore) Load() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.load()
+}
+
+func (s *FileStore) load() (uint64, error) {
+	buf, err := os.ReadFile(s.path)
+	if err != nil {
+		return 0, err
+	}
+	if len(buf) != 8 {
+		return 0, ErrCorruptState
+	}
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+// Commit atomically replaces the index in the file by next. It returns
+// once the new file and its directory entry have been synced.
+func (s *FileStore) Commit(next uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cur, err := s.load()
+	if err != nil {
+		return err
+	}
+	if next < cur {
+		return ErrRollback
+	}
+
+	dir := filepath.Dir(s.path)
+	f, err := os.CreateTemp(dir, filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], next)
+	if _, err = f.Write(buf[:]); err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(tmp, s.path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return syncDir(dir)
+}
@@ -0,0 +1,102 @@
+// Package stateful provides persistence of the state of stateful
+// hash-based signature schemes, such as XMSS and LMS/HSS.
+//
+// A private key of such a scheme consists of a large but finite number of
+// one-time keys, which are used in order. Using a one-time key twice breaks
+// the security of the scheme, so the index of the next unused one-time key
+// has to be recorded durably before a signature is released. A Store
+// holds this index. Signers call Reserve before every signature, which
+// commits the advanced index to the Store first, and refuse to sign if the
+// commit fails.
+//
+// Two implementations are provided: MemoryStore, for keys that do not
+// outlive the process, and FileStore, which keeps the index in a file.
+package stateful
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrExhausted is returned when all one-time keys of a private key
+	// have been used.
+	ErrExhausted = errors.New("stateful: private key exhausted")
+
+	// ErrNoStore is returned when signing with a private key that has
+	// no Store attached.
+	ErrNoStore = errors.New("stateful: no state store attached to private key")
+
+	// ErrRollback is returned by a Store when asked to commit an index
+	// below the one it holds.
+	ErrRollback = errors.New("stateful: state rollback")
+)
+
+// Store persists the index of the next unused one-time key of a single
+// private key.
+//
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the index of the next unused one-time key.
+	Load() (uint64, error)
+
+	// Commit durably records next as the index of the next unused
+	// one-time key. It must return an error, such as ErrRollback, if next
+	// is below the index held by the Store, and must not return before
+	// the new index will survive a crash.
+	Commit(next uint64) error
+}
+
+// Reserve returns the index of the next unused one-time key held by s, and
+// commits the following index to s, which marks the returned one as used.
+// Returns ErrExhausted if the index is not below max, the number of
+// one-time keys, and the error of s if the commit fails, in which case the
+// one-time key must not be used.
+//
+// Callers have to serialize calls to Reserve on the same Store.
+func Reserve(s Store, max uint64) (uint64, error) {
+	if s == nil {
+		return 0, ErrNoStore
+	}
+	idx, err := s.Load()
+	if err != nil {
+		return 0, err
+	}
+	if idx >= max {
+		return 0, ErrExhausted
+	}
+	if err := s.Commit(idx + 1); err != nil {
+		return 0, err
+	}
+	return idx, nil
+}
+
+// MemoryStore is a Store that keeps the index in memory.
+//
+// It is only suitable for private keys that are discarded when the
+// process exits.
+type MemoryStore struct {
+	mu   sync.Mutex
+	next uint64
+}
+
+// NewMemoryStore returns a MemoryStore holding the index next.
+func NewMemoryStore(next uint64) *MemoryStore { return &MemoryStore{next: next} }
+
+// Load returns the index held by s.
+func (s *MemoryStore) Load() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next, nil
+}
+
+// Commit sets the index held by s to next.
+func (s *MemoryStore) Commit(next uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if next < s.next {
+		return ErrRollback
+	}
+	s.next = next
+	return nil
+}
@@ -0,0 +1,88 @@
+package stateful
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testStore(t *testing.T, s Store, next uint64) {
+	for i := uint64(0); i < 3; i++ {
+		idx, err := Reserve(s, next+3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if idx != next+i {
+			t.Fatalf("reserved %d, want %d", idx, next+i)
+		}
+	}
+	if _, err := Reserve(s, next+3); err != ErrExhausted {
+		t.Fatalf("reserved index of exhausted key: %v", err)
+	}
+	if got, _ := s.Load(); got != next+3 {
+		t.Fatalf("loaded %d, want %d", got, next+3)
+	}
+	if err := s.Commit(next + 2); err != ErrRollback {
+		t.Fatalf("rolled back: %v", err)
+	}
+	if err := s.Commit(next + 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Commit(next + 10); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.Load(); got != next+10 {
+		t.Fatalf("loaded %d, want %d", got, next+10)
+	}
+}
+
+func TestReserve(t *testing.T) {
+	if _, err := Reserve(nil, 1); err != ErrNoStore {
+		t.Fatalf("reserved without store: %v", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore(5), 5)
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key.state")
+
+	s, err := CreateFileStore(path, 1<<40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s, 1<<40)
+
+	if _, err := CreateFileStore(path, 0); !errors.Is(err, os.ErrExist) {
+		t.Fatalf("overwrote state file: %v", err)
+	}
+
+	s2, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s2.Load(); got != 1<<40+10 {
+		t.Fatalf("reopened store holds %d", got)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("%d files left in directory", len(entries))
+	}
+
+	if err := os.WriteFile(path, []byte{1, 2, 3}, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Reserve(s2, 1<<50); err != ErrCorruptState {
+		t.Fatalf("reserved from corrupt file: %v", err)
+	}
+	if _, err := OpenFileStore(path); err != ErrCorruptState {
+		t.Fatalf("opened corrupt file: %v", err)
+	}
+	if _, err := OpenFileStore(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("opened missing file")
+	}
+}
@@ -0,0 +1,5 @@
+package stateful
+
+// syncDir is a no-op on Windows, where a directory cannot be opened for
+// syncing; durability of the rename is left to the file system.
+func syncDir(string) error { return nil }
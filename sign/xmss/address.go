@@ -0,0 +1,36 @@
+package xmss
+
+import "encoding/binary"
+
+// Types of addresses, see Section 2.5 of RFC 8391.
+const (
+	addrOTS = iota
+	addrLTree
+	addrHashTree
+)
+
+// address is the 32-byte ADRS structure used for domain separation.
+type address [32]byte
+
+func (a *address) setLayer(l uint32) { binary.BigEndian.PutUint32(a[0:], l) }
+func (a *address) setTree(t uint64)  { binary.BigEndian.PutUint64(a[4:], t) }
+
+func (a *address) setTypeAndClear(t uint32) {
+	binary.BigEndian.PutUint32(a[12:], t)
+	for i := 16; i < 32; i++ {
+		a[i] = 0
+	}
+}
+
+func (a *address) setOTS(i uint32)   { binary.BigEndian.PutUint32(a[16:], i) }
+func (a *address) setLTree(i uint32) { binary.BigEndian.PutUint32(a[16:], i) }
+
+func (a *address) setChain(i uint32)      { binary.BigEndian.PutUint32(a[20:], i) }
+func (a *address) setTreeHeight(z uint32) { binary.BigEndian.PutUint32(a[20:], z) }
+func (a *address) treeHeight() uint32     { return binary.BigEndian.Uint32(a[20:]) }
+
+func (a *address) setHash(i uint32)      { binary.BigEndian.PutUint32(a[24:], i) }
+func (a *address) setTreeIndex(i uint32) { binary.BigEndian.PutUint32(a[24:], i) }
+func (a *address) treeIndex() uint32     { return binary.BigEndian.Uint32(a[24:]) }
+
+func (a *address) setKeyAndMask(i uint32) { binary.BigEndian.PutUint32(a[28:], i) }
@@ -0,0 +1,29 @@
+// Package xmss implements the stateful hash-based signature schemes XMSS
+// and XMSS^MT as defined in RFC 8391.
+//
+// https://www.rfc-editor.org/rfc/rfc8391
+//
+// All parameter sets of Sections 5.3 and 5.4 of RFC 8391 are supported.
+// The secret values of the WOTS+ keys, which RFC 8391 leaves to the
+// implementation, are derived with PRF_keygen of NIST SP 800-208.
+//
+// A private key can make 2^h signatures, each with its own one-time key.
+// Using a one-time key twice breaks the scheme, so signing requires a
+// stateful.Store holding the index of the next unused one-time key:
+//
+//	pk, sk, err := xmss.GenerateKey(nil, xmss.SHA2_10_256)
+//	store, err := stateful.CreateFileStore("key.state", 0)
+//	sk.SetStore(store)
+//	err = xmss.SignTo(sk, msg, sig)
+//
+// The index is committed to the Store before a signature is computed. If
+// the commit fails, signing fails too, so that a crash can skip one-time
+// keys but never reuse them. A private key keeps the nodes of the trees
+// it currently signs with in memory, so that consecutive signatures only
+// require a few WOTS+ signatures.
+//
+// Verification does not need any state, and the parameter sets can be
+// used with the generic signatures API through ID.Scheme(). They are not
+// registered in sign/schemes, as their private keys cannot be used like
+// stateless ones.
+package xmss
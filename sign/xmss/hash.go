@@ -0,0 +1,185 @@
+package xmss
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding"
+	"encoding/binary"
+	"hash"
+
+	"github.com/katzenpost/circl/internal/sha3"
+)
+
+// Domain separators of the hash functions, see Section 5.1 of RFC 8391,
+// and of PRF_keygen of Section 5.1 of NIST SP 800-208.
+const (
+	padF = iota
+	padH
+	padHMsg
+	padPRF
+	padPRFKeygen
+)
+
+// hasher implements the keyed hash functions of Section 5.1 of RFC 8391
+// together with the randomized tree hashing of Section 4.1.4, for a fixed
+// public SEED and secret SK_SEED.
+//
+// Each function computes Hash(toByte(pad, n) ‖ KEY ‖ M), where Hash is
+// SHA-256 or SHA-512 for the SHA2 parameter sets, and SHAKE128 or
+// SHAKE256 for the SHAKE parameter sets, with n = 32 or n = 64
+// respectively. The output may alias the inputs. A hasher is not safe for
+// concurrent use.
+type hasher struct {
+	*params
+	pubSeed []byte
+	skSeed  []byte
+
+	sha2 hash.Hash
+	x    sha3.State
+
+	// prfSeed is the state of sha2 after absorbing toByte(3, n) ‖ SEED,
+	// which fills exactly one block.
+	prfSeed []byte
+	buf     [sha512.Size]byte
+}
+
+func newHasher(p *params, pubSeed, skSeed []byte) *hasher {
+	s := &hasher{params: p, pubSeed: pubSeed, skSeed: skSeed}
+	switch {
+	case p.shake && p.n == 32:
+		s.x = sha3.NewShake128()
+	case p.shake:
+		s.x = sha3.NewShake256()
+	case p.n == 32:
+		s.sha2 = sha256.New()
+	default:
+		s.sha2 = sha512.New()
+	}
+
+	if s.sha2 != nil {
+		s.begin(padPRF)
+		s.write(pubSeed)
+		state, err := s.sha2.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			panic(err)
+		}
+		s.prfSeed = state
+	}
+	return s
+}
+
+// begin starts a hash with the domain separator toByte(pad, n).
+func (s *hasher) begin(pad byte) {
+	var buf [64]byte
+	buf[s.n-1] = pad
+	if s.sha2 != nil {
+		s.sha2.Reset()
+	} else {
+		s.x.Reset()
+	}
+	s.write(buf[:s.n])
+}
+
+func (s *hasher) write(b []byte) {
+	if s.sha2 != nil {
+		_, _ = s.sha2.Write(b)
+	} else {
+		_, _ = s.x.Write(b)
+	}
+}
+
+// sum writes the n-byte hash into out.
+func (s *hasher) sum(out []byte) {
+	if s.sha2 != nil {
+		copy(out[:s.n], s.sha2.Sum(s.buf[:0]))
+	} else {
+		_, _ = s.x.Read(out[:s.n])
+	}
+}
+
+// prfAddr computes PRF(SEED, adrs), which yields the keys and bitmasks.
+func (s *hasher) prfAddr(out []byte, adrs *address) {
+	if s.sha2 != nil {
+		err := s.sha2.(encoding.BinaryUnmarshaler).UnmarshalBinary(s.prfSeed)
+		if err != nil {
+			panic(err)
+		}
+	} else {
+		s.begin(padPRF)
+		s.write(s.pubSeed)
+	}
+	s.write(adrs[:])
+	s.sum(out)
+}
+
+// prf computes PRF(key, m).
+func (s *hasher) prf(out, key, m []byte) {
+	s.begin(padPRF)
+	s.write(key)
+	s.write(m)
+	s.sum(out)
+}
+
+// prfKeygen computes PRF_keygen(SK_SEED, SEED ‖ adrs), which yields the
+// secret values of the WOTS+ keys.
+func (s *hasher) prfKeygen(out []byte, adrs *address) {
+	s.begin(padPRFKeygen)
+	s.write(s.skSeed)
+	s.write(s.pubSeed)
+	s.write(adrs[:])
+	s.sum(out)
+}
+
+// f computes F(KEY, in XOR BM) of the chaining function of Section 3.1.2
+// of RFC 8391.
+func (s *hasher) f(out, in []byte, adrs *address) {
+	n := s.n
+	var key, bm [64]byte
+	adrs.setKeyAndMask(0)
+	s.prfAddr(key[:n], adrs)
+	adrs.setKeyAndMask(1)
+	s.prfAddr(bm[:n], adrs)
+	for i := 0; i < n; i++ {
+		bm[i] ^= in[i]
+	}
+
+	s.begin(padF)
+	s.write(key[:n])
+	s.write(bm[:n])
+	s.sum(out)
+}
+
+// h computes RAND_HASH(left, right, SEED, adrs) of Section 4.1.4 of
+// RFC 8391.
+func (s *hasher) h(out, left, right []byte, adrs *address) {
+	n := s.n
+	var key, bm [64]byte
+	var bm2 [128]byte
+	adrs.setKeyAndMask(0)
+	s.prfAddr(key[:n], adrs)
+	adrs.setKeyAndMask(1)
+	s.prfAddr(bm[:n], adrs)
+	adrs.setKeyAndMask(2)
+	s.prfAddr(bm2[n:2*n], adrs)
+	for i := 0; i < n; i++ {
+		bm2[i] = bm[i] ^ left[i]
+		bm2[n+i] ^= right[i]
+	}
+
+	s.begin(padH)
+	s.write(key[:n])
+	s.write(bm2[:2*n])
+	s.sum(out)
+}
+
+// hMsg computes H_msg(r ‖ root ‖ toByte(idx, n), msg).
+func (s *hasher) hMsg(out, r, root []byte, idx uint64, msg []byte) {
+	var buf [64]byte
+	binary.BigEndian.PutUint64(buf[s.n-8:], idx)
+	s.begin(padHMsg)
+	s.write(r)
+	s.write(root)
+	s.write(buf[:s.n])
+	s.write(msg)
+	s.sum(out)
+}
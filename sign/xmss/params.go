@@ -0,0 +1,185 @@
+package xmss
+
+import "fmt"
+
+// ID identifies a parameter set of XMSS or XMSS^MT.
+type ID uint8
+
+// Parameter sets of XMSS, see Section 5.3 of RFC 8391, and of XMSS^MT,
+// prefixed with MT_, see Section 5.4 of RFC 8391. SHA2_h_n uses SHA2 with
+// a tree of height h and n-bit hashes, MT_SHA2_h_d_n uses SHA2 with a
+// hypertree of height h made of d layers of trees.
+const (
+	SHA2_10_256 ID = iota + 1
+	SHA2_16_256
+	SHA2_20_256
+	SHA2_10_512
+	SHA2_16_512
+	SHA2_20_512
+	SHAKE_10_256
+	SHAKE_16_256
+	SHAKE_20_256
+	SHAKE_10_512
+	SHAKE_16_512
+	SHAKE_20_512
+	MT_SHA2_20_2_256
+	MT_SHA2_20_4_256
+	MT_SHA2_40_2_256
+	MT_SHA2_40_4_256
+	MT_SHA2_40_8_256
+	MT_SHA2_60_3_256
+	MT_SHA2_60_6_256
+	MT_SHA2_60_12_256
+	MT_SHA2_20_2_512
+	MT_SHA2_20_4_512
+	MT_SHA2_40_2_512
+	MT_SHA2_40_4_512
+	MT_SHA2_40_8_512
+	MT_SHA2_60_3_512
+	MT_SHA2_60_6_512
+	MT_SHA2_60_12_512
+	MT_SHAKE_20_2_256
+	MT_SHAKE_20_4_256
+	MT_SHAKE_40_2_256
+	MT_SHAKE_40_4_256
+	MT_SHAKE_40_8_256
+	MT_SHAKE_60_3_256
+	MT_SHAKE_60_6_256
+	MT_SHAKE_60_12_256
+	MT_SHAKE_20_2_512
+	MT_SHAKE_20_4_512
+	MT_SHAKE_40_2_512
+	MT_SHAKE_40_4_512
+	MT_SHAKE_40_8_512
+	MT_SHAKE_60_3_512
+	MT_SHAKE_60_6_512
+	MT_SHAKE_60_12_512
+)
+
+// params holds the parameters of a parameter set.
+type params struct {
+	name   string
+	oid    uint32 // numeric identifier of Section 5.3 or 5.4 of RFC 8391
+	mt     bool   // whether this is a parameter set of XMSS^MT
+	n      int    // length in bytes of hashes and of the WOTS+ chain values
+	height int    // total height h of the (hyper)tree
+	d      int    // number of layers of trees
+	shake  bool   // whether to use SHAKE rather than SHA2
+}
+
+var paramSets = [...]params{
+	SHA2_10_256:        {"XMSS-SHA2_10_256", 0x1, false, 32, 10, 1, false},
+	SHA2_16_256:        {"XMSS-SHA2_16_256", 0x2, false, 32, 16, 1, false},
+	SHA2_20_256:        {"XMSS-SHA2_20_256", 0x3, false, 32, 20, 1, false},
+	SHA2_10_512:        {"XMSS-SHA2_10_512", 0x4, false, 64, 10, 1, false},
+	SHA2_16_512:        {"XMSS-SHA2_16_512", 0x5, false, 64, 16, 1, false},
+	SHA2_20_512:        {"XMSS-SHA2_20_512", 0x6, false, 64, 20, 1, false},
+	SHAKE_10_256:       {"XMSS-SHAKE_10_256", 0x7, false, 32, 10, 1, true},
+	SHAKE_16_256:       {"XMSS-SHAKE_16_256", 0x8, false, 32, 16, 1, true},
+	SHAKE_20_256:       {"XMSS-SHAKE_20_256", 0x9, false, 32, 20, 1, true},
+	SHAKE_10_512:       {"XMSS-SHAKE_10_512", 0xa, false, 64, 10, 1, true},
+	SHAKE_16_512:       {"XMSS-SHAKE_16_512", 0xb, false, 64, 16, 1, true},
+	SHAKE_20_512:       {"XMSS-SHAKE_20_512", 0xc, false, 64, 20, 1, true},
+	MT_SHA2_20_2_256:   {"XMSSMT-SHA2_20/2_256", 0x1, true, 32, 20, 2, false},
+	MT_SHA2_20_4_256:   {"XMSSMT-SHA2_20/4_256", 0x2, true, 32, 20, 4, false},
+	MT_SHA2_40_2_256:   {"XMSSMT-SHA2_40/2_256", 0x3, true, 32, 40, 2, false},
+	MT_SHA2_40_4_256:   {"XMSSMT-SHA2_40/4_256", 0x4, true, 32, 40, 4, false},
+	MT_SHA2_40_8_256:   {"XMSSMT-SHA2_40/8_256", 0x5, true, 32, 40, 8, false},
+	MT_SHA2_60_3_256:   {"XMSSMT-SHA2_60/3_256", 0x6, true, 32, 60, 3, false},
+	MT_SHA2_60_6_256:   {"XMSSMT-SHA2_60/6_256", 0x7, true, 32, 60, 6, false},
+	MT_SHA2_60_12_256:  {"XMSSMT-SHA2_60/12_256", 0x8, true, 32, 60, 12, false},
+	MT_SHA2_20_2_512:   {"XMSSMT-SHA2_20/2_512", 0x9, true, 64, 20, 2, false},
+	MT_SHA2_20_4_512:   {"XMSSMT-SHA2_20/4_512", 0xa, true, 64, 20, 4, false},
+	MT_SHA2_40_2_512:   {"XMSSMT-SHA2_40/2_512", 0xb, true, 64, 40, 2, false},
+	MT_SHA2_40_4_512:   {"XMSSMT-SHA2_40/4_512", 0xc, true, 64, 40, 4, false},
+	MT_SHA2_40_8_512:   {"XMSSMT-SHA2_40/8_512", 0xd, true, 64, 40, 8, false},
+	MT_SHA2_60_3_512:   {"XMSSMT-SHA2_60/3_512", 0xe, true, 64, 60, 3, false},
+	MT_SHA2_60_6_512:   {"XMSSMT-SHA2_60/6_512", 0xf, true, 64, 60, 6, false},
+	MT_SHA2_60_12_512:  {"XMSSMT-SHA2_60/12_512", 0x10, true, 64, 60, 12, false},
+	MT_SHAKE_20_2_256:  {"XMSSMT-SHAKE_20/2_256", 0x11, true, 32, 20, 2, true},
+	MT_SHAKE_20_4_256:  {"XMSSMT-SHAKE_20/4_256", 0x12, true, 32, 20, 4, true},
+	MT_SHAKE_40_2_256:  {"XMSSMT-SHAKE_40/2_256", 0x13, true, 32, 40, 2, true},
+	MT_SHAKE_40_4_256:  {"XMSSMT-SHAKE_40/4_256", 0x14, true, 32, 40, 4, true},
+	MT_SHAKE_40_8_256:  {"XMSSMT-SHAKE_40/8_256", 0x15, true, 32, 40, 8, true},
+	MT_SHAKE_60_3_256:  {"XMSSMT-SHAKE_60/3_256", 0x16, true, 32, 60, 3, true},
+	MT_SHAKE_60_6_256:  {"XMSSMT-SHAKE_60/6_256", 0x17, true, 32, 60, 6, true},
+	MT_SHAKE_60_12_256: {"XMSSMT-SHAKE_60/12_256", 0x18, true, 32, 60, 12, true},
+	MT_SHAKE_20_2_512:  {"XMSSMT-SHAKE_20/2_512", 0x19, true, 64, 20, 2, true},
+	MT_SHAKE_20_4_512:  {"XMSSMT-SHAKE_20/4_512", 0x1a, true, 64, 20, 4, true},
+	MT_SHAKE_40_2_512:  {"XMSSMT-SHAKE_40/2_512", 0x1b, true, 64, 40, 2, true},
+	MT_SHAKE_40_4_512:  {"XMSSMT-SHAKE_40/4_512", 0x1c, true, 64, 40, 4, true},
+	MT_SHAKE_40_8_512:  {"XMSSMT-SHAKE_40/8_512", 0x1d, true, 64, 40, 8, true},
+	MT_SHAKE_60_3_512:  {"XMSSMT-SHAKE_60/3_512", 0x1e, true, 64, 60, 3, true},
+	MT_SHAKE_60_6_512:  {"XMSSMT-SHAKE_60/6_512", 0x1f, true, 64, 60, 6, true},
+	MT_SHAKE_60_12_512: {"XMSSMT-SHAKE_60/12_512", 0x20, true, 64, 60, 12, true},
+}
+
+// IsValid returns whether id is one of the parameter sets of RFC 8391.
+func (id ID) IsValid() bool { return id >= SHA2_10_256 && id <= MT_SHAKE_60_12_512 }
+
+func (id ID) String() string {
+	if !id.IsValid() {
+		return fmt.Sprintf("XMSS(%d)", uint8(id))
+	}
+	return paramSets[id].name
+}
+
+// OID returns the numeric identifier of the parameter set used in the
+// encoding of public keys. The identifiers of XMSS and XMSS^MT overlap.
+func (id ID) OID() uint32 { return id.params().oid }
+
+// IsMT returns whether id is a parameter set of XMSS^MT.
+func (id ID) IsMT() bool { return id.params().mt }
+
+// Signatures returns the number of signatures that a private key of the
+// parameter set can make, which is 2^h.
+func (id ID) Signatures() uint64 { return id.params().signatures() }
+
+func (id ID) params() *params {
+	if !id.IsValid() {
+		panic(ErrID)
+	}
+	return &paramSets[id]
+}
+
+// Winternitz parameter w = 2^lgW. RFC 8391 fixes w = 16.
+const (
+	lgW = 4
+	w   = 1 << lgW
+)
+
+// hPrime returns the height of the trees of a layer.
+func (p *params) hPrime() int { return p.height / p.d }
+
+// signatures returns the number of one-time keys, 2^h.
+func (p *params) signatures() uint64 { return 1 << p.height }
+
+// wotsLen returns the number of chains of a WOTS+ key, that is
+// len1 + len2 with len1 = 2n and len2 = 3.
+func (p *params) wotsLen() int { return 2*p.n + 3 }
+
+// idxSize returns the length of the index in a signature.
+func (p *params) idxSize() int {
+	if p.mt {
+		return (p.height + 7) / 8
+	}
+	return 4
+}
+
+// treeSigSize returns the size of the signature of a tree of a layer,
+// made of a WOTS+ signature and an authentication path.
+func (p *params) treeSigSize() int { return (p.wotsLen() + p.hPrime()) * p.n }
+
+// signatureSize returns the size of idx_sig ‖ r ‖ the tree signatures.
+func (p *params) signatureSize() int {
+	return p.idxSize() + p.n + p.d*p.treeSigSize()
+}
+
+// publicKeySize returns the size of OID ‖ root ‖ SEED.
+func (p *params) publicKeySize() int { return 4 + 2*p.n }
+
+// privateKeySize returns the size of OID ‖ SK_SEED ‖ SK_PRF ‖ root ‖ SEED.
+func (p *params) privateKeySize() int { return 4 + 4*p.n }
+
+// seedSize returns the size of SK_SEED ‖ SK_PRF ‖ SEED.
+func (p *params) seedSize() int { return 3 * p.n }
@@ -0,0 +1,125 @@
+package xmss
+
+import (
+	"encoding/binary"
+
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/stateful"
+)
+
+// Boilerplate for generic signatures API
+
+type scheme struct{ id ID }
+
+var schemes = func() (ret [MT_SHAKE_60_12_512 + 1]sign.Scheme) {
+	for id := SHA2_10_256; id <= MT_SHAKE_60_12_512; id++ {
+		ret[id] = &scheme{id}
+	}
+	return
+}()
+
+// Scheme returns a generic signature interface for the parameter set.
+// Panics if id is not valid.
+//
+// The Sign method of the returned scheme panics, instead of reusing a
+// one-time key, if the private key has no Store attached or its Store
+// fails.
+func (id ID) Scheme() sign.Scheme {
+	if !id.IsValid() {
+		panic(ErrID)
+	}
+	return schemes[id]
+}
+
+func (s *scheme) Name() string          { return s.id.String() }
+func (s *scheme) PublicKeySize() int    { return s.id.params().publicKeySize() }
+func (s *scheme) PrivateKeySize() int   { return s.id.params().privateKeySize() }
+func (s *scheme) SignatureSize() int    { return s.id.params().signatureSize() }
+func (s *scheme) SeedSize() int         { return s.id.params().seedSize() }
+func (s *scheme) SupportsContext() bool { return false }
+
+// GenerateKey generates a fresh key pair, whose private key has a
+// stateful.MemoryStore attached.
+func (s *scheme) GenerateKey() (sign.PublicKey, sign.PrivateKey, error) {
+	pk, sk, err := GenerateKey(nil, s.id)
+	if err != nil {
+		return nil, nil, err
+	}
+	sk.SetStore(stateful.NewMemoryStore(0))
+	return pk, sk, nil
+}
+
+func (s *scheme) Sign(
+	sk sign.PrivateKey,
+	msg []byte,
+	opts *sign.SignatureOpts,
+) []byte {
+	priv, ok := sk.(*PrivateKey)
+	if !ok || priv.id != s.id {
+		panic(sign.ErrTypeMismatch)
+	}
+	if opts != nil && opts.Context != "" {
+		panic(sign.ErrContextNotSupported)
+	}
+	sig := make([]byte, s.SignatureSize())
+	if err := SignTo(priv, msg, sig); err != nil {
+		panic(err)
+	}
+	return sig
+}
+
+func (s *scheme) Verify(
+	pk sign.PublicKey,
+	msg, sig []byte,
+	opts *sign.SignatureOpts,
+) bool {
+	pub, ok := pk.(*PublicKey)
+	if !ok || pub.id != s.id {
+		panic(sign.ErrTypeMismatch)
+	}
+	if opts != nil && opts.Context != "" {
+		panic(sign.ErrContextNotSupported)
+	}
+	return Verify(pub, msg, sig)
+}
+
+// DeriveKey derives a key pair from seed. The private key has no Store
+// attached.
+func (s *scheme) DeriveKey(seed []byte) (sign.PublicKey, sign.PrivateKey) {
+	return NewKeyFromSeed(s.id, seed)
+}
+
+func (s *scheme) UnmarshalBinaryPublicKey(buf []byte) (sign.PublicKey, error) {
+	if len(buf) != s.PublicKeySize() {
+		return nil, sign.ErrPubKeySize
+	}
+	if binary.BigEndian.Uint32(buf) != s.id.OID() {
+		return nil, ErrID
+	}
+	buf2 := append([]byte(nil), buf[4:]...)
+	n := len(buf2) / 2
+	return &PublicKey{id: s.id, root: buf2[:n], seed: buf2[n:]}, nil
+}
+
+// UnmarshalBinaryPrivateKey unmarshals a private key, which has no Store
+// attached.
+func (s *scheme) UnmarshalBinaryPrivateKey(buf []byte) (sign.PrivateKey, error) {
+	if len(buf) != s.PrivateKeySize() {
+		return nil, sign.ErrPrivKeySize
+	}
+	if binary.BigEndian.Uint32(buf) != s.id.OID() {
+		return nil, ErrID
+	}
+	buf2 := append([]byte(nil), buf[4:]...)
+	n := len(buf2) / 4
+	return &PrivateKey{
+		id:      s.id,
+		skSeed:  buf2[:n],
+		skPrf:   buf2[n : 2*n],
+		root:    buf2[2*n : 3*n],
+		pubSeed: buf2[3*n:],
+	}, nil
+}
+
+func (sk *PrivateKey) Scheme() sign.Scheme { return sk.id.Scheme() }
+func (pk *PublicKey) Scheme() sign.Scheme  { return pk.id.Scheme() }
@@ -0,0 +1,192 @@
+package xmss
+
+// XMSS trees, see Sections 4.1 and 4.2 of RFC 8391.
+
+// ltree compresses the uncompressed WOTS+ public key pk into out with the
+// unbalanced L-tree of adrs. pk is overwritten.
+func (s *state) ltree(out, pk []byte, adrs *address) {
+	n := s.n
+	l := s.wotsLen()
+	adrs.setTreeHeight(0)
+	for l > 1 {
+		for i := 0; i < l/2; i++ {
+			adrs.setTreeIndex(uint32(i))
+			s.h(pk[i*n:(i+1)*n], pk[2*i*n:(2*i+1)*n], pk[(2*i+1)*n:(2*i+2)*n], adrs)
+		}
+		if l%2 == 1 {
+			copy(pk[(l/2)*n:(l/2+1)*n], pk[(l-1)*n:l*n])
+		}
+		l = (l + 1) / 2
+		adrs.setTreeHeight(adrs.treeHeight() + 1)
+	}
+	copy(out[:n], pk[:n])
+}
+
+// leaf writes the leaf i of the tree of adrs, which is the compressed
+// WOTS+ public key of the key pair i, into out.
+func (s *state) leaf(out []byte, i uint32, adrs *address) {
+	var pk [(2*64 + 3) * 64]byte
+	adrs.setTypeAndClear(addrOTS)
+	adrs.setOTS(i)
+	s.wotsPkGen(pk[:s.wotsLen()*s.n], adrs)
+	adrs.setTypeAndClear(addrLTree)
+	adrs.setLTree(i)
+	s.ltree(out, pk[:s.wotsLen()*s.n], adrs)
+}
+
+// newLevels allocates the levels of a subtree of height hh, which hold
+// 2^hh, 2^(hh-1), ..., 1 nodes.
+func newLevels(hh, n int) [][]byte {
+	buf := make([]byte, ((2<<hh)-1)*n)
+	levels := make([][]byte, hh+1)
+	for z := range levels {
+		l := (1 << (hh - z)) * n
+		levels[z], buf = buf[:l], buf[l:]
+	}
+	return levels
+}
+
+// hashLevels computes levels[1:] of a subtree of the tree of adrs from
+// levels[0], which holds the consecutive nodes at height base starting at
+// index start.
+func (s *state) hashLevels(levels [][]byte, base int, start uint32, adrs *address) {
+	n := s.n
+	adrs.setTypeAndClear(addrHashTree)
+	for r := 1; r < len(levels); r++ {
+		below := levels[r-1]
+		for j := 0; j < len(levels[r])/n; j++ {
+			// The tree height of the address is that of the children.
+			adrs.setTreeHeight(uint32(base + r - 1))
+			adrs.setTreeIndex(start>>r + uint32(j))
+			s.h(levels[r][j*n:(j+1)*n], below[2*j*n:(2*j+1)*n], below[(2*j+1)*n:(2*j+2)*n], adrs)
+		}
+	}
+}
+
+// subtree computes all nodes of the subtree of the tree of adrs whose
+// leaves are start, ..., start + 2^(len(levels)-1) - 1.
+func (s *state) subtree(levels [][]byte, start uint32, adrs *address) {
+	n := s.n
+	for j := 0; j < len(levels[0])/n; j++ {
+		s.leaf(levels[0][j*n:(j+1)*n], start+uint32(j), adrs)
+	}
+	s.hashLevels(levels, 0, start, adrs)
+}
+
+// rootFromSig writes the root of the tree of adrs computed from the
+// signature sig with leaf idx of the n-byte message msg into out, see
+// Algorithm 13 of RFC 8391.
+func (s *state) rootFromSig(out []byte, idx uint32, sig, msg []byte, adrs *address) {
+	n := s.n
+	var pk [(2*64 + 3) * 64]byte
+	wotsSize := s.wotsLen() * n
+	adrs.setTypeAndClear(addrOTS)
+	adrs.setOTS(idx)
+	s.wotsPkFromSig(pk[:wotsSize], sig[:wotsSize], msg, adrs)
+	adrs.setTypeAndClear(addrLTree)
+	adrs.setLTree(idx)
+
+	var node [64]byte
+	s.ltree(node[:n], pk[:wotsSize], adrs)
+
+	auth := sig[wotsSize:]
+	adrs.setTypeAndClear(addrHashTree)
+	adrs.setTreeIndex(idx)
+	for k := 0; k < s.hPrime(); k++ {
+		authK := auth[k*n : (k+1)*n]
+		adrs.setTreeHeight(uint32(k))
+		if (idx>>k)&1 == 0 {
+			adrs.setTreeIndex(adrs.treeIndex() / 2)
+			s.h(node[:n], node[:n], authK, adrs)
+		} else {
+			adrs.setTreeIndex((adrs.treeIndex() - 1) / 2)
+			s.h(node[:n], authK, node[:n], adrs)
+		}
+	}
+	copy(out[:n], node[:n])
+}
+
+// maxCacheHeight bounds the height of the subtrees of which a private key
+// keeps all nodes in memory. It is a variable for testing.
+var maxCacheHeight = 10
+
+// treeCache holds nodes of the tree of a layer in which a private key
+// currently signs, so that the authentication paths of consecutive
+// signatures do not have to be recomputed from scratch.
+//
+// Trees of height h' ≤ maxCacheHeight are kept entirely. Higher trees are
+// split at height cut = h' - maxCacheHeight: only the nodes above the cut
+// are kept, together with the subtree of height cut below it that holds
+// the last leaf used.
+type treeCache struct {
+	tree     uint64
+	cut      int
+	top      [][]byte // nodes at heights cut, ..., h'
+	low      [][]byte // nodes at heights 0, ..., cut of the last subtree
+	lowStart uint32   // first leaf of low
+}
+
+// cut returns the height at which the trees of a layer are split.
+func (p *params) cut() int {
+	if p.hPrime() <= maxCacheHeight {
+		return 0
+	}
+	return p.hPrime() - maxCacheHeight
+}
+
+// fill computes the nodes above the cut of the given tree of layer.
+func (s *state) fill(c *treeCache, layer uint32, tree uint64) {
+	n, hp, cut := s.n, s.hPrime(), s.cut()
+	var adrs address
+	adrs.setLayer(layer)
+	adrs.setTree(tree)
+
+	c.tree = tree
+	c.cut = cut
+	c.top = newLevels(hp-cut, n)
+	c.low = nil
+	if cut == 0 {
+		s.subtree(c.top, 0, &adrs)
+		return
+	}
+
+	low := newLevels(cut, n)
+	for j := 0; j < 1<<(hp-cut); j++ {
+		s.subtree(low, uint32(j)<<cut, &adrs)
+		copy(c.top[0][j*n:(j+1)*n], low[cut])
+	}
+	s.hashLevels(c.top, cut, 0, &adrs)
+}
+
+// root returns the root of the tree held by c.
+func (c *treeCache) root() []byte { return c.top[len(c.top)-1] }
+
+// authPath writes the authentication path of leaf idx of the tree held by
+// c, which is that of layer, into auth.
+func (s *state) authPath(auth []byte, c *treeCache, layer uint32, idx uint32) {
+	n, cut := s.n, c.cut
+	if cut > 0 {
+		start := idx >> cut << cut
+		if c.low == nil || c.lowStart != start {
+			var adrs address
+			adrs.setLayer(layer)
+			adrs.setTree(c.tree)
+			if c.low == nil {
+				c.low = newLevels(cut, n)
+			}
+			s.subtree(c.low, start, &adrs)
+			c.lowStart = start
+		}
+	}
+
+	for z := 0; z < s.hPrime(); z++ {
+		sib := (idx >> z) ^ 1
+		var node []byte
+		if z < cut {
+			node = c.low[z][(sib-c.lowStart>>z)*uint32(n):]
+		} else {
+			node = c.top[z-cut][sib*uint32(n):]
+		}
+		copy(auth[z*n:(z+1)*n], node[:n])
+	}
+}
@@ -0,0 +1,91 @@
+package xmss
+
+// WOTS+ one-time signatures, see Section 3 of RFC 8391.
+
+// state holds a parameter set together with the hash functions keyed with
+// SEED and SK_SEED of a key pair.
+type state struct {
+	*params
+	*hasher
+}
+
+func newState(p *params, pubSeed, skSeed []byte) state {
+	return state{p, newHasher(p, pubSeed, skSeed)}
+}
+
+// chain applies steps iterations of F to x, starting at index i of the
+// chain, and writes the result into out.
+func (s *state) chain(out, x []byte, i, steps uint32, adrs *address) {
+	copy(out[:s.n], x)
+	for j := i; j < i+steps; j++ {
+		adrs.setHash(j)
+		s.f(out, out, adrs)
+	}
+}
+
+// wotsDigits writes the base-w digits of msg followed by those of its
+// checksum into digits, which must be of length wotsLen().
+func (s *state) wotsDigits(digits []byte, msg []byte) {
+	len1 := 2 * s.n
+	csum := 0
+	for i := 0; i < s.n; i++ {
+		digits[2*i] = msg[i] >> 4
+		digits[2*i+1] = msg[i] & 0xf
+		csum += 2*(w-1) - int(digits[2*i]) - int(digits[2*i+1])
+	}
+
+	// The checksum is shifted left to be byte aligned, encoded as two bytes
+	// and split into len2 = 3 digits, which are its three top nibbles.
+	csum <<= 4
+	digits[len1] = byte(csum>>12) & 0xf
+	digits[len1+1] = byte(csum>>8) & 0xf
+	digits[len1+2] = byte(csum>>4) & 0xf
+}
+
+// wotsSk writes the secret value of chain i of the WOTS+ key pair of adrs
+// into out, following Section 7.2.1 of NIST SP 800-208.
+func (s *state) wotsSk(out []byte, i uint32, adrs *address) {
+	adrs.setChain(i)
+	adrs.setHash(0)
+	adrs.setKeyAndMask(0)
+	s.prfKeygen(out, adrs)
+}
+
+// wotsPkGen writes the uncompressed WOTS+ public key of the key pair of
+// adrs into pk, which must be of length wotsLen()*n.
+func (s *state) wotsPkGen(pk []byte, adrs *address) {
+	n := s.n
+	for i := 0; i < s.wotsLen(); i++ {
+		pkI := pk[i*n : (i+1)*n]
+		s.wotsSk(pkI, uint32(i), adrs)
+		s.chain(pkI, pkI, 0, w-1, adrs)
+	}
+}
+
+// wotsSign writes the WOTS+ signature of the n-byte message msg with the
+// key pair of adrs into sig.
+func (s *state) wotsSign(sig, msg []byte, adrs *address) {
+	n := s.n
+	var digits [2*64 + 3]byte
+	s.wotsDigits(digits[:s.wotsLen()], msg)
+
+	for i := 0; i < s.wotsLen(); i++ {
+		sigI := sig[i*n : (i+1)*n]
+		s.wotsSk(sigI, uint32(i), adrs)
+		s.chain(sigI, sigI, 0, uint32(digits[i]), adrs)
+	}
+}
+
+// wotsPkFromSig writes the uncompressed WOTS+ public key computed from the
+// signature sig of the n-byte message msg into pk.
+func (s *state) wotsPkFromSig(pk, sig, msg []byte, adrs *address) {
+	n := s.n
+	var digits [2*64 + 3]byte
+	s.wotsDigits(digits[:s.wotsLen()], msg)
+
+	for i := 0; i < s.wotsLen(); i++ {
+		adrs.setChain(uint32(i))
+		s.chain(pk[i*n:(i+1)*n], sig[i*n:(i+1)*n],
+			uint32(digits[i]), w-1-uint32(digits[i]), adrs)
+	}
+}
@@ -0,0 +1,314 @@
+package xmss
+
+import (
+	"bytes"
+	"crypto"
+	cryptoRand "crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/stateful"
+)
+
+var (
+	// ErrID is returned or raised when an invalid parameter set is used.
+	ErrID = errors.New("xmss: invalid parameter set")
+
+	// ErrHashedMessage is returned by PrivateKey.Sign if it is asked to
+	// sign a hashed message.
+	ErrHashedMessage = errors.New("xmss: cannot sign hashed message")
+)
+
+// PublicKey is the type of XMSS and XMSS^MT public keys.
+type PublicKey struct {
+	id   ID
+	root []byte
+	seed []byte
+}
+
+// PrivateKey is the type of XMSS and XMSS^MT private keys.
+//
+// The index of the next unused one-time key is not part of the private
+// key, but is kept in the Store attached with SetStore. A PrivateKey is
+// safe for concurrent use.
+type PrivateKey struct {
+	id      ID
+	skSeed  []byte
+	skPrf   []byte
+	root    []byte
+	pubSeed []byte
+
+	mu    sync.Mutex
+	store stateful.Store
+	cache []treeCache // one per layer
+}
+
+// GenerateKey generates a key pair of the parameter set id using
+// entropy from rand. If rand is nil, crypto/rand.Reader will be used.
+//
+// This computes the whole top tree, which takes a long time for the
+// parameter sets with trees of height 16 and above. The private key has no
+// Store attached.
+func GenerateKey(rand io.Reader, id ID) (*PublicKey, *PrivateKey, error) {
+	if !id.IsValid() {
+		return nil, nil, ErrID
+	}
+	if rand == nil {
+		rand = cryptoRand.Reader
+	}
+	seed := make([]byte, id.params().seedSize())
+	if _, err := io.ReadFull(rand, seed); err != nil {
+		return nil, nil, err
+	}
+	pk, sk := NewKeyFromSeed(id, seed)
+	return pk, sk, nil
+}
+
+// NewKeyFromSeed derives a key pair of the parameter set id from seed,
+// which is the concatenation SK_SEED ‖ SK_PRF ‖ SEED. Panics if seed is
+// not of length 3n; see Scheme().SeedSize().
+//
+// The private key has no Store attached. Deriving the same key twice and
+// signing with both copies reuses one-time keys, unless they share the
+// same Store.
+func NewKeyFromSeed(id ID, seed []byte) (*PublicKey, *PrivateKey) {
+	p := id.params()
+	if len(seed) != p.seedSize() {
+		panic(sign.ErrSeedSize)
+	}
+
+	n := p.n
+	buf := make([]byte, 4*n)
+	copy(buf, seed[:2*n])
+	copy(buf[3*n:], seed[2*n:])
+	sk := &PrivateKey{
+		id:      id,
+		skSeed:  buf[:n],
+		skPrf:   buf[n : 2*n],
+		root:    buf[2*n : 3*n],
+		pubSeed: buf[3*n:],
+		cache:   make([]treeCache, p.d),
+	}
+
+	s := newState(p, sk.pubSeed, sk.skSeed)
+	top := &sk.cache[p.d-1]
+	s.fill(top, uint32(p.d-1), 0)
+	copy(sk.root, top.root())
+
+	return sk.public(), sk
+}
+
+// SetStore attaches the Store holding the index of the next unused
+// one-time key to sk. Signing fails until a Store is attached.
+func (sk *PrivateKey) SetStore(store stateful.Store) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	sk.store = store
+}
+
+// Remaining returns the number of signatures sk can still make, according
+// to its Store.
+func (sk *PrivateKey) Remaining() (uint64, error) {
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+	if sk.store == nil {
+		return 0, stateful.ErrNoStore
+	}
+	next, err := sk.store.Load()
+	if err != nil {
+		return 0, err
+	}
+	max := sk.id.params().signatures()
+	if next >= max {
+		return 0, nil
+	}
+	return max - next, nil
+}
+
+// SignTo signs the given message and writes the signature into sig. It will
+// panic if sig is not of length Scheme().SignatureSize().
+//
+// The index of the one-time key is reserved in the Store of sk before
+// signing. Errors, and does not sign, if no Store is attached, if the
+// Store fails to commit the next index, or if sk is exhausted.
+func SignTo(sk *PrivateKey, msg, sig []byte) error {
+	p := sk.id.params()
+	if len(sig) != p.signatureSize() {
+		panic("xmss: wrong signature size")
+	}
+
+	sk.mu.Lock()
+	defer sk.mu.Unlock()
+
+	idx, err := stateful.Reserve(sk.store, p.signatures())
+	if err != nil {
+		return err
+	}
+	sk.signInternal(sig, msg, idx)
+	return nil
+}
+
+// Verify checks whether the given signature by pk on msg is valid.
+func Verify(pk *PublicKey, msg, sig []byte) bool {
+	p := pk.id.params()
+	n, hp := p.n, p.hPrime()
+	if len(sig) != p.signatureSize() {
+		return false
+	}
+
+	var buf [8]byte
+	copy(buf[8-p.idxSize():], sig)
+	idx := binary.BigEndian.Uint64(buf[:])
+	if idx >= p.signatures() {
+		return false
+	}
+
+	s := newState(p, pk.seed, nil)
+	r := sig[p.idxSize() : p.idxSize()+n]
+	var node [64]byte
+	s.hMsg(node[:n], r, pk.root, idx, msg)
+
+	var adrs address
+	sig = sig[p.idxSize()+n:]
+	idxTree := idx >> hp
+	idxLeaf := uint32(idx & (1<<hp - 1))
+	for j := 0; j < p.d; j++ {
+		if j > 0 {
+			idxLeaf = uint32(idxTree & (1<<hp - 1))
+			idxTree >>= hp
+		}
+		adrs.setLayer(uint32(j))
+		adrs.setTree(idxTree)
+		sigJ := sig[j*p.treeSigSize() : (j+1)*p.treeSigSize()]
+		s.rootFromSig(node[:n], idxLeaf, sigJ, node[:n], &adrs)
+	}
+
+	return subtle.ConstantTimeCompare(node[:n], pk.root) == 1
+}
+
+// signInternal writes the signature of msg with the one-time key idx into
+// sig, see Algorithm 16 of RFC 8391. Must be called with sk.mu held.
+func (sk *PrivateKey) signInternal(sig, msg []byte, idx uint64) {
+	p := sk.id.params()
+	n, hp := p.n, p.hPrime()
+	s := newState(p, sk.pubSeed, sk.skSeed)
+	if sk.cache == nil {
+		sk.cache = make([]treeCache, p.d)
+	}
+
+	var buf [32]byte
+	binary.BigEndian.PutUint64(buf[24:], idx)
+	copy(sig, buf[32-p.idxSize():])
+
+	r := sig[p.idxSize() : p.idxSize()+n]
+	s.prf(r, sk.skPrf, buf[:])
+	var node [64]byte
+	s.hMsg(node[:n], r, sk.root, idx, msg)
+
+	var adrs address
+	sig = sig[p.idxSize()+n:]
+	wotsSize := p.wotsLen() * n
+	idxTree := idx >> hp
+	idxLeaf := uint32(idx & (1<<hp - 1))
+	for j := 0; j < p.d; j++ {
+		if j > 0 {
+			idxLeaf = uint32(idxTree & (1<<hp - 1))
+			idxTree >>= hp
+		}
+		c := &sk.cache[j]
+		if c.top == nil || c.tree != idxTree {
+			s.fill(c, uint32(j), idxTree)
+		}
+
+		sigJ := sig[j*p.treeSigSize() : (j+1)*p.treeSigSize()]
+		adrs.setLayer(uint32(j))
+		adrs.setTree(idxTree)
+		adrs.setTypeAndClear(addrOTS)
+		adrs.setOTS(idxLeaf)
+		s.wotsSign(sigJ[:wotsSize], node[:n], &adrs)
+		s.authPath(sigJ[wotsSize:], c, uint32(j), idxLeaf)
+		copy(node[:n], c.root())
+	}
+}
+
+// ID returns the parameter set of the key.
+func (pk *PublicKey) ID() ID { return pk.id }
+
+// ID returns the parameter set of the key.
+func (sk *PrivateKey) ID() ID { return sk.id }
+
+// MarshalBinary returns OID ‖ root ‖ SEED, as in Section 4.1.7 of
+// RFC 8391.
+func (pk *PublicKey) MarshalBinary() ([]byte, error) {
+	ret := make([]byte, 4, 4+2*len(pk.root))
+	binary.BigEndian.PutUint32(ret, pk.id.OID())
+	ret = append(ret, pk.root...)
+	return append(ret, pk.seed...), nil
+}
+
+// MarshalBinary returns OID ‖ SK_SEED ‖ SK_PRF ‖ root ‖ SEED. The index of
+// the next unused one-time key is not included.
+func (sk *PrivateKey) MarshalBinary() ([]byte, error) {
+	ret := make([]byte, 4, 4+4*len(sk.root))
+	binary.BigEndian.PutUint32(ret, sk.id.OID())
+	ret = append(ret, sk.skSeed...)
+	ret = append(ret, sk.skPrf...)
+	ret = append(ret, sk.root...)
+	return append(ret, sk.pubSeed...), nil
+}
+
+func (sk *PrivateKey) public() *PublicKey {
+	return &PublicKey{id: sk.id, root: sk.root, seed: sk.pubSeed}
+}
+
+// Public returns the public key corresponding to sk.
+func (sk *PrivateKey) Public() crypto.PublicKey { return sk.public() }
+
+// Equal returns whether the two public keys are equal.
+func (pk *PublicKey) Equal(other crypto.PublicKey) bool {
+	castOther, ok := other.(*PublicKey)
+	if !ok {
+		return false
+	}
+	return pk.id == castOther.id &&
+		bytes.Equal(pk.root, castOther.root) &&
+		bytes.Equal(pk.seed, castOther.seed)
+}
+
+// Equal returns whether the two private keys are equal. The attached
+// Stores are not compared.
+func (sk *PrivateKey) Equal(other crypto.PrivateKey) bool {
+	castOther, ok := other.(*PrivateKey)
+	if !ok {
+		return false
+	}
+	a, _ := sk.MarshalBinary()
+	b, _ := castOther.MarshalBinary()
+	return sk.id == castOther.id && subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Sign signs the given message.
+//
+// opts.HashFunc() must return zero, which can be achieved by passing
+// crypto.Hash(0) for opts. rand is ignored, as XMSS signatures are
+// deterministic. Errors as SignTo does.
+//
+// This function is used to make PrivateKey implement the crypto.Signer
+// interface.  The package-level SignTo function might be more convenient
+// to use.
+func (sk *PrivateKey) Sign(rand io.Reader, msg []byte, opts crypto.SignerOpts) (
+	signature []byte, err error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, ErrHashedMessage
+	}
+
+	sig := make([]byte, sk.id.params().signatureSize())
+	if err := SignTo(sk, msg, sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
@@ -0,0 +1,351 @@
+package xmss
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/katzenpost/circl/sign"
+	"github.com/katzenpost/circl/sign/stateful"
+)
+
+func TestParams(t *testing.T) {
+	// Sections 5.3 and 5.4 of RFC 8391.
+	sizes := map[ID]int{
+		SHA2_10_256: 2500, SHA2_16_256: 2692, SHA2_20_256: 2820,
+		MT_SHA2_20_2_256: 4963, MT_SHA2_20_4_256: 9251,
+		MT_SHA2_40_2_256: 5605, MT_SHA2_40_4_256: 9893, MT_SHA2_40_8_256: 18469,
+		MT_SHA2_60_3_256: 8392, MT_SHA2_60_6_256: 14824, MT_SHA2_60_12_256: 27688,
+	}
+	for id := SHA2_10_256; id <= MT_SHAKE_60_12_512; id++ {
+		p := id.params()
+		if p.height%p.d != 0 {
+			t.Fatalf("%v: wrong d", id)
+		}
+		if want, ok := sizes[id]; ok && p.signatureSize() != want {
+			t.Fatalf("%v: signature size %d, want %d", id, p.signatureSize(), want)
+		}
+		if p.publicKeySize() != 4+2*p.n {
+			t.Fatalf("%v: wrong public key size", id)
+		}
+	}
+	if SHAKE_20_512.OID() != 0xc || MT_SHAKE_60_12_512.OID() != 0x20 {
+		t.Fatal("wrong OIDs")
+	}
+	if ID(0).IsValid() || (MT_SHAKE_60_12_512 + 1).IsValid() {
+		t.Fatal()
+	}
+	if _, _, err := GenerateKey(nil, ID(0)); err != ErrID {
+		t.Fatal()
+	}
+}
+
+// Regression values for the key derived from the seed 0, 1, …, 3n-1 and
+// the signature of "message" with the given one-time key. They were
+// computed with this package, which uses PRF_keygen of NIST SP 800-208 for
+// the WOTS+ secret values; TestReference checks the vectors of the
+// reference implementation.
+func TestRegression(t *testing.T) {
+	for _, tc := range []struct {
+		id   ID
+		idx  uint64
+		root string
+		sig  string
+	}{
+		{SHA2_10_256, 5, "9d898033e37af48e6a116f8b15651cc26773467007ad19375d38c23c690c3483", "820617a75aa8d9ec27edb1132c8cec11450d00f1e33fc82fa8d4c8a58d6947ce"},
+		{SHAKE_10_256, 1000, "8012297b4ba4716a3797657818056ccf69e42527b640857896c2fee8d023de07", "83dc33d38f63caca20b6c93f5bf6a312e859d6d0110e075507ebd6809bb6030d"},
+		{SHA2_10_512, 513, "20f3bd9b45621c1aff11294887644558e6a23103f1992f8c6586ee4f4a02cbb8446a1c0d3c2ae392ea53b9a0b06b9dfd46758db35d43817092bf03cb91555c4c", "1acc515f13c60a5d0a70854aac62d4e40dfcfb140a302e7ebda01bdf39758505"},
+		{SHAKE_10_512, 2, "8e4661183105330454c96af0e17a7e4df813b09778df6458b56ef235d505f08aa00571159a32462244ba5a38999dd31cb1b405b78c44bba1670e5afe7f7e8dbe", "d2a604938365c9d52f82f11b04ef5e41e6c6814b5be4e0d413480a601f185f73"},
+		{MT_SHA2_20_4_256, 0x2f3a1, "2063c0b3ddf86940b17f60d5f607b1af8a2a8be6281ce5121012291e66a1f83a", "d25b872eb240ff7b35a9fa9898141c6b3fd9b4767986540e7ddc7dc7661eb99c"},
+		{MT_SHAKE_20_4_256, 0xfffff, "5a4f569c68caf8933d40e2f64a0f2cc1799278d66fa87821af5395372522d3db", "6a99d270e038371a12be31c44a56f77dc364df4390aee9537852c2fc5e86f139"},
+		{MT_SHA2_40_8_512, 0x123456789a, "45526783e9cde2d9284665f72c6878f298dd52a7f536c5baec553f0091f115de69cc76f135191d99323218e30f9a93dadc5738b7b149d37ec30daa605900e169", "133e48aeda0c17604c58c0ccb7443afde722985225d9cb759dd41202cdd2f83e"},
+		{MT_SHAKE_60_12_256, 0x0fedcba987654321, "8e7738540e1aa9714554999995f91547ca4003d5fe86de13bef400dc82e07245", "b24fe7652a4da2ab00f6cb696a3e5ad5930ec869e1611b3bc8b860e960766cdc"},
+	} {
+		t.Run(tc.id.String(), func(t *testing.T) {
+			if testing.Short() && tc.id == SHAKE_10_512 {
+				t.Skip("slow")
+			}
+			seed := make([]byte, tc.id.Scheme().SeedSize())
+			for i := range seed {
+				seed[i] = byte(i)
+			}
+			pk, sk := NewKeyFromSeed(tc.id, seed)
+			if got := hex.EncodeToString(pk.root); got != tc.root {
+				t.Fatalf("root %s, want %s", got, tc.root)
+			}
+
+			sk.SetStore(stateful.NewMemoryStore(tc.idx))
+			msg := []byte("message")
+			sig, err := sk.Sign(nil, msg, crypto.Hash(0))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := sha256.Sum256(sig)
+			if got := hex.EncodeToString(h[:]); got != tc.sig {
+				t.Fatalf("signature %s, want %s", got, tc.sig)
+			}
+			if !Verify(pk, msg, sig) {
+				t.Fatal("verification failed")
+			}
+			if Verify(pk, []byte("massage"), sig) {
+				t.Fatal("verified wrong message")
+			}
+			sig[len(sig)-1] ^= 1
+			if Verify(pk, msg, sig) {
+				t.Fatal("verified corrupted signature")
+			}
+		})
+	}
+}
+
+// TestReference checks signatures of the xmss-reference implementation,
+// see testdata/README.md.
+func TestReference(t *testing.T) {
+	buf, err := os.ReadFile("testdata/xmss-reference.json")
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("missing testdata/xmss-reference.json, see testdata/README.md")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vectors []struct {
+		Name      string   `json:"name"`
+		PublicKey hexBytes `json:"publicKey"`
+		SM        hexBytes `json:"sm"`
+	}
+	if err := json.Unmarshal(buf, &vectors); err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) == 0 {
+		t.Fatal("no test vectors")
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			var id ID
+			for i := SHA2_10_256; i <= MT_SHAKE_60_12_512; i++ {
+				if i.String() == v.Name {
+					id = i
+				}
+			}
+			if !id.IsValid() {
+				t.Fatalf("unknown parameter set %s", v.Name)
+			}
+			pk, err := id.Scheme().UnmarshalBinaryPublicKey(v.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			size := id.Scheme().SignatureSize()
+			if len(v.SM) < size {
+				t.Fatal("malformed test vector")
+			}
+			sig, msg := v.SM[:size], v.SM[size:]
+			if !Verify(pk.(*PublicKey), msg, sig) {
+				t.Fatal("verification failed")
+			}
+			sig = append([]byte(nil), sig...)
+			sig[size-1] ^= 1
+			if Verify(pk.(*PublicKey), msg, sig) {
+				t.Fatal("verified corrupted signature")
+			}
+		})
+	}
+}
+
+type hexBytes []byte
+
+func (b *hexBytes) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*b, err = hex.DecodeString(s)
+	return err
+}
+
+func TestCache(t *testing.T) {
+	// Split the trees of height 5 at height 3 to exercise the cache of the
+	// subtrees below the cut.
+	defer func(h int) { maxCacheHeight = h }(maxCacheHeight)
+
+	id := MT_SHA2_20_4_256
+	seed := make([]byte, id.Scheme().SeedSize())
+	sig1 := make([]byte, id.Scheme().SignatureSize())
+	sig2 := make([]byte, id.Scheme().SignatureSize())
+	pk1, sk1 := NewKeyFromSeed(id, seed)
+	maxCacheHeight = 2
+	pk2, sk2 := NewKeyFromSeed(id, seed)
+	if !pk1.Equal(pk2) {
+		t.Fatal("roots differ")
+	}
+
+	for _, idx := range []uint64{0, 1, 7, 8, 9, 31, 32, 0x1234, 0x1235, 0x1240} {
+		sk1.SetStore(stateful.NewMemoryStore(idx))
+		sk2.SetStore(stateful.NewMemoryStore(idx))
+		if err := SignTo(sk1, nil, sig1); err != nil {
+			t.Fatal(err)
+		}
+		if err := SignTo(sk2, nil, sig2); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sig1, sig2) {
+			t.Fatalf("signatures differ at %d", idx)
+		}
+		if !Verify(pk1, nil, sig2) {
+			t.Fatalf("verification failed at %d", idx)
+		}
+	}
+}
+
+type failingStore struct{ stateful.MemoryStore }
+
+var errFailing = errors.New("commit failed")
+
+func (*failingStore) Commit(uint64) error { return errFailing }
+
+func TestState(t *testing.T) {
+	id := MT_SHA2_20_4_256
+	seed := make([]byte, id.Scheme().SeedSize())
+	pk, sk := NewKeyFromSeed(id, seed)
+	msg := []byte("message")
+	sig := make([]byte, id.Scheme().SignatureSize())
+
+	if err := SignTo(sk, msg, sig); err != stateful.ErrNoStore {
+		t.Fatalf("signed without store: %v", err)
+	}
+	if !bytes.Equal(sig, make([]byte, len(sig))) {
+		t.Fatal("signature written without store")
+	}
+
+	sk.SetStore(&failingStore{})
+	if err := SignTo(sk, msg, sig); err != errFailing {
+		t.Fatalf("signed without commit: %v", err)
+	}
+
+	store := stateful.NewMemoryStore(0)
+	sk.SetStore(store)
+	seen := make(map[[32]byte]bool)
+	for i := 0; i < 3; i++ {
+		if err := SignTo(sk, msg, sig); err != nil {
+			t.Fatal(err)
+		}
+		if next, _ := store.Load(); next != uint64(i+1) {
+			t.Fatalf("index %d after %d signatures", next, i+1)
+		}
+		if !Verify(pk, msg, sig) {
+			t.Fatal("verification failed")
+		}
+		seen[sha256.Sum256(sig)] = true
+	}
+	if len(seen) != 3 {
+		t.Fatal("signatures repeat")
+	}
+
+	sk.SetStore(stateful.NewMemoryStore(id.Signatures() - 1))
+	if n, _ := sk.Remaining(); n != 1 {
+		t.Fatalf("%d remaining, want 1", n)
+	}
+	if err := SignTo(sk, msg, sig); err != nil {
+		t.Fatal(err)
+	}
+	if err := SignTo(sk, msg, sig); err != stateful.ErrExhausted {
+		t.Fatalf("signed with exhausted key: %v", err)
+	}
+}
+
+func TestScheme(t *testing.T) {
+	scheme := MT_SHA2_20_4_256.Scheme()
+	var _ sign.Scheme = scheme
+	pk, sk, err := scheme.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("message")
+	sig := scheme.Sign(sk, msg, nil)
+	if !scheme.Verify(pk, msg, sig, nil) {
+		t.Fatal("verification failed")
+	}
+
+	ppk, _ := pk.MarshalBinary()
+	psk, _ := sk.MarshalBinary()
+	pk2, err := scheme.UnmarshalBinaryPublicKey(ppk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk2, err := scheme.UnmarshalBinaryPrivateKey(psk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pk.Equal(pk2) || !sk.Equal(sk2) {
+		t.Fatal("marshalling roundtrip failed")
+	}
+	if _, err := MT_SHA2_20_4_512.Scheme().UnmarshalBinaryPublicKey(ppk); err == nil {
+		t.Fatal("unmarshalled public key of other parameter set")
+	}
+
+	func() {
+		defer func() {
+			if recover() != stateful.ErrNoStore {
+				t.Fatal("expected panic")
+			}
+		}()
+		scheme.Sign(sk2, msg, nil)
+	}()
+
+	// Continue where the first key stopped.
+	sk2.(*PrivateKey).SetStore(stateful.NewMemoryStore(1))
+	sig2 := scheme.Sign(sk2, msg, nil)
+	if bytes.Equal(sig, sig2) || !scheme.Verify(pk2, msg, sig2, nil) {
+		t.Fatal("signature with unmarshalled key")
+	}
+
+	func() {
+		defer func() {
+			if recover() != sign.ErrContextNotSupported {
+				t.Fatal("expected panic")
+			}
+		}()
+		scheme.Sign(sk, msg, &sign.SignatureOpts{Context: "abc"})
+	}()
+
+	if _, err := sk.Sign(nil, msg, crypto.SHA256); err != ErrHashedMessage {
+		t.Fatal("signed hashed message")
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	for _, id := range []ID{SHA2_10_256, MT_SHA2_20_4_256} {
+		seed := make([]byte, id.Scheme().SeedSize())
+		_, sk := NewKeyFromSeed(id, seed)
+		sk.SetStore(stateful.NewMemoryStore(0))
+		sig := make([]byte, id.Scheme().SignatureSize())
+		b.Run(id.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := SignTo(sk, nil, sig); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	for _, id := range []ID{SHA2_10_256, MT_SHA2_20_4_256} {
+		seed := make([]byte, id.Scheme().SeedSize())
+		pk, sk := NewKeyFromSeed(id, seed)
+		sk.SetStore(stateful.NewMemoryStore(0))
+		sig := make([]byte, id.Scheme().SignatureSize())
+		_ = SignTo(sk, nil, sig)
+		b.Run(id.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				Verify(pk, nil, sig)
+			}
+		})
+	}
+}
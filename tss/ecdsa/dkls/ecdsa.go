@@ -0,0 +1,229 @@
+// Package dkls provides experimental threshold ECDSA signatures, following
+// the protocol of Doerner, Kondi, Lee and shelat [2], which is secure against
+// malicious players.
+//
+// A group of players runs a distributed key generation, KeyGen, after which
+// each of them holds a KeyShare of a private key no one knows, such that any
+// threshold of them can sign. The signatures are standard ECDSA signatures
+// under the corresponding public key, which verify with crypto/ecdsa. It
+// supports the curves P-256, P-384, P-521 and secp256k1. The two-party
+// case is a threshold of 2 out of 2 players.
+//
+// # Protocol Overview
+//
+// Key generation is the Pedersen DKG with Feldman commitments, where the
+// players prove knowledge of their secrets as in [1]. Signing takes two
+// phases: the signers first run Presign, which can happen before the
+// message is known, and then produce their SignatureShares with Sign in a
+// non-interactive way. Anyone can combine the shares into a signature with
+// Combine.
+//
+//	Signer_i(KeyShare_i)                       Combiner(PublicKey, digest)
+//	======================================================================
+//	pre_i = Presign([signers])  <- talking with the other signers ->
+//
+//	share_i = pre_i.Sign(digest)
+//
+//	                            share_i
+//	                          ---------->
+//
+//	                                   sig = Combine(digest, [share_j])
+//
+// Presign follows Protocol 3.6 of [2]: each signer picks an instance key
+// r_i, commits to R_i = [r_i]G, and picks an inversion mask φ_i. The signers
+// hold additive shares sk_i of the private key, with pk_i = [sk_i]G. Every
+// pair of signers computes additive shares of r_i·φ_j and sk_i·φ_j with the
+// multiplication of [3], based on oblivious transfer with the package
+// ot/simot, where the input of P_j is random and then corrected. The
+// signers check in the exponent that the inputs of every P_i to the
+// multiplications are the r_i and sk_i of R_i and pk_i. Then R = Σ R_i,
+// the signers hold shares of u = rφ and v = skφ, and a signature share for
+// a digest m is (u_i, φ_i·m + r_x·v_i), so that s = (m + r_x·sk)/r.
+// Each Presignature must be used to sign one digest only.
+//
+// # Security
+//
+// The protocol is secure against malicious players, as long as fewer than
+// threshold players collude, with abort: a malicious player can make Presign
+// fail, or the signature shares fail to combine, but it learns nothing from
+// it. An error wrapping ErrInvalidMessage, ErrInvalidShare, ErrInvalidProof,
+// ErrInvalidCommitment or ErrInvalidMultiplication identifies a player that
+// deviated from the protocol, and a Presign that fails must not be retried
+// with this player. ErrInvalidBroadcast means that some player sent
+// different messages to different players, but does not identify it.
+// Combine detects invalid signature shares, but cannot attribute them.
+//
+// The multiplications check the consistency of the oblivious transfers of
+// the sender, and encode the input of the receiver at random, so that the
+// sender cannot learn it from selective failures. Values that must be
+// broadcast are echoed to all the players, so that a player sending
+// different values to different players is detected. The network must
+// still authenticate and encrypt messages. The security also relies on that
+// of ot/simot, and this implementation has not been audited.
+//
+// # References
+//
+// [1] https://eprint.iacr.org/2020/852
+//
+// [2] https://eprint.iacr.org/2023/765
+//
+// [3] https://eprint.iacr.org/2019/523
+package dkls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+
+	"github.com/katzenpost/circl/ecc/secp256k1"
+	"github.com/katzenpost/circl/group"
+)
+
+// Network is used by a player to exchange messages with the other players
+// during KeyGen and Presign. Messages sent from one player to another must be
+// delivered in order, and Send must not wait for the message to be received.
+// The network is expected to authenticate and encrypt messages.
+type Network interface {
+	// Send sends msg to the player with identifier to.
+	Send(to uint16, msg []byte) error
+	// Receive returns the next message sent by the player with identifier
+	// from.
+	Receive(from uint16) ([]byte, error)
+}
+
+// PublicKey is the public key of a group of players.
+type PublicKey struct {
+	g group.Group
+	e group.Element
+}
+
+// ECDSA returns the public key as a crypto/ecdsa public key.
+func (pub *PublicKey) ECDSA() *ecdsa.PublicKey {
+	c := curveOf(pub.g)
+	enc, err := pub.e.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	byteLen := (c.Params().BitSize + 7) / 8
+	return &ecdsa.PublicKey{
+		Curve: c,
+		X:     new(big.Int).SetBytes(enc[1 : 1+byteLen]),
+		Y:     new(big.Int).SetBytes(enc[1+byteLen:]),
+	}
+}
+
+// Group returns the group of the public key.
+func (pub *PublicKey) Group() group.Group { return pub.g }
+
+// MarshalBinary encodes the public key in compressed form.
+func (pub *PublicKey) MarshalBinary() ([]byte, error) { return pub.e.MarshalBinaryCompress() }
+
+func (pub *PublicKey) UnmarshalBinary(g group.Group, data []byte) error {
+	if curveOf(g) == nil {
+		return ErrInvalidGroup
+	}
+	e, err := decodeElement(g, data)
+	if err != nil {
+		return err
+	}
+	*pub = PublicKey{g, e}
+	return nil
+}
+
+// curveOf returns the curve of the group, or nil if it is not supported.
+func curveOf(g group.Group) elliptic.Curve {
+	switch g {
+	case group.P256:
+		return elliptic.P256()
+	case group.P384:
+		return elliptic.P384()
+	case group.P521:
+		return elliptic.P521()
+	case group.Secp256k1:
+		return secp256k1.Curve()
+	default:
+		return nil
+	}
+}
+
+// digestToScalar converts a digest into a scalar as ECDSA does, using its
+// leftmost bits up to the bit length of the order of the group.
+func digestToScalar(g group.Group, digest []byte) group.Scalar {
+	orderBits := curveOf(g).Params().N.BitLen()
+	orderBytes := (orderBits + 7) / 8
+	if len(digest) > orderBytes {
+		digest = digest[:orderBytes]
+	}
+	e := new(big.Int).SetBytes(digest)
+	if excess := len(digest)*8 - orderBits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return g.NewScalar().SetBigInt(e)
+}
+
+// xCoordinate returns the x-coordinate of a point that is not the identity,
+// reduced modulo the order of the group.
+func xCoordinate(e group.Element) (group.Scalar, error) {
+	enc, err := e.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(enc[1:])
+	return e.Group().NewScalar().SetBigInt(x), nil
+}
+
+// decodeElement decodes an element in compressed form, and rejects the
+// identity.
+func decodeElement(g group.Group, data []byte) (group.Element, error) {
+	if uint(len(data)) != g.Params().CompressedElementLength {
+		return nil, group.ErrUnmarshal
+	}
+	e := g.NewElement()
+	if err := e.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if e.IsIdentity() {
+		return nil, ErrIdentityElement
+	}
+	return e, nil
+}
+
+// decodeScalar decodes a scalar and rejects non-canonical encodings.
+func decodeScalar(g group.Group, data []byte) (group.Scalar, error) {
+	if uint(len(data)) != g.Params().ScalarLength {
+		return nil, group.ErrUnmarshal
+	}
+	s := g.NewScalar()
+	if err := s.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	enc, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(enc, data) != 1 {
+		return nil, group.ErrUnmarshal
+	}
+	return s, nil
+}
+
+var (
+	ErrInvalidGroup          = errors.New("ecdsa_threshold: group not supported")
+	ErrInvalidParameters     = errors.New("ecdsa_threshold: invalid threshold parameters")
+	ErrInvalidIdentifier     = errors.New("ecdsa_threshold: invalid player identifier")
+	ErrInvalidSigners        = errors.New("ecdsa_threshold: invalid set of signers")
+	ErrInvalidMessage        = errors.New("ecdsa_threshold: malformed message")
+	ErrInvalidShare          = errors.New("ecdsa_threshold: invalid share")
+	ErrInvalidProof          = errors.New("ecdsa_threshold: invalid proof of knowledge")
+	ErrInvalidCommitment     = errors.New("ecdsa_threshold: invalid commitment")
+	ErrInvalidBroadcast      = errors.New("ecdsa_threshold: inconsistent broadcast")
+	ErrInvalidMultiplication = errors.New("ecdsa_threshold: inconsistent multiplication")
+	ErrInvalidSignature      = errors.New("ecdsa_threshold: invalid signature")
+	ErrIdentityElement       = errors.New("ecdsa_threshold: unexpected identity element")
+	ErrPresignatureUsed      = errors.New("ecdsa_threshold: presignature already used")
+	ErrGroupMismatch         = errors.New("ecdsa_threshold: mismatched groups")
+	ErrNotEnoughShares       = errors.New("ecdsa_threshold: not enough signature shares")
+	ErrDegeneratePresign     = errors.New("ecdsa_threshold: degenerate presignature, try again")
+)
@@ -0,0 +1,377 @@
+package dkls
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/internal/test"
+)
+
+// localNetwork connects players running in the same process.
+type localNetwork struct {
+	id    uint16
+	links [][]chan []byte // links[from-1][to-1]
+}
+
+func (n *localNetwork) Send(to uint16, msg []byte) error {
+	n.links[n.id-1][to-1] <- msg
+	return nil
+}
+
+func (n *localNetwork) Receive(from uint16) ([]byte, error) {
+	return <-n.links[from-1][n.id-1], nil
+}
+
+func newLinks(players uint16) [][]chan []byte {
+	links := make([][]chan []byte, players)
+	for i := range links {
+		links[i] = make([]chan []byte, players)
+		for j := range links[i] {
+			links[i][j] = make(chan []byte, 4)
+		}
+	}
+	return links
+}
+
+func runKeyGen(t testing.TB, g group.Group, threshold, players uint16) []*KeyShare {
+	links := newLinks(players)
+	shares := make([]*KeyShare, players)
+	errs := make(chan error, players)
+	for i := range shares {
+		go func(i int) {
+			var err error
+			net := &localNetwork{uint16(i + 1), links}
+			shares[i], err = KeyGen(g, rand.Reader, net, uint16(i+1), threshold, players)
+			errs <- err
+		}(i)
+	}
+	for range shares {
+		test.CheckNoErr(t, <-errs, "key generation failed")
+	}
+	for i := range shares {
+		if !shares[i].PublicKey().e.IsEqual(shares[0].PublicKey().e) {
+			t.Fatal("players disagree on the public key")
+		}
+	}
+	return shares
+}
+
+func runPresign(t testing.TB, shares []*KeyShare, signers []uint16) []*Presignature {
+	links := newLinks(shares[0].Players)
+	pres := make([]*Presignature, len(signers))
+	errs := make(chan error, len(signers))
+	for i, id := range signers {
+		go func(i int, id uint16) {
+			var err error
+			net := &localNetwork{id, links}
+			pres[i], err = shares[id-1].Presign(rand.Reader, net, signers)
+			errs <- err
+		}(i, id)
+	}
+	for range signers {
+		test.CheckNoErr(t, <-errs, "presign failed")
+	}
+	return pres
+}
+
+func TestThresholdECDSA(t *testing.T) {
+	for _, g := range []group.Group{group.P256, group.Secp256k1} {
+		for _, v := range []struct {
+			threshold, players uint16
+			signers            []uint16
+		}{
+			{2, 2, []uint16{1, 2}},
+			{2, 3, []uint16{3, 1}},
+			{3, 5, []uint16{2, 4, 5}},
+			{3, 5, []uint16{1, 2, 3, 5}},
+		} {
+			name := fmt.Sprintf("%v/%v-of-%v/%v", g, v.threshold, v.players, v.signers)
+			t.Run(name, func(t *testing.T) {
+				shares := runKeyGen(t, g, v.threshold, v.players)
+				pub := shares[0].PublicKey()
+				digest := sha256.Sum256([]byte(name))
+
+				pres := runPresign(t, shares, v.signers)
+				sigShares := make([]SignatureShare, len(pres))
+				for i := range pres {
+					var err error
+					sigShares[i], err = pres[i].Sign(digest[:])
+					test.CheckNoErr(t, err, "sign failed")
+				}
+				sig, err := pub.Combine(digest[:], sigShares)
+				test.CheckNoErr(t, err, "combine failed")
+				test.CheckOk(ecdsa.VerifyASN1(pub.ECDSA(), digest[:], sig), "verification failed", t)
+				test.CheckOk(Verify(pub, digest[:], sig), "verification failed", t)
+
+				other := sha256.Sum256([]byte("other"))
+				test.CheckOk(!Verify(pub, other[:], sig), "verification should fail", t)
+				_, err = pres[0].Sign(other[:])
+				if !errors.Is(err, ErrPresignatureUsed) {
+					test.ReportError(t, err, ErrPresignatureUsed)
+				}
+				_, err = pub.Combine(digest[:], sigShares[1:])
+				test.CheckIsErr(t, err, "should fail with a missing share")
+			})
+		}
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	links := newLinks(3)
+	net := &localNetwork{1, links}
+	for _, v := range []struct {
+		g                      group.Group
+		id, threshold, players uint16
+	}{
+		{group.Ristretto255, 1, 2, 3},
+		{group.P256, 1, 1, 3},
+		{group.P256, 1, 4, 3},
+		{group.P256, 0, 2, 3},
+		{group.P256, 4, 2, 3},
+	} {
+		_, err := KeyGen(v.g, rand.Reader, net, v.id, v.threshold, v.players)
+		test.CheckIsErr(t, err, "should fail with invalid parameters")
+	}
+
+	shares := runKeyGen(t, group.P256, 2, 3)
+	for _, signers := range [][]uint16{{1}, {2, 3}, {1, 1}, {1, 4}} {
+		_, err := shares[0].Presign(rand.Reader, net, signers)
+		if !errors.Is(err, ErrInvalidSigners) {
+			test.ReportError(t, err, ErrInvalidSigners, signers)
+		}
+	}
+}
+
+func TestInvalidShares(t *testing.T) {
+	g := group.Secp256k1
+	shares := runKeyGen(t, g, 2, 3)
+	pub := shares[0].PublicKey()
+	digest := sha256.Sum256([]byte("hello"))
+	signers := []uint16{1, 2}
+
+	pres := runPresign(t, shares, signers)
+	s1, _ := pres[0].Sign(digest[:])
+	s2, _ := pres[1].Sign(digest[:])
+	bad := s2
+	bad.w = g.NewScalar().Add(s2.w, g.NewScalar().SetUint64(1))
+	_, err := pub.Combine(digest[:], []SignatureShare{s1, bad})
+	if !errors.Is(err, ErrInvalidSignature) {
+		test.ReportError(t, err, ErrInvalidSignature)
+	}
+	_, err = pub.Combine(digest[:], []SignatureShare{s1, s1})
+	test.CheckIsErr(t, err, "should fail with repeated shares")
+
+	// Shares from another presignature do not combine.
+	other := runPresign(t, shares, signers)
+	s3, _ := other[1].Sign(digest[:])
+	_, err = pub.Combine(digest[:], []SignatureShare{s1, s3})
+	test.CheckIsErr(t, err, "should fail mixing presignatures")
+
+	_, err = pub.Combine(digest[:], []SignatureShare{s1, s2})
+	test.CheckNoErr(t, err, "combine failed")
+}
+
+func TestMultiplication(t *testing.T) {
+	g := group.Secp256k1
+	ctx := mulPair(1, 2)
+	a := []group.Scalar{g.RandomScalar(rand.Reader), g.RandomScalar(rand.Reader)}
+	run := func(s *mulSender, msg []byte) ([]group.Scalar, *mulReceiver, error) {
+		r, msg, err := newMulReceiver(g, rand.Reader, ctx, len(a), msg)
+		test.CheckNoErr(t, err, "receiver failed")
+		msg, err = s.respond(msg)
+		test.CheckNoErr(t, err, "sender failed")
+		d, err := r.finish(msg)
+		return d, r, err
+	}
+
+	s, msg, err := newMulSender(g, rand.Reader, ctx, a)
+	test.CheckNoErr(t, err, "sender failed")
+	d, r, err := run(s, msg)
+	test.CheckNoErr(t, err, "receiver failed")
+	c := s.shares()
+	for k := range a {
+		want := g.NewScalar().Mul(r.chi, a[k])
+		test.CheckOk(g.NewScalar().Add(c[k], d[k]).IsEqual(want), "wrong product", t)
+	}
+
+	// A sender using other inputs in some of the transfers is detected.
+	s, _, err = newMulSender(g, rand.Reader, ctx, a)
+	test.CheckNoErr(t, err, "sender failed")
+	other := []group.Scalar{a[0], g.NewScalar().Add(a[1], g.NewScalar().SetUint64(1)), s.a[2]}
+	for l := 0; l < len(s.ots); l += 2 {
+		test.CheckNoErr(t, s.transfer(rand.Reader, l, other), "transfer failed")
+	}
+	msg, err = s.message()
+	test.CheckNoErr(t, err, "sender failed")
+	_, _, err = run(s, msg)
+	if !errors.Is(err, ErrInvalidMultiplication) {
+		test.ReportError(t, err, ErrInvalidMultiplication)
+	}
+
+	// So is a sender revealing another combination of its inputs.
+	s, msg, err = newMulSender(g, rand.Reader, ctx, a)
+	test.CheckNoErr(t, err, "sender failed")
+	s.a[2] = g.RandomScalar(rand.Reader)
+	_, _, err = run(s, msg)
+	if !errors.Is(err, ErrInvalidMultiplication) {
+		test.ReportError(t, err, ErrInvalidMultiplication)
+	}
+}
+
+// tamperingNetwork lets the player with identifier from modify the n-th
+// message it sends to the player with identifier to.
+type tamperingNetwork struct {
+	localNetwork
+	from, to uint16
+	n, sent  int
+	tamper   func(msg []byte) []byte
+}
+
+func (n *tamperingNetwork) Send(to uint16, msg []byte) error {
+	if n.id == n.from && to == n.to {
+		n.sent++
+		if n.sent == n.n {
+			msg = n.tamper(append([]byte{}, msg...))
+		}
+	}
+	return n.localNetwork.Send(to, msg)
+}
+
+// runTamperedPresign runs Presign with signers 1 and 2, where signer 1
+// modifies its n-th message to signer 2, and returns the error of signer 2.
+func runTamperedPresign(shares []*KeyShare, n int, tamper func(msg []byte) []byte) error {
+	links := newLinks(shares[0].Players)
+	signers := []uint16{1, 2}
+	errs := make(chan error, 1)
+	go func() {
+		net := &tamperingNetwork{localNetwork{1, links}, 1, 2, n, 0, tamper}
+		_, _ = shares[0].Presign(rand.Reader, net, signers)
+	}()
+	go func() {
+		net := &localNetwork{2, links}
+		_, err := shares[1].Presign(rand.Reader, net, signers)
+		errs <- err
+	}()
+	return <-errs
+}
+
+func TestMaliciousSigner(t *testing.T) {
+	g := group.P256
+	shares := runKeyGen(t, g, 2, 3)
+	scalarLen := int(g.Params().ScalarLength)
+	elementLen := int(g.Params().CompressedElementLength)
+
+	for _, v := range []struct {
+		name   string
+		n      int
+		tamper func(msg []byte) []byte
+		want   error
+	}{
+		{"commitment", 1, func(msg []byte) []byte { msg[2] ^= 1; return msg }, ErrInvalidBroadcast},
+		{"opening", 3, func(msg []byte) []byte { msg[4*(2+elementLen)+2] ^= 1; return msg }, ErrInvalidCommitment},
+		{"echo", 2, func(msg []byte) []byte { msg[2+scalarLen+2] ^= 1; return msg }, ErrInvalidBroadcast},
+		{"transfer", 3, func(msg []byte) []byte { msg[len(msg)-1] ^= 1; return msg }, ErrInvalidMultiplication},
+		{"input", 3, func(msg []byte) []byte {
+			// Replace Γ_u, which is the same as using another r_i.
+			other, _ := g.RandomElement(rand.Reader).MarshalBinaryCompress()
+			copy(msg[2:2+elementLen], other)
+			return msg
+		}, ErrInvalidMultiplication},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			err := runTamperedPresign(shares, v.n, v.tamper)
+			if !errors.Is(err, v.want) {
+				test.ReportError(t, err, v.want)
+			}
+		})
+	}
+}
+
+func TestKeyGenEcho(t *testing.T) {
+	g := group.P256
+	elementLen := int(g.Params().CompressedElementLength)
+	links := newLinks(3)
+	errs := make(chan error, 1)
+	// Player 1 sends another commitment to its second coefficient to player 2.
+	net := &tamperingNetwork{localNetwork{1, links}, 1, 2, 1, 0, func(msg []byte) []byte {
+		other, _ := g.RandomElement(rand.Reader).MarshalBinaryCompress()
+		copy(msg[2+elementLen+2:], other)
+		return msg
+	}}
+	go func() { _, _ = KeyGen(g, rand.Reader, net, 1, 2, 3) }()
+	go func() { _, _ = KeyGen(g, rand.Reader, &localNetwork{3, links}, 3, 2, 3) }()
+	go func() {
+		_, err := KeyGen(g, rand.Reader, &localNetwork{2, links}, 2, 2, 3)
+		errs <- err
+	}()
+	if err := <-errs; !errors.Is(err, ErrInvalidBroadcast) {
+		test.ReportError(t, err, ErrInvalidBroadcast)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	g := group.Secp256k1
+	shares := runKeyGen(t, g, 2, 2)
+	for i := range shares {
+		enc, err := shares[i].MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		shares[i] = new(KeyShare)
+		test.CheckNoErr(t, shares[i].UnmarshalBinary(g, enc), "unmarshal failed")
+		test.CheckIsErr(t, new(KeyShare).UnmarshalBinary(g, enc[:len(enc)-1]), "should fail with a truncated key share")
+	}
+	var pub PublicKey
+	enc, err := shares[0].PublicKey().MarshalBinary()
+	test.CheckNoErr(t, err, "marshal failed")
+	test.CheckNoErr(t, pub.UnmarshalBinary(g, enc), "unmarshal failed")
+
+	digest := sha256.Sum256([]byte("hello"))
+	pres := runPresign(t, shares, []uint16{1, 2})
+	sigShares := make([]SignatureShare, len(pres))
+	for i := range pres {
+		s, err := pres[i].Sign(digest[:])
+		test.CheckNoErr(t, err, "sign failed")
+		enc, err := s.MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		test.CheckNoErr(t, sigShares[i].UnmarshalBinary(g, enc), "unmarshal failed")
+		test.CheckIsErr(t, sigShares[i].UnmarshalBinary(g, enc[1:]), "should fail with a truncated share")
+	}
+	sig, err := pub.Combine(digest[:], sigShares)
+	test.CheckNoErr(t, err, "combine failed")
+	test.CheckOk(ecdsa.VerifyASN1(pub.ECDSA(), digest[:], sig), "verification failed", t)
+}
+
+func TestDecoder(t *testing.T) {
+	enc := &encoder{}
+	enc.bytes([]byte("hello"))
+	enc.scalar(group.P256.NewScalar().SetUint64(5))
+	msg, err := enc.marshal()
+	test.CheckNoErr(t, err, "marshal failed")
+
+	d := &decoder{data: msg}
+	_ = d.bytes()
+	_ = d.scalar(group.P256)
+	test.CheckNoErr(t, d.done(), "decode failed")
+	d = &decoder{data: msg[:len(msg)-1]}
+	_ = d.bytes()
+	_ = d.scalar(group.P256)
+	test.CheckIsErr(t, d.done(), "should fail with a truncated message")
+	d = &decoder{data: msg}
+	_ = d.bytes()
+	test.CheckIsErr(t, d.done(), "should fail with trailing data")
+}
+
+func BenchmarkPresign(b *testing.B) {
+	for _, g := range []group.Group{group.P256, group.Secp256k1} {
+		shares := runKeyGen(b, g, 2, 2)
+		b.Run(fmt.Sprint(g), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				runPresign(b, shares, []uint16{1, 2})
+			}
+		})
+	}
+}
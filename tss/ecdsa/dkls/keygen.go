@@ -0,0 +1,207 @@
+package dkls
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/math/polynomial"
+	"github.com/katzenpost/circl/zk/dl"
+)
+
+// keyGenContext is the context string of the proofs of knowledge of KeyGen.
+const keyGenContext = "ecdsa_threshold keygen"
+
+// KeyShare is the share of the private key held by a player.
+type KeyShare struct {
+	g         group.Group
+	ID        uint16 // Identifier of the player, starting at one.
+	Threshold uint16 // Minimum number of players required to sign.
+	Players   uint16 // Number of players holding a share.
+	share     group.Scalar
+	pub       *PublicKey
+}
+
+// PublicKey returns the public key of the group of players.
+func (k *KeyShare) PublicKey() *PublicKey { return k.pub }
+
+// KeyGen runs the distributed key generation of the player with identifier
+// id, from 1 to players, over g, which is one of group.P256, group.P384,
+// group.P521 and group.Secp256k1. All the players must run it concurrently
+// with the same parameters, and it requires 2 <= threshold <= players.
+//
+// Each player shares a random secret with a polynomial of degree
+// threshold-1, broadcasting Feldman commitments to its coefficients and a
+// proof of knowledge of the secret, which the players then echo. The private
+// key is the sum of the secrets. An error wrapping ErrInvalidShare or
+// ErrInvalidProof identifies a player that deviated from the protocol.
+func KeyGen(g group.Group, rnd io.Reader, net Network, id, threshold, players uint16) (*KeyShare, error) {
+	if curveOf(g) == nil {
+		return nil, ErrInvalidGroup
+	}
+	if threshold < 2 || players < threshold {
+		return nil, ErrInvalidParameters
+	}
+	if id < 1 || id > players {
+		return nil, ErrInvalidIdentifier
+	}
+	if rnd == nil {
+		return nil, io.ErrNoProgress
+	}
+	p := &party{net: net, id: id}
+	for j := uint16(1); j <= players; j++ {
+		if j != id {
+			p.peers = append(p.peers, j)
+		}
+	}
+
+	// Round 1: broadcast the commitments and the proof of knowledge.
+	coeffs := make([]group.Scalar, threshold)
+	coms := make([]group.Element, threshold)
+	enc := &encoder{}
+	for i := range coeffs {
+		coeffs[i] = g.RandomNonZeroScalar(rnd)
+		coms[i] = g.NewElement().MulGen(coeffs[i])
+		enc.element(coms[i])
+	}
+	proof := dl.Prove(g, g.Generator(), coms[0], coeffs[0], identifier(id), []byte(keyGenContext), rnd)
+	enc.element(proof.V)
+	enc.scalar(proof.R)
+	msg, err := enc.marshal()
+	if err != nil {
+		return nil, err
+	}
+	in, err := p.broadcast(msg)
+	if err != nil {
+		return nil, err
+	}
+	in[id] = msg
+	echo := view(keyGenContext, p.all(), in)
+	allComs := map[uint16][]group.Element{id: coms}
+	for _, j := range p.peers {
+		d := &decoder{data: in[j]}
+		jComs := make([]group.Element, threshold)
+		for i := range jComs {
+			jComs[i] = d.element(g)
+		}
+		V := d.element(g)
+		R := d.scalar(g)
+		if err := d.done(); err != nil {
+			return nil, fmt.Errorf("%w from player %d: %v", ErrInvalidMessage, j, err)
+		}
+		if !dl.Verify(g, g.Generator(), jComs[0], dl.Proof{V: V, R: R}, identifier(j), []byte(keyGenContext)) {
+			return nil, fmt.Errorf("%w from player %d", ErrInvalidProof, j)
+		}
+		allComs[j] = jComs
+	}
+
+	// Round 2: send the shares of the secret to each player, along with the
+	// echo of the first round.
+	poly := polynomial.New(coeffs)
+	out := make(map[uint16][]byte, len(p.peers))
+	for _, j := range p.peers {
+		enc := &encoder{}
+		enc.scalar(poly.Evaluate(identifierScalar(g, j)))
+		enc.bytes(echo)
+		if out[j], err = enc.marshal(); err != nil {
+			return nil, err
+		}
+	}
+	in, err = p.exchange(out)
+	if err != nil {
+		return nil, err
+	}
+	share := poly.Evaluate(identifierScalar(g, id))
+	for _, j := range p.peers {
+		d := &decoder{data: in[j]}
+		s := d.scalar(g)
+		echoj := d.bytes()
+		if err := d.done(); err != nil {
+			return nil, fmt.Errorf("%w from player %d: %v", ErrInvalidMessage, j, err)
+		}
+		if subtle.ConstantTimeCompare(echoj, echo) != 1 {
+			return nil, fmt.Errorf("%w, echoed by player %d", ErrInvalidBroadcast, j)
+		}
+		want := evaluateCommitments(g, allComs[j], id)
+		if !g.NewElement().MulGen(s).IsEqual(want) {
+			return nil, fmt.Errorf("%w from player %d", ErrInvalidShare, j)
+		}
+		share.Add(share, s)
+	}
+
+	pub := g.Identity()
+	for _, c := range allComs {
+		pub.Add(pub, c[0])
+	}
+	if pub.IsIdentity() {
+		return nil, ErrIdentityElement
+	}
+	return &KeyShare{g, id, threshold, players, share, &PublicKey{g, pub}}, nil
+}
+
+// evaluateCommitments returns [f(x)]G, where coms are the commitments to the
+// coefficients of the polynomial f.
+func evaluateCommitments(g group.Group, coms []group.Element, x uint16) group.Element {
+	xs := identifierScalar(g, x)
+	r := g.Identity()
+	for i := len(coms) - 1; i >= 0; i-- {
+		r.Mul(r, xs)
+		r.Add(r, coms[i])
+	}
+	return r
+}
+
+func identifier(id uint16) []byte {
+	return binary.BigEndian.AppendUint16(nil, id)
+}
+
+func identifierScalar(g group.Group, id uint16) group.Scalar {
+	return g.NewScalar().SetUint64(uint64(id))
+}
+
+// MarshalBinary encodes the key share as the identifier, the threshold and
+// the number of players, all as big-endian uint16, followed by the share and
+// the public key in compressed form.
+func (k *KeyShare) MarshalBinary() ([]byte, error) {
+	share, err := k.share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := k.pub.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 6, 6+len(share)+len(pub))
+	binary.BigEndian.PutUint16(data[0:], k.ID)
+	binary.BigEndian.PutUint16(data[2:], k.Threshold)
+	binary.BigEndian.PutUint16(data[4:], k.Players)
+	return append(append(data, share...), pub...), nil
+}
+
+func (k *KeyShare) UnmarshalBinary(g group.Group, data []byte) error {
+	if curveOf(g) == nil {
+		return ErrInvalidGroup
+	}
+	scalarLen := int(g.Params().ScalarLength)
+	if len(data) < 6+scalarLen {
+		return group.ErrUnmarshal
+	}
+	id := binary.BigEndian.Uint16(data[0:])
+	threshold := binary.BigEndian.Uint16(data[2:])
+	players := binary.BigEndian.Uint16(data[4:])
+	if threshold < 2 || players < threshold || id < 1 || id > players {
+		return ErrInvalidParameters
+	}
+	share, err := decodeScalar(g, data[6:6+scalarLen])
+	if err != nil {
+		return err
+	}
+	var pub PublicKey
+	if err := pub.UnmarshalBinary(g, data[6+scalarLen:]); err != nil {
+		return err
+	}
+	*k = KeyShare{g, id, threshold, players, share, &pub}
+	return nil
+}
@@ -0,0 +1,173 @@
+package dkls
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"github.com/katzenpost/circl/group"
+)
+
+// party is a player taking part in a protocol with peers.
+type party struct {
+	net   Network
+	id    uint16
+	peers []uint16
+}
+
+// all returns the identifiers of the player and its peers, in increasing
+// order.
+func (p *party) all() []uint16 {
+	ids := append([]uint16{p.id}, p.peers...)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// exchange sends out[j] to every peer j, and returns the messages received
+// from every peer.
+func (p *party) exchange(out map[uint16][]byte) (map[uint16][]byte, error) {
+	for _, j := range p.peers {
+		if err := p.net.Send(j, out[j]); err != nil {
+			return nil, err
+		}
+	}
+	in := make(map[uint16][]byte, len(p.peers))
+	for _, j := range p.peers {
+		msg, err := p.net.Receive(j)
+		if err != nil {
+			return nil, err
+		}
+		in[j] = msg
+	}
+	return in, nil
+}
+
+// broadcast sends msg to every peer, and returns the messages received from
+// every peer.
+func (p *party) broadcast(msg []byte) (map[uint16][]byte, error) {
+	out := make(map[uint16][]byte, len(p.peers))
+	for _, j := range p.peers {
+		out[j] = msg
+	}
+	return p.exchange(out)
+}
+
+// view returns a hash of the messages of a broadcast round from the players
+// with the given identifiers, which the players echo to each other to detect
+// a player that sent different messages to different players.
+func view(context string, ids []uint16, msgs map[uint16][]byte) []byte {
+	h := sha256.New()
+	writeBytes(h, []byte(context))
+	for _, id := range ids {
+		writeBytes(h, identifier(id))
+		writeBytes(h, msgs[id])
+	}
+	return h.Sum(nil)
+}
+
+// encoder builds a message as a list of byte strings, each prefixed by its
+// length as a big-endian uint16.
+type encoder struct {
+	data []byte
+	err  error
+}
+
+func (e *encoder) bytes(b []byte) {
+	if len(b) > math.MaxUint16 {
+		e.err = ErrInvalidMessage
+		return
+	}
+	e.data = binary.BigEndian.AppendUint16(e.data, uint16(len(b)))
+	e.data = append(e.data, b...)
+}
+
+func (e *encoder) element(x group.Element) {
+	b, err := x.MarshalBinaryCompress()
+	if err != nil {
+		e.err = err
+	}
+	e.bytes(b)
+}
+
+func (e *encoder) scalar(x group.Scalar) {
+	b, err := x.MarshalBinary()
+	if err != nil {
+		e.err = err
+	}
+	e.bytes(b)
+}
+
+// raw appends b without its length, so it must be the last part of the
+// message.
+func (e *encoder) raw(b []byte) { e.data = append(e.data, b...) }
+
+func (e *encoder) marshal() ([]byte, error) { return e.data, e.err }
+
+// decoder parses a message built by an encoder. Once an error occurs, the
+// following calls return nil values, and done returns the error.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) bytes() []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < 2 {
+		d.err = ErrInvalidMessage
+		return nil
+	}
+	n := int(binary.BigEndian.Uint16(d.data))
+	if len(d.data)-2 < n {
+		d.err = ErrInvalidMessage
+		return nil
+	}
+	b := d.data[2 : 2+n]
+	d.data = d.data[2+n:]
+	return b
+}
+
+func (d *decoder) element(g group.Group) group.Element {
+	b := d.bytes()
+	if d.err != nil {
+		return nil
+	}
+	e, err := decodeElement(g, b)
+	if err != nil {
+		d.err = err
+	}
+	return e
+}
+
+func (d *decoder) scalar(g group.Group) group.Scalar {
+	b := d.bytes()
+	if d.err != nil {
+		return nil
+	}
+	s, err := decodeScalar(g, b)
+	if err != nil {
+		d.err = err
+	}
+	return s
+}
+
+// rest returns the data that was not read, which was appended with raw.
+func (d *decoder) rest() []byte {
+	if d.err != nil {
+		return nil
+	}
+	b := d.data
+	d.data = nil
+	return b
+}
+
+// done returns the first error that occurred, or ErrInvalidMessage if some
+// data was not read.
+func (d *decoder) done() error {
+	if d.err == nil && len(d.data) != 0 {
+		return ErrInvalidMessage
+	}
+	return d.err
+}
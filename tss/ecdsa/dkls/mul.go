@@ -0,0 +1,305 @@
+package dkls
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash"
+	"io"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/ot/simot"
+)
+
+// mulContext is the context string of the consistency checks of the
+// multiplications.
+const mulContext = "ecdsa_threshold multiplication"
+
+// mulStatisticalSecurity is the statistical security parameter s of the
+// encoding of the input of the receiver.
+const mulStatisticalSecurity = 80
+
+// otGroup is the group used by the oblivious transfers.
+var otGroup = group.Ristretto255
+
+// The multiplication of secrets a_1, ..., a_n of the sender by a random
+// secret χ of the receiver, which outputs additive shares c_k and d_k of
+// χ·a_k, follows the protocol of [3] and its consistency check.
+//
+// The receiver picks random bits ω_1, ..., ω_ξ, and sets χ = Σ g_l ω_l,
+// where the gadget vector g holds the powers of two up to the bit length of
+// the scalars followed by 2s public random scalars. For each l, the sender
+// picks random t_l,k and the receiver gets v_l,k = t_l,k + ω_l·a_k with an
+// oblivious transfer, where a_n+1 is a random mask. Then the receiver holds
+// d_k = Σ g_l v_l,k and the sender c_k = -Σ g_l t_l,k.
+//
+// A malicious sender may use different inputs in different transfers, so
+// that the receiver gets an inconsistent result if and only if ω_l = 1. To
+// detect it, the sender reveals u = Σ μ_k a_k and ρ_l = Σ μ_k t_l,k for
+// challenges μ_k derived from the transcript, and the receiver checks that
+// Σ μ_k v_l,k - ρ_l = ω_l·u for every l. The sender still learns a few bits
+// of ω from whether the check fails, but thanks to the 2s random scalars of
+// the gadget vector, they reveal nothing about χ.
+
+// gadget returns the gadget vector of g.
+func gadget(g group.Group) []group.Scalar {
+	bits := 8 * int(g.Params().ScalarLength)
+	v := make([]group.Scalar, bits+2*mulStatisticalSecurity)
+	pow := g.NewScalar().SetUint64(1)
+	for l := 0; l < bits; l++ {
+		v[l] = pow.Copy()
+		pow.Add(pow, pow)
+	}
+	for l := bits; l < len(v); l++ {
+		v[l] = g.HashToScalar(binary.BigEndian.AppendUint16(nil, uint16(l)), []byte(mulContext+" gadget"))
+	}
+	return v
+}
+
+// mulSender is the sender of a multiplication.
+type mulSender struct {
+	g      group.Group
+	ctx    []byte
+	gadget []group.Scalar
+	a      []group.Scalar   // Inputs followed by the random mask.
+	t      [][]group.Scalar // t[l] are the messages for ω_l = 0.
+	ots    []simot.Sender
+	as     []group.Element
+}
+
+// newMulSender starts a multiplication by the secrets a, where ctx
+// identifies the sender and the receiver, and returns the first message for
+// the receiver.
+func newMulSender(g group.Group, rnd io.Reader, ctx []byte, a []group.Scalar) (*mulSender, []byte, error) {
+	s := &mulSender{g: g, ctx: ctx, gadget: gadget(g)}
+	s.a = append(append(s.a, a...), g.RandomScalar(rnd))
+	s.t = make([][]group.Scalar, len(s.gadget))
+	s.ots = make([]simot.Sender, len(s.gadget))
+	s.as = make([]group.Element, len(s.gadget))
+	for l := range s.ots {
+		if err := s.transfer(rnd, l, s.a); err != nil {
+			return nil, nil, err
+		}
+	}
+	msg, err := s.message()
+	return s, msg, err
+}
+
+// transfer starts the oblivious transfer l, of t_l or t_l + a.
+func (s *mulSender) transfer(rnd io.Reader, l int, a []group.Scalar) error {
+	s.t[l] = make([]group.Scalar, len(a))
+	m0 := &encoder{}
+	m1 := &encoder{}
+	for k := range a {
+		s.t[l][k] = s.g.RandomScalar(rnd)
+		m0.scalar(s.t[l][k])
+		m1.scalar(s.g.NewScalar().Add(s.t[l][k], a[k]))
+	}
+	msg0, err := m0.marshal()
+	if err != nil {
+		return err
+	}
+	msg1, err := m1.marshal()
+	if err != nil {
+		return err
+	}
+	s.as[l] = s.ots[l].InitSender(otGroup, msg0, msg1, l)
+	return nil
+}
+
+// message returns the first message of the multiplication.
+func (s *mulSender) message() ([]byte, error) {
+	enc := &encoder{}
+	for l := range s.as {
+		enc.element(s.as[l])
+	}
+	return enc.marshal()
+}
+
+// respond processes the second message of the multiplication, and returns
+// the third message for the receiver.
+func (s *mulSender) respond(msg []byte) ([]byte, error) {
+	d := &decoder{data: msg}
+	bs := make([]group.Element, len(s.ots))
+	for l := range bs {
+		bs[l] = d.element(otGroup)
+	}
+	if err := d.done(); err != nil {
+		return nil, err
+	}
+	msg1, err := s.message()
+	if err != nil {
+		return nil, err
+	}
+
+	enc := &encoder{}
+	h := newMulTranscript(s.ctx, msg1, msg)
+	for l := range s.ots {
+		e0, e1 := s.ots[l].Round2Sender(bs[l])
+		enc.bytes(e0)
+		enc.bytes(e1)
+		writeBytes(h, e0)
+		writeBytes(h, e1)
+	}
+	mu := mulChallenge(s.g, h, len(s.a))
+	u := s.g.NewScalar()
+	for k := range s.a {
+		u.Add(u, s.g.NewScalar().Mul(mu[k], s.a[k]))
+	}
+	enc.scalar(u)
+	for l := range s.t {
+		rho := s.g.NewScalar()
+		for k := range s.t[l] {
+			rho.Add(rho, s.g.NewScalar().Mul(mu[k], s.t[l][k]))
+		}
+		enc.scalar(rho)
+	}
+	return enc.marshal()
+}
+
+// shares returns the shares c_k of the sender.
+func (s *mulSender) shares() []group.Scalar {
+	c := make([]group.Scalar, len(s.a)-1)
+	for k := range c {
+		c[k] = s.g.NewScalar()
+		for l := range s.t {
+			c[k].Sub(c[k], s.g.NewScalar().Mul(s.gadget[l], s.t[l][k]))
+		}
+	}
+	return c
+}
+
+// mulReceiver is the receiver of a multiplication.
+type mulReceiver struct {
+	g          group.Group
+	ctx        []byte
+	gadget     []group.Scalar
+	ots        []simot.Receiver
+	omega      []int
+	chi        group.Scalar
+	count      int
+	msg1, msg2 []byte
+}
+
+// newMulReceiver processes the first message of a multiplication of a random
+// secret χ by count secrets of the sender, where ctx identifies the sender
+// and the receiver, and returns the second message for the sender.
+func newMulReceiver(g group.Group, rnd io.Reader, ctx []byte, count int, msg []byte) (*mulReceiver, []byte, error) {
+	r := &mulReceiver{g: g, ctx: ctx, gadget: gadget(g), count: count, msg1: msg}
+	r.ots = make([]simot.Receiver, len(r.gadget))
+	r.omega = make([]int, len(r.gadget))
+	d := &decoder{data: msg}
+	as := make([]group.Element, len(r.ots))
+	for l := range as {
+		as[l] = d.element(otGroup)
+	}
+	if err := d.done(); err != nil {
+		return nil, nil, err
+	}
+
+	bits := make([]byte, (len(r.omega)+7)/8)
+	if _, err := io.ReadFull(rnd, bits); err != nil {
+		return nil, nil, err
+	}
+	r.chi = g.NewScalar()
+	out := &encoder{}
+	for l := range r.ots {
+		r.omega[l] = int(bits[l/8]>>(l%8)) & 1
+		r.chi.Add(r.chi, g.NewScalar().Mul(r.gadget[l], g.NewScalar().SetUint64(uint64(r.omega[l]))))
+		out.element(r.ots[l].Round1Receiver(otGroup, r.omega[l], l, as[l]))
+	}
+	var err error
+	r.msg2, err = out.marshal()
+	return r, r.msg2, err
+}
+
+// finish processes the third message of the multiplication, and returns the
+// shares d_k of the receiver. It returns ErrInvalidMultiplication if the
+// sender did not use the same inputs in all the transfers.
+func (r *mulReceiver) finish(msg []byte) ([]group.Scalar, error) {
+	g := r.g
+	d := &decoder{data: msg}
+	es := make([][2][]byte, len(r.ots))
+	h := newMulTranscript(r.ctx, r.msg1, r.msg2)
+	for l := range es {
+		es[l][0] = d.bytes()
+		es[l][1] = d.bytes()
+		writeBytes(h, es[l][0])
+		writeBytes(h, es[l][1])
+	}
+	u := d.scalar(g)
+	rho := make([]group.Scalar, len(r.ots))
+	for l := range rho {
+		rho[l] = d.scalar(g)
+	}
+	if err := d.done(); err != nil {
+		return nil, err
+	}
+
+	// The check does not stop at the first failure, so that it does not
+	// reveal which transfer failed.
+	mu := mulChallenge(g, h, r.count+1)
+	ok := true
+	shares := make([]group.Scalar, r.count)
+	for k := range shares {
+		shares[k] = g.NewScalar()
+	}
+	for l := range r.ots {
+		v := make([]group.Scalar, r.count+1)
+		for k := range v {
+			v[k] = g.NewScalar()
+		}
+		if len(es[l][0]) != len(es[l][1]) || r.ots[l].Round3Receiver(es[l][0], es[l][1], r.omega[l]) != nil {
+			ok = false
+		} else {
+			d := &decoder{data: r.ots[l].Returnmc()}
+			for k := range v {
+				if x := d.scalar(g); x != nil {
+					v[k] = x
+				}
+			}
+			ok = d.done() == nil && ok
+		}
+
+		lhs := g.NewScalar().Neg(rho[l])
+		for k := range v {
+			lhs.Add(lhs, g.NewScalar().Mul(mu[k], v[k]))
+		}
+		rhs := g.NewScalar().Mul(u, g.NewScalar().SetUint64(uint64(r.omega[l])))
+		ok = lhs.IsEqual(rhs) && ok
+		for k := range shares {
+			shares[k].Add(shares[k], v[k].Mul(v[k], r.gadget[l]))
+		}
+	}
+	if !ok {
+		return nil, ErrInvalidMultiplication
+	}
+	return shares, nil
+}
+
+// newMulTranscript returns a hash of the first two messages of a
+// multiplication, to which the encrypted messages of the transfers are then
+// written.
+func newMulTranscript(ctx, msg1, msg2 []byte) hash.Hash {
+	h := sha256.New()
+	writeBytes(h, []byte(mulContext))
+	writeBytes(h, ctx)
+	writeBytes(h, msg1)
+	writeBytes(h, msg2)
+	return h
+}
+
+// mulChallenge returns the challenges μ_1, ..., μ_n of the check.
+func mulChallenge(g group.Group, h hash.Hash, n int) []group.Scalar {
+	seed := h.Sum(nil)
+	mu := make([]group.Scalar, n)
+	for k := range mu {
+		mu[k] = g.HashToScalar(binary.BigEndian.AppendUint16(seed[:len(seed):len(seed)], uint16(k)), []byte(mulContext))
+	}
+	return mu
+}
+
+// writeBytes writes b to h, prefixed by its length.
+func writeBytes(h hash.Hash, b []byte) {
+	_, _ = h.Write(binary.BigEndian.AppendUint64(nil, uint64(len(b))))
+	_, _ = h.Write(b)
+}
@@ -0,0 +1,389 @@
+package dkls
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/math/polynomial"
+)
+
+// presignContext is the context string of the commitments of Presign.
+const presignContext = "ecdsa_threshold presign"
+
+// Presignature is the output of Presign for a signer, which is used to sign
+// one digest only. It must be kept secret.
+type Presignature struct {
+	g    group.Group
+	id   uint16
+	r    group.Scalar
+	phi  group.Scalar
+	u, v group.Scalar
+}
+
+// SignatureShare is the output of Sign, which the signers send to the
+// combiner.
+type SignatureShare struct {
+	g       group.Group
+	ID      uint16
+	r, u, w group.Scalar
+}
+
+// Presign runs the presigning protocol with the other signers, all of which
+// must run it concurrently with the same list of identifiers of signers. The
+// signers must be distinct, include the identifier of the key share, and be
+// at least threshold. An error wrapping ErrInvalidMessage,
+// ErrInvalidCommitment or ErrInvalidMultiplication identifies a signer that
+// deviated from the protocol, see Security.
+//
+// The result does not depend on the message, so presignatures can be
+// computed in advance.
+func (k *KeyShare) Presign(rnd io.Reader, net Network, signers []uint16) (*Presignature, error) {
+	if rnd == nil {
+		return nil, io.ErrNoProgress
+	}
+	if len(signers) < int(k.Threshold) {
+		return nil, ErrInvalidSigners
+	}
+	g := k.g
+	p := &party{net: net, id: k.ID}
+	ids := make([]group.Scalar, len(signers))
+	pos := -1
+	seen := make(map[uint16]bool, len(signers))
+	for i, j := range signers {
+		if j < 1 || j > k.Players || seen[j] {
+			return nil, ErrInvalidSigners
+		}
+		seen[j] = true
+		ids[i] = identifierScalar(g, j)
+		if j == k.ID {
+			pos = i
+		} else {
+			p.peers = append(p.peers, j)
+		}
+	}
+	if pos < 0 {
+		return nil, ErrInvalidSigners
+	}
+
+	// The instance key r_i, the inversion mask φ_i, and the additive share
+	// sk_i of the private key.
+	ri := g.RandomNonZeroScalar(rnd)
+	phii := g.RandomNonZeroScalar(rnd)
+	ski := polynomial.LagrangeBase(uint(pos), ids, g.NewScalar())
+	ski.Mul(ski, k.share)
+	Ri := g.NewElement().MulGen(ri)
+	pki := g.NewElement().MulGen(ski)
+	var nonce [32]byte
+	if _, err := io.ReadFull(rnd, nonce[:]); err != nil {
+		return nil, err
+	}
+	com, err := presignCommitment(k.ID, Ri, pki, nonce[:])
+	if err != nil {
+		return nil, err
+	}
+
+	// Round 1: commit to R_i and pk_i, and start the multiplications of r_i
+	// and sk_i by the random χ_ji of P_j as the sender.
+	senders := make(map[uint16]*mulSender, len(p.peers))
+	out := make(map[uint16][]byte, len(p.peers))
+	for _, j := range p.peers {
+		var msg []byte
+		senders[j], msg, err = newMulSender(g, rnd, mulPair(k.ID, j), []group.Scalar{ri, ski})
+		if err != nil {
+			return nil, err
+		}
+		enc := &encoder{}
+		enc.bytes(com)
+		enc.raw(msg)
+		if out[j], err = enc.marshal(); err != nil {
+			return nil, err
+		}
+	}
+	in, err := p.exchange(out)
+	if err != nil {
+		return nil, err
+	}
+
+	// Round 2: start the multiplications by a random χ_ij as the receiver,
+	// send ψ_ij = φ_i - χ_ij, and echo the commitments.
+	coms := map[uint16][]byte{k.ID: com}
+	msgs := make(map[uint16][]byte, len(p.peers))
+	for _, j := range p.peers {
+		d := &decoder{data: in[j]}
+		coms[j] = d.bytes()
+		msgs[j] = d.rest()
+		if err := d.done(); err != nil {
+			return nil, presignError(j, err)
+		}
+	}
+	echo := view(presignContext, p.all(), coms)
+	receivers := make(map[uint16]*mulReceiver, len(p.peers))
+	for _, j := range p.peers {
+		var msg []byte
+		receivers[j], msg, err = newMulReceiver(g, rnd, mulPair(j, k.ID), 2, msgs[j])
+		if err != nil {
+			return nil, presignError(j, err)
+		}
+		enc := &encoder{}
+		enc.scalar(g.NewScalar().Sub(phii, receivers[j].chi))
+		enc.bytes(echo)
+		enc.raw(msg)
+		if out[j], err = enc.marshal(); err != nil {
+			return nil, err
+		}
+	}
+	if in, err = p.exchange(out); err != nil {
+		return nil, err
+	}
+
+	// Round 3: respond as the sender, with Γ_u = [c_u]G and Γ_v = [c_v]G,
+	// and open the commitment.
+	psi := make(map[uint16]group.Scalar, len(p.peers))
+	for _, j := range p.peers {
+		d := &decoder{data: in[j]}
+		psi[j] = d.scalar(g)
+		echoj := d.bytes()
+		msg := d.rest()
+		if err := d.done(); err != nil {
+			return nil, presignError(j, err)
+		}
+		if subtle.ConstantTimeCompare(echoj, echo) != 1 {
+			return nil, fmt.Errorf("%w, echoed by player %d", ErrInvalidBroadcast, j)
+		}
+		if msg, err = senders[j].respond(msg); err != nil {
+			return nil, presignError(j, err)
+		}
+		c := senders[j].shares()
+		enc := &encoder{}
+		enc.element(g.NewElement().MulGen(c[0]))
+		enc.element(g.NewElement().MulGen(c[1]))
+		enc.element(Ri)
+		enc.element(pki)
+		enc.bytes(nonce[:])
+		enc.raw(msg)
+		if out[j], err = enc.marshal(); err != nil {
+			return nil, err
+		}
+	}
+	if in, err = p.exchange(out); err != nil {
+		return nil, err
+	}
+
+	// Check the multiplications, and compute the shares u_i of rφ and v_i
+	// of skφ, and R.
+	u := g.NewScalar().Mul(ri, phii)
+	v := g.NewScalar().Mul(ski, phii)
+	R := Ri.Copy()
+	pub := pki.Copy()
+	for _, j := range p.peers {
+		d := &decoder{data: in[j]}
+		Gu := d.element(g)
+		Gv := d.element(g)
+		Rj := d.element(g)
+		pkj := d.element(g)
+		noncej := d.bytes()
+		msg := d.rest()
+		if err := d.done(); err != nil {
+			return nil, presignError(j, err)
+		}
+		comj, err := presignCommitment(j, Rj, pkj, noncej)
+		if err != nil {
+			return nil, err
+		}
+		if subtle.ConstantTimeCompare(comj, coms[j]) != 1 {
+			return nil, fmt.Errorf("%w from player %d", ErrInvalidCommitment, j)
+		}
+		dj, err := receivers[j].finish(msg)
+		if errors.Is(err, ErrInvalidMultiplication) {
+			return nil, fmt.Errorf("%w from player %d", err, j)
+		} else if err != nil {
+			return nil, presignError(j, err)
+		}
+
+		// [d_u]G = [χ_ij]R_j - Γ_u and [d_v]G = [χ_ij]pk_j - Γ_v, so that
+		// the inputs of P_j are r_j and sk_j.
+		chi := receivers[j].chi
+		okU := g.NewElement().MulGen(dj[0]).IsEqual(g.NewElement().Add(g.NewElement().Mul(Rj, chi), Gu.Neg(Gu)))
+		okV := g.NewElement().MulGen(dj[1]).IsEqual(g.NewElement().Add(g.NewElement().Mul(pkj, chi), Gv.Neg(Gv)))
+		if !okU || !okV {
+			return nil, fmt.Errorf("%w from player %d", ErrInvalidMultiplication, j)
+		}
+
+		c := senders[j].shares()
+		u.Add(u, c[0]).Add(u, dj[0]).Add(u, g.NewScalar().Mul(ri, psi[j]))
+		v.Add(v, c[1]).Add(v, dj[1]).Add(v, g.NewScalar().Mul(ski, psi[j]))
+		R.Add(R, Rj)
+		pub.Add(pub, pkj)
+	}
+	if !pub.IsEqual(k.pub.e) {
+		return nil, ErrInvalidShare
+	}
+	if R.IsIdentity() {
+		return nil, ErrDegeneratePresign
+	}
+	r, err := xCoordinate(R)
+	if err != nil {
+		return nil, err
+	}
+	if r.IsZero() {
+		return nil, ErrDegeneratePresign
+	}
+	return &Presignature{g, k.ID, r, phii, u, v}, nil
+}
+
+// presignCommitment returns the commitment of a signer to R_i and pk_i.
+func presignCommitment(id uint16, R, pk group.Element, nonce []byte) ([]byte, error) {
+	encR, err := R.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	encPK, err := pk.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	_, _ = h.Write([]byte(presignContext))
+	_, _ = h.Write(identifier(id))
+	_, _ = h.Write(encR)
+	_, _ = h.Write(encPK)
+	_, _ = h.Write(nonce)
+	return h.Sum(nil), nil
+}
+
+// mulPair identifies the multiplication with the given sender and receiver.
+func mulPair(sender, receiver uint16) []byte {
+	return append(identifier(sender), identifier(receiver)...)
+}
+
+func presignError(id uint16, err error) error {
+	return fmt.Errorf("%w from player %d: %v", ErrInvalidMessage, id, err)
+}
+
+// Sign returns the signature share of the digest of a message, which must
+// be computed as for crypto/ecdsa. The presignature is erased, so it cannot
+// be used again.
+func (pre *Presignature) Sign(digest []byte) (SignatureShare, error) {
+	if pre.phi == nil {
+		return SignatureShare{}, ErrPresignatureUsed
+	}
+	// w_i = m φ_i + r v_i
+	g := pre.g
+	w := digestToScalar(g, digest)
+	w.Mul(w, pre.phi)
+	w.Add(w, g.NewScalar().Mul(pre.r, pre.v))
+	share := SignatureShare{g, pre.id, pre.r.Copy(), pre.u.Copy(), w}
+
+	pre.phi.SetUint64(0)
+	pre.u.SetUint64(0)
+	pre.v.SetUint64(0)
+	pre.phi, pre.u, pre.v = nil, nil, nil
+	return share, nil
+}
+
+// Combine returns the ASN.1 encoded ECDSA signature of the digest from the
+// signature shares of all the signers of a presignature, as produced by
+// crypto/ecdsa.SignASN1. The signature is normalized to have a low s. It
+// returns ErrInvalidSignature if the result does not verify, which happens
+// if a share is missing or invalid.
+func (pub *PublicKey) Combine(digest []byte, shares []SignatureShare) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrNotEnoughShares
+	}
+	g := pub.g
+	r := shares[0].r
+	u := g.NewScalar()
+	w := g.NewScalar()
+	seen := make(map[uint16]bool, len(shares))
+	for i := range shares {
+		if shares[i].g != g {
+			return nil, ErrGroupMismatch
+		}
+		if seen[shares[i].ID] || !shares[i].r.IsEqual(r) {
+			return nil, ErrInvalidShare
+		}
+		seen[shares[i].ID] = true
+		u.Add(u, shares[i].u)
+		w.Add(w, shares[i].w)
+	}
+	// s = w/u = (m + r sk)/r
+	if u.IsZero() {
+		return nil, ErrInvalidSignature
+	}
+	s := w.Mul(w, u.Inv(u))
+
+	c := curveOf(g)
+	n := c.Params().N
+	rb, err := r.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sb, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	bigS := new(big.Int).SetBytes(sb)
+	if bigS.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		bigS.Sub(n, bigS)
+	}
+	sig, err := asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(rb), bigS})
+	if err != nil {
+		return nil, err
+	}
+	if !Verify(pub, digest, sig) {
+		return nil, ErrInvalidSignature
+	}
+	return sig, nil
+}
+
+// Verify returns whether sig is a valid ASN.1 encoded ECDSA signature of the
+// digest under pub.
+func Verify(pub *PublicKey, digest, sig []byte) bool {
+	return ecdsa.VerifyASN1(pub.ECDSA(), digest, sig)
+}
+
+// MarshalBinary encodes the signature share as the identifier, as a
+// big-endian uint16, followed by the scalars r, u and w.
+func (s *SignatureShare) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 2)
+	binary.BigEndian.PutUint16(data, s.ID)
+	for _, x := range []group.Scalar{s.r, s.u, s.w} {
+		enc, err := x.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, enc...)
+	}
+	return data, nil
+}
+
+func (s *SignatureShare) UnmarshalBinary(g group.Group, data []byte) error {
+	if curveOf(g) == nil {
+		return ErrInvalidGroup
+	}
+	scalarLen := int(g.Params().ScalarLength)
+	if len(data) != 2+3*scalarLen {
+		return group.ErrUnmarshal
+	}
+	id := binary.BigEndian.Uint16(data)
+	if id == 0 {
+		return ErrInvalidIdentifier
+	}
+	var x [3]group.Scalar
+	for i := range x {
+		var err error
+		x[i], err = decodeScalar(g, data[2+i*scalarLen:2+(i+1)*scalarLen])
+		if err != nil {
+			return err
+		}
+	}
+	*s = SignatureShare{g, id, x[0], x[1], x[2]}
+	return nil
+}
@@ -0,0 +1,337 @@
+package frost
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/katzenpost/circl/ecc/goldilocks"
+	"github.com/katzenpost/circl/expander"
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/internal/conv"
+	"github.com/katzenpost/circl/xof"
+)
+
+// edwards448 is the prime-order subgroup of the edwards448 curve, with
+// elements and scalars encoded as in RFC 8032. Decoding rejects
+// non-canonical encodings and points outside the prime-order subgroup.
+//
+// It is only used by FROST(Ed448, SHAKE256), which does not hash to
+// elements: HashToElement and HashToElementNonUniform panic, which is why
+// it is not part of the group package.
+var edwards448 group.Group = edwards448Group{}
+
+// edwards448Size is the length in bytes of elements and scalars.
+const edwards448Size = 57
+
+// edwards448Order is the order of the group.
+var edwards448Order = func() *big.Int {
+	q := goldilocks.Curve{}.Order()
+	return conv.BytesLe2BigInt(q[:])
+}()
+
+type edwards448Group struct{}
+
+func (g edwards448Group) String() string {
+	return "edwards448"
+}
+
+func (g edwards448Group) Params() *group.Params {
+	return &group.Params{
+		ElementLength:           edwards448Size,
+		CompressedElementLength: edwards448Size,
+		ScalarLength:            edwards448Size,
+	}
+}
+
+type edwards448Element struct {
+	p goldilocks.Point
+}
+
+type edwards448Scalar struct {
+	k goldilocks.Scalar
+}
+
+func (g edwards448Group) NewElement() group.Element {
+	return g.Identity()
+}
+
+func (g edwards448Group) NewScalar() group.Scalar {
+	return &edwards448Scalar{}
+}
+
+func (g edwards448Group) Identity() group.Element {
+	return &edwards448Element{*goldilocks.Curve{}.Identity()}
+}
+
+func (g edwards448Group) Generator() group.Element {
+	return &edwards448Element{*goldilocks.Curve{}.Generator()}
+}
+
+func (g edwards448Group) Order() group.Scalar {
+	return &edwards448Scalar{goldilocks.Curve{}.Order()}
+}
+
+func (g edwards448Group) RandomElement(r io.Reader) group.Element {
+	return g.NewElement().MulGen(g.RandomScalar(r))
+}
+
+func (g edwards448Group) RandomScalar(r io.Reader) group.Scalar {
+	var buf [2 * goldilocks.ScalarSize]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		panic(err)
+	}
+	s := &edwards448Scalar{}
+	s.k.FromBytes(buf[:])
+	return s
+}
+
+func (g edwards448Group) RandomNonZeroScalar(r io.Reader) group.Scalar {
+	for {
+		s := g.RandomScalar(r)
+		if !s.IsZero() {
+			return s
+		}
+	}
+}
+
+func (g edwards448Group) HashToElementNonUniform(b, dst []byte) group.Element {
+	panic("frost: hashing to edwards448 is not supported")
+}
+
+func (g edwards448Group) HashToElement(msg, dst []byte) group.Element {
+	panic("frost: hashing to edwards448 is not supported")
+}
+
+func (g edwards448Group) HashToScalar(msg, dst []byte) group.Scalar {
+	e := expander.NewExpanderXOF(xof.SHAKE256, 224, dst)
+	uniformBytes := e.Expand(msg, 84)
+	s := &edwards448Scalar{}
+	s.k.FromBytes(uniformBytes)
+	return s
+}
+
+func (e *edwards448Element) Group() group.Group { return edwards448 }
+
+func (e *edwards448Element) String() string {
+	enc, _ := e.MarshalBinary()
+	return fmt.Sprintf("%x", enc)
+}
+
+func (e *edwards448Element) IsIdentity() bool {
+	return e.IsEqual(edwards448.Identity())
+}
+
+func (e *edwards448Element) IsEqual(x group.Element) bool {
+	return e.p.IsEqual(&x.(*edwards448Element).p)
+}
+
+func (e *edwards448Element) Set(x group.Element) group.Element {
+	e.p = x.(*edwards448Element).p
+	return e
+}
+
+func (e *edwards448Element) Copy() group.Element {
+	return &edwards448Element{e.p}
+}
+
+func (e *edwards448Element) CMov(v int, x group.Element) group.Element {
+	if !(v == 0 || v == 1) {
+		panic(group.ErrSelector)
+	}
+	bufE, _ := e.MarshalBinary()
+	bufX, _ := x.MarshalBinary()
+	subtle.ConstantTimeCopy(v, bufE, bufX)
+	P, _ := goldilocks.FromBytes(bufE)
+	e.p = *P
+	return e
+}
+
+func (e *edwards448Element) CSelect(v int, x group.Element, y group.Element) group.Element {
+	if !(v == 0 || v == 1) {
+		panic(group.ErrSelector)
+	}
+	bufX, _ := x.MarshalBinary()
+	bufY, _ := y.MarshalBinary()
+	subtle.ConstantTimeCopy(v, bufY, bufX)
+	P, _ := goldilocks.FromBytes(bufY)
+	e.p = *P
+	return e
+}
+
+func (e *edwards448Element) Add(x group.Element, y group.Element) group.Element {
+	p := x.(*edwards448Element).p
+	p.Add(&y.(*edwards448Element).p)
+	e.p = p
+	return e
+}
+
+func (e *edwards448Element) Dbl(x group.Element) group.Element {
+	e.p = x.(*edwards448Element).p
+	e.p.Double()
+	return e
+}
+
+func (e *edwards448Element) Neg(x group.Element) group.Element {
+	e.p = x.(*edwards448Element).p
+	e.p.Neg()
+	return e
+}
+
+func (e *edwards448Element) Mul(x group.Element, y group.Scalar) group.Element {
+	e.p = *goldilocks.Curve{}.ScalarMult(&y.(*edwards448Scalar).k, &x.(*edwards448Element).p)
+	return e
+}
+
+func (e *edwards448Element) MulGen(x group.Scalar) group.Element {
+	e.p = *goldilocks.Curve{}.ScalarBaseMult(&x.(*edwards448Scalar).k)
+	return e
+}
+
+func (e *edwards448Element) MarshalBinaryCompress() ([]byte, error) {
+	return e.MarshalBinary()
+}
+
+func (e *edwards448Element) MarshalBinary() ([]byte, error) {
+	p := e.p
+	return p.MarshalBinary()
+}
+
+func (e *edwards448Element) UnmarshalBinary(data []byte) error {
+	if len(data) != edwards448Size || data[edwards448Size-1]&0x7f != 0 {
+		return group.ErrUnmarshal
+	}
+	P, err := goldilocks.FromBytes(data)
+	if err != nil {
+		return group.ErrUnmarshal
+	}
+
+	// Multiplication by a scalar goes through an isogeny, which discards
+	// the torsion component of P, so [order]P is computed with additions.
+	Q := goldilocks.Curve{}.Identity()
+	for i := edwards448Order.BitLen() - 1; i >= 0; i-- {
+		Q.Double()
+		if edwards448Order.Bit(i) == 1 {
+			Q.Add(P)
+		}
+	}
+	if !Q.IsIdentity() {
+		return group.ErrUnmarshal
+	}
+	e.p = *P
+	return nil
+}
+
+func (s *edwards448Scalar) Group() group.Group { return edwards448 }
+func (s *edwards448Scalar) String() string {
+	enc, _ := s.MarshalBinary()
+	return conv.BytesLe2Hex(enc)
+}
+
+func (s *edwards448Scalar) SetUint64(n uint64) group.Scalar {
+	return s.SetBigInt(new(big.Int).SetUint64(n))
+}
+
+func (s *edwards448Scalar) SetBigInt(x *big.Int) group.Scalar {
+	conv.BigInt2BytesLe(s.k[:], new(big.Int).Mod(x, edwards448Order))
+	return s
+}
+
+func (s *edwards448Scalar) IsZero() bool { return s.k.IsZero() }
+func (s *edwards448Scalar) IsEqual(x group.Scalar) bool {
+	a, b := s.k, x.(*edwards448Scalar).k
+	a.Red()
+	b.Red()
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
+func (s *edwards448Scalar) Set(x group.Scalar) group.Scalar {
+	s.k = x.(*edwards448Scalar).k
+	return s
+}
+
+func (s *edwards448Scalar) Copy() group.Scalar {
+	return &edwards448Scalar{s.k}
+}
+
+func (s *edwards448Scalar) CMov(v int, x group.Scalar) group.Scalar {
+	if !(v == 0 || v == 1) {
+		panic(group.ErrSelector)
+	}
+	subtle.ConstantTimeCopy(v, s.k[:], x.(*edwards448Scalar).k[:])
+	return s
+}
+
+func (s *edwards448Scalar) CSelect(v int, x group.Scalar, y group.Scalar) group.Scalar {
+	if !(v == 0 || v == 1) {
+		panic(group.ErrSelector)
+	}
+	xx, yy := x.(*edwards448Scalar), y.(*edwards448Scalar)
+	for i := range s.k {
+		s.k[i] = byte(subtle.ConstantTimeSelect(v, int(xx.k[i]), int(yy.k[i])))
+	}
+	return s
+}
+
+func (s *edwards448Scalar) Add(x group.Scalar, y group.Scalar) group.Scalar {
+	s.k.Add(&x.(*edwards448Scalar).k, &y.(*edwards448Scalar).k)
+	return s
+}
+
+func (s *edwards448Scalar) Sub(x group.Scalar, y group.Scalar) group.Scalar {
+	s.k.Sub(&x.(*edwards448Scalar).k, &y.(*edwards448Scalar).k)
+	return s
+}
+
+func (s *edwards448Scalar) Mul(x group.Scalar, y group.Scalar) group.Scalar {
+	s.k.Mul(&x.(*edwards448Scalar).k, &y.(*edwards448Scalar).k)
+	return s
+}
+
+func (s *edwards448Scalar) Neg(x group.Scalar) group.Scalar {
+	s.k = x.(*edwards448Scalar).k
+	s.k.Neg()
+	return s
+}
+
+// Inv computes x^(order-2) using Fermat's little theorem. The exponent is
+// public, so the sequence of operations does not depend on x.
+func (s *edwards448Scalar) Inv(x group.Scalar) group.Scalar {
+	e := new(big.Int).Sub(edwards448Order, big.NewInt(2))
+	a := x.(*edwards448Scalar).k
+	var z goldilocks.Scalar
+	z[0] = 1
+	for i := e.BitLen() - 1; i >= 0; i-- {
+		z.Mul(&z, &z)
+		if e.Bit(i) == 1 {
+			z.Mul(&z, &a)
+		}
+	}
+	s.k = z
+	return s
+}
+
+func (s *edwards448Scalar) MarshalBinary() ([]byte, error) {
+	k := s.k
+	k.Red()
+	data := make([]byte, edwards448Size)
+	copy(data, k[:])
+	return data, nil
+}
+
+// UnmarshalBinary decodes a scalar in little-endian order, and rejects
+// values not less than the order of the group.
+func (s *edwards448Scalar) UnmarshalBinary(data []byte) error {
+	if len(data) != edwards448Size || data[edwards448Size-1] != 0 {
+		return group.ErrUnmarshal
+	}
+	var k goldilocks.Scalar
+	copy(k[:], data)
+	k.Red()
+	if subtle.ConstantTimeCompare(k[:], data[:goldilocks.ScalarSize]) != 1 {
+		return group.ErrUnmarshal
+	}
+	s.k = k
+	return nil
+}
@@ -0,0 +1,94 @@
+package frost
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/conv"
+	"github.com/katzenpost/circl/internal/sha3"
+	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/sign/ed448"
+)
+
+func TestEdwards448PublicKeys(t *testing.T) {
+	// Public keys of RFC 8032 are the secret scalars times the generator.
+	const testTimes = 1 << 5
+	for i := 0; i < testTimes; i++ {
+		seed := make([]byte, ed448.SeedSize)
+		_, _ = rand.Read(seed)
+		var h [114]byte
+		sha3.ShakeSum256(h[:], seed)
+		h[0] &= 252
+		h[55] |= 128
+		h[56] = 0
+		k := edwards448.NewScalar().SetBigInt(conv.BytesLe2BigInt(h[:57]))
+		got, _ := edwards448.NewElement().MulGen(k).MarshalBinary()
+		want := ed448.NewKeyFromSeed(seed).Public().(ed448.PublicKey)
+		if !bytes.Equal(got, want) {
+			test.ReportError(t, got, want, seed)
+		}
+	}
+}
+
+func TestEdwards448Group(t *testing.T) {
+	const testTimes = 1 << 5
+	g := edwards448
+	for i := 0; i < testTimes; i++ {
+		P := g.RandomElement(rand.Reader)
+		k := g.RandomScalar(rand.Reader)
+		l := g.RandomScalar(rand.Reader)
+
+		// (k+l)P = kP + lP, and P - P is the identity.
+		got := g.NewElement().Mul(P, g.NewScalar().Add(k, l))
+		want := g.NewElement().Add(g.NewElement().Mul(P, k), g.NewElement().Mul(P, l))
+		if !got.IsEqual(want) {
+			test.ReportError(t, got, want, P, k, l)
+		}
+		if !g.NewElement().Add(P, g.NewElement().Neg(P)).IsIdentity() {
+			test.ReportError(t, P, g.Identity())
+		}
+		if !g.NewElement().Mul(P, g.Order()).IsIdentity() {
+			test.ReportError(t, P, g.Order())
+		}
+
+		enc, err := P.MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		Q := g.NewElement()
+		test.CheckNoErr(t, Q.UnmarshalBinary(enc), "unmarshal failed")
+		if !P.IsEqual(Q) {
+			test.ReportError(t, Q, P)
+		}
+		enc, err = k.MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		s := g.NewScalar()
+		test.CheckNoErr(t, s.UnmarshalBinary(enc), "unmarshal failed")
+		if !s.IsEqual(k) {
+			test.ReportError(t, s, k)
+		}
+	}
+}
+
+func TestEdwards448InvalidEncodings(t *testing.T) {
+	for _, enc := range []string{
+		// (0,-1) has order 2.
+		"fefffffffffffffffffffffffffffffffffffffffffffffffffffffffeffffffffffffffffffffffffffffffffffffffffffffffffffffff00",
+		// The identity with the sign bit set.
+		"010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000080",
+		// Non-zero bits in the last byte.
+		"010000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000001",
+	} {
+		b, _ := hex.DecodeString(enc)
+		err := edwards448.NewElement().UnmarshalBinary(b)
+		test.CheckIsErr(t, err, "should reject "+enc)
+	}
+
+	// Scalars must be less than the order.
+	enc, _ := hex.DecodeString("f34458ab92c27823558fc58d72c26c219036d6ae49db4ec4e923ca7cffffffffffffffffffffffffffffffffffffffffffffffffffffff3f00")
+	err := edwards448.NewScalar().UnmarshalBinary(enc)
+	test.CheckIsErr(t, err, "should reject the order")
+	enc[0]--
+	err = edwards448.NewScalar().UnmarshalBinary(enc)
+	test.CheckNoErr(t, err, "should accept the order minus one")
+}
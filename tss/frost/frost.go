@@ -0,0 +1,120 @@
+// Package frost provides the FROST threshold Schnorr signature scheme.
+//
+// FROST (Flexible Round-Optimized Schnorr Threshold) allows any t out of n
+// participants holding shares of a private key to produce a Schnorr
+// signature under the corresponding public key. This package is compatible
+// with RFC 9591 [1].
+//
+// # Protocol Overview
+//
+// A trusted dealer generates a key pair and splits the private key into n
+// KeyShares using Split. Then, signing takes two rounds, coordinated by a
+// party who does not need to hold any secret.
+//
+//	Signer_i(KeyShare_i)                     Coordinator(PublicKey, msg)
+//	=================================================================
+//	nonce_i, com_i = Commit()
+//
+//	                            com_i
+//	                          ---------->
+//
+//	                            msg, [com_j]
+//	                          <----------
+//
+//	share_i = Sign(nonce_i, msg, [com_j])
+//
+//	                           share_i
+//	                          ---------->
+//
+//	                            sig = Aggregate(msg, [com_j], [share_j])
+//
+// Each nonce must be used for one signature only. Signature shares can be
+// checked with VerifySignatureShare, which allows the coordinator to
+// identify misbehaving signers.
+//
+// The signatures of the Ed25519 and Ed448 ciphersuites are, respectively,
+// Ed25519 and Ed448 signatures of RFC 8032, and verify with the packages
+// sign/ed25519 and sign/ed448.
+//
+// # References
+//
+// [1] RFC 9591: https://www.rfc-editor.org/rfc/rfc9591
+package frost
+
+import (
+	"errors"
+
+	"github.com/katzenpost/circl/group"
+)
+
+// Suite is a FROST ciphersuite, which specifies a prime-order group and the
+// hash functions used by the protocol.
+type Suite interface {
+	Identifier() string
+	Group() group.Group
+	cannotBeImplementedExternally()
+}
+
+var (
+	// SuiteEd25519 is FROST(Ed25519, SHA-512).
+	SuiteEd25519 Suite = suiteEd25519
+	// SuiteRistretto255 is FROST(ristretto255, SHA-512).
+	SuiteRistretto255 Suite = suiteRistretto255
+	// SuiteEd448 is FROST(Ed448, SHAKE256).
+	SuiteEd448 Suite = suiteEd448
+	// SuiteP256 is FROST(P-256, SHA-256).
+	SuiteP256 Suite = suiteP256
+)
+
+// GetSuite returns the ciphersuite with the given context string of RFC 9591,
+// such as "FROST-ED25519-SHA512-v1".
+func GetSuite(identifier string) (Suite, error) {
+	for _, suite := range []Suite{SuiteEd25519, SuiteRistretto255, SuiteEd448, SuiteP256} {
+		if suite.Identifier() == identifier {
+			return suite, nil
+		}
+	}
+	return nil, ErrInvalidSuite
+}
+
+// Verify returns whether signature is a valid signature of msg under pub.
+func Verify(pub *PublicKey, msg, signature []byte) bool {
+	p := pub.p
+	g := p.group
+	eltLen := g.Params().CompressedElementLength
+	if uint(len(signature)) != eltLen+g.Params().ScalarLength {
+		return false
+	}
+	R, err := p.decodeElement(signature[:eltLen])
+	if err != nil {
+		return false
+	}
+	z, err := p.decodeScalar(signature[eltLen:])
+	if err != nil {
+		return false
+	}
+	c, err := p.challenge(R, pub.e, msg)
+	if err != nil {
+		return false
+	}
+
+	// All the elements of the group are of prime order, so checking
+	// [z]B = R + [c]PK is the same as the cofactored equation of RFC 9591.
+	l := g.NewElement().MulGen(z)
+	r := g.NewElement().Mul(pub.e, c)
+	r.Add(r, R)
+	return l.IsEqual(r)
+}
+
+var (
+	ErrInvalidSuite        = errors.New("frost: invalid suite")
+	ErrInvalidParameters   = errors.New("frost: invalid threshold parameters")
+	ErrInvalidIdentifier   = errors.New("frost: invalid participant identifier")
+	ErrInvalidCommitments  = errors.New("frost: invalid list of commitments")
+	ErrInvalidShare        = errors.New("frost: invalid signature share")
+	ErrInvalidSignature    = errors.New("frost: invalid signature")
+	ErrNonceUsed           = errors.New("frost: nonce already used")
+	ErrIdentityElement     = errors.New("frost: unexpected identity element")
+	ErrSuiteMismatch       = errors.New("frost: mismatched ciphersuites")
+	ErrNotEnoughSignatures = errors.New("frost: not enough signature shares")
+)
@@ -0,0 +1,225 @@
+package frost_test
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/sign/ed25519"
+	"github.com/katzenpost/circl/sign/ed448"
+	"github.com/katzenpost/circl/tss/frost"
+)
+
+var allSuites = []frost.Suite{
+	frost.SuiteEd25519,
+	frost.SuiteRistretto255,
+	frost.SuiteEd448,
+	frost.SuiteP256,
+}
+
+// signers runs both rounds of the protocol for the given key shares.
+func signers(t testing.TB, shares []frost.KeyShare, msg []byte) ([]frost.Commitment, []frost.SignatureShare) {
+	nonces := make([]*frost.Nonce, len(shares))
+	coms := make([]frost.Commitment, len(shares))
+	for i := range shares {
+		var err error
+		nonces[i], coms[i], err = shares[i].Commit(rand.Reader)
+		test.CheckNoErr(t, err, "commit failed")
+	}
+	sigShares := make([]frost.SignatureShare, len(shares))
+	for i := range shares {
+		var err error
+		sigShares[i], err = shares[i].Sign(msg, nonces[i], coms)
+		test.CheckNoErr(t, err, "sign failed")
+	}
+	return coms, sigShares
+}
+
+func TestFrost(t *testing.T) {
+	for _, suite := range allSuites {
+		t.Run(suite.Identifier(), func(t *testing.T) {
+			for _, v := range []struct{ threshold, maxSigners uint }{{2, 2}, {2, 3}, {3, 5}} {
+				testFrost(t, suite, v.threshold, v.maxSigners)
+			}
+		})
+	}
+}
+
+func testFrost(t *testing.T, suite frost.Suite, threshold, maxSigners uint) {
+	key, err := frost.GenerateKey(suite, rand.Reader)
+	test.CheckNoErr(t, err, "key generation failed")
+	pub := key.Public()
+	shares, err := key.Split(rand.Reader, threshold, maxSigners)
+	test.CheckNoErr(t, err, "split failed")
+
+	msg := []byte(fmt.Sprintf("message for %v-of-%v", threshold, maxSigners))
+	for _, set := range [][]frost.KeyShare{shares[:threshold], shares[maxSigners-threshold:], shares} {
+		coms, sigShares := signers(t, set, msg)
+		for i := range set {
+			if !pub.VerifySignatureShare(set[i].Public(), msg, coms, sigShares[i]) {
+				test.ReportError(t, false, true, suite, i)
+			}
+		}
+		sig, err := pub.Aggregate(msg, coms, sigShares)
+		test.CheckNoErr(t, err, "aggregate failed")
+		if !frost.Verify(pub, msg, sig) {
+			test.ReportError(t, false, true, suite)
+		}
+		if frost.Verify(pub, []byte("other message"), sig) {
+			test.ReportError(t, true, false, suite)
+		}
+
+		// Signatures of Ed25519 and Ed448 ciphersuites are RFC 8032 signatures.
+		enc, err := pub.MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		switch suite {
+		case frost.SuiteEd25519:
+			if !ed25519.Verify(enc, msg, sig) {
+				test.ReportError(t, false, true, suite)
+			}
+		case frost.SuiteEd448:
+			if !ed448.Verify(enc, msg, sig, "") {
+				test.ReportError(t, false, true, suite)
+			}
+		}
+	}
+
+	// Fewer signers than the threshold cannot sign.
+	nonce, com, err := shares[0].Commit(rand.Reader)
+	test.CheckNoErr(t, err, "commit failed")
+	_, err = shares[0].Sign(msg, nonce, []frost.Commitment{com})
+	test.CheckIsErr(t, err, "should fail with too few signers")
+}
+
+func TestInvalidShares(t *testing.T) {
+	for _, suite := range allSuites {
+		key, _ := frost.GenerateKey(suite, rand.Reader)
+		pub := key.Public()
+		shares, _ := key.Split(rand.Reader, 3, 5)
+		set := shares[1:4]
+		msg := []byte("message")
+
+		// A signature share for a different message is identified.
+		coms, sigShares := signers(t, set, msg)
+		bad := 1
+		_, otherShares := signers(t, set, msg)
+		sigShares[bad] = otherShares[bad]
+		_, err := pub.Aggregate(msg, coms, sigShares)
+		test.CheckIsErr(t, err, "should fail with an invalid share")
+		for i := range set {
+			got := pub.VerifySignatureShare(set[i].Public(), msg, coms, sigShares[i])
+			want := i != bad
+			if got != want {
+				test.ReportError(t, got, want, suite, i)
+			}
+		}
+
+		// Shares must be checked against the right public key share.
+		if pub.VerifySignatureShare(set[0].Public(), msg, coms, sigShares[2]) {
+			test.ReportError(t, true, false, suite)
+		}
+
+		// Nonces cannot be reused.
+		nonce, com, _ := set[0].Commit(rand.Reader)
+		coms[0] = com
+		_, err = set[0].Sign(msg, nonce, coms)
+		test.CheckNoErr(t, err, "sign failed")
+		_, err = set[0].Sign(msg, nonce, coms)
+		test.CheckIsErr(t, err, "should fail reusing a nonce")
+
+		// Commitments must be sorted and include the signer's commitment.
+		nonce, com, _ = set[0].Commit(rand.Reader)
+		coms[0] = com
+		_, err = set[0].Sign(msg, nonce, []frost.Commitment{coms[1], coms[0], coms[2]})
+		test.CheckIsErr(t, err, "should fail with unsorted commitments")
+		_, err = set[0].Sign(msg, nonce, []frost.Commitment{coms[1], coms[2], coms[2]})
+		test.CheckIsErr(t, err, "should fail without the signer's commitment")
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	for _, suite := range allSuites {
+		key, _ := frost.GenerateKey(suite, rand.Reader)
+		shares, _ := key.Split(rand.Reader, 2, 3)
+		msg := []byte("message")
+
+		enc, err := key.MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		var key2 frost.PrivateKey
+		test.CheckNoErr(t, key2.UnmarshalBinary(suite, enc), "unmarshal failed")
+		enc, err = key.Public().MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		var pub frost.PublicKey
+		test.CheckNoErr(t, pub.UnmarshalBinary(suite, enc), "unmarshal failed")
+		enc2, _ := key2.Public().MarshalBinary()
+		if string(enc) != string(enc2) {
+			test.ReportError(t, enc2, enc, suite)
+		}
+
+		// Signing with decoded shares and messages.
+		decoded := make([]frost.KeyShare, 2)
+		pubShares := make([]frost.PublicKeyShare, 2)
+		for i := range decoded {
+			enc, err := shares[i+1].MarshalBinary()
+			test.CheckNoErr(t, err, "marshal failed")
+			test.CheckNoErr(t, decoded[i].UnmarshalBinary(suite, enc), "unmarshal failed")
+			enc, err = shares[i+1].Public().MarshalBinary()
+			test.CheckNoErr(t, err, "marshal failed")
+			test.CheckNoErr(t, pubShares[i].UnmarshalBinary(suite, enc), "unmarshal failed")
+		}
+		coms, sigShares := signers(t, decoded, msg)
+		for i := range coms {
+			enc, err := coms[i].MarshalBinary()
+			test.CheckNoErr(t, err, "marshal failed")
+			test.CheckNoErr(t, coms[i].UnmarshalBinary(suite, enc), "unmarshal failed")
+			enc, err = sigShares[i].MarshalBinary()
+			test.CheckNoErr(t, err, "marshal failed")
+			test.CheckNoErr(t, sigShares[i].UnmarshalBinary(suite, enc), "unmarshal failed")
+			if !pub.VerifySignatureShare(&pubShares[i], msg, coms, sigShares[i]) {
+				test.ReportError(t, false, true, suite, i)
+			}
+		}
+		sig, err := pub.Aggregate(msg, coms, sigShares)
+		test.CheckNoErr(t, err, "aggregate failed")
+		if !frost.Verify(&pub, msg, sig) {
+			test.ReportError(t, false, true, suite)
+		}
+
+		got, err := frost.GetSuite(suite.Identifier())
+		if err != nil || got != suite {
+			test.ReportError(t, got, suite)
+		}
+	}
+}
+
+func BenchmarkFrost(b *testing.B) {
+	for _, suite := range allSuites {
+		key, _ := frost.GenerateKey(suite, rand.Reader)
+		pub := key.Public()
+		shares, _ := key.Split(rand.Reader, 3, 3)
+		msg := []byte("message")
+		coms, sigShares := signers(b, shares, msg)
+		sig, _ := pub.Aggregate(msg, coms, sigShares)
+
+		b.Run(suite.Identifier()+"/Commit", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _, _ = shares[0].Commit(rand.Reader)
+			}
+		})
+		b.Run(suite.Identifier()+"/Sign", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				nonce, com, _ := shares[0].Commit(rand.Reader)
+				coms[0] = com
+				b.StartTimer()
+				_, _ = shares[0].Sign(msg, nonce, coms)
+			}
+		})
+		b.Run(suite.Identifier()+"/Verify", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				frost.Verify(pub, msg, sig)
+			}
+		})
+	}
+}
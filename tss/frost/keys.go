@@ -0,0 +1,253 @@
+package frost
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/math/polynomial"
+)
+
+// PrivateKey is a FROST private key, which is split into KeyShares by a
+// trusted dealer.
+type PrivateKey struct {
+	p   *params
+	k   group.Scalar
+	pub *PublicKey
+}
+
+// PublicKey is the public key of a group of signers.
+type PublicKey struct {
+	p *params
+	e group.Element
+}
+
+// KeyShare is the share of the private key held by a signer.
+type KeyShare struct {
+	p         *params
+	ID        uint16 // Identifier of the signer, starting at one.
+	Threshold uint16 // Minimum number of signers required to sign.
+	share     group.Scalar
+	groupKey  *PublicKey
+	pub       *PublicKeyShare
+}
+
+// PublicKeyShare is the public counterpart of a KeyShare, used to verify
+// the signature shares of a signer.
+type PublicKeyShare struct {
+	p  *params
+	ID uint16
+	e  group.Element
+}
+
+// GenerateKey generates a private key compatible with the suite.
+func GenerateKey(s Suite, rnd io.Reader) (*PrivateKey, error) {
+	p, ok := s.(*params)
+	if !ok {
+		return nil, ErrInvalidSuite
+	}
+	if rnd == nil {
+		return nil, io.ErrNoProgress
+	}
+	return &PrivateKey{p: p, k: p.group.RandomNonZeroScalar(rnd)}, nil
+}
+
+// Public returns the public key corresponding to the private key.
+func (k *PrivateKey) Public() *PublicKey {
+	if k.pub == nil {
+		k.pub = &PublicKey{k.p, k.p.group.NewElement().MulGen(k.k)}
+	}
+	return k.pub
+}
+
+// Split acts as a trusted dealer: it splits the private key into maxSigners
+// shares with identifiers 1 to maxSigners, such that any threshold of them
+// can sign. It requires 2 <= threshold <= maxSigners.
+func (k *PrivateKey) Split(rnd io.Reader, threshold, maxSigners uint) ([]KeyShare, error) {
+	if threshold < 2 || maxSigners < threshold || maxSigners > math.MaxUint16 {
+		return nil, ErrInvalidParameters
+	}
+	if rnd == nil {
+		return nil, io.ErrNoProgress
+	}
+
+	g := k.p.group
+	coeffs := make([]group.Scalar, threshold)
+	coeffs[0] = k.k.Copy()
+	for i := 1; i < len(coeffs); i++ {
+		coeffs[i] = g.RandomScalar(rnd)
+	}
+	poly := polynomial.New(coeffs)
+
+	shares := make([]KeyShare, maxSigners)
+	for i := range shares {
+		id := uint16(i + 1)
+		shares[i] = KeyShare{
+			p:         k.p,
+			ID:        id,
+			Threshold: uint16(threshold),
+			share:     poly.Evaluate(k.p.identifier2Scalar(id)),
+			groupKey:  k.Public(),
+		}
+	}
+	return shares, nil
+}
+
+// GroupPublicKey returns the public key of the group of signers.
+func (k *KeyShare) GroupPublicKey() *PublicKey { return k.groupKey }
+
+// Public returns the public key share corresponding to the key share.
+func (k *KeyShare) Public() *PublicKeyShare {
+	if k.pub == nil {
+		k.pub = &PublicKeyShare{k.p, k.ID, k.p.group.NewElement().MulGen(k.share)}
+	}
+	return k.pub
+}
+
+func (k *PrivateKey) MarshalBinary() ([]byte, error) { return k.k.MarshalBinary() }
+func (k *PublicKey) MarshalBinary() ([]byte, error)  { return k.e.MarshalBinaryCompress() }
+
+func (k *PrivateKey) UnmarshalBinary(s Suite, data []byte) error {
+	p, ok := s.(*params)
+	if !ok {
+		return ErrInvalidSuite
+	}
+	sk, err := p.decodeScalar(data)
+	if err != nil {
+		return err
+	}
+	if sk.IsZero() {
+		return group.ErrUnmarshal
+	}
+	*k = PrivateKey{p: p, k: sk}
+	return nil
+}
+
+func (k *PublicKey) UnmarshalBinary(s Suite, data []byte) error {
+	p, ok := s.(*params)
+	if !ok {
+		return ErrInvalidSuite
+	}
+	e, err := p.decodeElement(data)
+	if err != nil {
+		return err
+	}
+	*k = PublicKey{p, e}
+	return nil
+}
+
+// MarshalBinary encodes the key share as the identifier and the threshold,
+// both as big-endian uint16, followed by the share and the group public key.
+func (k *KeyShare) MarshalBinary() ([]byte, error) {
+	share, err := k.share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := k.groupKey.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 4, 4+len(share)+len(pub))
+	binary.BigEndian.PutUint16(data[0:], k.ID)
+	binary.BigEndian.PutUint16(data[2:], k.Threshold)
+	return append(append(data, share...), pub...), nil
+}
+
+func (k *KeyShare) UnmarshalBinary(s Suite, data []byte) error {
+	p, ok := s.(*params)
+	if !ok {
+		return ErrInvalidSuite
+	}
+	scalarLen := int(p.group.Params().ScalarLength)
+	if len(data) < 4+scalarLen {
+		return group.ErrUnmarshal
+	}
+	id := binary.BigEndian.Uint16(data[0:])
+	threshold := binary.BigEndian.Uint16(data[2:])
+	if id == 0 || threshold < 2 {
+		return ErrInvalidParameters
+	}
+	share, err := p.decodeScalar(data[4 : 4+scalarLen])
+	if err != nil {
+		return err
+	}
+	var pub PublicKey
+	if err := pub.UnmarshalBinary(s, data[4+scalarLen:]); err != nil {
+		return err
+	}
+	*k = KeyShare{p: p, ID: id, Threshold: threshold, share: share, groupKey: &pub}
+	return nil
+}
+
+// MarshalBinary encodes the public key share as the identifier, as a
+// big-endian uint16, followed by the element.
+func (k *PublicKeyShare) MarshalBinary() ([]byte, error) {
+	enc, err := k.e.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 2, 2+len(enc))
+	binary.BigEndian.PutUint16(data, k.ID)
+	return append(data, enc...), nil
+}
+
+func (k *PublicKeyShare) UnmarshalBinary(s Suite, data []byte) error {
+	p, ok := s.(*params)
+	if !ok {
+		return ErrInvalidSuite
+	}
+	if len(data) < 2 {
+		return group.ErrUnmarshal
+	}
+	id := binary.BigEndian.Uint16(data)
+	if id == 0 {
+		return ErrInvalidIdentifier
+	}
+	e, err := p.decodeElement(data[2:])
+	if err != nil {
+		return err
+	}
+	*k = PublicKeyShare{p, id, e}
+	return nil
+}
+
+func (p *params) identifier2Scalar(id uint16) group.Scalar {
+	return p.group.NewScalar().SetUint64(uint64(id))
+}
+
+// decodeScalar decodes a scalar and rejects non-canonical encodings.
+func (p *params) decodeScalar(data []byte) (group.Scalar, error) {
+	if uint(len(data)) != p.group.Params().ScalarLength {
+		return nil, group.ErrUnmarshal
+	}
+	s := p.group.NewScalar()
+	if err := s.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	enc, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(enc, data) != 1 {
+		return nil, group.ErrUnmarshal
+	}
+	return s, nil
+}
+
+// decodeElement decodes an element in compressed form, and rejects the
+// identity.
+func (p *params) decodeElement(data []byte) (group.Element, error) {
+	if uint(len(data)) != p.group.Params().CompressedElementLength {
+		return nil, group.ErrUnmarshal
+	}
+	e := p.group.NewElement()
+	if err := e.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	if e.IsIdentity() {
+		return nil, ErrIdentityElement
+	}
+	return e, nil
+}
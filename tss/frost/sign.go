@@ -0,0 +1,395 @@
+package frost
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/math/polynomial"
+)
+
+// Nonce is the secret generated by a signer in the first round of the
+// protocol. It must be used to sign one message only.
+type Nonce struct {
+	p               *params
+	id              uint16
+	hiding, binding group.Scalar
+	com             Commitment
+}
+
+// Commitment is the public counterpart of a Nonce, which the signer sends to
+// the coordinator in the first round of the protocol.
+type Commitment struct {
+	p               *params
+	ID              uint16
+	hiding, binding group.Element
+}
+
+// SignatureShare is the output of a signer in the second round of the
+// protocol.
+type SignatureShare struct {
+	p     *params
+	ID    uint16
+	share group.Scalar
+}
+
+// Commit performs the first round of the protocol, generating a fresh
+// nonce and its commitment. The nonce must be kept secret.
+func (k *KeyShare) Commit(rnd io.Reader) (*Nonce, Commitment, error) {
+	if rnd == nil {
+		return nil, Commitment{}, io.ErrNoProgress
+	}
+	hiding, err := k.nonceGenerate(rnd)
+	if err != nil {
+		return nil, Commitment{}, err
+	}
+	binding, err := k.nonceGenerate(rnd)
+	if err != nil {
+		return nil, Commitment{}, err
+	}
+	g := k.p.group
+	com := Commitment{
+		p:       k.p,
+		ID:      k.ID,
+		hiding:  g.NewElement().MulGen(hiding),
+		binding: g.NewElement().MulGen(binding),
+	}
+	return &Nonce{k.p, k.ID, hiding, binding, com}, com, nil
+}
+
+// nonceGenerate computes H3(random_bytes(32) || SerializeScalar(secret)).
+func (k *KeyShare) nonceGenerate(rnd io.Reader) (group.Scalar, error) {
+	var buf [32]byte
+	if _, err := io.ReadFull(rnd, buf[:]); err != nil {
+		return nil, err
+	}
+	secret, err := k.share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return k.p.h3(append(buf[:], secret...)), nil
+}
+
+// Sign performs the second round of the protocol, producing the signature
+// share of msg for the signers of the commitments, which must be sorted by
+// identifier and include the commitment of nonce. The nonce is erased, so
+// it cannot be used again.
+func (k *KeyShare) Sign(msg []byte, nonce *Nonce, commitments []Commitment) (SignatureShare, error) {
+	if nonce.hiding == nil {
+		return SignatureShare{}, ErrNonceUsed
+	}
+	if nonce.p != k.p || nonce.id != k.ID {
+		return SignatureShare{}, ErrSuiteMismatch
+	}
+	if len(commitments) < int(k.Threshold) {
+		return SignatureShare{}, ErrInvalidCommitments
+	}
+	pos := -1
+	for i := range commitments {
+		if commitments[i].ID == k.ID {
+			pos = i
+		}
+	}
+	if pos < 0 || !commitments[pos].isEqual(&nonce.com) {
+		return SignatureShare{}, ErrInvalidCommitments
+	}
+
+	s, err := k.p.newSession(k.groupKey, msg, commitments)
+	if err != nil {
+		return SignatureShare{}, err
+	}
+
+	// z_i = d_i + e_i*rho_i + lambda_i*s_i*c
+	g := k.p.group
+	z := g.NewScalar().Mul(nonce.binding, s.bindingFactors[pos])
+	z.Add(z, nonce.hiding)
+	t := g.NewScalar().Mul(s.lambda(pos), k.share)
+	t.Mul(t, s.challenge)
+	z.Add(z, t)
+
+	nonce.hiding.SetUint64(0)
+	nonce.binding.SetUint64(0)
+	nonce.hiding, nonce.binding = nil, nil
+	return SignatureShare{k.p, k.ID, z}, nil
+}
+
+// VerifySignatureShare returns whether share is the valid signature share of
+// msg by the owner of pubShare, for the signers of the commitments.
+func (pub *PublicKey) VerifySignatureShare(
+	pubShare *PublicKeyShare,
+	msg []byte,
+	commitments []Commitment,
+	share SignatureShare,
+) bool {
+	if pubShare.p != pub.p || share.p != pub.p || share.ID != pubShare.ID {
+		return false
+	}
+	s, err := pub.p.newSession(pub, msg, commitments)
+	if err != nil {
+		return false
+	}
+	pos := -1
+	for i := range commitments {
+		if commitments[i].ID == share.ID {
+			pos = i
+		}
+	}
+	if pos < 0 {
+		return false
+	}
+
+	// [z_i]B = D_i + [rho_i]E_i + [c*lambda_i]PK_i
+	g := pub.p.group
+	com := &commitments[pos]
+	l := g.NewElement().MulGen(share.share)
+	r := g.NewElement().Mul(com.binding, s.bindingFactors[pos])
+	r.Add(r, com.hiding)
+	t := g.NewScalar().Mul(s.challenge, s.lambda(pos))
+	r.Add(r, g.NewElement().Mul(pubShare.e, t))
+	return l.IsEqual(r)
+}
+
+// Aggregate combines the signature shares of msg, one for each of the
+// commitments, into a signature under pub. It returns ErrInvalidSignature
+// if the result does not verify, in which case VerifySignatureShare can
+// identify the invalid shares.
+func (pub *PublicKey) Aggregate(msg []byte, commitments []Commitment, shares []SignatureShare) ([]byte, error) {
+	if len(shares) != len(commitments) {
+		return nil, ErrNotEnoughSignatures
+	}
+	s, err := pub.p.newSession(pub, msg, commitments)
+	if err != nil {
+		return nil, err
+	}
+
+	g := pub.p.group
+	z := g.NewScalar()
+	seen := make(map[uint16]bool, len(shares))
+	for i := range shares {
+		if shares[i].p != pub.p {
+			return nil, ErrSuiteMismatch
+		}
+		if seen[shares[i].ID] || !s.hasSigner(shares[i].ID) {
+			return nil, ErrInvalidShare
+		}
+		seen[shares[i].ID] = true
+		z.Add(z, shares[i].share)
+	}
+
+	R, err := s.groupCommitment.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	encZ, err := z.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	signature := append(R, encZ...)
+	if !Verify(pub, msg, signature) {
+		return nil, ErrInvalidSignature
+	}
+	return signature, nil
+}
+
+// session holds the values derived from the message and the commitments
+// of a signing session.
+type session struct {
+	signers         []uint16
+	ids             []group.Scalar
+	bindingFactors  []group.Scalar
+	groupCommitment group.Element
+	challenge       group.Scalar
+}
+
+func (p *params) newSession(pub *PublicKey, msg []byte, commitments []Commitment) (*session, error) {
+	if pub.p != p {
+		return nil, ErrSuiteMismatch
+	}
+	encCommitments, err := p.encodeCommitments(commitments)
+	if err != nil {
+		return nil, err
+	}
+	encPub, err := pub.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	// rho_input = SerializeElement(PK) || H4(msg) || H5(commitments) ||
+	// SerializeScalar(id_i)
+	prefix := append(append(encPub, p.h4(msg)...), p.h5(encCommitments)...)
+	g := p.group
+	s := &session{
+		signers:         make([]uint16, len(commitments)),
+		ids:             make([]group.Scalar, len(commitments)),
+		bindingFactors:  make([]group.Scalar, len(commitments)),
+		groupCommitment: g.Identity(),
+	}
+	for i := range commitments {
+		s.signers[i] = commitments[i].ID
+		s.ids[i] = p.identifier2Scalar(commitments[i].ID)
+		encID, err := s.ids[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		rhoInput := append(append([]byte{}, prefix...), encID...)
+		s.bindingFactors[i] = p.h1(rhoInput)
+
+		// R = sum D_i + [rho_i]E_i
+		t := g.NewElement().Mul(commitments[i].binding, s.bindingFactors[i])
+		s.groupCommitment.Add(s.groupCommitment, t)
+		s.groupCommitment.Add(s.groupCommitment, commitments[i].hiding)
+	}
+
+	s.challenge, err = p.challenge(s.groupCommitment, pub.e, msg)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// lambda returns the Lagrange coefficient of the i-th signer.
+func (s *session) lambda(i int) group.Scalar {
+	zero := s.ids[i].Group().NewScalar()
+	return polynomial.LagrangeBase(uint(i), s.ids, zero)
+}
+
+func (s *session) hasSigner(id uint16) bool {
+	for i := range s.signers {
+		if s.signers[i] == id {
+			return true
+		}
+	}
+	return false
+}
+
+// challenge computes H2(SerializeElement(R) || SerializeElement(PK) || msg).
+func (p *params) challenge(R, pub group.Element, msg []byte) (group.Scalar, error) {
+	if R.IsIdentity() {
+		return nil, ErrIdentityElement
+	}
+	encR, err := R.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	encPub, err := pub.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	return p.h2(append(append(encR, encPub...), msg...)), nil
+}
+
+// encodeCommitments checks that the commitments are sorted by identifier
+// and encodes them as in RFC 9591.
+func (p *params) encodeCommitments(commitments []Commitment) ([]byte, error) {
+	if len(commitments) == 0 {
+		return nil, ErrInvalidCommitments
+	}
+	var out []byte
+	for i := range commitments {
+		c := &commitments[i]
+		if c.p != p {
+			return nil, ErrSuiteMismatch
+		}
+		if c.ID == 0 || (i > 0 && c.ID <= commitments[i-1].ID) {
+			return nil, ErrInvalidCommitments
+		}
+		enc, err := c.marshal(true)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, enc...)
+	}
+	return out, nil
+}
+
+// marshal encodes the commitment as the identifier followed by the hiding
+// and binding elements. The identifier is a scalar if asScalar is set, or
+// a big-endian uint16 otherwise.
+func (c *Commitment) marshal(asScalar bool) ([]byte, error) {
+	if c.hiding.IsIdentity() || c.binding.IsIdentity() {
+		return nil, ErrIdentityElement
+	}
+	var out []byte
+	if asScalar {
+		id, err := c.p.identifier2Scalar(c.ID).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		out = id
+	} else {
+		out = binary.BigEndian.AppendUint16(nil, c.ID)
+	}
+	hiding, err := c.hiding.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	binding, err := c.binding.MarshalBinaryCompress()
+	if err != nil {
+		return nil, err
+	}
+	return append(append(out, hiding...), binding...), nil
+}
+
+func (c *Commitment) isEqual(x *Commitment) bool {
+	return c.p == x.p && c.ID == x.ID &&
+		c.hiding.IsEqual(x.hiding) && c.binding.IsEqual(x.binding)
+}
+
+// MarshalBinary encodes the commitment as the identifier, as a big-endian
+// uint16, followed by the hiding and binding elements.
+func (c *Commitment) MarshalBinary() ([]byte, error) { return c.marshal(false) }
+
+func (c *Commitment) UnmarshalBinary(s Suite, data []byte) error {
+	p, ok := s.(*params)
+	if !ok {
+		return ErrInvalidSuite
+	}
+	eltLen := int(p.group.Params().CompressedElementLength)
+	if len(data) != 2+2*eltLen {
+		return group.ErrUnmarshal
+	}
+	id := binary.BigEndian.Uint16(data)
+	if id == 0 {
+		return ErrInvalidIdentifier
+	}
+	hiding, err := p.decodeElement(data[2 : 2+eltLen])
+	if err != nil {
+		return err
+	}
+	binding, err := p.decodeElement(data[2+eltLen:])
+	if err != nil {
+		return err
+	}
+	*c = Commitment{p, id, hiding, binding}
+	return nil
+}
+
+// MarshalBinary encodes the signature share as the identifier, as a
+// big-endian uint16, followed by the scalar.
+func (s *SignatureShare) MarshalBinary() ([]byte, error) {
+	enc, err := s.share.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(binary.BigEndian.AppendUint16(nil, s.ID), enc...), nil
+}
+
+func (s *SignatureShare) UnmarshalBinary(suite Suite, data []byte) error {
+	p, ok := suite.(*params)
+	if !ok {
+		return ErrInvalidSuite
+	}
+	if len(data) < 2 {
+		return group.ErrUnmarshal
+	}
+	id := binary.BigEndian.Uint16(data)
+	if id == 0 {
+		return ErrInvalidIdentifier
+	}
+	share, err := p.decodeScalar(data[2:])
+	if err != nil {
+		return err
+	}
+	*s = SignatureShare{p, id, share}
+	return nil
+}
@@ -0,0 +1,148 @@
+package frost
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"math/big"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/internal/sha3"
+)
+
+var (
+	suiteEd25519 = &params{
+		identifier:   "FROST-ED25519-SHA512-v1",
+		group:        group.Edwards25519,
+		hash:         sha512Sum,
+		hashToScalar: wideReduce(sha512Sum),
+		// H2 is the challenge of Ed25519, which has no prefix.
+		challengeDST: []byte{},
+	}
+	suiteRistretto255 = &params{
+		identifier:   "FROST-RISTRETTO255-SHA512-v1",
+		group:        group.Ristretto255,
+		hash:         sha512Sum,
+		hashToScalar: wideReduce(sha512Sum),
+		challengeDST: []byte("FROST-RISTRETTO255-SHA512-v1chal"),
+	}
+	suiteEd448 = &params{
+		identifier:   "FROST-ED448-SHAKE256-v1",
+		group:        edwards448,
+		hash:         shake256Sum,
+		hashToScalar: wideReduce(shake256Sum),
+		// H2 is the challenge of Ed448, which is prefixed with dom4(0, "").
+		challengeDST: []byte("SigEd448\x00\x00"),
+	}
+	suiteP256 = &params{
+		identifier: "FROST-P256-SHA256-v1",
+		group:      group.P256,
+		hash:       sha256Sum,
+		hashToScalar: func(g group.Group, dst, msg []byte) group.Scalar {
+			return g.HashToScalar(msg, dst)
+		},
+		challengeDST: []byte("FROST-P256-SHA256-v1chal"),
+	}
+)
+
+type params struct {
+	identifier string
+	group      group.Group
+	// hash returns the digest of dst || msg.
+	hash func(dst, msg []byte) []byte
+	// hashToScalar maps dst and msg to a scalar.
+	hashToScalar func(g group.Group, dst, msg []byte) group.Scalar
+	challengeDST []byte
+}
+
+func (p *params) cannotBeImplementedExternally() {}
+
+func (p *params) String() string     { return p.Identifier() }
+func (p *params) Group() group.Group { return p.group }
+func (p *params) Identifier() string { return p.identifier }
+
+func (p *params) dst(label string) []byte {
+	return append([]byte(p.identifier), label...)
+}
+
+// h1 derives binding factors.
+func (p *params) h1(m []byte) group.Scalar {
+	return p.hashToScalar(p.group, p.dst("rho"), m)
+}
+
+// h2 derives the challenge.
+func (p *params) h2(m []byte) group.Scalar {
+	return p.hashToScalar(p.group, p.challengeDST, m)
+}
+
+// h3 derives nonces.
+func (p *params) h3(m []byte) group.Scalar {
+	return p.hashToScalar(p.group, p.dst("nonce"), m)
+}
+
+// h4 hashes the message.
+func (p *params) h4(m []byte) []byte { return p.hash(p.dst("msg"), m) }
+
+// h5 hashes the list of commitments.
+func (p *params) h5(m []byte) []byte { return p.hash(p.dst("com"), m) }
+
+func sha512Sum(dst, msg []byte) []byte {
+	h := sha512.New()
+	_, _ = h.Write(dst)
+	_, _ = h.Write(msg)
+	return h.Sum(nil)
+}
+
+func sha256Sum(dst, msg []byte) []byte {
+	h := sha256.New()
+	_, _ = h.Write(dst)
+	_, _ = h.Write(msg)
+	return h.Sum(nil)
+}
+
+func shake256Sum(dst, msg []byte) []byte {
+	var out [114]byte
+	h := sha3.NewShake256()
+	_, _ = h.Write(dst)
+	_, _ = h.Write(msg)
+	_, _ = h.Read(out[:])
+	return out[:]
+}
+
+// wideReduce returns a function that interprets the digest of hash as a
+// little-endian integer and reduces it modulo the order of a group whose
+// scalars are encoded in little-endian order.
+func wideReduce(hash func(dst, msg []byte) []byte) func(group.Group, []byte, []byte) group.Scalar {
+	return func(g group.Group, dst, msg []byte) group.Scalar {
+		return scalarFromLE(g, hash(dst, msg))
+	}
+}
+
+// scalarFromLE reduces the little-endian integer b modulo the order of g.
+// As b may be secret, it is split into chunks smaller than the order, which
+// are combined using the arithmetic of g.
+func scalarFromLE(g group.Group, b []byte) group.Scalar {
+	scalarLen := int(g.Params().ScalarLength)
+	chunkLen := scalarLen - 2
+	radix := g.NewScalar().SetBigInt(new(big.Int).Lsh(big.NewInt(1), uint(8*chunkLen)))
+
+	s := g.NewScalar()
+	chunk := g.NewScalar()
+	buf := make([]byte, scalarLen)
+	for j := (len(b) + chunkLen - 1) / chunkLen; j > 0; j-- {
+		start := (j - 1) * chunkLen
+		end := start + chunkLen
+		if end > len(b) {
+			end = len(b)
+		}
+		for i := range buf {
+			buf[i] = 0
+		}
+		copy(buf, b[start:end])
+		if err := chunk.UnmarshalBinary(buf); err != nil {
+			panic(err)
+		}
+		s.Mul(s, radix)
+		s.Add(s, chunk)
+	}
+	return s
+}
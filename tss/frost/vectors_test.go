@@ -0,0 +1,168 @@
+package frost
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/katzenpost/circl/group"
+	"github.com/katzenpost/circl/internal/test"
+	"github.com/katzenpost/circl/math/polynomial"
+)
+
+// TestVectors checks the test vectors of RFC 9591, Appendix E, in the
+// format of the reference implementation. Files that are missing are
+// skipped; see testdata/README.md.
+func TestVectors(t *testing.T) {
+	for _, v := range []struct {
+		file  string
+		suite *params
+	}{
+		{"frost_ed25519_sha512.json", suiteEd25519},
+		{"frost_ristretto255_sha512.json", suiteRistretto255},
+		{"frost_ed448_shake256.json", suiteEd448},
+		{"frost_p256_sha256.json", suiteP256},
+	} {
+		t.Run(v.suite.identifier, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", v.file))
+			if errors.Is(err, os.ErrNotExist) {
+				t.Skipf("%v not found", v.file)
+			}
+			test.CheckNoErr(t, err, "read failed")
+			var vec vector
+			test.CheckNoErr(t, json.Unmarshal(data, &vec), "decode failed")
+			testVector(t, v.suite, &vec)
+		})
+	}
+}
+
+func testVector(t *testing.T, p *params, v *vector) {
+	var sk PrivateKey
+	test.CheckNoErr(t, sk.UnmarshalBinary(p, v.Inputs.GroupSecretKey), "bad secret key")
+	pub := sk.Public()
+	checkBytes(t, pub.MarshalBinary, v.Inputs.GroupPublicKey, "group public key")
+
+	coeffs := []group.Scalar{sk.k}
+	for _, c := range v.Inputs.Coefficients {
+		s, err := p.decodeScalar(c)
+		test.CheckNoErr(t, err, "bad coefficient")
+		coeffs = append(coeffs, s)
+	}
+	poly := polynomial.New(coeffs)
+	shares := make(map[uint16]KeyShare)
+	for _, s := range v.Inputs.Shares {
+		shares[s.ID] = KeyShare{
+			p:         p,
+			ID:        s.ID,
+			Threshold: uint16(len(coeffs)),
+			share:     poly.Evaluate(p.identifier2Scalar(s.ID)),
+			groupKey:  pub,
+		}
+		checkBytes(t, shares[s.ID].share.MarshalBinary, s.Share, "participant share")
+	}
+	if len(v.RoundOne.Outputs) == 0 {
+		// The file only holds the key generation part of the vector.
+		return
+	}
+
+	signers := make([]KeyShare, len(v.Inputs.Participants))
+	nonces := make([]*Nonce, len(signers))
+	coms := make([]Commitment, len(signers))
+	for i, id := range v.Inputs.Participants {
+		out := v.RoundOne.Outputs[i]
+		if out.ID != id {
+			t.Fatalf("round one output %v has identifier %v, want %v", i, out.ID, id)
+		}
+		signers[i] = shares[id]
+		rnd := bytes.NewReader(append(append([]byte{}, out.HidingRandomness...), out.BindingRandomness...))
+		var err error
+		nonces[i], coms[i], err = signers[i].Commit(rnd)
+		test.CheckNoErr(t, err, "commit failed")
+		checkBytes(t, nonces[i].hiding.MarshalBinary, out.HidingNonce, "hiding nonce")
+		checkBytes(t, nonces[i].binding.MarshalBinary, out.BindingNonce, "binding nonce")
+		checkBytes(t, coms[i].hiding.MarshalBinaryCompress, out.HidingCommitment, "hiding commitment")
+		checkBytes(t, coms[i].binding.MarshalBinaryCompress, out.BindingCommitment, "binding commitment")
+	}
+
+	s, err := p.newSession(pub, v.Inputs.Message, coms)
+	test.CheckNoErr(t, err, "session failed")
+	for i := range signers {
+		checkBytes(t, s.bindingFactors[i].MarshalBinary, v.RoundOne.Outputs[i].BindingFactor, "binding factor")
+	}
+
+	sigShares := make([]SignatureShare, len(signers))
+	for i := range signers {
+		out := v.RoundTwo.Outputs[i]
+		sigShares[i], err = signers[i].Sign(v.Inputs.Message, nonces[i], coms)
+		test.CheckNoErr(t, err, "sign failed")
+		checkBytes(t, sigShares[i].share.MarshalBinary, out.SigShare, "signature share")
+		test.CheckOk(pub.VerifySignatureShare(signers[i].Public(), v.Inputs.Message, coms, sigShares[i]),
+			"signature share was not verified", t)
+	}
+
+	sig, err := pub.Aggregate(v.Inputs.Message, coms, sigShares)
+	test.CheckNoErr(t, err, "aggregate failed")
+	if !bytes.Equal(sig, v.FinalOutput.Sig) {
+		test.ReportError(t, hex.EncodeToString(sig), hex.EncodeToString(v.FinalOutput.Sig))
+	}
+	test.CheckOk(Verify(pub, v.Inputs.Message, sig), "signature was not verified", t)
+}
+
+func checkBytes(t *testing.T, marshal func() ([]byte, error), want []byte, name string) {
+	t.Helper()
+	got, err := marshal()
+	test.CheckNoErr(t, err, name)
+	if !bytes.Equal(got, want) {
+		test.ReportError(t, hex.EncodeToString(got), hex.EncodeToString(want), name)
+	}
+}
+
+type hexBytes []byte
+
+func (h *hexBytes) UnmarshalJSON(data []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*h, err = hex.DecodeString(s)
+	return err
+}
+
+type vector struct {
+	Inputs struct {
+		Participants   []uint16   `json:"participant_list"`
+		GroupSecretKey hexBytes   `json:"group_secret_key"`
+		GroupPublicKey hexBytes   `json:"group_public_key"`
+		Message        hexBytes   `json:"message"`
+		Coefficients   []hexBytes `json:"share_polynomial_coefficients"`
+		Shares         []struct {
+			ID    uint16   `json:"identifier"`
+			Share hexBytes `json:"participant_share"`
+		} `json:"participant_shares"`
+	} `json:"inputs"`
+	RoundOne struct {
+		Outputs []struct {
+			ID                uint16   `json:"identifier"`
+			HidingRandomness  hexBytes `json:"hiding_nonce_randomness"`
+			BindingRandomness hexBytes `json:"binding_nonce_randomness"`
+			HidingNonce       hexBytes `json:"hiding_nonce"`
+			BindingNonce      hexBytes `json:"binding_nonce"`
+			HidingCommitment  hexBytes `json:"hiding_nonce_commitment"`
+			BindingCommitment hexBytes `json:"binding_nonce_commitment"`
+			BindingFactor     hexBytes `json:"binding_factor"`
+		} `json:"outputs"`
+	} `json:"round_one_outputs"`
+	RoundTwo struct {
+		Outputs []struct {
+			ID       uint16   `json:"identifier"`
+			SigShare hexBytes `json:"sig_share"`
+		} `json:"outputs"`
+	} `json:"round_two_outputs"`
+	FinalOutput struct {
+		Sig hexBytes `json:"sig"`
+	} `json:"final_output"`
+}
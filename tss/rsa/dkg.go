@@ -0,0 +1,615 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// Network is used by a player to exchange messages with the other players
+// during DistributedKeyGen. Messages sent from one player to another must be
+// delivered in order, and Send must not wait for the message to be received.
+// The network is expected to authenticate and encrypt messages.
+type Network interface {
+	// Send sends msg to the player with index to.
+	Send(to uint, msg []byte) error
+	// Receive returns the next message sent by the player with index from.
+	Receive(from uint) ([]byte, error)
+}
+
+const (
+	// dkgBatchSize is the number of candidate moduli tested at once.
+	dkgBatchSize = 128
+	// dkgBiprimalityRounds is the number of bases used to test a modulus.
+	dkgBiprimalityRounds = 64
+	// dkgSieveBound bounds the small primes dividing rejected moduli.
+	dkgSieveBound = 1 << 16
+	// dkgStatisticalSecurity is the statistical security parameter of the
+	// integer sharing of the private exponent.
+	dkgStatisticalSecurity = 128
+	// dkgPublicExponent is the public exponent of the generated keys.
+	dkgPublicExponent = 65537
+)
+
+// DistributedKeyGen runs the key generation of the player with the given
+// index, from 1 to players, without a trusted dealer. All the players must
+// run it concurrently with the same parameters. It returns the public key, a
+// KeyShare of the player, such that any threshold of the KeyShares produce
+// signatures with Sign and CombineSignShares, and the VerificationKeys of all
+// the players. No player learns the factors of the modulus nor the private
+// exponent. If cache is true, cached values are stored in the KeyShare as for
+// Deal.
+//
+// The modulus is generated using the protocol of Boneh and Franklin [1]:
+// each player picks additive shares of the primes p and q, the players
+// compute N = pq with the BGW protocol, discard the moduli with small
+// factors, and run a distributed biprimality test. Then, the players compute
+// additive shares of the private exponent, which are shared again with
+// integer polynomials of degree threshold-1 as in [2]. Each player commits to
+// the coefficients a_k of its polynomial by broadcasting V^{a_k}, as in the
+// scheme of Feldman, so that the players check the shares they receive and
+// that the constant terms add up to the private exponent. The verification
+// keys are derived from these commitments.
+//
+// The protocol is secure against semi-honest players, that is, players
+// following the protocol, as long as fewer than half of them collude; so it
+// requires at least 3 players. It reveals φ(N) mod e, as in [1]. The number
+// of candidate moduli grows quadratically with bits, making it expensive for
+// large moduli.
+//
+// The primes of the modulus are not safe primes, so the proofs of correctness
+// of SignWithProof are not sound for the keys generated here: SignWithProof,
+// VerifySignShare and CombineVerifiedSignShares reject the returned
+// VerificationKeys, which can only be used with Reshare and CombineReshares.
+//
+// [1] https://doi.org/10.1145/382780.382782
+// [2] https://doi.org/10.1007/3-540-44448-3_12
+func DistributedKeyGen(randSource io.Reader, network Network, index, players, threshold uint, bits int, cache bool) (*rsa.PublicKey, KeyShare, *VerificationKeys, error) {
+	if err := validateParams(players, threshold); err != nil {
+		return nil, KeyShare{}, nil, err
+	}
+	if players < 3 {
+		return nil, KeyShare{}, nil, errors.New("rsa_threshold: dkg: at least 3 players are required")
+	}
+	if index < 1 || index > players {
+		return nil, KeyShare{}, nil, fmt.Errorf("rsa_threshold: dkg: index %d is not in [1, %d]", index, players)
+	}
+	if bits < 64 {
+		return nil, KeyShare{}, nil, errors.New("rsa_threshold: dkg: modulus is too small")
+	}
+
+	p := &dkgPlayer{
+		rnd:       randSource,
+		net:       network,
+		index:     index,
+		players:   players,
+		threshold: threshold,
+		bits:      bits,
+		degree:    (players - 1) / 2,
+		field:     nextPrime(new(big.Int).Lsh(big.NewInt(1), uint(bits))),
+	}
+	pub, si, keys, err := p.run()
+	if err != nil {
+		return nil, KeyShare{}, nil, err
+	}
+
+	share := KeyShare{si: si, Index: index, Players: players, Threshold: threshold}
+	if cache {
+		share.get2DeltaSi(int64(players))
+	}
+	return pub, share, keys, nil
+}
+
+type dkgPlayer struct {
+	rnd       io.Reader
+	net       Network
+	index     uint
+	players   uint
+	threshold uint
+	bits      int
+	degree    uint     // degree of the polynomials of the BGW protocol.
+	field     *big.Int // prime modulus of the BGW protocol, larger than N.
+}
+
+func (pl *dkgPlayer) run() (*rsa.PublicKey, *big.Int, *VerificationKeys, error) {
+	for {
+		n, p, q, err := pl.generateModulus()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		d, ok, err := pl.computeExponent(n, p, q)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !ok {
+			// e divides φ(N), start again with another modulus.
+			continue
+		}
+		si, keys, err := pl.reshare(n, d)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return &rsa.PublicKey{N: n, E: dkgPublicExponent}, si, keys, nil
+	}
+}
+
+// generateModulus returns a modulus N = pq, along with the additive shares of
+// p and q of the player.
+func (pl *dkgPlayer) generateModulus() (n, p, q *big.Int, err error) {
+	for {
+		ps := make([]*big.Int, dkgBatchSize)
+		qs := make([]*big.Int, dkgBatchSize)
+		for c := range ps {
+			if ps[c], err = pl.primeShare(pl.bits / 2); err != nil {
+				return nil, nil, nil, err
+			}
+			if qs[c], err = pl.primeShare(pl.bits - pl.bits/2); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+
+		// BGW: players share their shares of p and q with polynomials of
+		// degree t. The products of the sums of the shares are shares of N
+		// with polynomials of degree 2t < players.
+		out := make([][]*big.Int, pl.players)
+		for j := range out {
+			out[j] = make([]*big.Int, 0, 2*dkgBatchSize)
+		}
+		for c := range ps {
+			for _, secret := range []*big.Int{ps[c], qs[c]} {
+				poly, err := pl.randomPoly(secret, pl.degree, pl.field)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				for j := range out {
+					out[j] = append(out[j], evaluatePolynomial(poly, uint(j+1), pl.field))
+				}
+			}
+		}
+		in, err := pl.exchange(out)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		nShares := make([]*big.Int, dkgBatchSize)
+		for c := range nShares {
+			sp, sq := new(big.Int), new(big.Int)
+			for i := range in {
+				sp.Add(sp, in[i][2*c])
+				sq.Add(sq, in[i][2*c+1])
+			}
+			nShares[c] = sp.Mul(sp, sq).Mod(sp, pl.field)
+		}
+		in, err = pl.broadcast(nShares)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		points := 2*pl.degree + 1
+		lambdas := lagrangeAtZero(points, pl.field)
+		for c := range nShares {
+			n := new(big.Int)
+			for j := uint(0); j < points; j++ {
+				n.Add(n, new(big.Int).Mul(lambdas[j], in[j][c]))
+			}
+			n.Mod(n, pl.field)
+			if n.BitLen() != pl.bits || hasSmallFactor(n) {
+				continue
+			}
+			ok, err := pl.isBiprime(n, ps[c], qs[c])
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if ok {
+				return n, ps[c], qs[c], nil
+			}
+		}
+	}
+}
+
+// primeShare returns an additive share of a prime of the given bit length.
+// The sum of the shares lies in [3·2^(length-2), 2^length), and it is congruent
+// to 3 mod 4, as the share of the first player is 3 mod 4 and the others 0.
+func (pl *dkgPlayer) primeShare(length int) (*big.Int, error) {
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(length-2-bits.Len(pl.players)))
+	s, err := rand.Int(pl.rnd, bound)
+	if err != nil {
+		return nil, errors.New("rsa_threshold: dkg: unable to generate a random share")
+	}
+	s.Rsh(s, 2).Lsh(s, 2)
+	if pl.index == 1 {
+		s.Add(s, big.NewInt(3))
+		offset := new(big.Int).Lsh(big.NewInt(3), uint(length-2))
+		s.Add(s, offset)
+	}
+	return s, nil
+}
+
+// isBiprime runs the distributed biprimality test of Boneh and Franklin,
+// which requires p = q = 3 mod 4. Given a base g with Jacobi symbol 1, the
+// player with index 1 computes g^((N-p_1-q_1+1)/4) and the others compute
+// g^((p_i+q_i)/4); N is the product of two primes if the quotient of these
+// values is ±1 mod N. A random modulus is rejected with the first base with
+// overwhelming probability, so the remaining bases are tested afterwards.
+func (pl *dkgPlayer) isBiprime(n, p, q *big.Int) (bool, error) {
+	exp := new(big.Int).Add(p, q)
+	if pl.index == 1 {
+		exp.Sub(n, exp).Add(exp, big.NewInt(1))
+	}
+	exp.Rsh(exp, 2)
+
+	one := big.NewInt(1)
+	minusOne := new(big.Int).Sub(n, one)
+	counter := uint32(0)
+	for _, rounds := range []int{1, dkgBiprimalityRounds - 1} {
+		bases := make([]*big.Int, rounds)
+		vs := make([]*big.Int, rounds)
+		for k := range bases {
+			for {
+				bases[k] = publicCoin("biprimality", n, counter)
+				counter++
+				if big.Jacobi(bases[k], n) == 1 {
+					break
+				}
+			}
+			vs[k] = new(big.Int).Exp(bases[k], exp, n)
+		}
+
+		in, err := pl.broadcast(vs)
+		if err != nil {
+			return false, err
+		}
+		for k := range bases {
+			den := big.NewInt(1)
+			for i := 1; i < len(in); i++ {
+				den.Mul(den, in[i][k]).Mod(den, n)
+			}
+			if den.ModInverse(den, n) == nil {
+				return false, nil
+			}
+			v := den.Mul(den, in[0][k]).Mod(den, n)
+			if v.Cmp(one) != 0 && v.Cmp(minusOne) != 0 {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// computeExponent returns an additive share of the private exponent d, using
+// the inversion for small public exponents of Boneh and Franklin. It returns
+// false if the public exponent is not invertible modulo φ(N).
+func (pl *dkgPlayer) computeExponent(n, p, q *big.Int) (*big.Int, bool, error) {
+	// φ(N) = N - p - q + 1 is additively shared.
+	phi := new(big.Int).Add(p, q)
+	phi.Neg(phi)
+	if pl.index == 1 {
+		phi.Add(phi, n).Add(phi, big.NewInt(1))
+	}
+	e := big.NewInt(dkgPublicExponent)
+	in, err := pl.broadcast([]*big.Int{new(big.Int).Mod(phi, e)})
+	if err != nil {
+		return nil, false, err
+	}
+	zeta := new(big.Int)
+	for i := range in {
+		zeta.Add(zeta, in[i][0])
+	}
+	// ζ = -φ(N)^{-1} mod e, so that d = (1 + ζφ(N))/e is an integer and
+	// ed = 1 mod φ(N).
+	if zeta.ModInverse(zeta.Mod(zeta, e), e) == nil {
+		return nil, false, nil
+	}
+	zeta.Sub(e, zeta)
+
+	// d_i = ⌊ζφ_i/e⌋, plus 1 in the numerator for the first player. The sum
+	// of the shares is d - r, where 0 <= r < players.
+	d := new(big.Int).Mul(zeta, phi)
+	if pl.index == 1 {
+		d.Add(d, big.NewInt(1))
+	}
+	d.Div(d, e)
+
+	// The players find r by signing a public message x.
+	x := publicCoin("trial", n, 0)
+	y := new(big.Int).Exp(x, d, n)
+	if y == nil {
+		return nil, false, errors.New("rsa_threshold: dkg: no mod inverse")
+	}
+	in, err = pl.broadcast([]*big.Int{y})
+	if err != nil {
+		return nil, false, err
+	}
+	y = big.NewInt(1)
+	for i := range in {
+		y.Mul(y, in[i][0]).Mod(y, n)
+	}
+	var ye big.Int
+	for r := uint(0); r < pl.players; r++ {
+		if ye.Exp(y, e, n).Cmp(x) == 0 {
+			if pl.index == 1 {
+				d.Add(d, big.NewInt(int64(r)))
+			}
+			return d, true, nil
+		}
+		y.Mul(y, x).Mod(y, n)
+	}
+	return nil, false, errors.New("rsa_threshold: dkg: inconsistent shares of the private exponent")
+}
+
+// reshare converts the additive shares d_i of the private exponent into
+// shares s_j = Σ_i f_i(j) of a polynomial of degree threshold-1 over the
+// integers, with f_i(0) = d_i. The coefficients are chosen in [0, ∆2^{b+κ})
+// so that fewer than threshold shares reveal nothing about d. As d > 0, all
+// the shares are positive.
+//
+// The players broadcast the commitments V^{a_k} to the coefficients of their
+// polynomials, for a random square V derived from N, and check that
+// V^{f_i(j)} = Π_k C_{i,k}^{j^k} for the shares f_i(j) they receive, and that
+// (Π_i C_{i,0})^e = V. It returns the share of the player and the
+// verification keys V^{s_j} = Π_i Π_k C_{i,k}^{j^k} of all the players.
+func (pl *dkgPlayer) reshare(n, d *big.Int) (*big.Int, *VerificationKeys, error) {
+	bound := calculateDelta(int64(pl.players))
+	bound.Lsh(bound, uint(pl.bits+dkgStatisticalSecurity))
+	poly := make([]*big.Int, pl.threshold)
+	poly[0] = d
+	for k := 1; k < len(poly); k++ {
+		var err error
+		poly[k], err = rand.Int(pl.rnd, bound)
+		if err != nil {
+			return nil, nil, errors.New("rsa_threshold: dkg: unable to generate a random coefficient")
+		}
+	}
+
+	v := publicCoin("verification", n, 0)
+	v.Mul(v, v).Mod(v, n)
+	commitments := make([]*big.Int, len(poly))
+	for k := range poly {
+		commitments[k] = new(big.Int).Exp(v, poly[k], n)
+	}
+	coms, err := pl.broadcast(commitments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make([][]*big.Int, pl.players)
+	for j := range out {
+		out[j] = []*big.Int{evaluatePolynomial(poly, uint(j+1), nil)}
+	}
+	in, err := pl.exchange(out)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	si := new(big.Int)
+	d0 := big.NewInt(1)
+	keys := &VerificationKeys{V: v, Vi: make([]*big.Int, pl.players), Threshold: pl.threshold}
+	for j := range keys.Vi {
+		keys.Vi[j] = big.NewInt(1)
+	}
+	for i := range in {
+		got := new(big.Int).Exp(v, in[i][0], n)
+		if got == nil || got.Cmp(evaluateCommitments(coms[i], pl.index, n)) != 0 {
+			return nil, nil, fmt.Errorf("rsa_threshold: dkg: invalid share from player %d", i+1)
+		}
+		si.Add(si, in[i][0])
+		d0.Mul(d0, coms[i][0]).Mod(d0, n)
+		for j := range keys.Vi {
+			vj := evaluateCommitments(coms[i], uint(j+1), n)
+			keys.Vi[j].Mul(keys.Vi[j], vj).Mod(keys.Vi[j], n)
+		}
+	}
+	if d0.Exp(d0, big.NewInt(dkgPublicExponent), n).Cmp(v) != 0 {
+		return nil, nil, errors.New("rsa_threshold: dkg: commitments do not match the private exponent")
+	}
+	if si.Sign() <= 0 {
+		return nil, nil, errors.New("rsa_threshold: dkg: invalid share of the private exponent")
+	}
+	return si, keys, nil
+}
+
+// randomPoly returns the coefficients of a random polynomial of the given
+// degree modulo m, with secret as constant term.
+func (pl *dkgPlayer) randomPoly(secret *big.Int, degree uint, m *big.Int) ([]*big.Int, error) {
+	poly := make([]*big.Int, degree+1)
+	poly[0] = secret
+	for k := 1; k < len(poly); k++ {
+		var err error
+		poly[k], err = rand.Int(pl.rnd, m)
+		if err != nil {
+			return nil, errors.New("rsa_threshold: dkg: unable to generate a random coefficient")
+		}
+	}
+	return poly, nil
+}
+
+// exchange sends out[j-1] to the player with index j, and returns the values
+// received from every player, indexed by index-1, including out[index-1].
+func (pl *dkgPlayer) exchange(out [][]*big.Int) ([][]*big.Int, error) {
+	for j := uint(1); j <= pl.players; j++ {
+		if j == pl.index {
+			continue
+		}
+		if err := pl.net.Send(j, marshalInts(out[j-1])); err != nil {
+			return nil, err
+		}
+	}
+	in := make([][]*big.Int, pl.players)
+	in[pl.index-1] = out[pl.index-1]
+	for i := uint(1); i <= pl.players; i++ {
+		if i == pl.index {
+			continue
+		}
+		msg, err := pl.net.Receive(i)
+		if err != nil {
+			return nil, err
+		}
+		in[i-1], err = unmarshalInts(msg, len(out[i-1]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return in, nil
+}
+
+// broadcast sends the values to every player, and returns the values sent by
+// every player, indexed by index-1.
+func (pl *dkgPlayer) broadcast(values []*big.Int) ([][]*big.Int, error) {
+	out := make([][]*big.Int, pl.players)
+	for j := range out {
+		out[j] = values
+	}
+	return pl.exchange(out)
+}
+
+// marshalInts encodes integers as a sign byte, followed by the length of the
+// absolute value as a big-endian uint32 and the absolute value.
+func marshalInts(values []*big.Int) []byte {
+	var out []byte
+	for _, v := range values {
+		abs := v.Bytes()
+		sign := byte(0)
+		if v.Sign() < 0 {
+			sign = 1
+		}
+		out = append(out, sign)
+		out = binary.BigEndian.AppendUint32(out, uint32(len(abs)))
+		out = append(out, abs...)
+	}
+	return out
+}
+
+func unmarshalInts(data []byte, count int) ([]*big.Int, error) {
+	values := make([]*big.Int, count)
+	for i := range values {
+		if len(data) < 5 || data[0] > 1 {
+			return nil, errors.New("rsa_threshold: dkg: malformed message")
+		}
+		length := binary.BigEndian.Uint32(data[1:5])
+		if uint32(len(data)-5) < length {
+			return nil, errors.New("rsa_threshold: dkg: malformed message")
+		}
+		values[i] = new(big.Int).SetBytes(data[5 : 5+length])
+		if data[0] == 1 {
+			values[i].Neg(values[i])
+		}
+		data = data[5+length:]
+	}
+	if len(data) != 0 {
+		return nil, errors.New("rsa_threshold: dkg: malformed message")
+	}
+	return values, nil
+}
+
+// evaluatePolynomial evaluates the polynomial with coefficients a at x using
+// Horner's method, reducing modulo m if m is not nil.
+func evaluatePolynomial(a []*big.Int, x uint, m *big.Int) *big.Int {
+	bx := new(big.Int).SetUint64(uint64(x))
+	sum := new(big.Int)
+	for k := len(a) - 1; k >= 0; k-- {
+		sum.Mul(sum, bx).Add(sum, a[k])
+		if m != nil {
+			sum.Mod(sum, m)
+		}
+	}
+	return sum
+}
+
+// lagrangeAtZero returns the Lagrange coefficients modulo the prime m that
+// interpolate at zero a polynomial from its values at 1, ..., points.
+func lagrangeAtZero(points uint, m *big.Int) []*big.Int {
+	lambdas := make([]*big.Int, points)
+	for j := uint(1); j <= points; j++ {
+		num, den := big.NewInt(1), big.NewInt(1)
+		for k := uint(1); k <= points; k++ {
+			if k == j {
+				continue
+			}
+			num.Mul(num, big.NewInt(int64(k)))
+			den.Mul(den, big.NewInt(int64(k)-int64(j)))
+		}
+		den.Mod(den, m).ModInverse(den, m)
+		lambdas[j-1] = num.Mul(num, den).Mod(num, m)
+	}
+	return lambdas
+}
+
+// publicCoin derives an integer modulo n from the label, n and the counter,
+// which all the players compute identically.
+func publicCoin(label string, n *big.Int, counter uint32) *big.Int {
+	var out []byte
+	for block := uint32(0); len(out) < (n.BitLen()+7)/8+16; block++ {
+		h := sha256.New()
+		_, _ = h.Write([]byte("rsa_threshold dkg " + label))
+		_, _ = h.Write(n.Bytes())
+		_ = binary.Write(h, binary.BigEndian, [2]uint32{counter, block})
+		out = h.Sum(out)
+	}
+	v := new(big.Int).SetBytes(out)
+	return v.Mod(v, n)
+}
+
+// primeGroup is a set of small primes whose product fits in an uint64.
+type primeGroup struct {
+	product uint64
+	primes  []uint64
+}
+
+var (
+	smallPrimesOnce sync.Once
+	// smallPrimeGroups are the odd primes smaller than dkgSieveBound.
+	smallPrimeGroups []primeGroup
+)
+
+// hasSmallFactor returns whether n is divisible by a prime smaller than
+// dkgSieveBound.
+func hasSmallFactor(n *big.Int) bool {
+	smallPrimesOnce.Do(func() {
+		composite := make([]bool, dkgSieveBound)
+		group := primeGroup{product: 1}
+		for i := uint64(3); i < dkgSieveBound; i += 2 {
+			if composite[i] {
+				continue
+			}
+			for j := i * i; j < dkgSieveBound; j += 2 * i {
+				composite[j] = true
+			}
+			if hi, _ := bits.Mul64(group.product, i); hi != 0 {
+				smallPrimeGroups = append(smallPrimeGroups, group)
+				group = primeGroup{product: 1}
+			}
+			group.primes = append(group.primes, i)
+			group.product *= i
+		}
+		smallPrimeGroups = append(smallPrimeGroups, group)
+	})
+
+	if n.Bit(0) == 0 {
+		return true
+	}
+	var prod, rem big.Int
+	for _, group := range smallPrimeGroups {
+		r := rem.Mod(n, prod.SetUint64(group.product)).Uint64()
+		for _, q := range group.primes {
+			if r%q == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nextPrime returns the smallest prime larger than or equal to n.
+func nextPrime(n *big.Int) *big.Int {
+	p := new(big.Int).SetBit(n, 0, 1)
+	for !p.ProbablyPrime(20) {
+		p.Add(p, big.NewInt(2))
+	}
+	return p
+}
@@ -0,0 +1,255 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+)
+
+// localNetwork connects players running in the same process.
+type localNetwork struct {
+	index uint
+	links [][]chan []byte // links[from-1][to-1]
+}
+
+func (n *localNetwork) Send(to uint, msg []byte) error {
+	n.links[n.index-1][to-1] <- msg
+	return nil
+}
+
+func (n *localNetwork) Receive(from uint) ([]byte, error) {
+	return <-n.links[from-1][n.index-1], nil
+}
+
+func runDistributedKeyGen(t testing.TB, players, threshold uint, bits int) (*rsa.PublicKey, []KeyShare, *VerificationKeys) {
+	links := make([][]chan []byte, players)
+	for i := range links {
+		links[i] = make([]chan []byte, players)
+		for j := range links[i] {
+			links[i][j] = make(chan []byte, 4)
+		}
+	}
+
+	type result struct {
+		pub   *rsa.PublicKey
+		share KeyShare
+		keys  *VerificationKeys
+		err   error
+	}
+	results := make([]chan result, players)
+	for i := range results {
+		results[i] = make(chan result, 1)
+		net := &localNetwork{index: uint(i + 1), links: links}
+		go func(i int) {
+			pub, share, keys, err := DistributedKeyGen(rand.Reader, net, uint(i+1), players, threshold, bits, i%2 == 0)
+			results[i] <- result{pub, share, keys, err}
+		}(i)
+	}
+
+	var pub *rsa.PublicKey
+	var keys *VerificationKeys
+	shares := make([]KeyShare, players)
+	for i := range results {
+		r := <-results[i]
+		test.CheckNoErr(t, r.err, "distributed key generation failed")
+		if pub == nil {
+			pub, keys = r.pub, r.keys
+		} else if pub.N.Cmp(r.pub.N) != 0 || pub.E != r.pub.E {
+			t.Fatal("players disagree on the public key")
+		}
+		for j := range keys.Vi {
+			if keys.Vi[j].Cmp(r.keys.Vi[j]) != 0 {
+				t.Fatal("players disagree on the verification keys")
+			}
+		}
+		shares[i] = r.share
+	}
+	return pub, shares, keys
+}
+
+func TestDistributedKeyGen(t *testing.T) {
+	// [Warning]: this is only for tests, use a secure bitlen above 2048 bits.
+	const bits = 512
+	const algo = crypto.SHA256
+	for _, v := range []struct{ players, threshold uint }{{3, 1}, {3, 2}, {4, 3}, {5, 3}} {
+		pub, shares, keys := runDistributedKeyGen(t, v.players, v.threshold, bits)
+		if pub.N.BitLen() != bits {
+			test.ReportError(t, pub.N.BitLen(), bits, v.players, v.threshold)
+		}
+
+		msg := []byte("hello")
+		msgPH, err := PadHash(&PKCS1v15Padder{}, algo, pub, msg)
+		test.CheckNoErr(t, err, "padding failed")
+		h := algo.New()
+		h.Write(msg)
+		hashed := h.Sum(nil)
+
+		for _, set := range [][]KeyShare{shares[:v.threshold], shares[v.players-v.threshold:]} {
+			signShares := make([]SignShare, len(set))
+			for i := range set {
+				signShares[i], err = set[i].Sign(rand.Reader, pub, msgPH, true)
+				test.CheckNoErr(t, err, "sign failed")
+			}
+			sig, err := CombineSignShares(pub, signShares, msgPH)
+			test.CheckNoErr(t, err, "combine failed")
+			err = rsa.VerifyPKCS1v15(pub, algo, hashed, sig)
+			test.CheckNoErr(t, err, "verification failed")
+		}
+
+		// The proofs of correctness are not sound without safe primes.
+		_, err = shares[0].SignWithProof(rand.Reader, pub, keys, msgPH, true)
+		test.CheckOk(errors.Is(err, errUnsafeModulus), "SignWithProof should fail without safe primes", t)
+		signShare, err := shares[0].Sign(rand.Reader, pub, msgPH, true)
+		test.CheckNoErr(t, err, "sign failed")
+		err = VerifySignShare(pub, keys, signShare, msgPH)
+		test.CheckOk(errors.Is(err, errUnsafeModulus), "VerifySignShare should fail without safe primes", t)
+	}
+}
+
+// runDKGReshare runs the resharing step of DistributedKeyGen on a key of
+// crypto/rsa, whose private exponent plus extra is split into additive
+// shares. If tamper is not nil, it modifies the share sent by the player with
+// index 2 to the player with index 1.
+func runDKGReshare(t *testing.T, players, threshold uint, extra int64, tamper func(*big.Int)) (*big.Int, []*big.Int, []*VerificationKeys, []error) {
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	test.CheckNoErr(t, err, "failed to create key")
+	d := make([]*big.Int, players)
+	d[0] = new(big.Int).Set(key.D)
+	for i := 1; i < len(d); i++ {
+		d[i], _ = rand.Int(rand.Reader, key.N)
+		d[0].Sub(d[0], d[i])
+	}
+	d[players-1].Add(d[players-1], big.NewInt(extra))
+
+	links := make([][]chan []byte, players)
+	for i := range links {
+		links[i] = make([]chan []byte, players)
+		for j := range links[i] {
+			links[i][j] = make(chan []byte, 4)
+		}
+	}
+	shares := make([]*big.Int, players)
+	keys := make([]*VerificationKeys, players)
+	errs := make([]error, players)
+	done := make(chan struct{})
+	for i := range shares {
+		var net Network = &localNetwork{index: uint(i + 1), links: links}
+		if i == 1 && tamper != nil {
+			net = &tamperingNetwork{Network: net, tamper: tamper}
+		}
+		pl := &dkgPlayer{rnd: rand.Reader, net: net, index: uint(i + 1), players: players, threshold: threshold, bits: key.N.BitLen()}
+		go func(i int) {
+			shares[i], keys[i], errs[i] = pl.reshare(key.N, d[i])
+			done <- struct{}{}
+		}(i)
+	}
+	for range shares {
+		<-done
+	}
+	return key.N, shares, keys, errs
+}
+
+// tamperingNetwork modifies the second message sent to the player with
+// index 1, which is the share of the resharing.
+type tamperingNetwork struct {
+	Network
+	tamper func(*big.Int)
+	sent   int
+}
+
+func (n *tamperingNetwork) Send(to uint, msg []byte) error {
+	if to == 1 {
+		n.sent++
+		if n.sent == 2 {
+			v, err := unmarshalInts(msg, 1)
+			if err != nil {
+				return err
+			}
+			n.tamper(v[0])
+			msg = marshalInts(v)
+		}
+	}
+	return n.Network.Send(to, msg)
+}
+
+func TestDistributedKeyGenReshare(t *testing.T) {
+	const players, threshold = 4, 3
+	n, shares, keys, errs := runDKGReshare(t, players, threshold, 0, nil)
+	for i := range shares {
+		test.CheckNoErr(t, errs[i], "reshare failed")
+		for j := range keys[i].Vi {
+			if keys[i].Vi[j].Cmp(keys[0].Vi[j]) != 0 {
+				t.Fatal("players disagree on the verification keys")
+			}
+		}
+		if want := new(big.Int).Exp(keys[0].V, shares[i], n); keys[0].Vi[i].Cmp(want) != 0 {
+			test.ReportError(t, keys[0].Vi[i], want, i)
+		}
+	}
+
+	_, _, _, errs = runDKGReshare(t, players, threshold, 0, func(v *big.Int) { v.Add(v, big.NewInt(1)) })
+	test.CheckIsErr(t, errs[0], "should fail with an inconsistent share")
+	for i := 1; i < players; i++ {
+		test.CheckNoErr(t, errs[i], "reshare failed")
+	}
+
+	_, _, _, errs = runDKGReshare(t, players, threshold, 1, nil)
+	for i := range errs {
+		test.CheckIsErr(t, errs[i], "should fail with a wrong private exponent")
+	}
+}
+
+func TestDistributedKeyGenParams(t *testing.T) {
+	net := &localNetwork{}
+	for _, v := range []struct {
+		index, players, threshold uint
+		bits                      int
+	}{
+		{1, 2, 2, 512}, // too few players
+		{0, 3, 2, 512}, // invalid index
+		{4, 3, 2, 512}, // invalid index
+		{1, 3, 4, 512}, // invalid threshold
+		{1, 3, 2, 32},  // too small modulus
+	} {
+		_, _, _, err := DistributedKeyGen(rand.Reader, net, v.index, v.players, v.threshold, v.bits, false)
+		test.CheckIsErr(t, err, "should fail with invalid parameters")
+	}
+}
+
+func TestMarshalInts(t *testing.T) {
+	values := []*big.Int{big.NewInt(0), big.NewInt(-5), new(big.Int).Lsh(big.NewInt(1), 300)}
+	enc := marshalInts(values)
+	got, err := unmarshalInts(enc, len(values))
+	test.CheckNoErr(t, err, "unmarshal failed")
+	for i := range values {
+		if got[i].Cmp(values[i]) != 0 {
+			test.ReportError(t, got[i], values[i], i)
+		}
+	}
+	_, err = unmarshalInts(enc[:len(enc)-1], len(values))
+	test.CheckIsErr(t, err, "should fail with a truncated message")
+	_, err = unmarshalInts(enc, len(values)-1)
+	test.CheckIsErr(t, err, "should fail with trailing data")
+}
+
+func TestHasSmallFactor(t *testing.T) {
+	for _, v := range []struct {
+		n    int64
+		want bool
+	}{{65521 * 65537, true}, {65537 * 65539, false}, {1 << 40, true}, {3 * 1000003, true}} {
+		if got := hasSmallFactor(big.NewInt(v.n)); got != v.want {
+			test.ReportError(t, got, v.want, v.n)
+		}
+	}
+}
+
+func BenchmarkDistributedKeyGen(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		runDistributedKeyGen(b, 3, 2, 1024)
+	}
+}
@@ -12,6 +12,13 @@ import (
 	"sync"
 )
 
+// Flags of the twoDeltaSiNil byte of the encoding of a KeyShare.
+const (
+	keyShareHasTwoDeltaSi      = 1 << 0
+	keyShareSiNegative         = 1 << 1
+	keyShareTwoDeltaSiNegative = 1 << 2
+)
+
 // KeyShare represents a portion of the key. It can only be used to generate SignShare's. During the dealing phase (when Deal is called), one KeyShare is generated per player.
 type KeyShare struct {
 	si *big.Int
@@ -33,7 +40,8 @@ func (kshare KeyShare) String() string {
 func (kshare *KeyShare) MarshalBinary() ([]byte, error) {
 	// The encoding format is
 	// | Players: uint16 | Threshold: uint16 | Index: uint16 | siLen: uint16 | si: []byte | twoDeltaSiNil: bool | twoDeltaSiLen: uint16 | twoDeltaSi: []byte |
-	// with all values in big-endian.
+	// with all values in big-endian. si and twoDeltaSi are stored as absolute values, and the twoDeltaSiNil byte also
+	// holds the flags siNegative and twoDeltaSiNegative, which are only set for shares obtained by resharing.
 
 	if kshare.Players > math.MaxUint16 {
 		return nil, fmt.Errorf("rsa_threshold: keyshare marshall: Players is too big to fit in a uint16")
@@ -87,7 +95,13 @@ func (kshare *KeyShare) MarshalBinary() ([]byte, error) {
 	copy(out[8:8+siLength], siBytes)
 
 	if twoDeltaSiBytes != nil {
-		out[8+siLength] = 1 // twoDeltaSiNil
+		out[8+siLength] = keyShareHasTwoDeltaSi // twoDeltaSiNil
+		if kshare.twoDeltaSi.Sign() < 0 {
+			out[8+siLength] |= keyShareTwoDeltaSiNegative
+		}
+	}
+	if kshare.si.Sign() < 0 {
+		out[8+siLength] |= keyShareSiNegative
 	}
 
 	binary.BigEndian.PutUint16(out[8+siLength+1:8+siLength+3], uint16(twoDeltaSiLen))
@@ -103,7 +117,7 @@ func (kshare *KeyShare) MarshalBinary() ([]byte, error) {
 func (kshare *KeyShare) UnmarshalBinary(data []byte) error {
 	// The encoding format is
 	// | Players: uint16 | Threshold: uint16 | Index: uint16 | siLen: uint16 | si: []byte | twoDeltaSiNil: bool | twoDeltaSiLen: uint16 | twoDeltaSi: []byte |
-	// with all values in big-endian.
+	// with all values in big-endian. See MarshalBinary for the flags held by the twoDeltaSiNil byte.
 	if len(data) < 6 {
 		return fmt.Errorf("rsa_threshold: keyshare unmarshalKeyShareTest failed: data length was too short for reading Players, Threshold, Index")
 	}
@@ -133,10 +147,13 @@ func (kshare *KeyShare) UnmarshalBinary(data []byte) error {
 	}
 
 	isNil := data[8+siLen]
+	if isNil&keyShareSiNegative != 0 {
+		si.Neg(si)
+	}
 
 	var twoDeltaSi *big.Int
 
-	if isNil != 0 {
+	if isNil&keyShareHasTwoDeltaSi != 0 {
 		if len(data[8+siLen+1:]) < 2 {
 			return fmt.Errorf("rsa_threshold: keyshare unmarshalKeyShareTest failed: data length was too short for reading twoDeltaSiLen length")
 		}
@@ -148,6 +165,9 @@ func (kshare *KeyShare) UnmarshalBinary(data []byte) error {
 		}
 
 		twoDeltaSi = new(big.Int).SetBytes(data[8+siLen+3 : 8+siLen+3+twoDeltaSiLen])
+		if isNil&keyShareTwoDeltaSiNegative != 0 {
+			twoDeltaSi.Neg(twoDeltaSi)
+		}
 	}
 
 	kshare.Players = uint(players)
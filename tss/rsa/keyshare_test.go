@@ -123,6 +123,14 @@ func TestMarshallKeyShare(t *testing.T) {
 		Players:    200,
 	}, t)
 
+	marshalTestKeyShare(KeyShare{
+		si:         big.NewInt(-10),
+		twoDeltaSi: big.NewInt(-20),
+		Index:      3,
+		Threshold:  2,
+		Players:    3,
+	}, t)
+
 	marshalTestKeyShare(KeyShare{
 		si:         big.NewInt(0),
 		twoDeltaSi: big.NewInt(0),
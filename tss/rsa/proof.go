@@ -0,0 +1,294 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+// proofChallengeBits is the bit length of the challenges of the proofs of
+// correctness of the SignShares.
+const proofChallengeBits = 128
+
+var errUnsafeModulus = errors.New("rsa_threshold: proofs of correctness require a modulus of two safe primes")
+
+// VerificationKeys are the public values used to check the proofs of
+// correctness attached to SignShares, as in the Protocol 1 of [1]. They are
+// generated along with the KeyShares, and published to the combiners. The
+// proofs are only sound if N is the product of two safe primes, so only the
+// VerificationKeys of NewVerificationKeys, and those obtained from them by
+// CombineReshares, can be used to check proofs; see VerifySignShare.
+type VerificationKeys struct {
+	V  *big.Int   // V is a random square modulo N.
+	Vi []*big.Int // Vi[i-1] = V^{s_i} is the verification key of the player with index i.
+
+	Threshold uint
+
+	// safePrimes is set if N is the product of two safe primes.
+	safePrimes bool
+}
+
+// NewVerificationKeys generates the verification keys of the KeyShares
+// returned by Deal, which must all be provided. It is run by the dealer, who
+// publishes the result. The key must come from GenerateKey, so that N is the
+// product of two safe primes.
+func NewVerificationKeys(randSource io.Reader, pub *rsa.PublicKey, shares []KeyShare) (*VerificationKeys, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("rsa_threshold: no key shares")
+	}
+	players := shares[0].Players
+	threshold := shares[0].Threshold
+	if uint(len(shares)) != players {
+		return nil, errors.New("rsa_threshold: all the key shares are required")
+	}
+
+	v, err := randomSquare(randSource, pub.N)
+	if err != nil {
+		return nil, err
+	}
+	keys := &VerificationKeys{V: v, Vi: make([]*big.Int, players), Threshold: threshold, safePrimes: true}
+	for _, share := range shares {
+		if share.Players != players || share.Threshold != threshold {
+			return nil, errors.New("rsa_threshold: key shares didn't have consistent players or threshold")
+		}
+		if share.Index < 1 || share.Index > players || keys.Vi[share.Index-1] != nil {
+			return nil, fmt.Errorf("rsa_threshold: invalid or repeated key share index: %d", share.Index)
+		}
+		keys.Vi[share.Index-1] = new(big.Int).Exp(v, share.si, pub.N)
+	}
+	return keys, nil
+}
+
+// randomSquare returns a random square modulo n of an invertible element.
+func randomSquare(randSource io.Reader, n *big.Int) (*big.Int, error) {
+	var gcd big.Int
+	for {
+		r, err := rand.Int(randSource, n)
+		if err != nil {
+			return nil, errors.New("rsa_threshold: unable to get random value")
+		}
+		if gcd.GCD(nil, nil, r, n).Cmp(big.NewInt(1)) == 0 {
+			return r.Mul(r, r).Mod(r, n), nil
+		}
+	}
+}
+
+// SignWithProof is like Sign, but it also attaches to the SignShare a proof
+// that it was correctly computed, which VerifySignShare checks using the
+// verification keys. randSource must not be nil. It fails with the
+// verification keys of DistributedKeyGen; see VerifySignShare.
+func (kshare *KeyShare) SignWithProof(randSource io.Reader, pub *rsa.PublicKey, keys *VerificationKeys, digest []byte, parallel bool) (SignShare, error) {
+	if randSource == nil {
+		return SignShare{}, errors.New("rsa_threshold: proofs require a random source")
+	}
+	if !keys.safePrimes {
+		return SignShare{}, errUnsafeModulus
+	}
+	if kshare.Index < 1 || kshare.Index > uint(len(keys.Vi)) {
+		return SignShare{}, fmt.Errorf("rsa_threshold: no verification key for index %d", kshare.Index)
+	}
+	share, err := kshare.Sign(randSource, pub, digest, parallel)
+	if err != nil {
+		return SignShare{}, err
+	}
+
+	// Proof that log_v(v_i) = log_x̃(x_i²), where x̃ = x^{4∆}:
+	// r ∈ [0, 2^{L(N)+2L1}), c = H(v, x̃, v_i, x_i², v^r, x̃^r) and z = s_i c + r.
+	// As shares obtained by resharing may be negative, r is shifted by
+	// 2^{L(N)+L1} so that z is always positive.
+	x := new(big.Int).SetBytes(digest)
+	xTilde := calculateXTilde(x, kshare.Players, pub.N)
+	xi2 := new(big.Int).Mul(share.xi, share.xi)
+	xi2.Mod(xi2, pub.N)
+
+	rBits := pub.N.BitLen()
+	if kshare.si.BitLen() > rBits {
+		rBits = kshare.si.BitLen()
+	}
+	bound := new(big.Int).Lsh(big.NewInt(1), uint(rBits+2*proofChallengeBits))
+	r, err := rand.Int(randSource, bound)
+	if err != nil {
+		return SignShare{}, errors.New("rsa_threshold: unable to get random value for the proof")
+	}
+	r.Add(r, new(big.Int).Lsh(big.NewInt(1), uint(rBits+proofChallengeBits)))
+	vr := new(big.Int).Exp(keys.V, r, pub.N)
+	xr := new(big.Int).Exp(xTilde, r, pub.N)
+
+	share.c = proofChallenge(pub.N, keys.V, xTilde, keys.Vi[kshare.Index-1], xi2, vr, xr)
+	share.z = new(big.Int).Mul(kshare.si, share.c)
+	share.z.Add(share.z, r)
+	return share, nil
+}
+
+// VerifySignShare checks the proof of correctness of a SignShare generated by
+// SignWithProof for the digest, and returns an error if it is invalid.
+//
+// The proof is sound only if N is the product of two safe primes, as for the
+// keys of GenerateKey: the squares modulo N then form a cyclic group with no
+// small subgroups. This does not hold for the keys of DistributedKeyGen, for
+// which a corrupted player could get an invalid SignShare accepted, so
+// VerifySignShare rejects their verification keys.
+func VerifySignShare(pub *rsa.PublicKey, keys *VerificationKeys, share SignShare, digest []byte) error {
+	if !keys.safePrimes {
+		return errUnsafeModulus
+	}
+	if share.c == nil || share.z == nil {
+		return errors.New("rsa_threshold: sign share has no proof")
+	}
+	if share.Players != uint(len(keys.Vi)) || share.Threshold != keys.Threshold {
+		return errors.New("rsa_threshold: sign share didn't have consistent players or threshold")
+	}
+	if share.Index < 1 || share.Index > share.Players {
+		return fmt.Errorf("rsa_threshold: no verification key for index %d", share.Index)
+	}
+	if share.xi.Sign() <= 0 || share.xi.Cmp(pub.N) >= 0 {
+		return errors.New("rsa_threshold: invalid sign share")
+	}
+
+	x := new(big.Int).SetBytes(digest)
+	xTilde := calculateXTilde(x, share.Players, pub.N)
+	xi2 := new(big.Int).Mul(share.xi, share.xi)
+	xi2.Mod(xi2, pub.N)
+	vi := keys.Vi[share.Index-1]
+
+	// v^r = v^z v_i^{-c} and x̃^r = x̃^z x_i^{-2c}.
+	vr, ok := expQuo(keys.V, share.z, vi, share.c, pub.N)
+	if !ok {
+		return errors.New("rsa_threshold: invalid sign share")
+	}
+	xr, ok := expQuo(xTilde, share.z, xi2, share.c, pub.N)
+	if !ok {
+		return errors.New("rsa_threshold: invalid sign share")
+	}
+	c := proofChallenge(pub.N, keys.V, xTilde, vi, xi2, vr, xr)
+	if c.Cmp(share.c) != 0 {
+		return fmt.Errorf("rsa_threshold: invalid sign share from index %d", share.Index)
+	}
+	return nil
+}
+
+// CombineVerifiedSignShares is like CombineSignShares, but it discards the
+// shares whose proof of correctness is invalid, as well as repeated shares.
+// It succeeds as long as at least threshold valid shares remain.
+func CombineVerifiedSignShares(pub *rsa.PublicKey, keys *VerificationKeys, shares []SignShare, msg []byte) (Signature, error) {
+	if !keys.safePrimes {
+		return nil, errUnsafeModulus
+	}
+	valid := make([]SignShare, 0, keys.Threshold)
+	seen := make(map[uint]bool, len(shares))
+	for _, share := range shares {
+		if uint(len(valid)) == keys.Threshold {
+			break
+		}
+		if seen[share.Index] || VerifySignShare(pub, keys, share, msg) != nil {
+			continue
+		}
+		seen[share.Index] = true
+		valid = append(valid, share)
+	}
+	if len(valid) == 0 || uint(len(valid)) < keys.Threshold {
+		return nil, errors.New("rsa_threshold: insufficient valid shares for the threshold")
+	}
+	return CombineSignShares(pub, valid, msg)
+}
+
+// calculateXTilde returns x̃ = x^{4∆} mod n.
+func calculateXTilde(x *big.Int, players uint, n *big.Int) *big.Int {
+	exp := calculateDelta(int64(players))
+	exp.Lsh(exp, 2)
+	return new(big.Int).Exp(x, exp, n)
+}
+
+// expQuo returns a^x b^{-y} mod n, and false if b is not invertible.
+func expQuo(a, x, b, y, n *big.Int) (*big.Int, bool) {
+	by := new(big.Int).Exp(b, y, n)
+	if by.ModInverse(by, n) == nil {
+		return nil, false
+	}
+	ax := new(big.Int).Exp(a, x, n)
+	return ax.Mul(ax, by).Mod(ax, n), true
+}
+
+// proofChallenge hashes the values into an integer of proofChallengeBits bits.
+func proofChallenge(values ...*big.Int) *big.Int {
+	h := sha256.New()
+	_, _ = h.Write([]byte("rsa_threshold proof"))
+	for _, v := range values {
+		b := v.Bytes()
+		_ = binary.Write(h, binary.BigEndian, uint32(len(b)))
+		_, _ = h.Write(b)
+	}
+	return new(big.Int).SetBytes(h.Sum(nil)[:proofChallengeBits/8])
+}
+
+// MarshalBinary encodes VerificationKeys into a byte array in a format readable by UnmarshalBinary.
+func (keys *VerificationKeys) MarshalBinary() ([]byte, error) {
+	// | Players: uint16 | Threshold: uint16 | SafePrimes: uint8 | vLen: uint16 | v: []byte | (viLen: uint16 | vi: []byte)* |
+	// with all values in big-endian.
+	if len(keys.Vi) > math.MaxUint16 {
+		return nil, fmt.Errorf("rsa_threshold: verification keys marshall: Players is too big to fit in a uint16")
+	}
+	if keys.Threshold > math.MaxUint16 {
+		return nil, fmt.Errorf("rsa_threshold: verification keys marshall: Threshold is too big to fit in a uint16")
+	}
+
+	out := make([]byte, 5)
+	binary.BigEndian.PutUint16(out[0:2], uint16(len(keys.Vi)))
+	binary.BigEndian.PutUint16(out[2:4], uint16(keys.Threshold))
+	if keys.safePrimes {
+		out[4] = 1
+	}
+	for _, v := range append([]*big.Int{keys.V}, keys.Vi...) {
+		vBytes := v.Bytes()
+		if len(vBytes) > math.MaxUint16 {
+			return nil, fmt.Errorf("rsa_threshold: verification keys marshall: key is too big to fit it's length in a uint16")
+		}
+		out = binary.BigEndian.AppendUint16(out, uint16(len(vBytes)))
+		out = append(out, vBytes...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary recovers VerificationKeys from a slice of bytes, or returns an error if the encoding is invalid.
+func (keys *VerificationKeys) UnmarshalBinary(data []byte) error {
+	// | Players: uint16 | Threshold: uint16 | SafePrimes: uint8 | vLen: uint16 | v: []byte | (viLen: uint16 | vi: []byte)* |
+	// with all values in big-endian.
+	if len(data) < 5 {
+		return fmt.Errorf("rsa_threshold: verification keys unmarshall failed: data length was too short for reading Players, Threshold and SafePrimes")
+	}
+	players := binary.BigEndian.Uint16(data[0:2])
+	threshold := binary.BigEndian.Uint16(data[2:4])
+	if data[4] > 1 {
+		return fmt.Errorf("rsa_threshold: verification keys unmarshall failed: invalid SafePrimes: %d", data[4])
+	}
+	safePrimes := data[4] == 1
+	data = data[5:]
+
+	values := make([]*big.Int, int(players)+1)
+	for i := range values {
+		if len(data) < 2 {
+			return fmt.Errorf("rsa_threshold: verification keys unmarshall failed: data length was too short for reading a key length")
+		}
+		vLen := binary.BigEndian.Uint16(data[0:2])
+		if vLen == 0 || len(data[2:]) < int(vLen) {
+			return fmt.Errorf("rsa_threshold: verification keys unmarshall failed: invalid key length: %d", vLen)
+		}
+		values[i] = new(big.Int).SetBytes(data[2 : 2+vLen])
+		data = data[2+vLen:]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("rsa_threshold: verification keys unmarshall failed: unexpected trailing data")
+	}
+
+	keys.V = values[0]
+	keys.Vi = values[1:]
+	keys.Threshold = uint(threshold)
+	keys.safePrimes = safePrimes
+	return nil
+}
@@ -0,0 +1,121 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+)
+
+func TestVerifiedSignShares(t *testing.T) {
+	const players = 5
+	const threshold = 3
+	// [Warning]: this is only for tests, use a secure bitlen above 2048 bits.
+	const bits = 512
+	const algo = crypto.SHA256
+
+	key, err := GenerateKey(rand.Reader, bits)
+	test.CheckNoErr(t, err, "failed to create key")
+	pub := &key.PublicKey
+	shares, err := Deal(rand.Reader, players, threshold, key, false)
+	test.CheckNoErr(t, err, "deal failed")
+	keys, err := NewVerificationKeys(rand.Reader, pub, shares)
+	test.CheckNoErr(t, err, "failed to create verification keys")
+
+	msg := []byte("hello")
+	msgPH, err := PadHash(&PKCS1v15Padder{}, algo, pub, msg)
+	test.CheckNoErr(t, err, "padding failed")
+	h := algo.New()
+	h.Write(msg)
+	hashed := h.Sum(nil)
+
+	signShares := make([]SignShare, players)
+	for i := range shares {
+		signShares[i], err = shares[i].SignWithProof(rand.Reader, pub, keys, msgPH, true)
+		test.CheckNoErr(t, err, "sign failed")
+		test.CheckNoErr(t, VerifySignShare(pub, keys, signShares[i], msgPH), "invalid sign share")
+	}
+
+	// Invalid shares are identified.
+	other := []byte("other")
+	otherPH, _ := PadHash(&PKCS1v15Padder{}, algo, pub, other)
+	test.CheckIsErr(t, VerifySignShare(pub, keys, signShares[0], otherPH), "should fail with another digest")
+	unproven, _ := shares[0].Sign(rand.Reader, pub, msgPH, true)
+	test.CheckIsErr(t, VerifySignShare(pub, keys, unproven, msgPH), "should fail without proof")
+	forged := signShares[1]
+	forged.xi = new(big.Int).Mul(forged.xi, big.NewInt(2))
+	forged.xi.Mod(forged.xi, pub.N)
+	test.CheckIsErr(t, VerifySignShare(pub, keys, forged, msgPH), "should fail with a forged share")
+	stolen := signShares[2]
+	stolen.Index = 4
+	test.CheckIsErr(t, VerifySignShare(pub, keys, stolen, msgPH), "should fail with another index")
+
+	// Bad and repeated shares are discarded while enough valid shares remain.
+	set := []SignShare{signShares[0], forged, signShares[0], unproven, stolen, signShares[2], signShares[4]}
+	sig, err := CombineVerifiedSignShares(pub, keys, set, msgPH)
+	test.CheckNoErr(t, err, "combine failed")
+	test.CheckNoErr(t, rsa.VerifyPKCS1v15(pub, algo, hashed, sig), "verification failed")
+
+	set = []SignShare{signShares[0], forged, signShares[0], stolen, signShares[4]}
+	_, err = CombineVerifiedSignShares(pub, keys, set, msgPH)
+	test.CheckIsErr(t, err, "should fail with too few valid shares")
+
+	// Proofs and verification keys survive encoding.
+	enc, err := signShares[3].MarshalBinary()
+	test.CheckNoErr(t, err, "marshal failed")
+	var decoded SignShare
+	test.CheckNoErr(t, decoded.UnmarshalBinary(enc), "unmarshal failed")
+	enc, err = keys.MarshalBinary()
+	test.CheckNoErr(t, err, "marshal failed")
+	var decodedKeys VerificationKeys
+	test.CheckNoErr(t, decodedKeys.UnmarshalBinary(enc), "unmarshal failed")
+	test.CheckNoErr(t, VerifySignShare(pub, &decodedKeys, decoded, msgPH), "invalid decoded sign share")
+	test.CheckIsErr(t, decoded.UnmarshalBinary(enc[:len(enc)-1]), "should fail with a truncated share")
+	test.CheckIsErr(t, decodedKeys.UnmarshalBinary(enc[:len(enc)-1]), "should fail with truncated keys")
+
+	// Verification keys that do not come from NewVerificationKeys are
+	// rejected, even once encoded.
+	unsafeKeys := *keys
+	unsafeKeys.safePrimes = false
+	test.CheckIsErr(t, VerifySignShare(pub, &unsafeKeys, signShares[0], msgPH), "should fail without safe primes")
+	enc, err = unsafeKeys.MarshalBinary()
+	test.CheckNoErr(t, err, "marshal failed")
+	test.CheckNoErr(t, decodedKeys.UnmarshalBinary(enc), "unmarshal failed")
+	test.CheckIsErr(t, VerifySignShare(pub, &decodedKeys, signShares[0], msgPH), "should fail without safe primes")
+}
+
+func TestNewVerificationKeysParams(t *testing.T) {
+	key, err := GenerateKey(rand.Reader, 512)
+	test.CheckNoErr(t, err, "failed to create key")
+	shares, err := Deal(rand.Reader, 3, 2, key, false)
+	test.CheckNoErr(t, err, "deal failed")
+
+	_, err = NewVerificationKeys(rand.Reader, &key.PublicKey, shares[:2])
+	test.CheckIsErr(t, err, "should fail with missing shares")
+	shares[1].Index = 1
+	_, err = NewVerificationKeys(rand.Reader, &key.PublicKey, shares)
+	test.CheckIsErr(t, err, "should fail with repeated shares")
+}
+
+func BenchmarkVerifySignShare(b *testing.B) {
+	key, _ := GenerateKey(rand.Reader, 2048)
+	pub := &key.PublicKey
+	shares, _ := Deal(rand.Reader, 3, 2, key, true)
+	keys, _ := NewVerificationKeys(rand.Reader, pub, shares)
+	msgPH, _ := PadHash(&PKCS1v15Padder{}, crypto.SHA256, pub, []byte("hello"))
+	share, _ := shares[0].SignWithProof(rand.Reader, pub, keys, msgPH, true)
+
+	b.Run("SignWithProof", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = shares[0].SignWithProof(rand.Reader, pub, keys, msgPH, true)
+		}
+	})
+	b.Run("VerifySignShare", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = VerifySignShare(pub, keys, share, msgPH)
+		}
+	})
+}
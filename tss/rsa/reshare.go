@@ -0,0 +1,365 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// ReshareCommitment is the public part of the contribution of a current
+// player, the dealer, to a resharing. It is sent to all the new players.
+type ReshareCommitment struct {
+	// commitments are V^{a_k} for the coefficients a_k of the polynomial of the dealer.
+	commitments []*big.Int
+
+	Dealer uint
+
+	Players   uint
+	Threshold uint
+}
+
+// ReshareShare is the secret part of the contribution of a dealer to a
+// resharing. It must be sent privately to the new player with the given
+// index.
+type ReshareShare struct {
+	share *big.Int
+
+	Dealer uint
+	Index  uint
+}
+
+// Reshare runs the part of the dealer kshare in a resharing of the key to a
+// new set of players, with possibly different players and threshold. The
+// dealers must be at least kshare.Threshold current players, whose indices are
+// given in dealers, and all of them must call Reshare. It returns the
+// commitment to broadcast to the new players, and the shares for the new
+// players with indices 1 to players. See CombineReshares.
+//
+// The public key and the signatures are unchanged, but the new KeyShares
+// cannot be combined with the current ones. The current players must erase
+// their KeyShares once the new players have theirs: resharing only protects
+// against an adversary who learns fewer than threshold shares of a given
+// sharing.
+//
+// As the dealers do not know the order of the group, the shares of the new
+// players have no upper bound, and may be negative. If ed = 1 mod m, the new
+// shares are a sharing of aΔd + b, with aΔ + be = 1 and ∆ = l!, which is also
+// an inverse of e modulo m.
+//
+// The shares grow with each resharing: the constant terms are multiplied by
+// aλ_i, where a < e and λ_i is about ∆, and the other coefficients are κ bits
+// longer than the constant terms, with κ = 128. Thus the bit length of the
+// shares, and the cost of Sign and SignWithProof, grow by about
+// log2(e) + log2(∆) + κ + log2(∆') bits per resharing, where ∆' = players!,
+// that is about 160 bits with e = 65537 and 5 players. Where the private key
+// is available, a dealer can instead call Deal again to get short shares.
+func (kshare *KeyShare) Reshare(randSource io.Reader, pub *rsa.PublicKey, keys *VerificationKeys, dealers []uint, players, threshold uint) (ReshareCommitment, []ReshareShare, error) {
+	if err := validateParams(players, threshold); err != nil {
+		return ReshareCommitment{}, nil, err
+	}
+	if players > math.MaxUint16 {
+		return ReshareCommitment{}, nil, errors.New("rsa_threshold: reshare: Players is too big to fit in a uint16")
+	}
+	S, err := reshareDealers(dealers, kshare.Players, kshare.Threshold)
+	if err != nil {
+		return ReshareCommitment{}, nil, err
+	}
+	first := S[0].Index
+	found := false
+	for i := range S {
+		found = found || S[i].Index == kshare.Index
+	}
+	if !found {
+		return ReshareCommitment{}, nil, fmt.Errorf("rsa_threshold: reshare: index %d is not a dealer", kshare.Index)
+	}
+
+	// The constant term of the dealer is aλ_i s_i, plus b for the first
+	// dealer, so the constant terms add up to aΔd + b.
+	a, b, err := reshareCoefficients(kshare.Players, pub.E)
+	if err != nil {
+		return ReshareCommitment{}, nil, err
+	}
+	lambda, err := computeLambda(calculateDelta(int64(kshare.Players)), S, 0, int64(kshare.Index))
+	if err != nil {
+		return ReshareCommitment{}, nil, err
+	}
+	poly := make([]*big.Int, threshold)
+	poly[0] = new(big.Int).Mul(a, lambda)
+	poly[0].Mul(poly[0], kshare.si)
+	if kshare.Index == first {
+		poly[0].Add(poly[0], b)
+	}
+
+	// The other coefficients are in [0, ∆'2^{L+κ}), where L bounds the bit
+	// length of the constant term, so that fewer than threshold shares reveal
+	// nothing about it.
+	boundBits := pub.N.BitLen()
+	if poly[0].BitLen() > boundBits {
+		boundBits = poly[0].BitLen()
+	}
+	bound := calculateDelta(int64(players))
+	bound.Lsh(bound, uint(boundBits+dkgStatisticalSecurity))
+	for k := 1; k < len(poly); k++ {
+		poly[k], err = rand.Int(randSource, bound)
+		if err != nil {
+			return ReshareCommitment{}, nil, errors.New("rsa_threshold: reshare: unable to generate a random coefficient")
+		}
+	}
+
+	commitment := ReshareCommitment{
+		commitments: make([]*big.Int, threshold),
+		Dealer:      kshare.Index,
+		Players:     players,
+		Threshold:   threshold,
+	}
+	for k := range poly {
+		commitment.commitments[k] = new(big.Int).Exp(keys.V, poly[k], pub.N)
+	}
+	shares := make([]ReshareShare, players)
+	for j := range shares {
+		shares[j] = ReshareShare{
+			share:  evaluatePolynomial(poly, uint(j+1), nil),
+			Dealer: kshare.Index,
+			Index:  uint(j + 1),
+		}
+	}
+	return commitment, shares, nil
+}
+
+// CombineReshares returns the KeyShare of the new player with the given index
+// from the commitments of all the dealers and the shares sent to this player,
+// where shares[i] comes from the dealer of commitments[i], along with the new verification keys, which are the same for all the new
+// players. The commitments and shares are checked against the verification
+// keys of the current players, so that invalid contributions are detected. If
+// cache is true, cached values are stored in the KeyShare as for Deal.
+func CombineReshares(pub *rsa.PublicKey, keys *VerificationKeys, index uint, commitments []ReshareCommitment, shares []ReshareShare, cache bool) (KeyShare, *VerificationKeys, error) {
+	if len(commitments) == 0 || len(commitments) != len(shares) {
+		return KeyShare{}, nil, errors.New("rsa_threshold: reshare: expected one commitment and one share per dealer")
+	}
+	players := commitments[0].Players
+	threshold := commitments[0].Threshold
+	if err := validateParams(players, threshold); err != nil {
+		return KeyShare{}, nil, err
+	}
+	if index < 1 || index > players {
+		return KeyShare{}, nil, fmt.Errorf("rsa_threshold: reshare: index %d is not in [1, %d]", index, players)
+	}
+
+	oldPlayers := uint(len(keys.Vi))
+	dealers := make([]uint, len(commitments))
+	for i := range commitments {
+		dealers[i] = commitments[i].Dealer
+	}
+	S, err := reshareDealers(dealers, oldPlayers, keys.Threshold)
+	if err != nil {
+		return KeyShare{}, nil, err
+	}
+	a, b, err := reshareCoefficients(oldPlayers, pub.E)
+	if err != nil {
+		return KeyShare{}, nil, err
+	}
+	delta := calculateDelta(int64(oldPlayers))
+
+	si := new(big.Int)
+	newKeys := &VerificationKeys{V: keys.V, Vi: make([]*big.Int, players), Threshold: threshold, safePrimes: keys.safePrimes}
+	for j := range newKeys.Vi {
+		newKeys.Vi[j] = big.NewInt(1)
+	}
+	for i, com := range commitments {
+		if com.Players != players || com.Threshold != threshold || uint(len(com.commitments)) != threshold {
+			return KeyShare{}, nil, fmt.Errorf("rsa_threshold: reshare: inconsistent commitment from dealer %d", com.Dealer)
+		}
+		share := shares[i]
+		if share.Dealer != com.Dealer || share.Index != index {
+			return KeyShare{}, nil, fmt.Errorf("rsa_threshold: reshare: unexpected share from dealer %d", share.Dealer)
+		}
+
+		// The constant term must match the verification key of the dealer:
+		// V^{a_0} = v_i^{aλ_i}, times V^b for the first dealer.
+		lambda, err := computeLambda(delta, S, 0, int64(com.Dealer))
+		if err != nil {
+			return KeyShare{}, nil, err
+		}
+		want := new(big.Int).Exp(keys.Vi[com.Dealer-1], lambda.Mul(lambda, a), pub.N)
+		if com.Dealer == S[0].Index {
+			want.Mul(want, new(big.Int).Exp(keys.V, b, pub.N)).Mod(want, pub.N)
+		}
+		if com.commitments[0].Cmp(want) != 0 {
+			return KeyShare{}, nil, fmt.Errorf("rsa_threshold: reshare: invalid commitment from dealer %d", com.Dealer)
+		}
+
+		// V^{f_i(j)} = Π_k C_k^{j^k} for every new player j.
+		for j := range newKeys.Vi {
+			vj := evaluateCommitments(com.commitments, uint(j+1), pub.N)
+			if uint(j+1) == index {
+				got := new(big.Int).Exp(keys.V, share.share, pub.N)
+				if got == nil || got.Cmp(vj) != 0 {
+					return KeyShare{}, nil, fmt.Errorf("rsa_threshold: reshare: invalid share from dealer %d", com.Dealer)
+				}
+			}
+			newKeys.Vi[j].Mul(newKeys.Vi[j], vj).Mod(newKeys.Vi[j], pub.N)
+		}
+		si.Add(si, share.share)
+	}
+
+	kshare := KeyShare{si: si, Index: index, Players: players, Threshold: threshold}
+	if cache {
+		kshare.get2DeltaSi(int64(players))
+	}
+	return kshare, newKeys, nil
+}
+
+// reshareDealers checks that the dealers are at least threshold distinct
+// indices of current players, and returns them sorted as SignShares for
+// computeLambda.
+func reshareDealers(dealers []uint, players, threshold uint) ([]SignShare, error) {
+	if uint(len(dealers)) < threshold {
+		return nil, errors.New("rsa_threshold: reshare: insufficient dealers for the threshold")
+	}
+	sorted := append([]uint(nil), dealers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	S := make([]SignShare, len(sorted))
+	for i, d := range sorted {
+		if d < 1 || d > players || (i > 0 && d == sorted[i-1]) {
+			return nil, fmt.Errorf("rsa_threshold: reshare: invalid or repeated dealer index: %d", d)
+		}
+		S[i].Index = d
+	}
+	return S, nil
+}
+
+// reshareCoefficients returns a > 0 and b such that a∆ + be = 1, with ∆ = l!.
+func reshareCoefficients(players uint, e int) (a, b *big.Int, err error) {
+	delta := calculateDelta(int64(players))
+	bigE := big.NewInt(int64(e))
+	a = new(big.Int)
+	if a.ModInverse(delta, bigE) == nil {
+		return nil, nil, errors.New("rsa_threshold: reshare: public exponent is not coprime to l!")
+	}
+	b = new(big.Int).Mul(a, delta)
+	b.Sub(big.NewInt(1), b)
+	b.Quo(b, bigE)
+	return a, b, nil
+}
+
+// evaluateCommitments returns Π_k C_k^{x^k} mod n.
+func evaluateCommitments(commitments []*big.Int, x uint, n *big.Int) *big.Int {
+	bx := new(big.Int).SetUint64(uint64(x))
+	out := big.NewInt(1)
+	for k := len(commitments) - 1; k >= 0; k-- {
+		out.Exp(out, bx, n)
+		out.Mul(out, commitments[k]).Mod(out, n)
+	}
+	return out
+}
+
+// MarshalBinary encodes a ReshareCommitment into a byte array in a format readable by UnmarshalBinary.
+func (com *ReshareCommitment) MarshalBinary() ([]byte, error) {
+	// | Dealer: uint16 | Players: uint16 | Threshold: uint16 | (cLen: uint16 | c: []byte)* |
+	// with Threshold values c, all in big-endian.
+	if com.Dealer > math.MaxUint16 || com.Players > math.MaxUint16 || com.Threshold > math.MaxUint16 {
+		return nil, fmt.Errorf("rsa_threshold: reshare commitment marshall: Dealer, Players or Threshold is too big to fit in a uint16")
+	}
+	if uint(len(com.commitments)) != com.Threshold {
+		return nil, fmt.Errorf("rsa_threshold: reshare commitment marshall: expected Threshold commitments")
+	}
+
+	out := make([]byte, 6)
+	binary.BigEndian.PutUint16(out[0:2], uint16(com.Dealer))
+	binary.BigEndian.PutUint16(out[2:4], uint16(com.Players))
+	binary.BigEndian.PutUint16(out[4:6], uint16(com.Threshold))
+	for _, c := range com.commitments {
+		cBytes := c.Bytes()
+		if len(cBytes) > math.MaxUint16 {
+			return nil, fmt.Errorf("rsa_threshold: reshare commitment marshall: commitment is too big to fit it's length in a uint16")
+		}
+		out = binary.BigEndian.AppendUint16(out, uint16(len(cBytes)))
+		out = append(out, cBytes...)
+	}
+	return out, nil
+}
+
+// UnmarshalBinary recovers a ReshareCommitment from a slice of bytes, or returns an error if the encoding is invalid.
+func (com *ReshareCommitment) UnmarshalBinary(data []byte) error {
+	// | Dealer: uint16 | Players: uint16 | Threshold: uint16 | (cLen: uint16 | c: []byte)* |
+	// with Threshold values c, all in big-endian.
+	if len(data) < 6 {
+		return fmt.Errorf("rsa_threshold: reshare commitment unmarshall failed: data length was too short for reading Dealer, Players and Threshold")
+	}
+	dealer := binary.BigEndian.Uint16(data[0:2])
+	players := binary.BigEndian.Uint16(data[2:4])
+	threshold := binary.BigEndian.Uint16(data[4:6])
+	data = data[6:]
+
+	commitments := make([]*big.Int, threshold)
+	for k := range commitments {
+		if len(data) < 2 {
+			return fmt.Errorf("rsa_threshold: reshare commitment unmarshall failed: data length was too short for reading a commitment length")
+		}
+		cLen := binary.BigEndian.Uint16(data[0:2])
+		if cLen == 0 || len(data[2:]) < int(cLen) {
+			return fmt.Errorf("rsa_threshold: reshare commitment unmarshall failed: invalid commitment length: %d", cLen)
+		}
+		commitments[k] = new(big.Int).SetBytes(data[2 : 2+cLen])
+		data = data[2+cLen:]
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("rsa_threshold: reshare commitment unmarshall failed: unexpected trailing data")
+	}
+
+	com.commitments = commitments
+	com.Dealer = uint(dealer)
+	com.Players = uint(players)
+	com.Threshold = uint(threshold)
+	return nil
+}
+
+// MarshalBinary encodes a ReshareShare into a byte array in a format readable by UnmarshalBinary.
+func (share *ReshareShare) MarshalBinary() ([]byte, error) {
+	// | Dealer: uint16 | Index: uint16 | negative: bool | shareLen: uint16 | share: []byte |
+	// with all values in big-endian.
+	if share.Dealer > math.MaxUint16 || share.Index > math.MaxUint16 {
+		return nil, fmt.Errorf("rsa_threshold: reshare share marshall: Dealer or Index is too big to fit in a uint16")
+	}
+	shareBytes := share.share.Bytes()
+	if len(shareBytes) > math.MaxUint16 {
+		return nil, fmt.Errorf("rsa_threshold: reshare share marshall: share is too big to fit it's length in a uint16")
+	}
+
+	out := make([]byte, 7, 7+len(shareBytes))
+	binary.BigEndian.PutUint16(out[0:2], uint16(share.Dealer))
+	binary.BigEndian.PutUint16(out[2:4], uint16(share.Index))
+	if share.share.Sign() < 0 {
+		out[4] = 1
+	}
+	binary.BigEndian.PutUint16(out[5:7], uint16(len(shareBytes)))
+	return append(out, shareBytes...), nil
+}
+
+// UnmarshalBinary recovers a ReshareShare from a slice of bytes, or returns an error if the encoding is invalid.
+func (share *ReshareShare) UnmarshalBinary(data []byte) error {
+	// | Dealer: uint16 | Index: uint16 | negative: bool | shareLen: uint16 | share: []byte |
+	// with all values in big-endian.
+	if len(data) < 7 {
+		return fmt.Errorf("rsa_threshold: reshare share unmarshall failed: data length was too short for reading Dealer, Index and shareLen")
+	}
+	shareLen := binary.BigEndian.Uint16(data[5:7])
+	if data[4] > 1 || len(data[7:]) != int(shareLen) {
+		return fmt.Errorf("rsa_threshold: reshare share unmarshall failed: invalid share encoding")
+	}
+	s := new(big.Int).SetBytes(data[7:])
+	if data[4] == 1 {
+		s.Neg(s)
+	}
+
+	share.share = s
+	share.Dealer = uint(binary.BigEndian.Uint16(data[0:2]))
+	share.Index = uint(binary.BigEndian.Uint16(data[2:4]))
+	return nil
+}
@@ -0,0 +1,200 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+
+	"github.com/katzenpost/circl/internal/test"
+)
+
+// runReshare reshares the key from the given dealers to new players, and
+// returns the KeyShares and verification keys of the new players.
+func runReshare(t *testing.T, pub *rsa.PublicKey, keys *VerificationKeys, shares []KeyShare, dealers []uint, players, threshold uint) ([]KeyShare, *VerificationKeys) {
+	commitments := make([]ReshareCommitment, len(dealers))
+	sent := make([][]ReshareShare, len(dealers))
+	for i, d := range dealers {
+		var err error
+		commitments[i], sent[i], err = shares[d-1].Reshare(rand.Reader, pub, keys, dealers, players, threshold)
+		test.CheckNoErr(t, err, "reshare failed")
+	}
+
+	newShares := make([]KeyShare, players)
+	var newKeys *VerificationKeys
+	for j := range newShares {
+		received := make([]ReshareShare, len(dealers))
+		for i := range dealers {
+			received[i] = sent[i][j]
+		}
+		var jKeys *VerificationKeys
+		var err error
+		newShares[j], jKeys, err = CombineReshares(pub, keys, uint(j+1), commitments, received, j%2 == 0)
+		test.CheckNoErr(t, err, "combine reshares failed")
+		if newKeys == nil {
+			newKeys = jKeys
+		}
+		for k := range newKeys.Vi {
+			if newKeys.Vi[k].Cmp(jKeys.Vi[k]) != 0 {
+				t.Fatal("new players disagree on the verification keys")
+			}
+		}
+	}
+	return newShares, newKeys
+}
+
+func checkSigning(t *testing.T, pub *rsa.PublicKey, keys *VerificationKeys, shares []KeyShare) {
+	const algo = crypto.SHA256
+	msg := []byte("hello")
+	msgPH, err := PadHash(&PKCS1v15Padder{}, algo, pub, msg)
+	test.CheckNoErr(t, err, "padding failed")
+	h := algo.New()
+	h.Write(msg)
+	hashed := h.Sum(nil)
+
+	signShares := make([]SignShare, len(shares))
+	for i := range shares {
+		signShares[i], err = shares[i].SignWithProof(rand.Reader, pub, keys, msgPH, true)
+		test.CheckNoErr(t, err, "sign failed")
+		test.CheckNoErr(t, VerifySignShare(pub, keys, signShares[i], msgPH), "invalid sign share")
+	}
+	threshold := shares[0].Threshold
+	for _, set := range [][]SignShare{signShares[:threshold], signShares[uint(len(shares))-threshold:]} {
+		sig, err := CombineSignShares(pub, set, msgPH)
+		test.CheckNoErr(t, err, "combine failed")
+		test.CheckNoErr(t, rsa.VerifyPKCS1v15(pub, algo, hashed, sig), "verification failed")
+	}
+}
+
+func TestReshare(t *testing.T) {
+	// [Warning]: this is only for tests, use a secure bitlen above 2048 bits.
+	key, err := GenerateKey(rand.Reader, 512)
+	test.CheckNoErr(t, err, "failed to create key")
+	pub := &key.PublicKey
+	shares, err := Deal(rand.Reader, 5, 3, key, false)
+	test.CheckNoErr(t, err, "deal failed")
+	keys, err := NewVerificationKeys(rand.Reader, pub, shares)
+	test.CheckNoErr(t, err, "failed to create verification keys")
+
+	// Change of players and threshold, then a refresh of the new shares.
+	shares2, keys2 := runReshare(t, pub, keys, shares, []uint{1, 3, 4}, 4, 2)
+	checkSigning(t, pub, keys2, shares2)
+	shares3, keys3 := runReshare(t, pub, keys2, shares2, []uint{2, 3, 4}, 4, 2)
+	checkSigning(t, pub, keys3, shares3)
+	shares4, keys4 := runReshare(t, pub, keys3, shares3, []uint{4, 1}, 3, 1)
+	checkSigning(t, pub, keys4, shares4)
+
+	// Old shares cannot be combined with the new ones.
+	msgPH, _ := PadHash(&PKCS1v15Padder{}, crypto.SHA256, pub, []byte("hello"))
+	old, _ := shares2[0].Sign(rand.Reader, pub, msgPH, true)
+	current, _ := shares3[1].Sign(rand.Reader, pub, msgPH, true)
+	_, err = CombineSignShares(pub, []SignShare{old, current}, msgPH)
+	test.CheckIsErr(t, err, "should fail mixing old and new shares")
+
+	// New shares survive encoding.
+	for i := range shares4 {
+		enc, err := shares4[i].MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		test.CheckNoErr(t, shares4[i].UnmarshalBinary(enc), "unmarshal failed")
+	}
+	checkSigning(t, pub, keys4, shares4)
+}
+
+func TestReshareChain(t *testing.T) {
+	const players = 5
+	const threshold = 3
+	const rounds = 6
+	key, err := GenerateKey(rand.Reader, 512)
+	test.CheckNoErr(t, err, "failed to create key")
+	pub := &key.PublicKey
+	shares, err := Deal(rand.Reader, players, threshold, key, false)
+	test.CheckNoErr(t, err, "deal failed")
+	keys, err := NewVerificationKeys(rand.Reader, pub, shares)
+	test.CheckNoErr(t, err, "failed to create verification keys")
+
+	// Each resharing adds at most log2(e) + log2(∆) + κ + log2(∆') bits, plus
+	// a few bits for λ_i and the evaluation of the polynomials.
+	delta := calculateDelta(players).BitLen()
+	growth := big.NewInt(int64(pub.E)).BitLen() + 2*delta + dkgStatisticalSecurity + 16
+	maxBits := func(shares []KeyShare) int {
+		bits := 0
+		for i := range shares {
+			if shares[i].si.BitLen() > bits {
+				bits = shares[i].si.BitLen()
+			}
+		}
+		return bits
+	}
+
+	bits := maxBits(shares)
+	for r := 0; r < rounds; r++ {
+		dealers := []uint{uint(r%players) + 1, uint((r+1)%players) + 1, uint((r+3)%players) + 1}
+		shares, keys = runReshare(t, pub, keys, shares, dealers, players, threshold)
+		newBits := maxBits(shares)
+		test.CheckOk(newBits <= bits+growth, "shares grew more than expected", t)
+		bits = newBits
+	}
+	checkSigning(t, pub, keys, shares)
+
+	// Dealing again from the private key gives short shares.
+	shares, err = Deal(rand.Reader, players, threshold, key, false)
+	test.CheckNoErr(t, err, "deal failed")
+	test.CheckOk(maxBits(shares) <= pub.N.BitLen(), "dealt shares are too long", t)
+}
+
+func TestReshareInvalid(t *testing.T) {
+	key, err := GenerateKey(rand.Reader, 512)
+	test.CheckNoErr(t, err, "failed to create key")
+	pub := &key.PublicKey
+	shares, _ := Deal(rand.Reader, 3, 2, key, false)
+	keys, _ := NewVerificationKeys(rand.Reader, pub, shares)
+
+	dealers := []uint{1, 2}
+	_, _, err = shares[0].Reshare(rand.Reader, pub, keys, dealers[:1], 3, 2)
+	test.CheckIsErr(t, err, "should fail with too few dealers")
+	_, _, err = shares[2].Reshare(rand.Reader, pub, keys, dealers, 3, 2)
+	test.CheckIsErr(t, err, "should fail if not a dealer")
+	_, _, err = shares[0].Reshare(rand.Reader, pub, keys, []uint{1, 1}, 3, 2)
+	test.CheckIsErr(t, err, "should fail with repeated dealers")
+
+	coms := make([]ReshareCommitment, 2)
+	sent := make([][]ReshareShare, 2)
+	for i, d := range dealers {
+		coms[i], sent[i], err = shares[d-1].Reshare(rand.Reader, pub, keys, dealers, 3, 2)
+		test.CheckNoErr(t, err, "reshare failed")
+	}
+	received := []ReshareShare{sent[0][1], sent[1][1]}
+	_, _, err = CombineReshares(pub, keys, 2, coms, received, false)
+	test.CheckNoErr(t, err, "combine reshares failed")
+
+	// A dealer sending an invalid share is identified.
+	bad := received[1]
+	bad.share = new(big.Int).Add(bad.share, big.NewInt(1))
+	_, _, err = CombineReshares(pub, keys, 2, coms, []ReshareShare{received[0], bad}, false)
+	test.CheckIsErr(t, err, "should fail with an invalid share")
+
+	// A dealer sharing another value is identified.
+	forged, forgedSent, _ := shares[1].Reshare(rand.Reader, pub, keys, []uint{2, 3}, 3, 2)
+	forged.Dealer = 2
+	_, _, err = CombineReshares(pub, keys, 2, []ReshareCommitment{coms[0], forged}, []ReshareShare{received[0], forgedSent[1]}, false)
+	test.CheckIsErr(t, err, "should fail with an invalid commitment")
+
+	// Contributions must come from enough dealers.
+	_, _, err = CombineReshares(pub, keys, 2, coms[:1], received[:1], false)
+	test.CheckIsErr(t, err, "should fail with too few dealers")
+
+	// Commitments and shares survive encoding.
+	for i := range coms {
+		enc, err := coms[i].MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		test.CheckNoErr(t, coms[i].UnmarshalBinary(enc), "unmarshal failed")
+		test.CheckIsErr(t, coms[i].UnmarshalBinary(enc[:len(enc)-1]), "should fail with a truncated commitment")
+		enc, err = received[i].MarshalBinary()
+		test.CheckNoErr(t, err, "marshal failed")
+		test.CheckNoErr(t, received[i].UnmarshalBinary(enc), "unmarshal failed")
+		test.CheckIsErr(t, received[i].UnmarshalBinary(enc[:len(enc)-1]), "should fail with a truncated share")
+	}
+	_, _, err = CombineReshares(pub, keys, 2, coms, received, false)
+	test.CheckNoErr(t, err, "combine reshares failed")
+}
@@ -18,7 +18,7 @@ import (
 	"math"
 	"math/big"
 
-	cmath "github.com/cloudflare/circl/math"
+	cmath "github.com/katzenpost/circl/math"
 )
 
 // GenerateKey generates a RSA keypair for its use in RSA threshold signatures.
@@ -302,8 +302,8 @@ func computeLambda(delta *big.Int, S []SignShare, i, j int64) (*big.Int, error)
 
 	// λ(s, i, j) = ∆( (  π{j'∈S\{j}} (i - j')  ) /  (  π{j'∈S\{j}} (j - j') ) )
 
-	num := int64(1)
-	den := int64(1)
+	num := big.NewInt(1)
+	den := big.NewInt(1)
 
 	// ∈ S
 	for _, s := range S {
@@ -319,17 +319,17 @@ func computeLambda(delta *big.Int, S []SignShare, i, j int64) (*big.Int, error)
 			break
 		}
 		//  (i - j')
-		num *= i - jprime
+		num.Mul(num, big.NewInt(i-jprime))
 		// (j - j')
-		den *= j - jprime
+		den.Mul(den, big.NewInt(j-jprime))
 	}
 
 	// ∆ * (num/den)
 	var lambda big.Int
-	// (num/den)
-	lambda.Div(big.NewInt(num), big.NewInt(den))
-	// ∆ * (num/den)
-	lambda.Mul(delta, &lambda)
+	// ∆ * num
+	lambda.Mul(delta, num)
+	// (∆ * num)/den, which is an integer as ∆ = l! and den divides it
+	lambda.Quo(&lambda, den)
 
 	if foundi {
 		return nil, fmt.Errorf("rsa_threshold: i: %d should not be in S", i)
@@ -12,7 +12,7 @@ import (
 	"math/big"
 	"testing"
 
-	"github.com/cloudflare/circl/internal/test"
+	"github.com/katzenpost/circl/internal/test"
 )
 
 var ONE = big.NewInt(1)
@@ -95,6 +95,54 @@ func TestComputeLambda(t *testing.T) {
 	}
 }
 
+func TestComputeLambdaFraction(t *testing.T) {
+	// shares = {1, 3}
+	// i = 0
+	// ∆ = 3! = 6
+	//
+	// j = 1: num/den = (0 - 3) / (1 - 3) = 3/2, and ∆ * 3/2 = 9
+	// j = 3: num/den = (0 - 1) / (3 - 1) = -1/2, and ∆ * -1/2 = -3
+	//
+	// The quotients are not integers, so ∆ must be multiplied first.
+	shares := []SignShare{{Index: 1}, {Index: 3}}
+	delta := big.NewInt(6)
+	for _, v := range []struct{ j, want int64 }{{1, 9}, {3, -3}} {
+		lambda, err := computeLambda(delta, shares, 0, v.j)
+		if err != nil || lambda.Cmp(big.NewInt(v.want)) != 0 {
+			t.Fatalf("computeLambda failed: j=%v got=%v want=%v", v.j, lambda, v.want)
+		}
+	}
+}
+
+func TestCombineNonConsecutiveShares(t *testing.T) {
+	// Players 1 and 3 of a 2-of-3 setup have Lagrange coefficients that
+	// are not integers, unlike players 1 and 2.
+	const players, threshold = 3, 2
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	test.CheckNoErr(t, err, "failed to create key")
+	keys, err := Deal(rand.Reader, players, threshold, key, false)
+	test.CheckNoErr(t, err, "failed to deal shares")
+
+	msg := []byte("hello")
+	pub := &key.PublicKey
+	padder := &PKCS1v15Padder{}
+	msgPH, err := PadHash(padder, crypto.SHA256, pub, msg)
+	test.CheckNoErr(t, err, "failed to pad message")
+	shares := []SignShare{}
+	for _, i := range []int{0, 2} {
+		share, err := keys[i].Sign(rand.Reader, pub, msgPH, false)
+		test.CheckNoErr(t, err, "failed to sign share")
+		shares = append(shares, share)
+	}
+	sig, err := CombineSignShares(pub, shares, msgPH)
+	test.CheckNoErr(t, err, "failed to combine shares")
+
+	h := crypto.SHA256.New()
+	h.Write(msg)
+	err = rsa.VerifyPKCS1v15(pub, crypto.SHA256, h.Sum(nil), sig)
+	test.CheckNoErr(t, err, "signature does not verify")
+}
+
 func TestDeal(t *testing.T) {
 	// Players = 3
 	// Threshold = 2
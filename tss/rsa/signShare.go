@@ -11,6 +11,10 @@ import (
 type SignShare struct {
 	xi *big.Int
 
+	// c and z are the proof of correctness of xi, which are nil if the SignShare was generated by Sign instead of SignWithProof.
+	c *big.Int
+	z *big.Int
+
 	Index uint
 
 	Players   uint
@@ -26,6 +30,8 @@ func (s SignShare) String() string {
 // Note: Only Index's up to math.MaxUint16 are supported
 func (s *SignShare) MarshalBinary() ([]byte, error) {
 	// | Players: uint16 | Threshold: uint16 | Index: uint16 | xiLen: uint16 | xi: []byte |
+	// followed, if the share has a proof, by
+	// | cLen: uint16 | c: []byte | zLen: uint16 | z: []byte |
 
 	if s.Players > math.MaxUint16 {
 		return nil, fmt.Errorf("rsa_threshold: signshare marshall: Players is too big to fit in a uint16")
@@ -66,12 +72,25 @@ func (s *SignShare) MarshalBinary() ([]byte, error) {
 
 	copy(out[8:8+xiLen], xiBytes)
 
+	if s.c != nil && s.z != nil {
+		for _, v := range []*big.Int{s.c, s.z} {
+			vBytes := v.Bytes()
+			if len(vBytes) > math.MaxUint16 {
+				return nil, fmt.Errorf("rsa_threshold: signshare marshall: proof is too big to fit it's length in a uint16")
+			}
+			out = binary.BigEndian.AppendUint16(out, uint16(len(vBytes)))
+			out = append(out, vBytes...)
+		}
+	}
+
 	return out, nil
 }
 
 // UnmarshalBinary converts a byte array outputted from Marshall into a SignShare or returns an error if the value is invalid
 func (s *SignShare) UnmarshalBinary(data []byte) error {
 	// | Players: uint16 | Threshold: uint16 | Index: uint16 | xiLen: uint16 | xi: []byte |
+	// followed, if the share has a proof, by
+	// | cLen: uint16 | c: []byte | zLen: uint16 | z: []byte |
 	if len(data) < 8 {
 		return fmt.Errorf("rsa_threshold: signshare unmarshalKeyShareTest failed: data length was too short for reading Players, Threshold, Index, and xiLen")
 	}
@@ -94,10 +113,30 @@ func (s *SignShare) UnmarshalBinary(data []byte) error {
 	copy(bytes, data[8:8+xiLen])
 	xi.SetBytes(bytes)
 
+	var proof [2]*big.Int
+	if rest := data[8+xiLen:]; len(rest) != 0 {
+		for i := range proof {
+			if len(rest) < 2 {
+				return fmt.Errorf("rsa_threshold: signshare unmarshalKeyShareTest failed: data length was too short for reading the proof length")
+			}
+			vLen := binary.BigEndian.Uint16(rest[0:2])
+			if len(rest[2:]) < int(vLen) {
+				return fmt.Errorf("rsa_threshold: signshare unmarshalKeyShareTest failed: data length was too short for reading the proof, needed: %d found: %d", vLen, len(rest[2:]))
+			}
+			proof[i] = new(big.Int).SetBytes(rest[2 : 2+vLen])
+			rest = rest[2+vLen:]
+		}
+		if len(rest) != 0 {
+			return fmt.Errorf("rsa_threshold: signshare unmarshalKeyShareTest failed: unexpected trailing data")
+		}
+	}
+
 	s.Players = uint(players)
 	s.Threshold = uint(threshold)
 	s.Index = uint(index)
 	s.xi = &xi
+	s.c = proof[0]
+	s.z = proof[1]
 
 	return nil
 }